@@ -18,9 +18,198 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/openapi.json": {
+            "get": {
+                "description": "Get the OpenAPI (Swagger 2.0) document describing every endpoint, the same document the Swagger UI shows. Robot endpoints of warehouse zones are served under /warehouses/{zone} with the same paths.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documentation"
+                ],
+                "summary": "Get the OpenAPI document",
+                "responses": {
+                    "200": {
+                        "description": "OpenAPI document",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/aborts": {
+            "get": {
+                "description": "List the latest aborted tasks newest first, with the reason, the error, the command the task stopped at and the robot position, for triaging failures. Purged tasks are not listed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "List recent aborts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of aborts, default 20, max 500",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Aborted tasks, newest first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/robot.AbortSummary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/cell": {
+            "get": {
+                "description": "Report whether a cell is inside the warehouse, holds an obstacle or is occupied by the robot",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Get the occupancy of a cell",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "X coordinate",
+                        "name": "x",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Y coordinate",
+                        "name": "y",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cell occupancy",
+                        "schema": {
+                            "$ref": "#/definitions/robot.CellInfo"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or invalid coordinates",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/cell/history": {
+            "get": {
+                "description": "List every move of a task that brought the robot onto a cell, with the task ID, the command index and the time, oldest first, e.g. to investigate an incident. Cells passed by a command with a step size above 1 count as well. Purged tasks are not included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Get the history of a cell",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "X coordinate",
+                        "name": "x",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Y coordinate",
+                        "name": "y",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Moves onto the cell",
+                        "schema": {
+                            "$ref": "#/definitions/api.CellHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or invalid coordinates, or a cell outside the warehouse",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/checkpoint": {
+            "post": {
+                "description": "Record the current robot position as the checkpoint, replacing the previous one. A task added with POST /robot/tasks/return-to-checkpoint moves the robot back to it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Set the checkpoint",
+                "responses": {
+                    "200": {
+                        "description": "The checkpoint",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Robot outside the warehouse",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/config": {
+            "get": {
+                "description": "Get the configuration the server actually runs with, e.g. to verify a deployment: the warehouse size, the default delay, the robot service settings and the API settings. Secrets such as credentials in the webhook URL are redacted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Get the effective configuration",
+                "responses": {
+                    "200": {
+                        "description": "Effective configuration",
+                        "schema": {
+                            "$ref": "#/definitions/api.ConfigResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/robot/events": {
             "get": {
-                "description": "Establishes a WebSocket connection to receive real-time task status updates. This endpoint requires a WebSocket client (not accessible via Swagger UI). Use tools like Postman, wscat, or the provided HTML test page.",
+                "description": "Establishes a WebSocket connection to receive real-time task status updates. Clients can cancel tasks by sending {\"action\":\"cancel\",\"task_id\":\"...\",\"id\":\"optional\"} and receive an ack. This endpoint requires a WebSocket client (not accessible via Swagger UI). Use tools like Postman, wscat, or the provided HTML test page.",
                 "produces": [
                     "application/json"
                 ],
@@ -28,6 +217,20 @@ const docTemplate = `{
                     "Robot Events"
                 ],
                 "summary": "WebSocket endpoint for real-time task status updates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Sequence number of the last event seen, missed events are replayed or a resync marker is sent",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event format: verbose (default) for full events or delta for WebSocketDelta messages with only the changed fields",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "101": {
                         "description": "WebSocket connection established, events will be sent as JSON",
@@ -36,7 +239,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Failed to upgrade connection",
+                        "description": "Failed to upgrade connection, invalid since or invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Maximum number of subscribers reached",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -44,29 +253,128 @@ const docTemplate = `{
                 }
             }
         },
-        "/robot/state": {
+        "/robot/grid": {
             "get": {
-                "description": "Get the current state of the robot service including robot position, task count and tasks",
+                "description": "Render the warehouse with the robot and the obstacles as JSON, as ASCII text ('R' robot, '#' obstacle, '.' free, north at the top) or as an SVG image, chosen by the Accept header",
+                "produces": [
+                    "application/json",
+                    "text/plain",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Get the warehouse grid",
+                "responses": {
+                    "200": {
+                        "description": "Warehouse grid",
+                        "schema": {
+                            "$ref": "#/definitions/robot.Grid"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the grid formats is acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/groups/{id}": {
+            "get": {
+                "description": "Get the number of tasks per state and the tasks of a group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Get a task group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Group summary",
+                        "schema": {
+                            "$ref": "#/definitions/robot.GroupSummary"
+                        }
+                    },
+                    "404": {
+                        "description": "No task belongs to the group",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/groups/{id}/cancel": {
+            "put": {
+                "description": "Cancel all pending and running tasks of a group, finished tasks are left alone",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Cancel a task group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Number of cancelled tasks and the group summary",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "No task belongs to the group",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/heatmap": {
+            "get": {
+                "description": "Get the cumulative time the robot spent in every cell of the warehouse since the service started, for heat-map analytics",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Robot State"
                 ],
-                "summary": "Get the current state of the robot service",
+                "summary": "Get the dwell time heat map",
                 "responses": {
                     "200": {
-                        "description": "Current state of the robot service",
+                        "description": "Seconds per cell, indexed [y][x]",
                         "schema": {
-                            "$ref": "#/definitions/robot.ServiceState"
+                            "$ref": "#/definitions/robot.Heatmap"
                         }
                     }
                 }
             }
         },
-        "/robot/tasks": {
-            "post": {
-                "description": "Add a new robot task with commands and optional delay",
+        "/robot/position": {
+            "put": {
+                "description": "Move the robot directly to the given cell without running commands. Only available when the server runs with -debug.",
                 "consumes": [
                     "application/json"
                 ],
@@ -74,32 +382,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Robot Tasks"
+                    "Robot State"
                 ],
-                "summary": "Add a new robot task",
+                "summary": "Force the robot position (debug only)",
                 "parameters": [
                     {
-                        "description": "Add Task Request",
+                        "description": "Set Position Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.AddTaskRequest"
+                            "$ref": "#/definitions/api.SetPositionRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Task ID",
+                    "200": {
+                        "description": "Robot state after the move",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/robot.RobotState"
                         }
                     },
                     "400": {
-                        "description": "Error message",
+                        "description": "Invalid or out of bounds position",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -107,75 +412,2441 @@ const docTemplate = `{
                 }
             }
         },
-        "/robot/tasks/{id}/cancel": {
-            "put": {
-                "description": "Cancel a robot task by its ID, if the task is in progress or pending",
-                "tags": [
-                    "Robot Tasks"
-                ],
-                "summary": "Cancel a robot task by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "202": {
-                        "description": "Cancellation request accepted",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+        "/robot/queue": {
+            "get": {
+                "description": "Get the pending tasks in the order they will be dispatched, with command counts and estimated start times",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Get the pending task queue",
+                "responses": {
+                    "200": {
+                        "description": "Pending tasks in dispatch order",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/robot.QueuedTask"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/queue/eta": {
+            "get": {
+                "description": "Get the estimated time until the running task and all pending tasks are finished, and the projected finish time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Get the queue ETA",
+                "responses": {
+                    "200": {
+                        "description": "Queue estimate",
+                        "schema": {
+                            "$ref": "#/definitions/robot.QueueETA"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/quiesce": {
+            "post": {
+                "description": "Stop accepting new tasks (further submissions get 503) while queued tasks are processed to completion. The mode is reported in the state.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Quiesce the service",
+                "responses": {
+                    "202": {
+                        "description": "Current mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/reachable": {
+            "get": {
+                "description": "Get the cells the robot can reach within the given number of moves, honoring the warehouse bounds and obstacles",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Get the reachable area",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of moves",
+                        "name": "steps",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reachable cells",
+                        "schema": {
+                            "$ref": "#/definitions/api.ReachableResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid steps",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/reset": {
+            "post": {
+                "description": "Move the robot back to the origin facing the configured initial heading, rejected while a task is running",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Reset the robot position",
+                "responses": {
+                    "200": {
+                        "description": "Robot state after the reset",
+                        "schema": {
+                            "$ref": "#/definitions/robot.RobotState"
+                        }
+                    },
+                    "409": {
+                        "description": "A task is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/restore": {
+            "post": {
+                "description": "Replace the robot position and all tasks with a snapshot from the snapshot endpoint, rejected while a task is running. The configuration in the snapshot is not restored, pending tasks are queued again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Restore the service state from a snapshot",
+                "parameters": [
+                    {
+                        "description": "Snapshot to restore",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/robot.Snapshot"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Service state after the restore",
+                        "schema": {
+                            "$ref": "#/definitions/robot.ServiceState"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid snapshot",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A task is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many pending tasks for the queue",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/snapshot": {
+            "get": {
+                "description": "Get the complete serializable state of the service (robot position, all tasks and configuration), which can be passed to the restore endpoint",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Get a snapshot of the service state",
+                "responses": {
+                    "200": {
+                        "description": "Complete service state",
+                        "schema": {
+                            "$ref": "#/definitions/robot.Snapshot"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/state": {
+            "get": {
+                "description": "Get the current state of the robot service including robot position, task count and tasks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Get the current state of the robot service",
+                "responses": {
+                    "200": {
+                        "description": "Current state of the robot service",
+                        "schema": {
+                            "$ref": "#/definitions/robot.ServiceState"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/stats": {
+            "get": {
+                "description": "Get health and diagnostic information, e.g. whether a task is stuck or events were dropped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot State"
+                ],
+                "summary": "Get service diagnostics",
+                "responses": {
+                    "200": {
+                        "description": "Service diagnostics",
+                        "schema": {
+                            "$ref": "#/definitions/robot.ServiceStats"
+                        }
+                    },
+                    "503": {
+                        "description": "Service diagnostics of an unhealthy service",
+                        "schema": {
+                            "$ref": "#/definitions/robot.ServiceStats"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks": {
+            "get": {
+                "description": "List tasks ordered by sequence number. Pass the returned next_cursor as cursor to fetch the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "List robot tasks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Sequence number of the last task of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of tasks per page, default 50, max 500",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of tasks",
+                        "schema": {
+                            "$ref": "#/definitions/robot.TaskPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor or limit",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new robot task with commands, optional delay and optional priority. Clients that cannot send JSON can pass the commands and the delay as query parameters instead of the body, e.g. ?commands=N+E+S+W\u0026delay=1s",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Add a new robot task",
+                "parameters": [
+                    {
+                        "description": "Add Task Request, required unless the commands are passed as query parameter",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.AddTaskRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Commands to be executed, instead of the request body",
+                        "name": "commands",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delay between executing commands, only with the commands query parameter",
+                        "name": "delay",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Task ID, normalized commands and optionally the robot state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Error message",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Task queue is full or request timed out",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove all completed, canceled and aborted tasks, e.g. to make room when the task limit is reached",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Purge finished tasks",
+                "responses": {
+                    "200": {
+                        "description": "Number of purged tasks",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/batch": {
+            "post": {
+                "description": "Enqueue a batch of dependent tasks all together or not at all. The tasks are validated against the robot positions projected from the queued tasks and the earlier tasks of the batch, the first invalid task rejects the whole batch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Add several tasks atomically",
+                "parameters": [
+                    {
+                        "description": "Add Batch Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.AddBatchRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Task IDs in submission order and optionally the robot state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task, the whole batch was rejected",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Task queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/compact": {
+            "post": {
+                "description": "Add a task whose commands are sent as base64 with 2 bits per command, for clients sending many commands at a high rate. The stream is a uvarint command count followed by the commands packed four per byte, most significant bits first, with N=0, E=1, S=2, W=3 and zero padding bits.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Add a task from a compact command stream",
+                "parameters": [
+                    {
+                        "description": "Base64 compact command stream",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delay between executing commands, e.g. 1s",
+                        "name": "delay_between_commands",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Task ID, normalized commands and optionally the robot state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed command stream or invalid task",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Task queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/follow": {
+            "post": {
+                "description": "Enqueue a task moving the robot toward a target cell one move at a time, re-planning the shortest path around obstacles after every move. The target can be moved or cleared while the task runs. The task completes when the robot reaches the target or the target is cleared, and aborts with reason no_path if the target cannot be reached.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Add a follow task",
+                "parameters": [
+                    {
+                        "description": "Follow Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.FollowRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Task ID and optionally the robot state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Target outside the warehouse or on an obstacle",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Task queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/import": {
+            "post": {
+                "description": "Enqueue every row of a CSV with the header commands,delay,priority,labels as a task, e.g. a job list exported from a spreadsheet. The CSV is the request body or the file field of a multipart form. Empty delay and priority cells use the defaults, labels are separated by semicolons. Rows are imported independently, a malformed or invalid row is reported with its line without affecting the others.",
+                "consumes": [
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Import tasks from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file, if not sent as the request body",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Outcome of every row",
+                        "schema": {
+                            "$ref": "#/definitions/api.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or invalid header, no rows or too many rows",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/patrol": {
+            "post": {
+                "description": "Generate and enqueue the commands for a closed rectangular loop starting and ending at the robot's current position. The width and height count commands, each moving the robot the configured step size. A loop leaving the warehouse or crossing an obstacle is rejected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Add a rectangular patrol task",
+                "parameters": [
+                    {
+                        "description": "Add Patrol Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.AddPatrolRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Task ID and optionally the robot state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "The loop does not fit the warehouse or crosses an obstacle",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Task queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/ping": {
+            "post": {
+                "description": "Enqueue a task without commands that is dispatched, publishes its events and completes like any other task without moving the robot, for end-to-end health checks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Add a ping task",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Task ID and optionally the robot state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Task queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/return-to-checkpoint": {
+            "post": {
+                "description": "Enqueue a task moving the robot back to the checkpoint along the shortest path around obstacles. The path is planned once the task is dispatched, so it starts from wherever the earlier tasks left the robot. The task is a follow task targeting the checkpoint.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Add a task returning to the checkpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delay between moves, e.g. 1s",
+                        "name": "delay_between_commands",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Task ID and optionally the robot state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "No checkpoint set",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Task queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/run-to-wall": {
+            "post": {
+                "description": "Enqueue the moves from the current position in a direction until the warehouse boundary or an obstacle, e.g. for calibration",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Add a run-to-wall task",
+                "parameters": [
+                    {
+                        "enum": [
+                            "N",
+                            "E",
+                            "S",
+                            "W"
+                        ],
+                        "type": "string",
+                        "description": "Direction to move in",
+                        "name": "dir",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delay between executing commands, e.g. 1s",
+                        "name": "delay_between_commands",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Task ID and optionally the robot state",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid direction or robot already at the wall",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Task queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/status": {
+            "post": {
+                "description": "Look up the states of a list of tasks in one call, unknown IDs are reported as NotFound",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Get the states of several tasks",
+                "parameters": [
+                    {
+                        "description": "Task IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.TaskStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "State of every requested task",
+                        "schema": {
+                            "$ref": "#/definitions/api.TaskStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/{id}/cancel": {
+            "put": {
+                "description": "Cancel a robot task by its ID, if the task is in progress or pending. Cancelling an already canceled task succeeds again, cancelling a completed or aborted task is a conflict.",
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Cancel a robot task by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Cancellation request accepted or task already canceled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Error message",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task already completed or aborted",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/{id}/replace": {
+            "put": {
+                "description": "Cancel a pending or running task and enqueue a new task with the given commands in one step. The new task keeps the priority and the queue position of the replaced one, so no other task is dispatched in between. Replacing a finished task is a conflict.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Replace a robot task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Commands of the replacement task",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ReplaceTaskRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "ID of the new task and of the replaced task",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid commands",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task already finished or being cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Task queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/{id}/target": {
+            "put": {
+                "description": "Move the target of a pending or running follow task, the robot re-routes toward it before its next move",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Move the target of a follow task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New target, the delay is ignored",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.FollowRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task ID and the new target",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Target outside the warehouse or on an obstacle",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not a follow task or the task already finished",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Clear the target of a pending or running follow task, which completes it with reason target_cleared before its next move",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Clear the target of a follow task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not a follow task or the task already finished",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/{id}/trace.csv": {
+            "get": {
+                "description": "Download the executed commands of a task with the resulting robot positions as CSV. Every command has the time its step began, before the delay preceding it, and the time it finished, so duration_ms is the real time it took including clamped delays, the speed ramp and retries.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Download a task trace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV with the columns index, command, x, y, timestamp, started, duration_ms",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/{id}/uncancel": {
+            "post": {
+                "description": "Reverse the cancel of a task that was pending when it was cancelled, if the service runs with an uncancel window and it has not passed yet. The task is Pending again and keeps its priority and queue position.",
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Restore a cancelled task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the current robot state in the response",
+                        "name": "include_state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Task restored",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task not cancelled while pending, or the cancel is final",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Task queue is full or the service is quiescing",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/tasks/{id}/webhook": {
+            "get": {
+                "description": "Get whether the completion webhook of a task is pending, delivered, failed after all retries or dropped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Tasks"
+                ],
+                "summary": "Get the webhook delivery status of a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delivery status",
+                        "schema": {
+                            "$ref": "#/definitions/robot.DeliveryStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "No webhook queued for the task",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/robot/telemetry": {
+            "get": {
+                "description": "Establishes a WebSocket connection sending the current robot position and heading at a fixed rate, whether or not tasks run. The first message is sent right away. Task events are not sent, use /robot/events for them. This endpoint requires a WebSocket client (not accessible via Swagger UI).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Robot Events"
+                ],
+                "summary": "WebSocket endpoint for periodic robot telemetry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Time between messages, e.g. 500ms, at least 10ms, defaults to the -telemetry-interval setting",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "WebSocket connection established, positions will be sent as JSON",
+                        "schema": {
+                            "$ref": "#/definitions/api.TelemetryMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid interval or failed to upgrade connection",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Maximum number of subscribers reached",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses": {
+            "get": {
+                "description": "List the names of the independent warehouse zones served under /warehouses/{zone}/robot",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Warehouses"
+                ],
+                "summary": "List warehouse zones",
+                "responses": {
+                    "200": {
+                        "description": "Zone names",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses/broadcast": {
+            "post": {
+                "description": "Enqueue the same commands in every listed zone, or in all zones. Each zone validates the task against the projected position of its own robot, so some zones may reject it while others run it. An unknown zone rejects the whole request before anything is enqueued.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Warehouses"
+                ],
+                "summary": "Broadcast a task to several zones",
+                "parameters": [
+                    {
+                        "description": "Broadcast Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BroadcastRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Outcome per zone",
+                        "schema": {
+                            "$ref": "#/definitions/api.BroadcastResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error message",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The server is a read-only observer",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown zone",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "A zone has not started processing its queue yet",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.AddBatchRequest": {
+            "description": "Request body for adding several tasks that are enqueued all together or not at all",
+            "type": "object",
+            "required": [
+                "tasks"
+            ],
+            "properties": {
+                "tasks": {
+                    "description": "Tasks in submission order, at most 100",
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api.AddTaskRequest"
+                    }
+                }
+            }
+        },
+        "api.AddPatrolRequest": {
+            "description": "Request body for adding a rectangular patrol task",
+            "type": "object",
+            "required": [
+                "height",
+                "width"
+            ],
+            "properties": {
+                "delay_between_commands": {
+                    "description": "Delay between executing commands, optional",
+                    "type": "string",
+                    "example": "1s"
+                },
+                "height": {
+                    "description": "Height of the rectangle in commands, at most 9, less with a larger step size",
+                    "type": "integer",
+                    "maximum": 9,
+                    "example": 2
+                },
+                "width": {
+                    "description": "Width of the rectangle in commands, at most 9, less with a larger step size",
+                    "type": "integer",
+                    "maximum": 9,
+                    "example": 3
+                }
+            }
+        },
+        "api.AddTaskRequest": {
+            "description": "Request body for adding a new robot task",
+            "type": "object",
+            "properties": {
+                "case_insensitive": {
+                    "description": "Accept the commands in any case, e.g. \"n e s w\", optional. Always on if the service is configured so",
+                    "type": "boolean",
+                    "example": false
+                },
+                "commands": {
+                    "description": "Commands to be executed by the robot, empty only if the service allows no-op tasks",
+                    "type": "string",
+                    "example": "N E S W"
+                },
+                "delay_between_commands": {
+                    "description": "Delay between executing commands, optional",
+                    "type": "string",
+                    "example": "1s"
+                },
+                "frame": {
+                    "description": "Whether N, E, S and W are grid directions or relative to the robot heading, the service default if omitted",
+                    "type": "string",
+                    "enum": [
+                        "absolute",
+                        "relative"
+                    ],
+                    "example": "relative"
+                },
+                "group_id": {
+                    "description": "Group to add the task to, optional",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "batch-42"
+                },
+                "new_group": {
+                    "description": "Start a new group named after the task ID, optional",
+                    "type": "boolean",
+                    "example": false
+                },
+                "on_invalid": {
+                    "description": "What happens if earlier tasks make the task invalid: abort (default), skip or replan, optional",
+                    "type": "string",
+                    "enum": [
+                        "abort",
+                        "skip",
+                        "replan"
+                    ],
+                    "example": "replan"
+                },
+                "priority": {
+                    "description": "Priority of the task, higher runs first, optional",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "api.BroadcastRequest": {
+            "description": "Request body for enqueuing the same commands in several warehouse zones",
+            "type": "object",
+            "properties": {
+                "commands": {
+                    "description": "Commands to be executed by the robot of every zone",
+                    "type": "string",
+                    "example": "N E S W"
+                },
+                "delay_between_commands": {
+                    "description": "Delay between executing commands, optional",
+                    "type": "string",
+                    "example": "1s"
+                },
+                "zones": {
+                    "description": "Zones to enqueue the task in, all zones if omitted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "north",
+                        "south"
+                    ]
+                }
+            }
+        },
+        "api.BroadcastResponse": {
+            "description": "Outcome of a broadcast task, every zone accepts or rejects it independently of the others",
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "description": "Number of zones that enqueued the task",
+                    "type": "integer",
+                    "example": 2
+                },
+                "rejected": {
+                    "description": "Number of zones that rejected the task",
+                    "type": "integer",
+                    "example": 1
+                },
+                "zones": {
+                    "description": "Outcome per zone name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/api.BroadcastZoneResult"
+                    }
+                }
+            }
+        },
+        "api.BroadcastZoneResult": {
+            "description": "Outcome of a broadcast task in one zone, either the ID of the enqueued task or the error",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Machine-readable error code, if the zone rejected the task",
+                    "type": "string",
+                    "example": "OUT_OF_BOUNDS"
+                },
+                "error": {
+                    "description": "Error message, if the zone rejected the task",
+                    "type": "string"
+                },
+                "task_id": {
+                    "description": "ID of the enqueued task, if the zone accepted it",
+                    "type": "string",
+                    "example": "12345"
+                }
+            }
+        },
+        "api.CellHistoryResponse": {
+            "description": "Moves of tasks that brought the robot onto a cell, oldest first",
+            "type": "object",
+            "properties": {
+                "cell": {
+                    "description": "The queried cell",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Coord"
+                        }
+                    ]
+                },
+                "visits": {
+                    "description": "Moves onto the cell, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/robot.CellVisit"
+                    }
+                }
+            }
+        },
+        "api.Config": {
+            "type": "object",
+            "properties": {
+                "camel_case_json": {
+                    "description": "Name the fields of JSON responses and WebSocket events in camelCase, e.g. taskId, instead of snake_case",
+                    "type": "boolean"
+                },
+                "command_allowlists": {
+                    "description": "Commands every client may use in the tasks it submits, keyed by the X-Client-ID header of the client,\nclients without an allowlist may use every command",
+                    "type": "object",
+                    "additionalProperties": {
+                        "description": "A string containing space-separated robot commands",
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/robot.RobotCommand"
+                        }
+                    }
+                },
+                "correlation_ids": {
+                    "description": "Tag the tasks created by a request, and all their events, with the X-Request-ID of the request",
+                    "type": "boolean"
+                },
+                "debug": {
+                    "description": "Enable development-only endpoints, never enable in production",
+                    "type": "boolean"
+                },
+                "grid_format": {
+                    "description": "Content type of the grid endpoint when the Accept header allows any format, see ParseGridFormat",
+                    "type": "string"
+                },
+                "handler_timeout": {
+                    "description": "Deadline for synchronous handlers, streaming endpoints are excluded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "idle_timeout": {
+                    "description": "Maximum time to wait for the next request on keep-alive connections",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "public_task_view": {
+                    "description": "Hide internal task fields (sequence number, raw error message) in the state, task list and group responses",
+                    "type": "boolean"
+                },
+                "read_only": {
+                    "description": "Serve as a read-only observer, e.g. for a dashboard: mutating endpoints answer 403 and WebSocket\ncontrol messages are refused, while state, stats, the grid and events stay available",
+                    "type": "boolean"
+                },
+                "read_timeout": {
+                    "description": "Maximum duration for reading an entire request",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "relaxed_json": {
+                    "description": "Accept trailing commas and ` + "`" + `//` + "`" + ` comments in request bodies",
+                    "type": "boolean"
+                },
+                "shutdown_timeout": {
+                    "description": "Time allowed for in-flight requests to finish on shutdown",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "telemetry_interval": {
+                    "description": "Time between two messages of the telemetry stream, clients may override it per connection",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "write_timeout": {
+                    "description": "Maximum duration before timing out writes of a response",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.ConfigResponse": {
+            "description": "Effective configuration of the robot service and the API, with secrets redacted",
+            "type": "object",
+            "properties": {
+                "api": {
+                    "description": "Configuration of the API server",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Config"
+                        }
+                    ]
+                },
+                "robot": {
+                    "description": "Configuration of the robot service",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.RuntimeConfig"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.ErrorResponse": {
+            "description": "Generic error response.",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Machine-readable error code",
+                    "type": "string",
+                    "example": "TASK_NOT_FOUND"
+                },
+                "error": {
+                    "description": "Human-readable error message",
+                    "type": "string",
+                    "example": "Job not found"
+                }
+            }
+        },
+        "api.FollowRequest": {
+            "description": "Request body with the target cell of a follow task",
+            "type": "object",
+            "required": [
+                "x",
+                "y"
+            ],
+            "properties": {
+                "delay_between_commands": {
+                    "description": "Delay between moves, only used when starting the task, optional",
+                    "type": "string",
+                    "example": "1s"
+                },
+                "x": {
+                    "description": "Target X coordinate",
+                    "type": "integer",
+                    "example": 3
+                },
+                "y": {
+                    "description": "Target Y coordinate",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "api.ImportResponse": {
+            "description": "Outcome of a task import, rows are imported independently of each other",
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "Number of rows rejected",
+                    "type": "integer",
+                    "example": 1
+                },
+                "imported": {
+                    "description": "Number of rows enqueued as tasks",
+                    "type": "integer",
+                    "example": 2
+                },
+                "rows": {
+                    "description": "Outcome of every row in CSV order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ImportRowResult"
+                    }
+                }
+            }
+        },
+        "api.ImportRowResult": {
+            "description": "Outcome of importing one CSV row, either the ID of the enqueued task or the error",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Machine-readable error code, if the row failed",
+                    "type": "string",
+                    "example": "OUT_OF_BOUNDS"
+                },
+                "error": {
+                    "description": "Error message, if the row failed",
+                    "type": "string"
+                },
+                "line": {
+                    "description": "Line of the row in the CSV, the header is line 1",
+                    "type": "integer",
+                    "example": 2
+                },
+                "task_id": {
+                    "description": "ID of the enqueued task, if the row was imported",
+                    "type": "string",
+                    "example": "12345"
+                }
+            }
+        },
+        "api.ReachableResponse": {
+            "description": "Cells reachable from the current robot position",
+            "type": "object",
+            "properties": {
+                "cells": {
+                    "description": "Reachable cells ordered by row, then column, including the current cell",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/robot.Coord"
+                    }
+                },
+                "count": {
+                    "description": "Number of reachable cells",
+                    "type": "integer",
+                    "example": 6
+                },
+                "steps": {
+                    "description": "Maximum number of moves",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "api.ReplaceTaskRequest": {
+            "description": "Request body with the commands of the task replacing a pending or running task",
+            "type": "object",
+            "required": [
+                "commands"
+            ],
+            "properties": {
+                "commands": {
+                    "description": "Commands of the replacement task",
+                    "type": "string",
+                    "example": "N E"
+                },
+                "delay_between_commands": {
+                    "description": "Delay between executing commands, optional",
+                    "type": "string",
+                    "example": "1s"
+                }
+            }
+        },
+        "api.SetPositionRequest": {
+            "description": "Request body for forcing the robot position, development only",
+            "type": "object",
+            "required": [
+                "x",
+                "y"
+            ],
+            "properties": {
+                "x": {
+                    "description": "Target X coordinate",
+                    "type": "integer",
+                    "example": 9
+                },
+                "y": {
+                    "description": "Target Y coordinate",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "api.TaskStatusRequest": {
+            "description": "Request body for querying the states of several tasks at once",
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "description": "IDs of the tasks, at most 500",
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "12345",
+                        "67890"
+                    ]
+                }
+            }
+        },
+        "api.TaskStatusResponse": {
+            "description": "States of the requested tasks, unknown IDs are reported as NotFound",
+            "type": "object",
+            "properties": {
+                "states": {
+                    "description": "Task ID to state, e.g. \"Completed\", or \"NotFound\"",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.TelemetryMessage": {
+            "description": "Periodic robot position, sent at a fixed rate independent of task events",
+            "type": "object",
+            "properties": {
+                "robot_state": {
+                    "description": "Current position and heading of the robot",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.RobotState"
+                        }
+                    ]
+                },
+                "timestamp": {
+                    "description": "Time the position was read",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Always \"telemetry\"",
+                    "type": "string",
+                    "example": "telemetry"
+                }
+            }
+        },
+        "robot.AbortSummary": {
+            "description": "Aborted task with the reason and the command it stopped at",
+            "type": "object",
+            "properties": {
+                "abort_category": {
+                    "description": "Kind of failure, see AbortCategory",
+                    "type": "string",
+                    "example": "Obstacle"
+                },
+                "aborted_at": {
+                    "description": "Time of the abort, omitted for tasks restored from older snapshots",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "commands_executed": {
+                    "description": "Number of commands executed before the abort",
+                    "type": "integer",
+                    "example": 2
+                },
+                "error": {
+                    "description": "Error message of the task",
+                    "type": "string",
+                    "example": "Error executing command 'N': obstacle"
+                },
+                "position": {
+                    "description": "Robot position when the task stopped",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Coord"
+                        }
+                    ]
+                },
+                "reason": {
+                    "description": "Why the task was aborted, see TransitionReason",
+                    "type": "string",
+                    "example": "obstacle"
+                },
+                "stopped_at": {
+                    "description": "First command not executed, omitted if the task stopped after its last one. For a path rejected at dispatch the error names the offending command",
+                    "type": "string",
+                    "example": "N"
+                },
+                "task_id": {
+                    "description": "ID of the aborted task",
+                    "type": "string",
+                    "example": "12345"
+                }
+            }
+        },
+        "robot.CellInfo": {
+            "description": "Occupancy of a warehouse cell",
+            "type": "object",
+            "properties": {
+                "cell": {
+                    "description": "The queried cell",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Coord"
+                        }
+                    ]
+                },
+                "in_bounds": {
+                    "description": "Whether the cell lies inside the warehouse",
+                    "type": "boolean",
+                    "example": true
+                },
+                "obstacle": {
+                    "description": "Whether the cell holds a permanent obstacle",
+                    "type": "boolean",
+                    "example": false
+                },
+                "occupied": {
+                    "description": "Whether the robot is currently in the cell",
+                    "type": "boolean",
+                    "example": false
+                },
+                "robot": {
+                    "description": "The robot occupying the cell, if any",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.RobotState"
+                        }
+                    ]
+                }
+            }
+        },
+        "robot.CellVisit": {
+            "description": "Move of a task that brought the robot onto a cell",
+            "type": "object",
+            "properties": {
+                "command_index": {
+                    "description": "Position of the command in the task, starting at 0",
+                    "type": "integer",
+                    "example": 2
+                },
+                "task_id": {
+                    "description": "Task that moved the robot onto the cell",
+                    "type": "string",
+                    "example": "12345"
+                },
+                "timestamp": {
+                    "description": "Time at which the command finished",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                }
+            }
+        },
+        "robot.CommandFrame": {
+            "type": "string",
+            "enum": [
+                "absolute",
+                "relative"
+            ],
+            "x-enum-comments": {
+                "FrameAbsolute": "N, E, S and W are grid directions, the default",
+                "FrameRelative": "N, E, S and W are forward, right, back and left of the robot's heading"
+            },
+            "x-enum-descriptions": [
+                "N, E, S and W are grid directions, the default",
+                "N, E, S and W are forward, right, back and left of the robot's heading"
+            ],
+            "x-enum-varnames": [
+                "FrameAbsolute",
+                "FrameRelative"
+            ]
+        },
+        "robot.Config": {
+            "type": "object",
+            "properties": {
+                "allow_empty_tasks": {
+                    "description": "Accept tasks without commands, which complete immediately as a no-op (e.g. heartbeats), instead of rejecting them",
+                    "type": "boolean"
+                },
+                "battery_capacity": {
+                    "description": "Number of moves the robot can make on a full battery, recharged at the origin. 0 disables the battery simulation",
+                    "type": "integer"
+                },
+                "cancel_grace": {
+                    "description": "A task still in RequestCancellation this long after the request is forced to Canceled, 0 disables it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "case_insensitive_commands": {
+                    "description": "Accept commands in any case, e.g. \"n e s w\", for every task. Commands are case-sensitive by default",
+                    "type": "boolean"
+                },
+                "command_frame": {
+                    "description": "Frame of the commands of tasks that do not choose one, absolute if empty",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.CommandFrame"
+                        }
+                    ]
+                },
+                "command_retries": {
+                    "description": "Number of times a command failing with a transient error (e.g. a blocked cell) is retried before the task is aborted",
+                    "type": "integer"
+                },
+                "command_retry_backoff": {
+                    "description": "Delay before the first retry of a command, doubled for every further retry",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "dropped_events_unhealthy": {
+                    "description": "Report the service as unhealthy in the stats once an event was dropped for a slow subscriber",
+                    "type": "boolean"
+                },
+                "event_buffer_size": {
+                    "description": "Number of recent events kept for replay on reconnect, 0 disables replay",
+                    "type": "integer"
+                },
+                "event_coalesce_window": {
+                    "description": "Window within which non-terminal events of the same task are coalesced into the latest one, 0 disables coalescing",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "initial_heading": {
+                    "description": "Heading of the robot at construction and after a reset",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Heading"
+                        }
+                    ]
+                },
+                "max_path_length": {
+                    "description": "Maximum number of cells a task may traverse, its commands times the step size, 0 for no limit.\nUnlike a limit on the commands, it bounds how far the robot travels however the commands are scaled",
+                    "type": "integer"
+                },
+                "max_pending_tasks": {
+                    "description": "Maximum number of pending tasks regardless of the queue capacity, 0 means unlimited",
+                    "type": "integer"
+                },
+                "max_subscriber_lag": {
+                    "description": "Number of consecutive events a subscriber with a full buffer may miss before it is disconnected,\nso one slow client cannot keep falling behind. 0 keeps slow subscribers connected, they only miss events",
+                    "type": "integer"
+                },
+                "max_subscribers": {
+                    "description": "Maximum number of concurrent event subscribers, 0 means unlimited",
+                    "type": "integer"
+                },
+                "max_tasks": {
+                    "description": "Maximum number of tasks kept by the service including finished ones, 0 means unlimited",
+                    "type": "integer"
+                },
+                "min_subscribed_delay": {
+                    "description": "Minimum delay between the commands of a task while events have subscribers, e.g. 1ms, so tasks without\ndelay cannot flood the subscriber buffers. 0 disables it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "move_event_delay": {
+                    "description": "Delay between the start and end event of a move, on top of the delay between commands",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "move_events": {
+                    "description": "Publish a move event before and after every command, so a frontend can animate the robot",
+                    "type": "boolean"
+                },
+                "obstacles": {
+                    "description": "Cells the robot can never enter",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/robot.Coord"
+                    }
+                },
+                "preempt_on_overload": {
+                    "description": "Abort the oldest in-progress task when the queue is full and a task with a higher priority arrives",
+                    "type": "boolean"
+                },
+                "progress_every_commands": {
+                    "description": "Publish a progress heartbeat with the commands executed and the robot position every so many commands\nof a running task, 0 disables it",
+                    "type": "integer"
+                },
+                "progress_interval": {
+                    "description": "Publish a progress heartbeat once this much time passed since the previous one, checked after every command.\n0 disables it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "queue_size": {
+                    "description": "Capacity of the task queue created by NewEmbeddedService",
+                    "type": "integer"
+                },
+                "reject_off_grid": {
+                    "description": "Reject and abort tasks while the robot is outside the warehouse, e.g. after a manual override for recovery",
+                    "type": "boolean"
+                },
+                "speed_ramp": {
+                    "description": "Extra delays before the first commands of every task while the robot speeds up, see SpeedRamp",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/time.Duration"
+                    }
+                },
+                "step_size": {
+                    "description": "Number of cells the robot moves per command, for larger robots that cover several cells per tick",
+                    "type": "integer"
+                },
+                "strict_sequence": {
+                    "description": "Deliver events through a single writer in the order of the state changes, and disconnect a subscriber\nwith a full buffer instead of letting it miss an event, so every client sees gapless sequence numbers",
+                    "type": "boolean"
+                },
+                "stuck_abort_grace": {
+                    "description": "A stuck task overrunning by more than this grace period is aborted, 0 disables auto-abort",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "stuck_safety_factor": {
+                    "description": "A task running longer than its expected run time multiplied by this factor is reported as stuck, must be positive",
+                    "type": "number"
+                },
+                "task_id_prefix": {
+                    "description": "Prefix of every task ID, e.g. \"whA-\"",
+                    "type": "string"
+                },
+                "throughput_window": {
+                    "description": "Sliding window over which the command and task throughput is computed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "tie_break": {
+                    "description": "Order of pending tasks with equal priority, fifo if empty",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.TieBreak"
+                        }
+                    ]
+                },
+                "time_scale": {
+                    "description": "Factor by which the service time runs faster than the clock, e.g. 10 plays every delay ten times faster. 0 or 1 is real time",
+                    "type": "number"
+                },
+                "uncancel_window": {
+                    "description": "A pending task cancelled by a client can be restored with UncancelTask this long after the cancel,\n0 makes every cancel final right away",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "webhook_max_attempts": {
+                    "description": "Number of delivery attempts per webhook before it is dropped",
+                    "type": "integer"
+                },
+                "webhook_queue_size": {
+                    "description": "Capacity of the webhook delivery queue, deliveries not fitting are dropped",
+                    "type": "integer"
+                },
+                "webhook_retry_backoff": {
+                    "description": "Delay before the first retry of a failed webhook, doubled for every further retry",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "webhook_url": {
+                    "description": "URL receiving a POST with the terminal event of every task, no webhooks when empty",
+                    "type": "string"
+                }
+            }
+        },
+        "robot.Coord": {
+            "description": "Cell of the warehouse grid",
+            "type": "object",
+            "properties": {
+                "x": {
+                    "description": "X coordinate, growing eastwards",
+                    "type": "integer",
+                    "example": 3
+                },
+                "y": {
+                    "description": "Y coordinate, growing northwards",
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "robot.DeliveryStatus": {
+            "description": "Delivery status of the completion webhook of a task",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Number of delivery attempts so far",
+                    "type": "integer",
+                    "example": 2
+                },
+                "delivered_at": {
+                    "description": "Time the receiver accepted the webhook",
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "Error of the latest failed attempt",
+                    "type": "string",
+                    "example": ""
+                },
+                "state": {
+                    "description": "pending, delivered, failed or dropped",
+                    "type": "string",
+                    "example": "delivered"
+                },
+                "task_id": {
+                    "type": "string",
+                    "example": "12345"
+                }
+            }
+        },
+        "robot.Grid": {
+            "description": "Warehouse floor with the robot position and the obstacles",
+            "type": "object",
+            "properties": {
+                "obstacles": {
+                    "description": "Obstacle cells ordered by row, then column",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/robot.Coord"
+                    }
+                },
+                "robot": {
+                    "description": "Cell of the robot",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Coord"
+                        }
+                    ]
+                },
+                "size": {
+                    "description": "Number of cells per row and column",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "robot.GroupSummary": {
+            "description": "States of the tasks in a task group",
+            "type": "object",
+            "properties": {
+                "done": {
+                    "description": "Whether no task of the group is pending or running anymore",
+                    "type": "boolean",
+                    "example": false
+                },
+                "group_id": {
+                    "description": "ID of the group",
+                    "type": "string",
+                    "example": "batch-42"
+                },
+                "states": {
+                    "description": "Number of tasks per state, e.g. {\"Completed\": 2, \"Pending\": 1}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "tasks": {
+                    "description": "Tasks of the group ordered by sequence number",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/robot.RobotTask"
+                    }
+                },
+                "total": {
+                    "description": "Number of tasks in the group",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "robot.Heading": {
+            "description": "Direction the robot is facing",
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3
+            ],
+            "x-enum-varnames": [
+                "HeadingNorth",
+                "HeadingEast",
+                "HeadingSouth",
+                "HeadingWest"
+            ]
+        },
+        "robot.Heatmap": {
+            "description": "Cumulative time the robot spent in every cell of the warehouse",
+            "type": "object",
+            "properties": {
+                "dwell_seconds": {
+                    "description": "Seconds spent per cell, indexed [y][x] with row 0 the southernmost",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "size": {
+                    "description": "Number of cells per row and column",
+                    "type": "integer",
+                    "example": 10
+                },
+                "total_seconds": {
+                    "description": "Sum of all cells",
+                    "type": "number",
+                    "example": 42.5
+                }
+            }
+        },
+        "robot.InvalidTaskPolicy": {
+            "type": "string",
+            "enum": [
+                "abort",
+                "skip",
+                "replan"
+            ],
+            "x-enum-comments": {
+                "InvalidAbort": "Abort the task, the default",
+                "InvalidReplan": "Move the robot by the displacement of the commands along the shortest valid path",
+                "InvalidSkip": "Cancel the task with reason skipped_invalid, so it does not count as a failure"
+            },
+            "x-enum-descriptions": [
+                "Abort the task, the default",
+                "Cancel the task with reason skipped_invalid, so it does not count as a failure",
+                "Move the robot by the displacement of the commands along the shortest valid path"
+            ],
+            "x-enum-varnames": [
+                "InvalidAbort",
+                "InvalidSkip",
+                "InvalidReplan"
+            ]
+        },
+        "robot.MoveEvent": {
+            "description": "Robot move between two cells",
+            "type": "object",
+            "properties": {
+                "command": {
+                    "description": "Command being executed",
+                    "type": "string",
+                    "example": "N"
+                },
+                "from": {
+                    "description": "Cell the robot leaves",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Coord"
                         }
-                    },
-                    "400": {
-                        "description": "Error message",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
+                    ]
+                },
+                "phase": {
+                    "description": "\"start\" before the move, \"end\" once the robot arrived",
+                    "type": "string",
+                    "example": "start"
+                },
+                "to": {
+                    "description": "Cell the robot moves to",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Coord"
                         }
-                    }
+                    ]
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.AddTaskRequest": {
-            "description": "Request body for adding a new robot task",
+        },
+        "robot.ProgressHeartbeat": {
+            "description": "Periodic progress of a running task",
             "type": "object",
-            "required": [
-                "commands"
-            ],
             "properties": {
-                "commands": {
-                    "description": "Commands to be executed by the robot",
+                "commands_executed": {
+                    "description": "Number of commands executed so far",
+                    "type": "integer",
+                    "example": 20
+                },
+                "position": {
+                    "description": "Robot position after the latest command",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Coord"
+                        }
+                    ]
+                },
+                "total_commands": {
+                    "description": "Number of commands of the task, 0 for follow tasks whose path is not known in advance",
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        },
+        "robot.QueueETA": {
+            "description": "Estimated time until all running and pending tasks are finished",
+            "type": "object",
+            "properties": {
+                "estimated_finish": {
+                    "description": "Time at which the queue is expected to be empty",
                     "type": "string",
-                    "example": "N E S W"
+                    "example": "2024-01-15T10:30:42Z"
                 },
-                "delay_between_commands": {
-                    "description": "Delay between executing commands, optional",
+                "pending_tasks": {
+                    "description": "Number of tasks waiting to be dispatched",
+                    "type": "integer",
+                    "example": 3
+                },
+                "remaining": {
+                    "description": "Remaining run time of the running task plus the run time of all pending tasks",
                     "type": "string",
-                    "example": "1s"
+                    "example": "42s"
                 }
             }
         },
-        "api.ErrorResponse": {
-            "description": "Generic error response.",
+        "robot.QueuedTask": {
+            "description": "Pending task in dispatch order with its estimated start time",
             "type": "object",
             "properties": {
-                "error": {
+                "command_count": {
+                    "description": "Number of commands in the task",
+                    "type": "integer",
+                    "example": 4
+                },
+                "estimated_start": {
+                    "description": "Estimated time at which the task starts",
                     "type": "string",
-                    "example": "Job not found"
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "priority": {
+                    "description": "Priority of the task",
+                    "type": "integer",
+                    "example": 0
+                },
+                "sequence_num": {
+                    "description": "Sequence number of the task",
+                    "type": "integer",
+                    "example": 3
+                },
+                "task_id": {
+                    "description": "Unique identifier for the task",
+                    "type": "string",
+                    "example": "12345"
                 }
             }
         },
+        "robot.RobotCommand": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                16777216
+            ],
+            "x-enum-varnames": [
+                "North",
+                "West",
+                "East",
+                "South",
+                "holdBase"
+            ]
+        },
         "robot.RobotState": {
             "type": "object",
             "properties": {
+                "heading": {
+                    "description": "Direction the robot is facing",
+                    "type": "string",
+                    "example": "N"
+                },
                 "x": {
                     "description": "Current X coordinate of the robot",
                     "type": "integer"
@@ -189,11 +2860,33 @@ const docTemplate = `{
         "robot.RobotTask": {
             "type": "object",
             "properties": {
+                "abort_category": {
+                    "description": "Kind of failure of an aborted task, see AbortCategory",
+                    "type": "string",
+                    "example": "Obstacle"
+                },
+                "aborted_at": {
+                    "description": "Time at which the task was aborted, see Service.RecentAborts",
+                    "type": "string"
+                },
+                "allowed_commands": {
+                    "description": "Commands the submitter may use, nil allows every command. Checked again at dispatch, once the commands are resolved",
+                    "type": "string",
+                    "example": "N S"
+                },
+                "cancel_requested_at": {
+                    "description": "Time at which the cancellation of the running task was requested",
+                    "type": "string"
+                },
                 "commands": {
                     "description": "List of commands to be executed by the robot",
                     "type": "string",
                     "example": "N E S W"
                 },
+                "correlation_id": {
+                    "description": "Request ID of the API call that created the task, copied to all its events",
+                    "type": "string"
+                },
                 "delay_between_commands": {
                     "description": "Delay between executing commands",
                     "type": "string",
@@ -203,14 +2896,102 @@ const docTemplate = `{
                     "description": "Error message if the task fails",
                     "type": "string"
                 },
+                "follow": {
+                    "description": "True for tasks chasing a target cell, see Service.Follow",
+                    "type": "boolean"
+                },
+                "follow_target": {
+                    "description": "Cell a follow task moves to, nil once cleared",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Coord"
+                        }
+                    ]
+                },
+                "frame": {
+                    "description": "How the commands are interpreted, see CommandFrame",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.CommandFrame"
+                        }
+                    ]
+                },
+                "group_id": {
+                    "description": "Group the task belongs to, if any",
+                    "type": "string"
+                },
                 "id": {
                     "description": "Unique identifier for the task",
                     "type": "string"
                 },
+                "labels": {
+                    "description": "Free-form labels of the submitter, e.g. the job list a task was imported from",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "on_invalid": {
+                    "description": "What happens if the task is invalid when dispatched, see InvalidTaskPolicy",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.InvalidTaskPolicy"
+                        }
+                    ]
+                },
+                "ping": {
+                    "description": "True for health check tasks without commands",
+                    "type": "boolean"
+                },
+                "priority": {
+                    "description": "Priority of the task, higher priority tasks are dispatched first",
+                    "type": "integer"
+                },
+                "progress": {
+                    "description": "How far the task got before it was aborted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.TaskProgress"
+                        }
+                    ]
+                },
+                "queue_seq": {
+                    "description": "Dispatch position among tasks of equal priority if not the sequence number, set for replacements",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Why the task entered its current state, see TransitionReason",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.TransitionReason"
+                        }
+                    ]
+                },
+                "relative_to": {
+                    "description": "Heading the relative commands were resolved against at dispatch, Commands holds the grid directions since",
+                    "type": "string",
+                    "example": "E"
+                },
+                "replaced_by": {
+                    "description": "ID of the task that replaced this task",
+                    "type": "string"
+                },
+                "replaces": {
+                    "description": "ID of the task this task replaced, see Service.ReplaceTask",
+                    "type": "string"
+                },
+                "replanned": {
+                    "description": "True if the commands were re-planned at dispatch",
+                    "type": "boolean"
+                },
                 "sequence_num": {
                     "description": "Sequence number for the task, used for ordering tasks in the queue",
                     "type": "integer"
                 },
+                "started_at": {
+                    "description": "Time at which the task execution started",
+                    "type": "string"
+                },
                 "state": {
                     "description": "Current state of the task",
                     "type": "string",
@@ -218,13 +2999,46 @@ const docTemplate = `{
                 }
             }
         },
+        "robot.RuntimeConfig": {
+            "description": "Effective configuration of the robot service, with secrets redacted",
+            "type": "object",
+            "properties": {
+                "config": {
+                    "description": "Effective configuration, see Config.Redacted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Config"
+                        }
+                    ]
+                },
+                "default_delay_between_commands": {
+                    "description": "Delay of tasks that do not set one",
+                    "type": "string",
+                    "example": "1s"
+                },
+                "warehouse_size": {
+                    "description": "Number of cells per row and column",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
         "robot.ServiceState": {
             "type": "object",
             "properties": {
+                "battery": {
+                    "description": "Battery level of the robot, omitted when the battery simulation is disabled",
+                    "type": "integer"
+                },
                 "current_task_count": {
                     "description": "Current number of tasks in the service",
                     "type": "integer"
                 },
+                "mode": {
+                    "description": "Whether the service accepts new tasks",
+                    "type": "string",
+                    "example": "Running"
+                },
                 "robot_state": {
                     "description": "Current state of the robot",
                     "allOf": [
@@ -242,15 +3056,196 @@ const docTemplate = `{
                 }
             }
         },
+        "robot.ServiceStats": {
+            "description": "Health and diagnostic information about the robot service",
+            "type": "object",
+            "properties": {
+                "commands_per_second": {
+                    "description": "Executed commands per second over the throughput window",
+                    "type": "number",
+                    "example": 0.8
+                },
+                "events_dropped_total": {
+                    "description": "Events not delivered to subscribers with a full buffer",
+                    "type": "integer",
+                    "example": 0
+                },
+                "forced_cancellations": {
+                    "description": "IDs of the stored tasks forced to Canceled because their cancellation request went unnoticed, see Config.CancelGrace",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "queue_cap": {
+                    "description": "Capacity of the queue, queue_len reaching it means backpressure",
+                    "type": "integer",
+                    "example": 100
+                },
+                "queue_len": {
+                    "description": "Number of tasks waiting in the queue",
+                    "type": "integer",
+                    "example": 3
+                },
+                "stuck": {
+                    "description": "True if a task runs much longer than expected",
+                    "type": "boolean",
+                    "example": false
+                },
+                "stuck_task_id": {
+                    "description": "ID of the stuck task, if any",
+                    "type": "string",
+                    "example": "123"
+                },
+                "tasks_per_minute": {
+                    "description": "Completed tasks per minute over the throughput window",
+                    "type": "number",
+                    "example": 12
+                },
+                "unhealthy": {
+                    "description": "True if the service is configured to treat dropped events as fatal and an event was dropped",
+                    "type": "boolean",
+                    "example": false
+                },
+                "webhooks_dropped_total": {
+                    "description": "Webhooks given up after all attempts failed or with a full queue",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "robot.Snapshot": {
+            "description": "Complete serializable state of the robot service",
+            "type": "object",
+            "properties": {
+                "battery": {
+                    "description": "Battery level, omitted when the battery simulation is disabled",
+                    "type": "integer"
+                },
+                "config": {
+                    "description": "Configuration of the service with secrets redacted, informational only and not restored",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.Config"
+                        }
+                    ]
+                },
+                "current_task_count": {
+                    "description": "Number of tasks submitted so far, the last assigned sequence number",
+                    "type": "integer"
+                },
+                "robot_state": {
+                    "description": "Position and heading of the robot",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.RobotState"
+                        }
+                    ]
+                },
+                "tasks": {
+                    "description": "All tasks ordered by sequence number",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/robot.RobotTask"
+                    }
+                }
+            }
+        },
+        "robot.TaskPage": {
+            "description": "Page of tasks ordered by sequence number",
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "description": "Cursor for the next page, omitted on the last page",
+                    "type": "integer",
+                    "example": 50
+                },
+                "tasks": {
+                    "description": "Tasks on this page, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/robot.RobotTask"
+                    }
+                },
+                "total": {
+                    "description": "Total number of tasks known to the service",
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "robot.TaskProgress": {
+            "description": "Progress of an aborted task",
+            "type": "object",
+            "properties": {
+                "commands_executed": {
+                    "description": "Number of commands executed successfully",
+                    "type": "integer",
+                    "example": 2
+                },
+                "final_position": {
+                    "description": "Robot position when the task stopped",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.RobotState"
+                        }
+                    ]
+                }
+            }
+        },
         "robot.TaskStatusUpdateEvent": {
             "description": "Websocket response for task status updates.",
             "type": "object",
             "properties": {
+                "abort_category": {
+                    "description": "Kind of failure, only set on the Aborted event, see AbortCategory",
+                    "type": "string",
+                    "example": "Obstacle"
+                },
+                "correlation_id": {
+                    "description": "Request ID of the API call that created the task, if recorded",
+                    "type": "string",
+                    "example": "req-7f3a"
+                },
                 "error": {
                     "description": "Error message if any",
                     "type": "string",
                     "example": ""
                 },
+                "heartbeat": {
+                    "description": "The progress of a progress heartbeat",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.ProgressHeartbeat"
+                        }
+                    ]
+                },
+                "move": {
+                    "description": "The move of a move event",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.MoveEvent"
+                        }
+                    ]
+                },
+                "progress": {
+                    "description": "How far an aborted task got, only set on the Aborted event",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/robot.TaskProgress"
+                        }
+                    ]
+                },
+                "reason": {
+                    "description": "Why the task entered the state, see TransitionReason",
+                    "type": "string",
+                    "example": "dispatched"
+                },
+                "seq": {
+                    "description": "Sequence number of the event, increases by one per event",
+                    "type": "integer",
+                    "example": 42
+                },
                 "state": {
                     "description": "Current state of the task",
                     "type": "string",
@@ -265,8 +3260,159 @@ const docTemplate = `{
                     "description": "Timestamp when the event occurred",
                     "type": "string",
                     "example": "2024-01-15T10:30:00Z"
+                },
+                "type": {
+                    "description": "\"move\", \"progress\", \"busy\" or \"idle\" for move events, heartbeats and dispatcher activity, omitted for task state events",
+                    "type": "string",
+                    "example": "move"
                 }
             }
+        },
+        "robot.TieBreak": {
+            "type": "string",
+            "enum": [
+                "fifo",
+                "shortest"
+            ],
+            "x-enum-comments": {
+                "TieBreakFIFO": "In submission order, the default",
+                "TieBreakShortest": "Fewest commands first, in submission order for equal counts"
+            },
+            "x-enum-descriptions": [
+                "In submission order, the default",
+                "Fewest commands first, in submission order for equal counts"
+            ],
+            "x-enum-varnames": [
+                "TieBreakFIFO",
+                "TieBreakShortest"
+            ]
+        },
+        "robot.TransitionReason": {
+            "type": "string",
+            "enum": [
+                "submitted",
+                "user_uncancel",
+                "dispatched",
+                "completed_normally",
+                "target_cleared",
+                "user_cancel",
+                "replaced",
+                "skipped_invalid",
+                "cancel_timeout",
+                "out_of_bounds",
+                "obstacle",
+                "cell_blocked",
+                "battery_depleted",
+                "off_grid",
+                "no_path",
+                "not_allowed",
+                "command_failed",
+                "timeout",
+                "preempted",
+                "dispatcher_failed",
+                "restored",
+                "shutdown"
+            ],
+            "x-enum-comments": {
+                "ReasonBatteryDepleted": "Aborted: the battery would run out away from the origin",
+                "ReasonCancelTimeout": "Canceled: the executor did not act on a cancellation request within the grace period",
+                "ReasonCellBlocked": "Aborted: the target cell stayed occupied after all retries",
+                "ReasonCommandFailed": "Aborted: a command failed for another reason",
+                "ReasonCompleted": "Completed: every command was executed, or a follow task reached its target",
+                "ReasonDispatched": "InProgress: the dispatcher started the task",
+                "ReasonDispatcherFailed": "Aborted: the dispatch loop died while running the task",
+                "ReasonNoPath": "Aborted: obstacles wall the target of a follow task off",
+                "ReasonNotAllowed": "Aborted: the commands resolved at dispatch are outside the allowed set of the submitter",
+                "ReasonObstacle": "Aborted: the robot would enter an obstacle",
+                "ReasonOffGrid": "Aborted: the robot was outside the warehouse when the task was dispatched",
+                "ReasonOutOfBounds": "Aborted: the robot would leave the warehouse",
+                "ReasonPreempted": "Aborted: a higher priority task took its place",
+                "ReasonReplaced": "RequestCancellation, Canceled: a client replaced the task with a new one",
+                "ReasonRestored": "Aborted: a snapshot restore replaced the running task",
+                "ReasonShutdown": "Aborted: the service shut down during a hold of the task",
+                "ReasonSkippedInvalid": "Canceled: the task was invalid when dispatched and its policy is to skip it",
+                "ReasonSubmitted": "Pending: the task was accepted",
+                "ReasonTargetCleared": "Completed: the target of a follow task was cleared",
+                "ReasonTimeout": "Aborted: the task overran its expected run time",
+                "ReasonUncanceled": "Pending: a client reversed the cancel within the uncancel window",
+                "ReasonUserCancel": "RequestCancellation, CancelPending, Canceled: a client cancelled the task or its group"
+            },
+            "x-enum-descriptions": [
+                "Pending: the task was accepted",
+                "Pending: a client reversed the cancel within the uncancel window",
+                "InProgress: the dispatcher started the task",
+                "Completed: every command was executed, or a follow task reached its target",
+                "Completed: the target of a follow task was cleared",
+                "RequestCancellation, CancelPending, Canceled: a client cancelled the task or its group",
+                "RequestCancellation, Canceled: a client replaced the task with a new one",
+                "Canceled: the task was invalid when dispatched and its policy is to skip it",
+                "Canceled: the executor did not act on a cancellation request within the grace period",
+                "Aborted: the robot would leave the warehouse",
+                "Aborted: the robot would enter an obstacle",
+                "Aborted: the target cell stayed occupied after all retries",
+                "Aborted: the battery would run out away from the origin",
+                "Aborted: the robot was outside the warehouse when the task was dispatched",
+                "Aborted: obstacles wall the target of a follow task off",
+                "Aborted: the commands resolved at dispatch are outside the allowed set of the submitter",
+                "Aborted: a command failed for another reason",
+                "Aborted: the task overran its expected run time",
+                "Aborted: a higher priority task took its place",
+                "Aborted: the dispatch loop died while running the task",
+                "Aborted: a snapshot restore replaced the running task",
+                "Aborted: the service shut down during a hold of the task"
+            ],
+            "x-enum-varnames": [
+                "ReasonSubmitted",
+                "ReasonUncanceled",
+                "ReasonDispatched",
+                "ReasonCompleted",
+                "ReasonTargetCleared",
+                "ReasonUserCancel",
+                "ReasonReplaced",
+                "ReasonSkippedInvalid",
+                "ReasonCancelTimeout",
+                "ReasonOutOfBounds",
+                "ReasonObstacle",
+                "ReasonCellBlocked",
+                "ReasonBatteryDepleted",
+                "ReasonOffGrid",
+                "ReasonNoPath",
+                "ReasonNotAllowed",
+                "ReasonCommandFailed",
+                "ReasonTimeout",
+                "ReasonPreempted",
+                "ReasonDispatcherFailed",
+                "ReasonRestored",
+                "ReasonShutdown"
+            ]
+        },
+        "time.Duration": {
+            "type": "integer",
+            "format": "int64",
+            "enum": [
+                1,
+                1000,
+                1000000,
+                1000000000,
+                60000000000,
+                3600000000000,
+                1,
+                1000,
+                1000000,
+                1000000000
+            ],
+            "x-enum-varnames": [
+                "Nanosecond",
+                "Microsecond",
+                "Millisecond",
+                "Second",
+                "Minute",
+                "Hour",
+                "Nanosecond",
+                "Microsecond",
+                "Millisecond",
+                "Second"
+            ]
         }
     }
 }`
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// ClientIDHeader is the request header naming the client of a request, used to look up its command allowlist.
+const ClientIDHeader = "X-Client-ID"
+
+// allowedCommandsKey is the gin context key holding the commands the client of the request may use.
+const allowedCommandsKey = "allowed_commands"
+
+// CommandAllowlist returns a middleware restricting the tasks a client submits to the commands of its allowlist,
+// the client is named by the X-Client-ID header. Once any allowlist is configured, requests without the header
+// or from a client without an allowlist may use no command, so dropping the header does not lift the restriction.
+// Tasks with other commands are rejected with COMMAND_NOT_ALLOWED and 403.
+func CommandAllowlist(allowlists map[string]robot.RobotCommands) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(allowlists) == 0 {
+			c.Next()
+			return
+		}
+		allowed := []robot.RobotCommand{} // Denied by default
+		if commands, ok := allowlists[c.GetHeader(ClientIDHeader)]; ok && commands != nil {
+			allowed = commands
+		}
+		c.Set(allowedCommandsKey, allowed)
+		c.Next()
+	}
+}
+
+// allowedCommands returns the commands the client of the request may use, nil allows every command.
+func allowedCommands(c *gin.Context) []robot.RobotCommand {
+	value, _ := c.Get(allowedCommandsKey)
+	allowed, _ := value.([]robot.RobotCommand)
+	return allowed
+}
+
+// ParseCommandAllowlist parses the allowlist of a client written as client=commands, e.g. dashboard=N,S.
+func ParseCommandAllowlist(raw string) (string, robot.RobotCommands, error) {
+	client, commands, found := strings.Cut(raw, "=")
+	if !found || strings.TrimSpace(client) == "" {
+		return "", nil, fmt.Errorf("invalid command allowlist %q, expected client=commands, e.g. dashboard=N,S", raw)
+	}
+	allowed, err := robot.ParseCommandSet(commands)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid command allowlist %q: %w", raw, err)
+	}
+	return strings.TrimSpace(client), allowed, nil
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// Test that every endpoint submitting commands rejects the commands outside the allowlist of the client
+func TestCommandAllowlist(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service := robot.NewService(ctx, make(chan string, 10))
+	go service.Start()
+	waitStarted(t, service)
+	taskID, _ := service.EnqueueTask("N", "1h")
+
+	config := DefaultConfig()
+	config.CommandAllowlists = map[string]robot.RobotCommands{"dashboard": {robot.North, robot.South}}
+	router := setupRouter()
+	SetupRouter(router, service, config)
+	serve := func(client, method, path, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		if client != "" {
+			req.Header.Set(ClientIDHeader, client)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, route := range []struct{ method, path, contentType, body string }{
+		{"POST", "/api/v1/robot/tasks", "application/json", `{"commands": "N E"}`},
+		{"POST", "/api/v1/robot/tasks/batch", "application/json", `{"tasks": [{"commands": "N"}, {"commands": "W"}]}`},
+		{"POST", "/api/v1/robot/tasks/patrol", "application/json", `{"width": 1, "height": 1}`},
+		{"POST", "/api/v1/robot/tasks/run-to-wall?dir=E", "application/json", ""},
+		{"POST", "/api/v1/robot/tasks/compact", "text/plain", compactStream(robot.North, robot.East)},
+		{"POST", "/api/v1/robot/tasks/follow", "application/json", `{"x": 0, "y": 3}`},
+		{"PUT", "/api/v1/robot/tasks/" + taskID + "/replace", "application/json", `{"commands": "E"}`},
+	} {
+		w := serve("dashboard", route.method, route.path, route.contentType, route.body)
+		var errorResponse ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &errorResponse)
+		if w.Code != http.StatusForbidden || errorResponse.Code != CodeCommandNotAllowed {
+			t.Errorf("%s %s: expected status code %d %s, got %d %s", route.method, route.path, http.StatusForbidden, CodeCommandNotAllowed, w.Code, w.Body.String())
+		}
+	}
+
+	w := serve("dashboard", "POST", "/api/v1/robot/tasks/import", "text/csv", "commands,delay,priority,labels\nN S,1h,,\nW,1h,,\n")
+	var importResponse ImportResponse
+	json.Unmarshal(w.Body.Bytes(), &importResponse)
+	if importResponse.Imported != 1 || len(importResponse.Rows) != 2 || importResponse.Rows[1].Code != CodeCommandNotAllowed {
+		t.Errorf("Expected the row moving west rejected with %s, got %d: %s", CodeCommandNotAllowed, w.Code, w.Body.String())
+	}
+
+	// Allowed commands pass
+	if w := serve("dashboard", "POST", "/api/v1/robot/tasks", "application/json", `{"commands": "N S", "delay_between_commands": "1h"}`); w.Code != http.StatusAccepted {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	// Clients without an allowlist, or without the header, may use no command once allowlists are configured
+	for _, client := range []string{"", "operator"} {
+		w := serve(client, "POST", "/api/v1/robot/tasks", "application/json", `{"commands": "N", "delay_between_commands": "1h"}`)
+		var errorResponse ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &errorResponse)
+		if w.Code != http.StatusForbidden || errorResponse.Code != CodeCommandNotAllowed {
+			t.Errorf("Client %q: expected status code %d %s, got %d %s", client, http.StatusForbidden, CodeCommandNotAllowed, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestParseCommandAllowlist(t *testing.T) {
+	tests := []struct {
+		raw        string
+		wantClient string
+		want       robot.RobotCommands
+		wantErr    bool
+	}{
+		{"dashboard=N,S", "dashboard", robot.RobotCommands{robot.North, robot.South}, false},
+		{" kiosk = e ", "kiosk", robot.RobotCommands{robot.East}, false},
+		{"viewer=", "viewer", robot.RobotCommands{}, false},
+		{"dashboard", "", nil, true},
+		{"=N", "", nil, true},
+		{"dashboard=N,X", "", nil, true},
+	}
+	for _, tt := range tests {
+		client, allowed, err := ParseCommandAllowlist(tt.raw)
+		if (err != nil) != tt.wantErr || client != tt.wantClient || !slices.Equal(allowed, tt.want) {
+			t.Errorf("ParseCommandAllowlist(%q) = %q, %v, %v, want %q, %v, error %t", tt.raw, client, allowed, err, tt.wantClient, tt.want, tt.wantErr)
+		}
+	}
+}
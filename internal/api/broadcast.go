@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// BroadcastRequest represents the request body for enqueuing the same task in several zones.
+// @Description Request body for enqueuing the same commands in several warehouse zones
+type BroadcastRequest struct {
+	Commands             string   `json:"commands" example:"N E S W"`                              // Commands to be executed by the robot of every zone
+	DelayBetweenCommands string   `json:"delay_between_commands" binding:"omitempty" example:"1s"` // Delay between executing commands, optional
+	Zones                []string `json:"zones" binding:"omitempty" example:"north,south"`         // Zones to enqueue the task in, all zones if omitted
+}
+
+// BroadcastZoneResult reports the outcome of a broadcast task in one zone.
+// @Description Outcome of a broadcast task in one zone, either the ID of the enqueued task or the error
+type BroadcastZoneResult struct {
+	TaskID string `json:"task_id,omitempty" example:"12345"`      // ID of the enqueued task, if the zone accepted it
+	Code   string `json:"code,omitempty" example:"OUT_OF_BOUNDS"` // Machine-readable error code, if the zone rejected the task
+	Error  string `json:"error,omitempty"`                        // Error message, if the zone rejected the task
+}
+
+// BroadcastResponse reports the outcome of a broadcast task.
+// @Description Outcome of a broadcast task, every zone accepts or rejects it independently of the others
+type BroadcastResponse struct {
+	Accepted int                            `json:"accepted" example:"2"` // Number of zones that enqueued the task
+	Rejected int                            `json:"rejected" example:"1"` // Number of zones that rejected the task
+	Zones    map[string]BroadcastZoneResult `json:"zones"`                // Outcome per zone name
+}
+
+// BroadcastTask handles the request to enqueue the same task in several warehouse zones at once.
+// @Summary Broadcast a task to several zones
+// @Description Enqueue the same commands in every listed zone, or in all zones. Each zone validates the task against the projected position of its own robot, so some zones may reject it while others run it. An unknown zone rejects the whole request before anything is enqueued.
+// @Accept json
+// @Produce json
+// @Param request body BroadcastRequest true "Broadcast Request"
+// @Success 202 {object} BroadcastResponse "Outcome per zone"
+// @Failure 400 {object} ErrorResponse "Error message"
+// @Failure 403 {object} ErrorResponse "The server is a read-only observer"
+// @Failure 404 {object} ErrorResponse "Unknown zone"
+// @Failure 503 {object} ErrorResponse "A zone has not started processing its queue yet"
+// @Router /warehouses/broadcast [post]
+// @Tags Warehouses
+func BroadcastTask(zones *robot.Zones) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BroadcastRequest
+		if err := bindJSON(c, &req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		results, err := zones.Broadcast(robot.TaskSpec{
+			Commands:             req.Commands,
+			DelayBetweenCommands: req.DelayBetweenCommands,
+			CorrelationID:        correlationID(c),
+			AllowedCommands:      allowedCommands(c),
+		}, req.Zones...)
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		response := BroadcastResponse{Zones: make(map[string]BroadcastZoneResult, len(results))}
+		for zone, result := range results {
+			if result.Err != nil {
+				response.Zones[zone] = BroadcastZoneResult{Code: errorCode(result.Err), Error: result.Err.Error()}
+				response.Rejected++
+				continue
+			}
+			response.Zones[zone] = BroadcastZoneResult{TaskID: result.TaskID}
+			response.Accepted++
+		}
+		respondJSON(c, http.StatusAccepted, response)
+	}
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// camelCaseKey is the gin context key marking requests whose JSON responses use camelCase field names.
+const camelCaseKey = "camel_case_json"
+
+// CamelCaseJSON returns a middleware making the JSON responses of a request use camelCase field names,
+// e.g. taskId instead of task_id, for clients whose ecosystem expects them. Request bodies keep snake_case.
+func CamelCaseJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(camelCaseKey, true)
+		c.Next()
+	}
+}
+
+// respondJSON writes obj as JSON, with camelCase field names if the request asks for them.
+func respondJSON(c *gin.Context, status int, obj any) {
+	if !c.GetBool(camelCaseKey) {
+		c.JSON(status, obj)
+		return
+	}
+	body, err := marshalCamelCase(obj)
+	if err != nil {
+		c.JSON(status, obj) // Cannot happen for values encoding/json accepts, keep the response usable anyway
+		return
+	}
+	c.Data(status, "application/json; charset=utf-8", body)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// marshalCamelCase encodes v like encoding/json, but with the field names of structs and of gin.H style maps
+// converted to camelCase. Keys of typed maps are data, e.g. task IDs or state names, and are kept as they are.
+// Types with their own JSON or text encoding, e.g. time.Time and robot.TaskState, are encoded as usual.
+func marshalCamelCase(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCamelCase(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCamelCase(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+	if ownEncoding(v) {
+		return writeJSON(buf, v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeCamelCase(buf, v.Elem())
+
+	case reflect.Struct:
+		buf.WriteByte('{')
+		first := true
+		if err := writeFields(buf, v, &first); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+		return nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return writeJSON(buf, v.Interface())
+		}
+		renameKeys := v.Type().Elem().Kind() == reflect.Interface // gin.H and map[string]any hold fields, not data
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			name := key.String()
+			if renameKeys {
+				name = camelCase(name)
+			}
+			if err := writeJSON(buf, name); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCamelCase(buf, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return writeJSON(buf, v.Interface()) // null, or base64 for []byte
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCamelCase(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+	return writeJSON(buf, v.Interface())
+}
+
+// writeFields writes the exported fields of a struct following the encoding/json tag rules,
+// inlining the fields of embedded structs without a name.
+func writeFields(buf *bytes.Buffer, v reflect.Value, first *bool) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		value := v.Field(i)
+		if field.Anonymous && name == "" && !ownEncoding(value) {
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				if err := writeFields(buf, value, first); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if strings.Contains(options, "omitempty") && isEmpty(value) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if !*first {
+			buf.WriteByte(',')
+		}
+		*first = false
+		if err := writeJSON(buf, camelCase(name)); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+		if err := writeCamelCase(buf, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ownEncoding reports whether the value is encoded by its own MarshalJSON or MarshalText method.
+func ownEncoding(v reflect.Value) bool {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return false
+	}
+	t := v.Type()
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// isEmpty reports whether encoding/json omits the value for the omitempty option.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Struct:
+		return false
+	}
+	return v.IsZero()
+}
+
+// writeJSON appends the encoding/json encoding of v.
+func writeJSON(buf *bytes.Buffer, v any) error {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(encoded)
+	return nil
+}
+
+// camelCase converts a snake_case name to camelCase, e.g. task_id to taskId. The first letter is lowered,
+// so exported Go field names without a JSON tag, e.g. Index, become index as well.
+func camelCase(name string) string {
+	var out strings.Builder
+	upper := false
+	for i, r := range name {
+		switch {
+		case r == '_' && i > 0:
+			upper = true
+		case upper:
+			out.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			out.WriteRune(r)
+		}
+	}
+	converted := out.String()
+	if converted == "" {
+		return ""
+	}
+	first, size := utf8.DecodeRuneInString(converted)
+	return string(unicode.ToLower(first)) + converted[size:]
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// TestCamelCaseJSON tests that the same responses use snake_case field names by default and camelCase when
+// configured, while task IDs used as map keys are kept as they are.
+func TestCamelCaseJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		camelCase bool
+		fields    []string // Field names of the state, a task and the add task response
+	}{
+		{"Snake case", false, []string{"robot_state", "current_task_count", "delay_between_commands", "sequence_num", "task_id", "normalized_commands"}},
+		{"Camel case", true, []string{"robotState", "currentTaskCount", "delayBetweenCommands", "sequenceNum", "taskId", "normalizedCommands"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			serviceConfig := robot.DefaultConfig()
+			serviceConfig.TaskIDPrefix = "wh_a-"
+			service, err := robot.NewEmbeddedService(ctx, serviceConfig)
+			if err != nil {
+				t.Fatalf("Failed to create service: %v", err)
+			}
+			waitStarted(t, service)
+			taskID, _ := service.EnqueueTask("N E", "1h")
+
+			config := DefaultConfig()
+			config.CamelCaseJSON = tt.camelCase
+			router := setupRouter()
+			SetupRouter(router, service, config)
+			serve := func(method, path, body string) map[string]any {
+				t.Helper()
+				req, _ := http.NewRequest(method, path, strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				var response map[string]any
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("%s %s: failed to parse response body %q: %v", method, path, w.Body.String(), err)
+				}
+				return response
+			}
+
+			state := serve("GET", "/api/v1/robot/state", "")
+			for _, field := range tt.fields[:2] {
+				if _, exists := state[field]; !exists {
+					t.Errorf("Expected the state field %s, got %v", field, state)
+				}
+			}
+			task, exists := state["tasks"].(map[string]any)[taskID].(map[string]any)
+			if !exists {
+				t.Fatalf("Expected task %s under its own ID, got %v", taskID, state["tasks"])
+			}
+			for _, field := range tt.fields[2:4] {
+				if _, exists := task[field]; !exists {
+					t.Errorf("Expected the task field %s, got %v", field, task)
+				}
+			}
+			if task["commands"] != "N E" || task["state"] != "Pending" {
+				t.Errorf("Expected the values to be unchanged, got commands %v and state %v", task["commands"], task["state"])
+			}
+
+			added := serve("POST", "/api/v1/robot/tasks", `{"commands": "N"}`)
+			for _, field := range tt.fields[4:] {
+				if _, exists := added[field]; !exists {
+					t.Errorf("Expected the response field %s, got %v", field, added)
+				}
+			}
+		})
+	}
+}
+
+// TestMarshalCamelCase tests the encoding of an event with nested structs, omitted fields and timestamps.
+func TestMarshalCamelCase(t *testing.T) {
+	event := robot.TaskStatusUpdateEvent{
+		Seq:       7,
+		TaskID:    "task_1",
+		State:     robot.InProgress,
+		Timestamp: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Type:      robot.EventTypeProgress,
+		Heartbeat: &robot.ProgressHeartbeat{CommandsExecuted: 2, TotalCommands: 5, Position: robot.Coord{X: 0, Y: 2}},
+	}
+	encoded, err := marshalCamelCase(event)
+	if err != nil {
+		t.Fatalf("Failed to encode the event: %v", err)
+	}
+	want := `{"seq":7,"taskId":"task_1","state":"InProgress","timestamp":"2024-01-15T10:30:00Z","type":"progress",` +
+		`"heartbeat":{"commandsExecuted":2,"totalCommands":5,"position":{"x":0,"y":2}}}`
+	if string(encoded) != want {
+		t.Errorf("Expected %s, got %s", want, encoded)
+	}
+
+	if name := camelCase("events_dropped_total"); name != "eventsDroppedTotal" {
+		t.Errorf("Expected eventsDroppedTotal, got %s", name)
+	}
+}
+
+// TestCamelCaseJSON_WebSocket tests that WebSocket messages use camelCase field names when configured.
+func TestCamelCaseJSON_WebSocket(t *testing.T) {
+	mockService := NewMockRobotService()
+	mockService.state.Tasks["task-1"] = robot.RobotTask{ID: "task-1", State: robot.Pending}
+	router := setupRouter()
+	router.GET("/robot/events", CamelCaseJSON(), TaskStatusWebSocket(mockService))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/robot/events", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect WebSocket: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	if err := conn.WriteJSON(WebSocketRequest{ID: "msg-1", Action: "cancel", TaskID: "task-1"}); err != nil {
+		t.Fatalf("Failed to send cancel request: %v", err)
+	}
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("Failed to read ack: %v", err)
+	}
+	if !strings.Contains(string(message), `"taskId":"task-1"`) || strings.Contains(string(message), "task_id") {
+		t.Errorf("Expected the ack with camelCase fields, got %s", message)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// Config holds the settings of the HTTP API layer.
+type Config struct {
+	RelaxedJSON bool `json:"relaxed_json"` // Accept trailing commas and `//` comments in request bodies
+	Debug       bool `json:"debug"`        // Enable development-only endpoints, never enable in production
+
+	// Hide internal task fields (sequence number, raw error message) in the state, task list and group responses
+	PublicTaskView bool `json:"public_task_view"`
+
+	// Tag the tasks created by a request, and all their events, with the X-Request-ID of the request
+	CorrelationIDs bool `json:"correlation_ids"`
+
+	// Name the fields of JSON responses and WebSocket events in camelCase, e.g. taskId, instead of snake_case
+	CamelCaseJSON bool `json:"camel_case_json"`
+
+	// Serve as a read-only observer, e.g. for a dashboard: mutating endpoints answer 403 and WebSocket
+	// control messages are refused, while state, stats, the grid and events stay available
+	ReadOnly bool `json:"read_only"`
+
+	// Commands every client may use in the tasks it submits, keyed by the X-Client-ID header of the client,
+	// clients without an allowlist may use every command
+	CommandAllowlists map[string]robot.RobotCommands `json:"command_allowlists,omitempty"`
+
+	// Content type of the grid endpoint when the Accept header allows any format, see ParseGridFormat
+	GridFormat string `json:"grid_format"`
+
+	ReadTimeout    time.Duration `json:"read_timeout"`    // Maximum duration for reading an entire request
+	WriteTimeout   time.Duration `json:"write_timeout"`   // Maximum duration before timing out writes of a response
+	IdleTimeout    time.Duration `json:"idle_timeout"`    // Maximum time to wait for the next request on keep-alive connections
+	HandlerTimeout time.Duration `json:"handler_timeout"` // Deadline for synchronous handlers, streaming endpoints are excluded
+
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"` // Time allowed for in-flight requests to finish on shutdown
+
+	// Time between two messages of the telemetry stream, clients may override it per connection
+	TelemetryInterval time.Duration `json:"telemetry_interval"`
+}
+
+// DefaultConfig returns the API configuration used when no explicit configuration is provided.
+func DefaultConfig() Config {
+	return Config{
+		RelaxedJSON:    false, // Strict JSON parsing by default
+		Debug:          false, // Development-only endpoints are disabled by default
+		GridFormat:     binding.MIMEJSON,
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   30 * time.Second,
+		IdleTimeout:    60 * time.Second,
+		HandlerTimeout: 15 * time.Second,
+
+		ShutdownTimeout: 10 * time.Second,
+
+		TelemetryInterval: time.Second,
+	}
+}
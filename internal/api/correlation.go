@@ -0,0 +1,48 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CorrelationIDHeader is the request and response header carrying the correlation ID of a request.
+const CorrelationIDHeader = "X-Request-ID"
+
+// correlationIDKey is the gin context key holding the correlation ID of the request.
+const correlationIDKey = "correlation_id"
+
+// maxCorrelationIDLength bounds client supplied correlation IDs, longer ones are replaced.
+const maxCorrelationIDLength = 128
+
+// CorrelationID returns a middleware assigning every request a correlation ID, taken from the X-Request-ID header
+// or generated if the client sent none or an invalid one. The ID is echoed in the response header and stored
+// on the tasks the request creates, so clients can match the task events to their API calls.
+func CorrelationID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(CorrelationIDHeader)
+		if !validCorrelationID(id) {
+			id = uuid.NewString()
+		}
+		c.Set(correlationIDKey, id)
+		c.Header(CorrelationIDHeader, id)
+		c.Next()
+	}
+}
+
+// validCorrelationID reports whether id is a non-empty printable ASCII string of acceptable length.
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// correlationID returns the correlation ID of the request, empty unless the CorrelationID middleware is installed.
+func correlationID(c *gin.Context) string {
+	return c.GetString(correlationIDKey)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// Test that a task created with a correlation header carries the ID in its events and that the ID is echoed
+func TestCorrelationID(t *testing.T) {
+	service := robot.NewService(context.Background(), make(chan string, 10))
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+	router := setupRouter()
+	router.Use(CorrelationID())
+	router.POST("/robot/tasks", AddTask(service))
+
+	tests := []struct {
+		name   string
+		header string
+		want   string // Expected correlation ID, generated if empty
+	}{
+		{"Client ID", "req-42", "req-42"},
+		{"Generated ID", "", ""},
+		{"Invalid ID replaced", "two words", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/robot/tasks", strings.NewReader(`{"commands": "N"}`))
+			if tt.header != "" {
+				req.Header.Set(CorrelationIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusAccepted {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+			}
+
+			echoed := w.Header().Get(CorrelationIDHeader)
+			if echoed == "" || (tt.want != "" && echoed != tt.want) || (tt.want == "" && echoed == tt.header) {
+				t.Errorf("Expected correlation ID %q to be echoed, got %q", tt.want, echoed)
+			}
+			var response map[string]any
+			json.Unmarshal(w.Body.Bytes(), &response)
+			select {
+			case event := <-sub.Events():
+				if event.TaskID != response["task_id"] || event.CorrelationID != echoed {
+					t.Errorf("Expected the event of task %v to carry %q, got %+v", response["task_id"], echoed, event)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Did not receive the task event")
+			}
+		})
+	}
+}
+
+// Test that tasks are created without a correlation ID unless the middleware is installed
+func TestCorrelationID_Disabled(t *testing.T) {
+	service := robot.NewService(context.Background(), make(chan string, 10))
+	router := setupRouter()
+	router.POST("/robot/tasks", AddTask(service))
+
+	req := httptest.NewRequest("POST", "/robot/tasks", strings.NewReader(`{"commands": "N"}`))
+	req.Header.Set(CorrelationIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]any
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if task := service.CurrentState().Tasks[response["task_id"].(string)]; task.CorrelationID != "" || w.Header().Get(CorrelationIDHeader) != "" {
+		t.Errorf("Expected no correlation ID, got %q", task.CorrelationID)
+	}
+}
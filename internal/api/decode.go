@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// relaxedJSONKey is the gin context key marking requests that may use relaxed JSON syntax.
+const relaxedJSONKey = "relaxed_json"
+
+// RelaxedJSON returns a middleware enabling relaxed JSON parsing for the request bodies of the routes it is applied to.
+func RelaxedJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(relaxedJSONKey, true)
+		c.Next()
+	}
+}
+
+// bindJSON decodes and validates the JSON request body into obj.
+// When relaxed parsing is enabled for the route, trailing commas and `//` comments are tolerated.
+func bindJSON(c *gin.Context, obj any) error {
+	if !c.GetBool(relaxedJSONKey) {
+		return c.ShouldBindJSON(obj)
+	}
+
+	if c.Request.Body == nil {
+		return fmt.Errorf("request body is empty")
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %v", err)
+	}
+
+	return binding.JSON.BindBody(relaxJSON(body), obj)
+}
+
+// relaxJSON converts relaxed JSON into strict JSON by removing `//` line comments
+// and trailing commas before closing brackets. String literals are left untouched.
+func relaxJSON(data []byte) []byte {
+	result := make([]byte, 0, len(data))
+	inString := false
+	escaped := false
+
+	for i := 0; i < len(data); i++ {
+		ch := data[i]
+
+		if inString {
+			result = append(result, ch)
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case ch == '"':
+			inString = true
+			result = append(result, ch)
+		case ch == '/' && i+1 < len(data) && data[i+1] == '/':
+			// Skip the comment up to the end of the line
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				result = append(result, '\n')
+			}
+		case ch == ',' && (nextSignificant(data, i+1) == '}' || nextSignificant(data, i+1) == ']'):
+			// Drop trailing comma
+		default:
+			result = append(result, ch)
+		}
+	}
+
+	return result
+}
+
+// nextSignificant returns the next character from position start that is neither whitespace nor part of a comment.
+// It returns 0 if the end of data is reached.
+func nextSignificant(data []byte, start int) byte {
+	for i := start; i < len(data); i++ {
+		switch ch := data[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			continue
+		case ch == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		default:
+			return ch
+		}
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const relaxedTaskBody = `{
+	// Move in a square
+	"commands": "N E S W", // back to start
+	"delay_between_commands": "1s",
+}`
+
+// Test that relaxed JSON is accepted when relaxed parsing is enabled
+func TestAddTask_RelaxedJSONAccepted(t *testing.T) {
+	mockService := NewMockRobotService()
+	router := setupRouter()
+	router.Use(RelaxedJSON())
+	router.POST("/robot/tasks", AddTask(mockService))
+
+	req, _ := http.NewRequest("POST", "/robot/tasks", strings.NewReader(relaxedTaskBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	if len(mockService.enqueuedTasks) != 1 {
+		t.Fatalf("Expected 1 enqueued task, got %d", len(mockService.enqueuedTasks))
+	}
+	if mockService.enqueuedTasks[0].commands != "N E S W" {
+		t.Errorf("Expected commands 'N E S W', got '%s'", mockService.enqueuedTasks[0].commands)
+	}
+	if mockService.enqueuedTasks[0].delayBetweenCommands != "1s" {
+		t.Errorf("Expected delay '1s', got '%s'", mockService.enqueuedTasks[0].delayBetweenCommands)
+	}
+}
+
+// Test that the same relaxed JSON is rejected with strict parsing
+func TestAddTask_RelaxedJSONRejectedWhenStrict(t *testing.T) {
+	mockService := NewMockRobotService()
+	router := setupRouter()
+	router.POST("/robot/tasks", AddTask(mockService))
+
+	req, _ := http.NewRequest("POST", "/robot/tasks", strings.NewReader(relaxedTaskBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if len(mockService.enqueuedTasks) != 0 {
+		t.Errorf("Expected no enqueued tasks, got %d", len(mockService.enqueuedTasks))
+	}
+}
+
+func TestRelaxJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Strict JSON unchanged", `{"a": [1, 2]}`, `{"a": [1, 2]}`},
+		{"Trailing comma in object", `{"a": 1,}`, `{"a": 1}`},
+		{"Trailing comma in array", `[1, 2, ]`, `[1, 2 ]`},
+		{"Line comment removed", "{\"a\": 1 // note\n}", "{\"a\": 1 \n}"},
+		{"Comment markers inside strings kept", `{"url": "http://x,}"}`, `{"url": "http://x,}"}`},
+		{"Escaped quote inside string", `{"a": "\"//,]"}`, `{"a": "\"//,]"}`},
+		{"Trailing comma before comment", "{\"a\": 1, // note\n}", "{\"a\": 1 \n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(relaxJSON([]byte(tt.input))); got != tt.want {
+				t.Errorf("relaxJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
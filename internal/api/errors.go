@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// Machine-readable error codes returned in ErrorResponse.Code.
+const (
+	CodeInvalidRequest     = "INVALID_REQUEST"      // Malformed request, e.g. invalid JSON or a missing field
+	CodeInvalidCommand     = "INVALID_COMMAND"      // Command sequence or delay cannot be parsed
+	CodeTaskNotFound       = "TASK_NOT_FOUND"       // No task with the given ID
+	CodeQueueFull          = "QUEUE_FULL"           // Task queue has no free slot, retry later
+	CodeTaskLimit          = "TASK_LIMIT"           // Maximum number of stored tasks reached, purge finished tasks
+	CodeQuiescing          = "QUIESCING"            // Service is draining its queue and rejects new tasks
+	CodeOutOfBounds        = "OUT_OF_BOUNDS"        // Robot would leave the warehouse
+	CodeInvalidState       = "INVALID_STATE"        // Operation not allowed in the current state
+	CodeBatteryDepleted    = "BATTERY_DEPLETED"     // Robot battery would run out away from home
+	CodeZoneNotFound       = "ZONE_NOT_FOUND"       // No warehouse zone with the given name
+	CodeGroupNotFound      = "GROUP_NOT_FOUND"      // No task belongs to the given group
+	CodeCellBlocked        = "CELL_BLOCKED"         // Target cell temporarily occupied
+	CodeObstacle           = "OBSTACLE"             // Target cell holds a permanent obstacle
+	CodeTooManySubscribers = "TOO_MANY_SUBSCRIBERS" // Event subscriber cap reached
+	CodeRequestExpired     = "REQUEST_EXPIRED"      // Handler deadline passed or client went away
+	CodeShuttingDown       = "SHUTTING_DOWN"        // Server is shutting down
+	CodeCommandNotAllowed  = "COMMAND_NOT_ALLOWED"  // Command not in the allowed set of the client
+	CodeNotStarted         = "NOT_STARTED"          // Robot service has not started processing tasks yet
+	CodeRobotOffGrid       = "ROBOT_OFF_GRID"       // Robot is outside the warehouse, tasks are rejected until it is moved back
+	CodePathTooLong        = "PATH_TOO_LONG"        // Task would traverse more cells than the configured maximum
+	CodeReadOnly           = "READ_ONLY"            // Server is a read-only observer, mutating endpoints are disabled
+	CodeNoCheckpoint       = "NO_CHECKPOINT"        // No checkpoint was set to return to
+	CodePendingLimit       = "PENDING_LIMIT"        // Maximum number of pending tasks reached, retry once some are dispatched
+)
+
+// errorCodes maps the robot sentinel errors to their error code.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{robot.ErrInvalidCommand, CodeInvalidCommand},
+	{robot.ErrTaskNotFound, CodeTaskNotFound},
+	{robot.ErrQueueFull, CodeQueueFull},
+	{robot.ErrTaskLimit, CodeTaskLimit},
+	{robot.ErrQuiescing, CodeQuiescing},
+	{robot.ErrOutOfBounds, CodeOutOfBounds},
+	{robot.ErrInvalidState, CodeInvalidState},
+	{robot.ErrBatteryDepleted, CodeBatteryDepleted},
+	{robot.ErrZoneNotFound, CodeZoneNotFound},
+	{robot.ErrGroupNotFound, CodeGroupNotFound},
+	{robot.ErrCellBlocked, CodeCellBlocked},
+	{robot.ErrObstacle, CodeObstacle},
+	{robot.ErrTooManySubscribers, CodeTooManySubscribers},
+	{robot.ErrShuttingDown, CodeShuttingDown},
+	{robot.ErrCommandNotAllowed, CodeCommandNotAllowed},
+	{robot.ErrNotStarted, CodeNotStarted},
+	{robot.ErrRobotOffGrid, CodeRobotOffGrid},
+	{robot.ErrPathTooLong, CodePathTooLong},
+	{robot.ErrNoCheckpoint, CodeNoCheckpoint},
+	{robot.ErrPendingLimit, CodePendingLimit},
+	{context.DeadlineExceeded, CodeRequestExpired},
+	{context.Canceled, CodeRequestExpired},
+}
+
+// errorCode returns the error code for err, falling back to CodeInvalidRequest for errors
+// that do not wrap a known sentinel error.
+func errorCode(err error) string {
+	for _, mapping := range errorCodes {
+		if errors.Is(err, mapping.err) {
+			return mapping.code
+		}
+	}
+	return CodeInvalidRequest
+}
+
+// errorStatus returns the HTTP status for err, using fallback unless the error calls for a specific status.
+func errorStatus(err error, fallback int) int {
+	if errors.Is(err, robot.ErrQueueFull) || errors.Is(err, robot.ErrTaskLimit) || errors.Is(err, robot.ErrPendingLimit) || errors.Is(err, robot.ErrQuiescing) || errors.Is(err, robot.ErrShuttingDown) ||
+		errors.Is(err, robot.ErrNotStarted) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return http.StatusServiceUnavailable // The service cannot take the task now
+	}
+	if errors.Is(err, robot.ErrCommandNotAllowed) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, robot.ErrRobotOffGrid) {
+		return http.StatusConflict // The robot must be moved back first
+	}
+	if errors.Is(err, robot.ErrNoCheckpoint) {
+		return http.StatusConflict // A checkpoint must be set first
+	}
+	return fallback
+}
+
+// respondError writes an ErrorResponse with the code derived from err and its message.
+func respondError(c *gin.Context, status int, err error) {
+	respondJSON(c, status, ErrorResponse{Code: errorCode(err), Error: err.Error()})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// Test that each error scenario is answered with the matching code and a human-readable message
+func TestErrorResponse_Codes(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		setup    func(m *MockRobotService)
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name: "Invalid command", method: "POST", path: "/robot/tasks", body: `{"commands": "N X"}`,
+			setup: func(m *MockRobotService) {
+				m.shouldFailEnqueue = true
+				m.enqueueError = fmt.Errorf("%w: X", robot.ErrInvalidCommand)
+			},
+			wantCode: http.StatusBadRequest, wantErr: CodeInvalidCommand,
+		},
+		{
+			name: "Queue full", method: "POST", path: "/robot/tasks", body: `{"commands": "N"}`,
+			setup: func(m *MockRobotService) {
+				m.shouldFailEnqueue = true
+				m.enqueueError = fmt.Errorf("%w: 100 tasks waiting", robot.ErrQueueFull)
+			},
+			wantCode: http.StatusServiceUnavailable, wantErr: CodeQueueFull,
+		},
+		{
+			name: "Quiescing", method: "POST", path: "/robot/tasks", body: `{"commands": "N"}`,
+			setup: func(m *MockRobotService) {
+				m.shouldFailEnqueue = true
+				m.enqueueError = robot.ErrQuiescing
+			},
+			wantCode: http.StatusServiceUnavailable, wantErr: CodeQuiescing,
+		},
+		{
+			name: "Task limit", method: "POST", path: "/robot/tasks", body: `{"commands": "N"}`,
+			setup: func(m *MockRobotService) {
+				m.shouldFailEnqueue = true
+				m.enqueueError = robot.ErrTaskLimit
+			},
+			wantCode: http.StatusServiceUnavailable, wantErr: CodeTaskLimit,
+		},
+		{
+			name: "Pending limit", method: "POST", path: "/robot/tasks", body: `{"commands": "N"}`,
+			setup: func(m *MockRobotService) {
+				m.shouldFailEnqueue = true
+				m.enqueueError = robot.ErrPendingLimit
+			},
+			wantCode: http.StatusServiceUnavailable, wantErr: CodePendingLimit,
+		},
+		{
+			name: "Shutting down", method: "POST", path: "/robot/tasks", body: `{"commands": "N"}`,
+			setup: func(m *MockRobotService) {
+				m.shouldFailEnqueue = true
+				m.enqueueError = robot.ErrShuttingDown
+			},
+			wantCode: http.StatusServiceUnavailable, wantErr: CodeShuttingDown,
+		},
+		{
+			name: "Request expired while waiting for the service", method: "POST", path: "/robot/tasks", body: `{"commands": "N"}`,
+			setup: func(m *MockRobotService) {
+				m.shouldFailEnqueue = true
+				m.enqueueError = fmt.Errorf("task not submitted: %w", context.DeadlineExceeded)
+			},
+			wantCode: http.StatusServiceUnavailable, wantErr: CodeRequestExpired,
+		},
+		{
+			name: "Command not allowed", method: "POST", path: "/robot/tasks", body: `{"commands": "N"}`,
+			setup: func(m *MockRobotService) {
+				m.shouldFailEnqueue = true
+				m.enqueueError = fmt.Errorf("%w: command 1 (N) is not in the allowed set E W", robot.ErrCommandNotAllowed)
+			},
+			wantCode: http.StatusForbidden, wantErr: CodeCommandNotAllowed,
+		},
+		{
+			name: "Robot off grid", method: "POST", path: "/robot/tasks", body: `{"commands": "N"}`,
+			setup: func(m *MockRobotService) {
+				m.shouldFailEnqueue = true
+				m.enqueueError = fmt.Errorf("%w: robot is at (12, 0)", robot.ErrRobotOffGrid)
+			},
+			wantCode: http.StatusConflict, wantErr: CodeRobotOffGrid,
+		},
+		{
+			name: "Malformed body", method: "POST", path: "/robot/tasks", body: `{"commands":`,
+			wantCode: http.StatusBadRequest, wantErr: CodeInvalidRequest,
+		},
+		{
+			name: "Task not found", method: "PUT", path: "/robot/tasks/missing/cancel",
+			setup: func(m *MockRobotService) {
+				m.shouldFailCancel = true
+				m.cancelError = fmt.Errorf("%w: missing", robot.ErrTaskNotFound)
+			},
+			wantCode: http.StatusBadRequest, wantErr: CodeTaskNotFound,
+		},
+		{
+			name: "Task not cancellable", method: "PUT", path: "/robot/tasks/done/cancel",
+			setup: func(m *MockRobotService) {
+				m.shouldFailCancel = true
+				m.cancelError = fmt.Errorf("%w: task done is 'Completed' state", robot.ErrInvalidState)
+			},
+			wantCode: http.StatusConflict, wantErr: CodeInvalidState,
+		},
+		{
+			name: "Patrol out of bounds", method: "POST", path: "/robot/tasks/patrol", body: `{"width": 6, "height": 2}`,
+			setup: func(m *MockRobotService) {
+				m.state.RobotState = robot.RobotState{X: 5, Y: 5}
+			},
+			wantCode: http.StatusBadRequest, wantErr: CodeOutOfBounds,
+		},
+		{
+			name: "Position out of bounds", method: "PUT", path: "/robot/position", body: `{"x": 10, "y": 0}`,
+			wantCode: http.StatusBadRequest, wantErr: CodeOutOfBounds,
+		},
+		{
+			name: "Reset while running", method: "POST", path: "/robot/reset",
+			setup: func(m *MockRobotService) {
+				m.resetError = fmt.Errorf("%w: cannot reset while task t is 'InProgress'", robot.ErrInvalidState)
+			},
+			wantCode: http.StatusConflict, wantErr: CodeInvalidState,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := NewMockRobotService()
+			if tt.setup != nil {
+				tt.setup(mockService)
+			}
+			router := setupRouter()
+			config := DefaultConfig()
+			config.Debug = true
+			SetupRouter(router, mockService, config)
+
+			req, _ := http.NewRequest(tt.method, "/api/v1"+tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Expected status code %d, got %d", tt.wantCode, w.Code)
+			}
+			var errorResponse ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &errorResponse); err != nil {
+				t.Fatalf("Failed to parse response body: %v", err)
+			}
+			if errorResponse.Code != tt.wantErr {
+				t.Errorf("Expected code %s, got '%s'", tt.wantErr, errorResponse.Code)
+			}
+			if errorResponse.Error == "" {
+				t.Error("Expected a human-readable error message")
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// MIMESVG is the content type of the SVG rendering of the grid.
+const MIMESVG = "image/svg+xml"
+
+// gridFormats maps the names of the grid formats to their content type, in the order they are offered.
+var gridFormats = []struct {
+	name string
+	mime string
+}{
+	{"json", binding.MIMEJSON},
+	{"text", binding.MIMEPlain},
+	{"svg", MIMESVG},
+}
+
+// gridFormatKey is the gin context key holding the content type the grid endpoint prefers.
+const gridFormatKey = "grid_format"
+
+// ParseGridFormat returns the content type of a grid format name: json, text or svg.
+func ParseGridFormat(raw string) (string, error) {
+	for _, format := range gridFormats {
+		if format.name == raw {
+			return format.mime, nil
+		}
+	}
+	return "", fmt.Errorf("invalid grid format %q, expected json, text or svg", raw)
+}
+
+// GridFormat returns a middleware making the grid endpoint answer with the given content type
+// when the Accept header of a request allows any format or is missing.
+func GridFormat(mime string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(gridFormatKey, mime)
+		c.Next()
+	}
+}
+
+// gridOffers returns the content types of the grid in the order of preference for the request.
+func gridOffers(c *gin.Context) []string {
+	preferred := c.GetString(gridFormatKey)
+	offers := []string{preferred}
+	for _, format := range gridFormats {
+		if format.mime != preferred {
+			offers = append(offers, format.mime)
+		}
+	}
+	return slices.DeleteFunc(offers, func(mime string) bool { return mime == "" })
+}
+
+// GetGrid handles the request to render the warehouse floor.
+// @Summary Get the warehouse grid
+// @Description Render the warehouse with the robot and the obstacles as JSON, as ASCII text ('R' robot, '#' obstacle, '.' free, north at the top) or as an SVG image, chosen by the Accept header
+// @Produce json
+// @Produce plain
+// @Produce image/svg+xml
+// @Success 200 {object} robot.Grid "Warehouse grid"
+// @Failure 406 {object} ErrorResponse "None of the grid formats is acceptable"
+// @Router /robot/grid [get]
+// @Tags Robot State
+func GetGrid(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		grid := service.Grid()
+		switch c.NegotiateFormat(gridOffers(c)...) {
+		case binding.MIMEJSON:
+			respondJSON(c, http.StatusOK, grid)
+		case binding.MIMEPlain:
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", grid.RenderASCII())
+		case MIMESVG:
+			c.Data(http.StatusOK, MIMESVG, grid.RenderSVG())
+		default:
+			respondJSON(c, http.StatusNotAcceptable, ErrorResponse{Code: CodeInvalidRequest, Error: "Accept must allow application/json, text/plain or image/svg+xml"})
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// Test that the grid endpoint renders the format chosen by the Accept header
+func TestGetGrid(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		defaultFormat   string
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{"No Accept header", "", binding.MIMEJSON, http.StatusOK, "application/json", `"robot":{"x":3,"y":2}`},
+		{"JSON", "application/json", MIMESVG, http.StatusOK, "application/json", `"size":10`},
+		{"ASCII", "text/plain", binding.MIMEJSON, http.StatusOK, "text/plain", "...R......\n"},
+		{"SVG", "image/svg+xml", binding.MIMEJSON, http.StatusOK, MIMESVG, `<circle class="robot" data-x="3" data-y="2"`},
+		{"First acceptable format", "image/png, image/svg+xml, application/json", binding.MIMEJSON, http.StatusOK, MIMESVG, "<svg "},
+		{"Any format uses the default", "*/*", binding.MIMEPlain, http.StatusOK, "text/plain", "R"},
+		{"Unsupported format", "image/png", binding.MIMEJSON, http.StatusNotAcceptable, "application/json", CodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := NewMockRobotService()
+			mockService.state.RobotState = robot.RobotState{X: 3, Y: 2}
+			router := setupRouter()
+			router.GET("/robot/grid", GridFormat(tt.defaultFormat), GetGrid(mockService))
+
+			req := httptest.NewRequest("GET", "/robot/grid", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("Expected status code %d, got %d", tt.wantCode, w.Code)
+			}
+			if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, tt.wantContentType) {
+				t.Errorf("Expected content type %s, got %s", tt.wantContentType, contentType)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body containing %q, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+// Test that the configured grid format is used by the registered routes
+func TestGetGrid_Config(t *testing.T) {
+	config := DefaultConfig()
+	format, err := ParseGridFormat("svg")
+	if err != nil {
+		t.Fatalf("Failed to parse the grid format: %v", err)
+	}
+	config.GridFormat = format
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupRouter(router, NewMockRobotService(), config)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/robot/grid", nil))
+	if contentType := w.Header().Get("Content-Type"); contentType != MIMESVG {
+		t.Errorf("Expected content type %s, got %s", MIMESVG, contentType)
+	}
+
+	if _, err := ParseGridFormat("png"); err == nil {
+		t.Error("Expected an error for an unknown grid format")
+	}
+	var grid robot.Grid
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/robot/grid", nil)
+	req.Header.Set("Accept", "application/json")
+	router.ServeHTTP(w, req)
+	if err := json.Unmarshal(w.Body.Bytes(), &grid); err != nil || grid.Size != 10 {
+		t.Errorf("Expected a JSON grid, got %s", w.Body.String())
+	}
+}
@@ -1,52 +1,676 @@
 package api
 
 import (
-	"log"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
 	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
 )
 
 // AddTaskRequest represents the request body for adding a new robot task.
 // @Description Request body for adding a new robot task
 type AddTaskRequest struct {
-	Commands             string `json:"commands" binding:"required" example:"N E S W"`           // Commands to be executed by the robot
+	Commands             string `json:"commands" example:"N E S W"`                                              // Commands to be executed by the robot, empty only if the service allows no-op tasks
+	DelayBetweenCommands string `json:"delay_between_commands" binding:"omitempty" example:"1s"`                 // Delay between executing commands, optional
+	Priority             int    `json:"priority" binding:"omitempty" example:"0"`                                // Priority of the task, higher runs first, optional
+	GroupID              string `json:"group_id" binding:"omitempty,max=64" example:"batch-42"`                  // Group to add the task to, optional
+	NewGroup             bool   `json:"new_group" example:"false"`                                               // Start a new group named after the task ID, optional
+	OnInvalid            string `json:"on_invalid" binding:"omitempty,oneof=abort skip replan" example:"replan"` // What happens if earlier tasks make the task invalid: abort (default), skip or replan, optional
+	Frame                string `json:"frame" binding:"omitempty,oneof=absolute relative" example:"relative"`    // Whether N, E, S and W are grid directions or relative to the robot heading, the service default if omitted
+	CaseInsensitive      bool   `json:"case_insensitive" example:"false"`                                        // Accept the commands in any case, e.g. "n e s w", optional. Always on if the service is configured so
+}
+
+// ReplaceTaskRequest represents the request body for replacing a task.
+// @Description Request body with the commands of the task replacing a pending or running task
+type ReplaceTaskRequest struct {
+	Commands             string `json:"commands" binding:"required" example:"N E"`               // Commands of the replacement task
+	DelayBetweenCommands string `json:"delay_between_commands" binding:"omitempty" example:"1s"` // Delay between executing commands, optional
+}
+
+// AddBatchRequest represents the request body for adding several tasks as one transaction.
+// @Description Request body for adding several tasks that are enqueued all together or not at all
+type AddBatchRequest struct {
+	Tasks []AddTaskRequest `json:"tasks" binding:"required,min=1,max=100,dive"` // Tasks in submission order, at most 100
+}
+
+// AddPatrolRequest represents the request body for adding a rectangular patrol task.
+// @Description Request body for adding a rectangular patrol task
+type AddPatrolRequest struct {
+	Width                uint   `json:"width" binding:"required,max=9" example:"3"`              // Width of the rectangle in commands, at most 9, less with a larger step size
+	Height               uint   `json:"height" binding:"required,max=9" example:"2"`             // Height of the rectangle in commands, at most 9, less with a larger step size
 	DelayBetweenCommands string `json:"delay_between_commands" binding:"omitempty" example:"1s"` // Delay between executing commands, optional
 }
 
+// FollowRequest represents the request body for starting a follow task or moving its target.
+// @Description Request body with the target cell of a follow task
+type FollowRequest struct {
+	X                    *int   `json:"x" binding:"required" example:"3"`                        // Target X coordinate
+	Y                    *int   `json:"y" binding:"required" example:"1"`                        // Target Y coordinate
+	DelayBetweenCommands string `json:"delay_between_commands" binding:"omitempty" example:"1s"` // Delay between moves, only used when starting the task, optional
+}
+
+// TaskStatusRequest represents the request body for querying the states of several tasks.
+// @Description Request body for querying the states of several tasks at once
+type TaskStatusRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1,max=500" example:"12345,67890"` // IDs of the tasks, at most 500
+}
+
+// TaskStatusResponse maps every requested task ID to its state.
+// @Description States of the requested tasks, unknown IDs are reported as NotFound
+type TaskStatusResponse struct {
+	States map[string]string `json:"states"` // Task ID to state, e.g. "Completed", or "NotFound"
+}
+
+// taskNotFoundState marks unknown task IDs in a TaskStatusResponse.
+const taskNotFoundState = "NotFound"
+
+// SetPositionRequest represents the request body for forcing the robot position.
+// @Description Request body for forcing the robot position, development only
+type SetPositionRequest struct {
+	X *int `json:"x" binding:"required" example:"9"` // Target X coordinate
+	Y *int `json:"y" binding:"required" example:"0"` // Target Y coordinate
+}
+
 // ErrorResponse represents a generic error response.
 // @Description Generic error response.
 type ErrorResponse struct {
-	Error string `json:"error" example:"Job not found"`
+	Code  string `json:"code" example:"TASK_NOT_FOUND"` // Machine-readable error code
+	Error string `json:"error" example:"Job not found"` // Human-readable error message
+}
+
+// requestExpired replies with 503 and returns true if the request context is already done,
+// e.g. because the handler deadline passed or the client went away.
+func requestExpired(c *gin.Context) bool {
+	if err := c.Request.Context().Err(); err != nil {
+		respondJSON(c, http.StatusServiceUnavailable, ErrorResponse{Code: CodeRequestExpired, Error: "request expired: " + err.Error()})
+		return true
+	}
+	return false
+}
+
+// includeStateParam is the query parameter asking a task endpoint to include the robot state in its response.
+const includeStateParam = "include_state"
+
+// respondTask replies 202 with the task response body, adding the current robot position as "robot_state"
+// if the client asked for it, which saves a separate state request.
+func respondTask(c *gin.Context, service robot.RobotService, includeState bool, body gin.H) {
+	if includeState {
+		body["robot_state"] = service.CurrentState().RobotState
+	}
+	respondJSON(c, http.StatusAccepted, body)
 }
 
 // AddTask handles the request to add a new robot task.
 // @Summary Add a new robot task
-// @Description Add a new robot task with commands and optional delay
+// @Description Add a new robot task with commands, optional delay and optional priority. Clients that cannot send JSON can pass the commands and the delay as query parameters instead of the body, e.g. ?commands=N+E+S+W&delay=1s
 // @Accept json
 // @Produce json
-// @Param request body AddTaskRequest true "Add Task Request"
-// @Success 202 {object} map[string]string "Task ID"
+// @Param request body AddTaskRequest false "Add Task Request, required unless the commands are passed as query parameter"
+// @Param commands query string false "Commands to be executed, instead of the request body"
+// @Param delay query string false "Delay between executing commands, only with the commands query parameter"
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "Task ID, normalized commands and optionally the robot state"
 // @Failure 400 {object} ErrorResponse "Error message"
+// @Failure 503 {object} ErrorResponse "Task queue is full or request timed out"
 // @Router /robot/tasks [post]
 // @Tags Robot Tasks
 func AddTask(service robot.RobotService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req AddTaskRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		req, err := bindAddTaskRequest(c)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		includeState, err := queryBool(c, includeStateParam)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		// Do not enqueue work for a request nobody waits for anymore
+		if requestExpired(c) {
+			return
+		}
+
+		// The deadline is checked again once the service lock is held, waiting for it can take a while
+		taskID, err := service.SubmitTaskContext(c.Request.Context(), robot.TaskSpec{
+			Commands:             req.Commands,
+			DelayBetweenCommands: req.DelayBetweenCommands,
+			Priority:             req.Priority,
+			GroupID:              req.GroupID,
+			NewGroup:             req.NewGroup,
+			OnInvalid:            req.OnInvalid,
+			Frame:                req.Frame,
+			CaseInsensitive:      req.CaseInsensitive,
+			CorrelationID:        correlationID(c),
+			AllowedCommands:      allowedCommands(c),
+		})
+		if err != nil {
+			respondError(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+
+		// Echo the canonical command sequence so clients can confirm what will run. The task was accepted,
+		// so its commands are upper case already unless they were parsed case-insensitively
+		normalized, _ := robot.NormalizeCommands(strings.ToUpper(req.Commands))
+		response := gin.H{"task_id": taskID, "normalized_commands": normalized}
+		if req.NewGroup {
+			response["group_id"] = taskID // A new group is named after its first task
+		} else if req.GroupID != "" {
+			response["group_id"] = req.GroupID
+		}
+		respondTask(c, service, includeState, response)
+	}
+}
+
+// bindAddTaskRequest reads the task from the commands and delay query parameters or else from the JSON body.
+// Exactly one of them must be sent.
+func bindAddTaskRequest(c *gin.Context) (AddTaskRequest, error) {
+	var req AddTaskRequest
+	commands, fromQuery := c.GetQuery("commands")
+	if !fromQuery {
+		if _, hasDelay := c.GetQuery("delay"); hasDelay {
+			return req, errors.New("the delay query parameter requires the commands query parameter")
+		}
+		err := bindJSON(c, &req)
+		return req, err
+	}
+	if c.Request.ContentLength != 0 {
+		return req, errors.New("send the task either as query parameters or as JSON body, not both")
+	}
+	req.Commands, req.DelayBetweenCommands = commands, c.Query("delay")
+	return req, nil
+}
+
+// AddBatchTask handles the request to add several tasks as one transaction.
+// @Summary Add several tasks atomically
+// @Description Enqueue a batch of dependent tasks all together or not at all. The tasks are validated against the robot positions projected from the queued tasks and the earlier tasks of the batch, the first invalid task rejects the whole batch.
+// @Accept json
+// @Produce json
+// @Param request body AddBatchRequest true "Add Batch Request"
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "Task IDs in submission order and optionally the robot state"
+// @Failure 400 {object} ErrorResponse "Invalid task, the whole batch was rejected"
+// @Failure 503 {object} ErrorResponse "Task queue is full"
+// @Router /robot/tasks/batch [post]
+// @Tags Robot Tasks
+func AddBatchTask(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AddBatchRequest
+		if err := bindJSON(c, &req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		includeState, err := queryBool(c, includeStateParam)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		specs := make([]robot.TaskSpec, len(req.Tasks))
+		for i, task := range req.Tasks {
+			specs[i] = robot.TaskSpec{
+				Commands:             task.Commands,
+				DelayBetweenCommands: task.DelayBetweenCommands,
+				Priority:             task.Priority,
+				GroupID:              task.GroupID,
+				NewGroup:             task.NewGroup,
+				OnInvalid:            task.OnInvalid,
+				Frame:                task.Frame,
+				CaseInsensitive:      task.CaseInsensitive,
+				CorrelationID:        correlationID(c),
+				AllowedCommands:      allowedCommands(c),
+			}
+		}
+
+		taskIDs, err := service.SubmitBatch(specs)
+		if err != nil {
+			respondError(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+
+		respondTask(c, service, includeState, gin.H{"task_ids": taskIDs})
+	}
+}
+
+// AddPatrolTask handles the request to add a patrol task tracing a closed rectangle from the current position.
+// @Summary Add a rectangular patrol task
+// @Description Generate and enqueue the commands for a closed rectangular loop starting and ending at the robot's current position. The width and height count commands, each moving the robot the configured step size. A loop leaving the warehouse or crossing an obstacle is rejected
+// @Accept json
+// @Produce json
+// @Param request body AddPatrolRequest true "Add Patrol Request"
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "Task ID and optionally the robot state"
+// @Failure 400 {object} ErrorResponse "The loop does not fit the warehouse or crosses an obstacle"
+// @Failure 503 {object} ErrorResponse "Task queue is full"
+// @Router /robot/tasks/patrol [post]
+// @Tags Robot Tasks
+func AddPatrolTask(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AddPatrolRequest
+		if err := bindJSON(c, &req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		includeState, err := queryBool(c, includeStateParam)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		taskID, err := service.EnqueuePatrol(req.Width, req.Height, robot.TaskSpec{
+			DelayBetweenCommands: req.DelayBetweenCommands,
+			AllowedCommands:      allowedCommands(c),
+		})
+		if err != nil {
+			respondError(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+
+		respondTask(c, service, includeState, gin.H{"task_id": taskID})
+	}
+}
+
+// AddRunToWallTask handles the request to add a task moving the robot as far as possible in one direction.
+// @Summary Add a run-to-wall task
+// @Description Enqueue the moves from the current position in a direction until the warehouse boundary or an obstacle, e.g. for calibration
+// @Produce json
+// @Param dir query string true "Direction to move in" Enums(N, E, S, W)
+// @Param delay_between_commands query string false "Delay between executing commands, e.g. 1s"
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "Task ID and optionally the robot state"
+// @Failure 400 {object} ErrorResponse "Invalid direction or robot already at the wall"
+// @Failure 503 {object} ErrorResponse "Task queue is full"
+// @Router /robot/tasks/run-to-wall [post]
+// @Tags Robot Tasks
+func AddRunToWallTask(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		direction := c.Query("dir")
+		if direction == "" {
+			respondJSON(c, http.StatusBadRequest, ErrorResponse{Code: CodeInvalidRequest, Error: "dir is required"})
+			return
+		}
+		includeState, err := queryBool(c, includeStateParam)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
 
-		taskID, err := service.EnqueueTask(req.Commands, req.DelayBetweenCommands)
+		taskID, err := service.EnqueueRunToWall(direction, robot.TaskSpec{
+			DelayBetweenCommands: c.Query("delay_between_commands"),
+			AllowedCommands:      allowedCommands(c),
+		})
 		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			respondError(c, errorStatus(err, http.StatusBadRequest), err)
 			return
 		}
 
-		c.JSON(http.StatusAccepted, gin.H{"task_id": taskID})
+		respondTask(c, service, includeState, gin.H{"task_id": taskID})
+	}
+}
+
+// AddPingTask handles the request to enqueue a ping task for health checks.
+// @Summary Add a ping task
+// @Description Enqueue a task without commands that is dispatched, publishes its events and completes like any other task without moving the robot, for end-to-end health checks
+// @Produce json
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "Task ID and optionally the robot state"
+// @Failure 503 {object} ErrorResponse "Task queue is full"
+// @Router /robot/tasks/ping [post]
+// @Tags Robot Tasks
+func AddPingTask(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		includeState, err := queryBool(c, includeStateParam)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		taskID, err := service.EnqueuePing()
+		if err != nil {
+			respondError(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+
+		respondTask(c, service, includeState, gin.H{"task_id": taskID})
+	}
+}
+
+// AddCompactTask handles the request to add a task from a compact command stream.
+// @Summary Add a task from a compact command stream
+// @Description Add a task whose commands are sent as base64 with 2 bits per command, for clients sending many commands at a high rate. The stream is a uvarint command count followed by the commands packed four per byte, most significant bits first, with N=0, E=1, S=2, W=3 and zero padding bits.
+// @Accept plain
+// @Produce json
+// @Param request body string true "Base64 compact command stream"
+// @Param delay_between_commands query string false "Delay between executing commands, e.g. 1s"
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "Task ID, normalized commands and optionally the robot state"
+// @Failure 400 {object} ErrorResponse "Malformed command stream or invalid task"
+// @Failure 503 {object} ErrorResponse "Task queue is full"
+// @Router /robot/tasks/compact [post]
+// @Tags Robot Tasks
+func AddCompactTask(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		includeState, err := queryBool(c, includeStateParam)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Errorf("failed to read request body: %v", err))
+			return
+		}
+
+		commands, err := robot.DecodeCompactCommands(strings.TrimSpace(string(body)))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		taskID, err := service.SubmitTask(robot.TaskSpec{
+			Commands:             commands.String(),
+			DelayBetweenCommands: c.Query("delay_between_commands"),
+			CorrelationID:        correlationID(c),
+			AllowedCommands:      allowedCommands(c),
+		})
+		if err != nil {
+			respondError(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+
+		respondTask(c, service, includeState, gin.H{"task_id": taskID, "normalized_commands": commands.String()})
+	}
+}
+
+// AddFollowTask handles the request to add a task chasing a target cell.
+// @Summary Add a follow task
+// @Description Enqueue a task moving the robot toward a target cell one move at a time, re-planning the shortest path around obstacles after every move. The target can be moved or cleared while the task runs. The task completes when the robot reaches the target or the target is cleared, and aborts with reason no_path if the target cannot be reached.
+// @Accept json
+// @Produce json
+// @Param request body FollowRequest true "Follow Request"
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "Task ID and optionally the robot state"
+// @Failure 400 {object} ErrorResponse "Target outside the warehouse or on an obstacle"
+// @Failure 503 {object} ErrorResponse "Task queue is full"
+// @Router /robot/tasks/follow [post]
+// @Tags Robot Tasks
+func AddFollowTask(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req FollowRequest
+		if err := bindJSON(c, &req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		includeState, err := queryBool(c, includeStateParam)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		taskID, err := service.Follow(robot.Coord{X: *req.X, Y: *req.Y}, robot.TaskSpec{
+			DelayBetweenCommands: req.DelayBetweenCommands,
+			AllowedCommands:      allowedCommands(c),
+		})
+		if err != nil {
+			respondError(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+
+		respondTask(c, service, includeState, gin.H{"task_id": taskID})
+	}
+}
+
+// SetCheckpoint handles the request to record the current robot position as the checkpoint.
+// @Summary Set the checkpoint
+// @Description Record the current robot position as the checkpoint, replacing the previous one. A task added with POST /robot/tasks/return-to-checkpoint moves the robot back to it.
+// @Produce json
+// @Success 200 {object} map[string]any "The checkpoint"
+// @Failure 409 {object} ErrorResponse "Robot outside the warehouse"
+// @Router /robot/checkpoint [post]
+// @Tags Robot Tasks
+func SetCheckpoint(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		checkpoint, err := service.SetCheckpoint()
+		if err != nil {
+			respondError(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+		respondJSON(c, http.StatusOK, gin.H{"checkpoint": checkpoint})
+	}
+}
+
+// AddReturnToCheckpointTask handles the request to add a task moving the robot back to the checkpoint.
+// @Summary Add a task returning to the checkpoint
+// @Description Enqueue a task moving the robot back to the checkpoint along the shortest path around obstacles. The path is planned once the task is dispatched, so it starts from wherever the earlier tasks left the robot. The task is a follow task targeting the checkpoint.
+// @Produce json
+// @Param delay_between_commands query string false "Delay between moves, e.g. 1s"
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "Task ID and optionally the robot state"
+// @Failure 409 {object} ErrorResponse "No checkpoint set"
+// @Failure 503 {object} ErrorResponse "Task queue is full"
+// @Router /robot/tasks/return-to-checkpoint [post]
+// @Tags Robot Tasks
+func AddReturnToCheckpointTask(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		includeState, err := queryBool(c, includeStateParam)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		taskID, err := service.ReturnToCheckpoint(robot.TaskSpec{
+			DelayBetweenCommands: c.Query("delay_between_commands"),
+			AllowedCommands:      allowedCommands(c),
+		})
+		if err != nil {
+			respondError(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+
+		respondTask(c, service, includeState, gin.H{"task_id": taskID})
+	}
+}
+
+// UpdateFollowTarget handles the request to move the target of a follow task.
+// @Summary Move the target of a follow task
+// @Description Move the target of a pending or running follow task, the robot re-routes toward it before its next move
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body FollowRequest true "New target, the delay is ignored"
+// @Success 200 {object} map[string]any "Task ID and the new target"
+// @Failure 400 {object} ErrorResponse "Target outside the warehouse or on an obstacle"
+// @Failure 404 {object} ErrorResponse "Task not found"
+// @Failure 409 {object} ErrorResponse "Not a follow task or the task already finished"
+// @Router /robot/tasks/{id}/target [put]
+// @Tags Robot Tasks
+func UpdateFollowTarget(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req FollowRequest
+		if err := bindJSON(c, &req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		target := robot.Coord{X: *req.X, Y: *req.Y}
+		if err := service.UpdateFollowTarget(c.Param("id"), &target); err != nil {
+			respondError(c, taskChangeStatus(err), err)
+			return
+		}
+		respondJSON(c, http.StatusOK, gin.H{"task_id": c.Param("id"), "target": target})
+	}
+}
+
+// ClearFollowTarget handles the request to clear the target of a follow task.
+// @Summary Clear the target of a follow task
+// @Description Clear the target of a pending or running follow task, which completes it with reason target_cleared before its next move
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} map[string]any "Task ID"
+// @Failure 404 {object} ErrorResponse "Task not found"
+// @Failure 409 {object} ErrorResponse "Not a follow task or the task already finished"
+// @Router /robot/tasks/{id}/target [delete]
+// @Tags Robot Tasks
+func ClearFollowTarget(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := service.UpdateFollowTarget(c.Param("id"), nil); err != nil {
+			respondError(c, taskChangeStatus(err), err)
+			return
+		}
+		respondJSON(c, http.StatusOK, gin.H{"task_id": c.Param("id"), "message": "Follow target cleared"})
+	}
+}
+
+// taskChangeStatus returns the HTTP status for an error changing an existing task, e.g. the target of a follow task.
+func taskChangeStatus(err error) int {
+	switch {
+	case errors.Is(err, robot.ErrTaskNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, robot.ErrInvalidState):
+		return http.StatusConflict
+	}
+	return errorStatus(err, http.StatusBadRequest)
+}
+
+// GetTaskStatuses handles the request to look up the states of several tasks at once.
+// @Summary Get the states of several tasks
+// @Description Look up the states of a list of tasks in one call, unknown IDs are reported as NotFound
+// @Accept json
+// @Produce json
+// @Param request body TaskStatusRequest true "Task IDs"
+// @Success 200 {object} TaskStatusResponse "State of every requested task"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Router /robot/tasks/status [post]
+// @Tags Robot Tasks
+func GetTaskStatuses(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req TaskStatusRequest
+		if err := bindJSON(c, &req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		states := service.TaskStates(req.IDs)
+		response := TaskStatusResponse{States: make(map[string]string, len(req.IDs))}
+		for _, taskID := range req.IDs {
+			response.States[taskID] = taskNotFoundState
+			if state, exists := states[taskID]; exists {
+				response.States[taskID] = state.String()
+			}
+		}
+		respondJSON(c, http.StatusOK, response)
+	}
+}
+
+// GetGroup handles the request to summarize the tasks of a task group.
+// @Summary Get a task group
+// @Description Get the number of tasks per state and the tasks of a group
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} robot.GroupSummary "Group summary"
+// @Failure 404 {object} ErrorResponse "No task belongs to the group"
+// @Router /robot/groups/{id} [get]
+// @Tags Robot Tasks
+func GetGroup(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		group, err := service.Group(c.Param("id"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+		respondJSON(c, http.StatusOK, groupView(c, group))
+	}
+}
+
+// CancelGroup handles the request to cancel all cancellable tasks of a task group.
+// @Summary Cancel a task group
+// @Description Cancel all pending and running tasks of a group, finished tasks are left alone
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 202 {object} map[string]any "Number of cancelled tasks and the group summary"
+// @Failure 404 {object} ErrorResponse "No task belongs to the group"
+// @Router /robot/groups/{id}/cancel [put]
+// @Tags Robot Tasks
+func CancelGroup(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID := c.Param("id")
+		canceled, err := service.CancelGroup(groupID)
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+		group, _ := service.Group(groupID)
+		respondJSON(c, http.StatusAccepted, gin.H{"canceled": canceled, "group": groupView(c, group)})
+	}
+}
+
+// GetWebhookStatus handles the request to get the delivery status of the completion webhook of a task.
+// @Summary Get the webhook delivery status of a task
+// @Description Get whether the completion webhook of a task is pending, delivered, failed after all retries or dropped
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} robot.DeliveryStatus "Delivery status"
+// @Failure 404 {object} ErrorResponse "No webhook queued for the task"
+// @Router /robot/tasks/{id}/webhook [get]
+// @Tags Robot Tasks
+func GetWebhookStatus(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := service.WebhookStatus(c.Param("id"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+		respondJSON(c, http.StatusOK, status)
+	}
+}
+
+// GetTaskTrace handles the request to download the executed commands of a task as CSV.
+// Every row holds the command index, the command, the robot position after it, the time it finished,
+// the time its step began and how long the step took.
+// @Summary Download a task trace
+// @Description Download the executed commands of a task with the resulting robot positions as CSV. Every command has the time its step began, before the delay preceding it, and the time it finished, so duration_ms is the real time it took including clamped delays, the speed ramp and retries.
+// @Produce text/csv
+// @Param id path string true "Task ID"
+// @Success 200 {string} string "CSV with the columns index, command, x, y, timestamp, started, duration_ms"
+// @Failure 404 {object} ErrorResponse "Task not found"
+// @Router /robot/tasks/{id}/trace.csv [get]
+// @Tags Robot Tasks
+func GetTaskTrace(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		taskID := c.Param("id")
+		steps, err := service.TaskTrace(taskID)
+		if err != nil {
+			respondError(c, http.StatusNotFound, err)
+			return
+		}
+
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		writer.Write([]string{"index", "command", "x", "y", "timestamp", "started", "duration_ms"})
+		for _, step := range steps {
+			writer.Write([]string{
+				strconv.Itoa(step.Index),
+				step.Command.String(),
+				strconv.FormatUint(uint64(step.Position.X), 10),
+				strconv.FormatUint(uint64(step.Position.Y), 10),
+				step.Timestamp.Format(time.RFC3339Nano),
+				step.Started.Format(time.RFC3339Nano),
+				strconv.FormatFloat(float64(step.Duration())/float64(time.Millisecond), 'f', 3, 64),
+			})
+		}
+		writer.Flush()
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-trace.csv"`, taskID))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
 	}
 }
 
@@ -59,84 +683,497 @@ func AddTask(service robot.RobotService) gin.HandlerFunc {
 // @Tags Robot State
 func GetState(service robot.RobotService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if requestExpired(c) {
+			return
+		}
 		state := service.CurrentState()
-		c.JSON(http.StatusOK, state)
+		// CurrentState waits for the service lock, the client may have given up meanwhile
+		if requestExpired(c) {
+			return
+		}
+		respondJSON(c, http.StatusOK, stateView(c, state))
+	}
+}
+
+// GetQueueETA handles the request to estimate how long it takes until the queue is empty.
+// @Summary Get the queue ETA
+// @Description Get the estimated time until the running task and all pending tasks are finished, and the projected finish time
+// @Produce json
+// @Success 200 {object} robot.QueueETA "Queue estimate"
+// @Router /robot/queue/eta [get]
+// @Tags Robot Tasks
+func GetQueueETA(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, service.QueueETA())
+	}
+}
+
+// GetQueue handles the request to get the pending tasks in dispatch order.
+// @Summary Get the pending task queue
+// @Description Get the pending tasks in the order they will be dispatched, with command counts and estimated start times
+// @Produce json
+// @Success 200 {array} robot.QueuedTask "Pending tasks in dispatch order"
+// @Router /robot/queue [get]
+// @Tags Robot Tasks
+func GetQueue(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, queueView(c, service.PendingQueue()))
+	}
+}
+
+// ListTasks handles the request to list tasks page by page.
+// @Summary List robot tasks
+// @Description List tasks ordered by sequence number. Pass the returned next_cursor as cursor to fetch the next page.
+// @Produce json
+// @Param cursor query int false "Sequence number of the last task of the previous page"
+// @Param limit query int false "Maximum number of tasks per page, default 50, max 500"
+// @Success 200 {object} robot.TaskPage "Page of tasks"
+// @Failure 400 {object} ErrorResponse "Invalid cursor or limit"
+// @Router /robot/tasks [get]
+// @Tags Robot Tasks
+func ListTasks(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cursor, err := queryInt(c, "cursor")
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		limit, err := queryInt(c, "limit")
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		respondJSON(c, http.StatusOK, taskPageView(c, service.ListTasks(cursor, limit)))
+	}
+}
+
+// GetRecentAborts handles the request to list the latest aborted tasks.
+// @Summary List recent aborts
+// @Description List the latest aborted tasks newest first, with the reason, the error, the command the task stopped at and the robot position, for triaging failures. Purged tasks are not listed.
+// @Produce json
+// @Param limit query int false "Maximum number of aborts, default 20, max 500"
+// @Success 200 {array} robot.AbortSummary "Aborted tasks, newest first"
+// @Failure 400 {object} ErrorResponse "Invalid limit"
+// @Router /robot/aborts [get]
+// @Tags Robot Tasks
+func GetRecentAborts(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := queryInt(c, "limit")
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		respondJSON(c, http.StatusOK, abortsView(c, service.RecentAborts(limit)))
+	}
+}
+
+// PurgeTasks handles the request to remove all finished tasks.
+// @Summary Purge finished tasks
+// @Description Remove all completed, canceled and aborted tasks, e.g. to make room when the task limit is reached
+// @Produce json
+// @Success 200 {object} map[string]int "Number of purged tasks"
+// @Router /robot/tasks [delete]
+// @Tags Robot Tasks
+func PurgeTasks(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{"purged": service.PurgeTasks()})
+	}
+}
+
+// queryInt parses an optional non-negative integer query parameter, returning 0 if it is absent.
+func queryInt(c *gin.Context, name string) (int, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", name, raw)
+	}
+	return value, nil
+}
+
+// queryBool parses an optional boolean query parameter, absent means false.
+func queryBool(c *gin.Context, name string) (bool, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %q", name, raw)
+	}
+	return value, nil
+}
+
+// ReachableResponse lists the cells the robot can reach within a number of moves.
+// @Description Cells reachable from the current robot position
+type ReachableResponse struct {
+	Steps int           `json:"steps" example:"2"` // Maximum number of moves
+	Count int           `json:"count" example:"6"` // Number of reachable cells
+	Cells []robot.Coord `json:"cells"`             // Reachable cells ordered by row, then column, including the current cell
+}
+
+// GetCell handles the request to report the occupancy of a cell.
+// @Summary Get the occupancy of a cell
+// @Description Report whether a cell is inside the warehouse, holds an obstacle or is occupied by the robot
+// @Produce json
+// @Param x query int true "X coordinate"
+// @Param y query int true "Y coordinate"
+// @Success 200 {object} robot.CellInfo "Cell occupancy"
+// @Failure 400 {object} ErrorResponse "Missing or invalid coordinates"
+// @Router /robot/cell [get]
+// @Tags Robot State
+func GetCell(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		x, errX := strconv.Atoi(c.Query("x"))
+		y, errY := strconv.Atoi(c.Query("y"))
+		if errX != nil || errY != nil {
+			respondJSON(c, http.StatusBadRequest, ErrorResponse{Code: CodeInvalidRequest, Error: "x and y must be integers"})
+			return
+		}
+		respondJSON(c, http.StatusOK, service.CellInfo(robot.Coord{X: x, Y: y}))
+	}
+}
+
+// CellHistoryResponse lists the moves onto a cell.
+// @Description Moves of tasks that brought the robot onto a cell, oldest first
+type CellHistoryResponse struct {
+	Cell   robot.Coord       `json:"cell"`   // The queried cell
+	Visits []robot.CellVisit `json:"visits"` // Moves onto the cell, oldest first
+}
+
+// GetCellHistory handles the request to list the tasks that moved the robot through a cell.
+// @Summary Get the history of a cell
+// @Description List every move of a task that brought the robot onto a cell, with the task ID, the command index and the time, oldest first, e.g. to investigate an incident. Cells passed by a command with a step size above 1 count as well. Purged tasks are not included.
+// @Produce json
+// @Param x query int true "X coordinate"
+// @Param y query int true "Y coordinate"
+// @Success 200 {object} CellHistoryResponse "Moves onto the cell"
+// @Failure 400 {object} ErrorResponse "Missing or invalid coordinates, or a cell outside the warehouse"
+// @Router /robot/cell/history [get]
+// @Tags Robot State
+func GetCellHistory(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		x, errX := strconv.Atoi(c.Query("x"))
+		y, errY := strconv.Atoi(c.Query("y"))
+		if errX != nil || errY != nil {
+			respondJSON(c, http.StatusBadRequest, ErrorResponse{Code: CodeInvalidRequest, Error: "x and y must be integers"})
+			return
+		}
+		cell := robot.Coord{X: x, Y: y}
+		visits, err := service.CellHistory(cell)
+		if err != nil {
+			respondError(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+		respondJSON(c, http.StatusOK, CellHistoryResponse{Cell: cell, Visits: visits})
+	}
+}
+
+// ConfigResponse reports the configuration the server runs with.
+// @Description Effective configuration of the robot service and the API, with secrets redacted
+type ConfigResponse struct {
+	Robot robot.RuntimeConfig `json:"robot"` // Configuration of the robot service
+	API   Config              `json:"api"`   // Configuration of the API server
+}
+
+// GetConfig returns the handler factory reporting the robot service configuration together with the API configuration.
+// @Summary Get the effective configuration
+// @Description Get the configuration the server actually runs with, e.g. to verify a deployment: the warehouse size, the default delay, the robot service settings and the API settings. Secrets such as credentials in the webhook URL are redacted.
+// @Produce json
+// @Success 200 {object} ConfigResponse "Effective configuration"
+// @Router /robot/config [get]
+// @Tags Robot State
+func GetConfig(config Config) handlerFactory {
+	return func(service robot.RobotService) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			respondJSON(c, http.StatusOK, ConfigResponse{Robot: service.RuntimeConfig(), API: config})
+		}
+	}
+}
+
+// GetHeatmap handles the request to get the time the robot spent in every cell.
+// @Summary Get the dwell time heat map
+// @Description Get the cumulative time the robot spent in every cell of the warehouse since the service started, for heat-map analytics
+// @Produce json
+// @Success 200 {object} robot.Heatmap "Seconds per cell, indexed [y][x]"
+// @Router /robot/heatmap [get]
+// @Tags Robot State
+func GetHeatmap(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, service.Heatmap())
+	}
+}
+
+// GetReachable handles the request to compute the cells reachable from the current robot position.
+// @Summary Get the reachable area
+// @Description Get the cells the robot can reach within the given number of moves, honoring the warehouse bounds and obstacles
+// @Produce json
+// @Param steps query int true "Maximum number of moves"
+// @Success 200 {object} ReachableResponse "Reachable cells"
+// @Failure 400 {object} ErrorResponse "Invalid steps"
+// @Router /robot/reachable [get]
+// @Tags Robot State
+func GetReachable(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query("steps") == "" {
+			respondJSON(c, http.StatusBadRequest, ErrorResponse{Code: CodeInvalidRequest, Error: "steps is required"})
+			return
+		}
+		steps, err := queryInt(c, "steps")
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		cells := service.Reachable(steps)
+		respondJSON(c, http.StatusOK, ReachableResponse{Steps: steps, Count: len(cells), Cells: cells})
+	}
+}
+
+// QuiesceService handles the request to stop accepting tasks while the queue drains.
+// @Summary Quiesce the service
+// @Description Stop accepting new tasks (further submissions get 503) while queued tasks are processed to completion. The mode is reported in the state.
+// @Produce json
+// @Success 202 {object} map[string]string "Current mode"
+// @Router /robot/quiesce [post]
+// @Tags Robot State
+func QuiesceService(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service.Quiesce()
+		respondJSON(c, http.StatusAccepted, gin.H{"mode": service.CurrentState().Mode})
+	}
+}
+
+// ResetRobot handles the request to move the robot back to the origin with its initial heading.
+// @Summary Reset the robot position
+// @Description Move the robot back to the origin facing the configured initial heading, rejected while a task is running
+// @Produce json
+// @Success 200 {object} robot.RobotState "Robot state after the reset"
+// @Failure 409 {object} ErrorResponse "A task is in progress"
+// @Router /robot/reset [post]
+// @Tags Robot State
+func ResetRobot(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		robotState, err := service.Reset()
+		if err != nil {
+			respondError(c, http.StatusConflict, err)
+			return
+		}
+		respondJSON(c, http.StatusOK, robotState)
+	}
+}
+
+// GetSnapshot handles the request to export the complete service state.
+// @Summary Get a snapshot of the service state
+// @Description Get the complete serializable state of the service (robot position, all tasks and configuration), which can be passed to the restore endpoint
+// @Produce json
+// @Success 200 {object} robot.Snapshot "Complete service state"
+// @Router /robot/snapshot [get]
+// @Tags Robot State
+func GetSnapshot(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, service.Snapshot())
+	}
+}
+
+// RestoreSnapshot handles the request to replace the service state with a snapshot.
+// @Summary Restore the service state from a snapshot
+// @Description Replace the robot position and all tasks with a snapshot from the snapshot endpoint, rejected while a task is running. The configuration in the snapshot is not restored, pending tasks are queued again.
+// @Accept json
+// @Produce json
+// @Param request body robot.Snapshot true "Snapshot to restore"
+// @Success 200 {object} robot.ServiceState "Service state after the restore"
+// @Failure 400 {object} ErrorResponse "Invalid snapshot"
+// @Failure 409 {object} ErrorResponse "A task is in progress"
+// @Failure 503 {object} ErrorResponse "Too many pending tasks for the queue"
+// @Router /robot/restore [post]
+// @Tags Robot State
+func RestoreSnapshot(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var snapshot robot.Snapshot
+		if err := bindJSON(c, &snapshot); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := service.Restore(snapshot); err != nil {
+			status := errorStatus(err, http.StatusBadRequest)
+			if errors.Is(err, robot.ErrInvalidState) {
+				status = http.StatusConflict
+			}
+			respondError(c, status, err)
+			return
+		}
+		respondJSON(c, http.StatusOK, stateView(c, service.CurrentState()))
+	}
+}
+
+// SetPosition handles the request to move the robot directly to a cell. The route is only registered in debug mode.
+// @Summary Force the robot position (debug only)
+// @Description Move the robot directly to the given cell without running commands. Only available when the server runs with -debug.
+// @Accept json
+// @Produce json
+// @Param request body SetPositionRequest true "Set Position Request"
+// @Success 200 {object} robot.RobotState "Robot state after the move"
+// @Failure 400 {object} ErrorResponse "Invalid or out of bounds position"
+// @Router /robot/position [put]
+// @Tags Robot State
+func SetPosition(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SetPositionRequest
+		if err := bindJSON(c, &req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		robotState, err := service.SetPosition(*req.X, *req.Y)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		respondJSON(c, http.StatusOK, robotState)
+	}
+}
+
+// ListZones handles the request to list the warehouse zones.
+// @Summary List warehouse zones
+// @Description List the names of the independent warehouse zones served under /warehouses/{zone}/robot
+// @Produce json
+// @Success 200 {object} map[string][]string "Zone names"
+// @Router /warehouses [get]
+// @Tags Warehouses
+func ListZones(zones *robot.Zones) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{"zones": zones.Names()})
+	}
+}
+
+// GetStats handles the request to get health and diagnostic information about the robot service.
+// @Summary Get service diagnostics
+// @Description Get health and diagnostic information, e.g. whether a task is stuck or events were dropped
+// @Produce json
+// @Success 200 {object} robot.ServiceStats "Service diagnostics"
+// @Failure 503 {object} robot.ServiceStats "Service diagnostics of an unhealthy service"
+// @Router /robot/stats [get]
+// @Tags Robot State
+func GetStats(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats := service.Stats()
+		status := http.StatusOK
+		if stats.Unhealthy {
+			status = http.StatusServiceUnavailable // Lets health checks fail on the status code alone
+		}
+		respondJSON(c, status, stats)
 	}
 }
 
 // CancelTask handles the request to cancel a robot task by its ID.
 // @Summary Cancel a robot task by ID
-// @Description Cancel a robot task by its ID, if the task is in progress or pending
+// @Description Cancel a robot task by its ID, if the task is in progress or pending. Cancelling an already canceled task succeeds again, cancelling a completed or aborted task is a conflict.
 // @Param id path string true "Task ID"
-// @Success 202 {object} map[string]string "Cancellation request accepted"
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "Cancellation request accepted or task already canceled"
 // @Failure 400 {object} ErrorResponse "Error message"
+// @Failure 409 {object} ErrorResponse "Task already completed or aborted"
 // @Router /robot/tasks/{id}/cancel [put]
 // @Tags Robot Tasks
 func CancelTask(service robot.RobotService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		taskID := c.Param("id")
 		if taskID == "" {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "task ID is required"})
+			respondJSON(c, http.StatusBadRequest, ErrorResponse{Code: CodeInvalidRequest, Error: "task ID is required"})
+			return
+		}
+		includeState, err := queryBool(c, includeStateParam)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
 
-		err := service.CancelTask(taskID)
+		err = service.CancelTask(taskID)
+		if errors.Is(err, robot.ErrAlreadyCanceled) {
+			// Cancel is idempotent, repeating it succeeds without changing anything
+			respondTask(c, service, includeState, gin.H{"message": "Task was already canceled", "already_canceled": true})
+			return
+		}
+		if errors.Is(err, robot.ErrInvalidState) {
+			respondError(c, http.StatusConflict, err) // The task already finished otherwise
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
-		c.JSON(http.StatusAccepted, gin.H{"message": "Task cancellation requested successfully"})
+		respondTask(c, service, includeState, gin.H{"message": "Task cancellation requested successfully"})
 	}
 }
 
-// WebSocket upgrader configuration
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		// Allow connections from any origin in development
-		// TODO: In production, we should validate the origin properly
-		return true
-	},
+// UncancelTask handles the request to reverse the cancel of a pending task.
+// @Summary Restore a cancelled task
+// @Description Reverse the cancel of a task that was pending when it was cancelled, if the service runs with an uncancel window and it has not passed yet. The task is Pending again and keeps its priority and queue position.
+// @Param id path string true "Task ID"
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "Task restored"
+// @Failure 404 {object} ErrorResponse "Task not found"
+// @Failure 409 {object} ErrorResponse "Task not cancelled while pending, or the cancel is final"
+// @Failure 503 {object} ErrorResponse "Task queue is full or the service is quiescing"
+// @Router /robot/tasks/{id}/uncancel [post]
+// @Tags Robot Tasks
+func UncancelTask(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		includeState, err := queryBool(c, includeStateParam)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		if err := service.UncancelTask(c.Param("id")); err != nil {
+			respondError(c, taskChangeStatus(err), err)
+			return
+		}
+		respondTask(c, service, includeState, gin.H{"task_id": c.Param("id"), "message": "Task restored"})
+	}
 }
 
-// TaskStatusWebSocket handles WebSocket connections for real-time task status updates.
-// @Summary WebSocket endpoint for real-time task status updates
-// @Description Establishes a WebSocket connection to receive real-time task status updates. This endpoint requires a WebSocket client (not accessible via Swagger UI). Use tools like Postman, wscat, or the provided HTML test page.
+// ReplaceTask handles the request to replace a pending or running task with a new one.
+// @Summary Replace a robot task
+// @Description Cancel a pending or running task and enqueue a new task with the given commands in one step. The new task keeps the priority and the queue position of the replaced one, so no other task is dispatched in between. Replacing a finished task is a conflict.
+// @Accept json
 // @Produce json
-// @Success 101 {object} robot.TaskStatusUpdateEvent "WebSocket connection established, events will be sent as JSON"
-// @Failure 400 {object} ErrorResponse "Failed to upgrade connection"
-// @Router /robot/events [get]
-// @Tags Robot Events
-func TaskStatusWebSocket(service robot.RobotService) gin.HandlerFunc {
+// @Param id path string true "Task ID"
+// @Param request body ReplaceTaskRequest true "Commands of the replacement task"
+// @Param include_state query bool false "Include the current robot state in the response"
+// @Success 202 {object} map[string]any "ID of the new task and of the replaced task"
+// @Failure 400 {object} ErrorResponse "Invalid commands"
+// @Failure 404 {object} ErrorResponse "Task not found"
+// @Failure 409 {object} ErrorResponse "Task already finished or being cancelled"
+// @Failure 503 {object} ErrorResponse "Task queue is full"
+// @Router /robot/tasks/{id}/replace [put]
+// @Tags Robot Tasks
+func ReplaceTask(service robot.RobotService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Upgrade HTTP connection to WebSocket
-		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		var req ReplaceTaskRequest
+		if err := bindJSON(c, &req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		includeState, err := queryBool(c, includeStateParam)
 		if err != nil {
-			log.Printf("Failed to upgrade connection: %v", err)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to upgrade to WebSocket"})
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
-		defer conn.Close()
-		log.Printf("WebSocket connection established from %s", c.ClientIP())
-
-		// Get the event channel from the service
-		eventChannel := service.GetEventChannel()
-
-		// Listen for task status events and send them to the WebSocket client
-		for {
-			select {
-			case event := <-eventChannel:
-				// Send the event to the WebSocket client
-				if err := conn.WriteJSON(event); err != nil {
-					log.Printf("Failed to send event to WebSocket client: %v", err)
-					return
-				}
-				log.Printf("Sent event to WebSocket client: task=%s, state=%s", event.TaskID, event.State)
 
-			case <-c.Request.Context().Done():
-				// Client disconnected
-				log.Printf("WebSocket client disconnected: %s", c.ClientIP())
-				return
-			}
+		taskID, err := service.ReplaceTask(c.Param("id"), robot.TaskSpec{
+			Commands:             req.Commands,
+			DelayBetweenCommands: req.DelayBetweenCommands,
+			CorrelationID:        correlationID(c),
+			AllowedCommands:      allowedCommands(c),
+		})
+		if err != nil {
+			respondError(c, taskChangeStatus(err), err)
+			return
 		}
+		respondTask(c, service, includeState, gin.H{"task_id": taskID, "replaced_task_id": c.Param("id")})
 	}
 }
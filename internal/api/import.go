@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// importColumns is the required header of a task import CSV, in this order.
+var importColumns = []string{"commands", "delay", "priority", "labels"}
+
+// maxImportRows bounds the number of tasks imported by one request.
+const maxImportRows = 1000
+
+// importFileField is the multipart form field holding an uploaded CSV file.
+const importFileField = "file"
+
+// ImportRowResult reports the outcome of one CSV row.
+// @Description Outcome of importing one CSV row, either the ID of the enqueued task or the error
+type ImportRowResult struct {
+	Line   int    `json:"line" example:"2"`                       // Line of the row in the CSV, the header is line 1
+	TaskID string `json:"task_id,omitempty" example:"12345"`      // ID of the enqueued task, if the row was imported
+	Code   string `json:"code,omitempty" example:"OUT_OF_BOUNDS"` // Machine-readable error code, if the row failed
+	Error  string `json:"error,omitempty"`                        // Error message, if the row failed
+}
+
+// ImportResponse reports the outcome of a task import.
+// @Description Outcome of a task import, rows are imported independently of each other
+type ImportResponse struct {
+	Imported int               `json:"imported" example:"2"` // Number of rows enqueued as tasks
+	Failed   int               `json:"failed" example:"1"`   // Number of rows rejected
+	Rows     []ImportRowResult `json:"rows"`                 // Outcome of every row in CSV order
+}
+
+// ImportTasks handles the request to enqueue the rows of a CSV file as tasks.
+// @Summary Import tasks from CSV
+// @Description Enqueue every row of a CSV with the header commands,delay,priority,labels as a task, e.g. a job list exported from a spreadsheet. The CSV is the request body or the file field of a multipart form. Empty delay and priority cells use the defaults, labels are separated by semicolons. Rows are imported independently, a malformed or invalid row is reported with its line without affecting the others.
+// @Accept text/csv
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file false "CSV file, if not sent as the request body"
+// @Success 202 {object} ImportResponse "Outcome of every row"
+// @Failure 400 {object} ErrorResponse "Missing or invalid header, no rows or too many rows"
+// @Router /robot/tasks/import [post]
+// @Tags Robot Tasks
+func ImportTasks(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := importBody(c)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		defer body.Close()
+
+		reader := csv.NewReader(body)
+		reader.FieldsPerRecord = -1 // Rows with a wrong number of cells are reported per line
+		reader.TrimLeadingSpace = true
+		header, err := reader.Read()
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Errorf("failed to read the CSV header: %v", err))
+			return
+		}
+		if err := checkImportHeader(header); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		// Read all rows first, so an oversized file enqueues nothing
+		type row struct {
+			line int
+			spec robot.TaskSpec
+			err  error
+		}
+		var rows []row
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rows = append(rows, row{line: parseErr.StartLine, err: fmt.Errorf("malformed CSV: %v", parseErr.Err)})
+			} else if err != nil {
+				respondError(c, http.StatusBadRequest, fmt.Errorf("failed to read the CSV: %v", err))
+				return
+			} else {
+				line, _ := reader.FieldPos(0)
+				spec, err := parseImportRow(record)
+				spec.CorrelationID = correlationID(c)
+				spec.AllowedCommands = allowedCommands(c)
+				rows = append(rows, row{line: line, spec: spec, err: err})
+			}
+			if len(rows) > maxImportRows {
+				respondError(c, http.StatusBadRequest, fmt.Errorf("the CSV has more than %d rows", maxImportRows))
+				return
+			}
+		}
+		if len(rows) == 0 {
+			respondError(c, http.StatusBadRequest, errors.New("the CSV has no rows"))
+			return
+		}
+
+		response := ImportResponse{Rows: make([]ImportRowResult, len(rows))}
+		for i, row := range rows {
+			result := ImportRowResult{Line: row.line}
+			err := row.err
+			if err == nil {
+				result.TaskID, err = service.SubmitTask(row.spec)
+			}
+			if err != nil {
+				result.Code, result.Error = errorCode(err), err.Error()
+				response.Failed++
+			} else {
+				response.Imported++
+			}
+			response.Rows[i] = result
+		}
+		respondJSON(c, http.StatusAccepted, response)
+	}
+}
+
+// importBody returns the uploaded CSV, the file field of a multipart form or else the request body.
+func importBody(c *gin.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		return c.Request.Body, nil
+	}
+	header, err := c.FormFile(importFileField)
+	if err != nil {
+		return nil, fmt.Errorf("the form has no %s field with the CSV: %v", importFileField, err)
+	}
+	return header.Open()
+}
+
+// checkImportHeader returns an error unless the header holds exactly the import columns.
+func checkImportHeader(header []string) error {
+	if len(header) == len(importColumns) {
+		valid := true
+		for i, column := range header {
+			// A spreadsheet may prefix the file with a byte order mark
+			column = strings.TrimPrefix(column, "\ufeff")
+			valid = valid && strings.EqualFold(strings.TrimSpace(column), importColumns[i])
+		}
+		if valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid CSV header %q, expected %q", strings.Join(header, ","), strings.Join(importColumns, ","))
+}
+
+// parseImportRow converts the cells of a CSV row to a task spec.
+func parseImportRow(record []string) (robot.TaskSpec, error) {
+	if len(record) != len(importColumns) {
+		return robot.TaskSpec{}, fmt.Errorf("expected %d cells, got %d", len(importColumns), len(record))
+	}
+	spec := robot.TaskSpec{
+		Commands:             record[0],
+		DelayBetweenCommands: strings.TrimSpace(record[1]),
+	}
+	if priority := strings.TrimSpace(record[2]); priority != "" {
+		var err error
+		if spec.Priority, err = strconv.Atoi(priority); err != nil {
+			return robot.TaskSpec{}, fmt.Errorf("invalid priority %q", priority)
+		}
+	}
+	for _, label := range strings.Split(record[3], ";") {
+		if label = strings.TrimSpace(label); label != "" {
+			spec.Labels = append(spec.Labels, label)
+		}
+	}
+	return spec, nil
+}
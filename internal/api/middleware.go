@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultGzipMinSize is the minimum response size (in bytes) before gzip compression is applied.
+// Small payloads are sent as-is because compressing them costs more than it saves.
+const DefaultGzipMinSize = 1024
+
+// gzipWriter buffers the response body so the middleware can decide whether to compress it
+// once the handler has finished and the final size is known.
+type gzipWriter struct {
+	gin.ResponseWriter
+	buf    bytes.Buffer
+	status int
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+// WriteHeaderNow keeps the header buffered as well, e.g. on AbortWithStatus, so the status and the
+// Content-Encoding are written together with the body once the middleware decided on the compression.
+func (w *gzipWriter) WriteHeaderNow() {}
+
+// Status returns the buffered status code, the wrapped writer only sees it once the response is written.
+func (w *gzipWriter) Status() int {
+	return w.status
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	return w.buf.Write(data)
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.buf.WriteString(s)
+}
+
+// Gzip returns a middleware that gzip-compresses responses when the client sends
+// `Accept-Encoding: gzip` and the body is at least minSize bytes.
+// Requests whose path ends with one of excludedPaths (e.g. WebSocket endpoints) are passed through untouched.
+func Gzip(minSize int, excludedPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hasPathSuffix(c.Request.URL.Path, excludedPaths) || !acceptsGzip(c.Request) {
+			c.Next()
+			return
+		}
+
+		writer := &gzipWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		body := writer.buf.Bytes()
+		header := writer.Header()
+		header.Add("Vary", "Accept-Encoding")
+
+		if len(body) < minSize || header.Get("Content-Encoding") != "" {
+			writer.ResponseWriter.WriteHeader(writer.status)
+			writer.ResponseWriter.Write(body)
+			return
+		}
+
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		if _, err := gz.Write(body); err != nil {
+			gz.Close()
+			writer.ResponseWriter.WriteHeader(writer.status)
+			writer.ResponseWriter.Write(body)
+			return
+		}
+		gz.Close()
+
+		header.Set("Content-Encoding", "gzip")
+		header.Set("Content-Length", strconv.Itoa(compressed.Len()))
+		writer.ResponseWriter.WriteHeader(writer.status)
+		writer.ResponseWriter.Write(compressed.Bytes())
+	}
+}
+
+// acceptsGzip reports whether the request advertises gzip support in its Accept-Encoding header.
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		enc = strings.TrimSpace(strings.SplitN(enc, ";", 2)[0])
+		if enc == "gzip" || enc == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// Test that a large state response is gzip compressed and can be decoded back
+func TestGzip_StateResponseCompressed(t *testing.T) {
+	mockService := NewMockRobotService()
+	for i := 0; i < 50; i++ {
+		taskID := fmt.Sprintf("task-%d", i)
+		mockService.state.Tasks[taskID] = robot.RobotTask{ID: taskID, State: robot.Completed}
+	}
+
+	router := setupRouter()
+	router.Use(Gzip(DefaultGzipMinSize))
+	router.GET("/robot/state", GetState(mockService))
+
+	req, _ := http.NewRequest("GET", "/robot/state", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected Content-Encoding gzip, got '%s'", w.Header().Get("Content-Encoding"))
+	}
+
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("Failed to create gzip reader: %v", err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to decompress body: %v", err)
+	}
+
+	var state struct {
+		Tasks map[string]json.RawMessage `json:"tasks"`
+	}
+	if err := json.Unmarshal(body, &state); err != nil {
+		t.Fatalf("Failed to parse decompressed body: %v", err)
+	}
+	if len(state.Tasks) != 50 {
+		t.Errorf("Expected 50 tasks, got %d", len(state.Tasks))
+	}
+}
+
+// Test that small responses and clients without gzip support are served uncompressed
+func TestGzip_NotCompressed(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+	}{
+		{"Client does not accept gzip", ""},
+		{"Body below threshold", "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := NewMockRobotService()
+			router := setupRouter()
+			router.Use(Gzip(DefaultGzipMinSize))
+			router.GET("/robot/state", GetState(mockService))
+
+			req, _ := http.NewRequest("GET", "/robot/state", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Header().Get("Content-Encoding") != "" {
+				t.Errorf("Expected no Content-Encoding, got '%s'", w.Header().Get("Content-Encoding"))
+			}
+
+			var state robot.ServiceState
+			if err := json.Unmarshal(w.Body.Bytes(), &state); err != nil {
+				t.Fatalf("Failed to parse response body: %v", err)
+			}
+		})
+	}
+}
+
+// Test that aborted requests keep their status and a body matching the Content-Encoding header
+func TestGzip_AbortedRequest(t *testing.T) {
+	large := strings.Repeat("x", 2*DefaultGzipMinSize)
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		body    string
+	}{
+		{"Abort without body", func(c *gin.Context) { c.AbortWithStatus(http.StatusTooManyRequests) }, ""},
+		{"Abort with a large body", func(c *gin.Context) {
+			c.AbortWithStatus(http.StatusTooManyRequests)
+			c.Writer.WriteString(large)
+		}, large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter()
+			router.Use(Gzip(DefaultGzipMinSize))
+			router.GET("/robot/state", tt.handler)
+
+			req, _ := http.NewRequest("GET", "/robot/state", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusTooManyRequests {
+				t.Errorf("Expected status code %d, got %d", http.StatusTooManyRequests, w.Code)
+			}
+			body := w.Body.Bytes()
+			if w.Header().Get("Content-Encoding") == "gzip" {
+				reader, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("Content-Encoding is gzip, but the body is not: %v", err)
+				}
+				body, _ = io.ReadAll(reader)
+			}
+			if string(body) != tt.body {
+				t.Errorf("Expected a body of %d bytes, got %d", len(tt.body), len(body))
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prasnitt/robot-challenge-prasnitt/docs"
+)
+
+// OpenAPI handles the request for the OpenAPI document of the API, e.g. for generating client SDKs.
+// @Summary Get the OpenAPI document
+// @Description Get the OpenAPI (Swagger 2.0) document describing every endpoint, the same document the Swagger UI shows. Robot endpoints of warehouse zones are served under /warehouses/{zone} with the same paths.
+// @Produce json
+// @Success 200 {object} map[string]any "OpenAPI document"
+// @Router /openapi.json [get]
+// @Tags Documentation
+func OpenAPI() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(docs.SwaggerInfo.ReadDoc()))
+	}
+}
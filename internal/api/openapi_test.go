@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestOpenAPI tests that the served OpenAPI document is valid JSON describing every registered endpoint,
+// so the generated docs cannot fall behind the routes.
+func TestOpenAPI(t *testing.T) {
+	router := setupRouter()
+	SetupRouter(router, NewMockRobotService(), Config{Debug: true})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		t.Errorf("Expected a JSON content type, got %q", contentType)
+	}
+	var doc struct {
+		BasePath string                    `json:"basePath"`
+		Paths    map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Expected a valid JSON document, got %v", err)
+	}
+	if doc.BasePath != "/api/v1" {
+		t.Errorf("Expected the base path /api/v1, got %q", doc.BasePath)
+	}
+
+	// Gin writes path parameters as :id, OpenAPI as {id}
+	param := regexp.MustCompile(`:(\w+)`)
+	for _, route := range router.Routes() {
+		path := param.ReplaceAllString(strings.TrimPrefix(route.Path, doc.BasePath), "{$1}")
+		if _, documented := doc.Paths[path][strings.ToLower(route.Method)]; !documented {
+			t.Errorf("Expected %s %s in the OpenAPI document", route.Method, path)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readOnlyKey is the gin context key marking requests served in read-only observer mode.
+const readOnlyKey = "read_only"
+
+// errReadOnly is returned for mutating requests in read-only observer mode.
+var errReadOnly = errors.New("the server is a read-only observer, mutating endpoints are disabled")
+
+// ReadOnly returns a middleware putting the routes it is applied to in observer mode: mutating endpoints
+// answer 403 and WebSocket control messages are refused, reading state and subscribing to events still work.
+func ReadOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(readOnlyKey, true)
+		c.Next()
+	}
+}
+
+// requireWritable wraps a mutating endpoint to answer 403 in read-only observer mode.
+func requireWritable[S any](newHandler func(S) gin.HandlerFunc) func(S) gin.HandlerFunc {
+	return func(service S) gin.HandlerFunc {
+		handler := newHandler(service)
+		return func(c *gin.Context) {
+			if c.GetBool(readOnlyKey) {
+				respondJSON(c, http.StatusForbidden, ErrorResponse{Code: CodeReadOnly, Error: errReadOnly.Error()})
+				return
+			}
+			handler(c)
+		}
+	}
+}
@@ -1,22 +1,156 @@
 package api
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
 )
 
-func SetupRouter(router *gin.Engine, robotService robot.RobotService) {
+// handlerFactory builds the handler of an endpoint for a robot service, e.g. AddTask.
+type handlerFactory func(robot.RobotService) gin.HandlerFunc
+
+// serviceBinder binds an endpoint to the robot service a route group serves.
+type serviceBinder func(handlerFactory) gin.HandlerFunc
+
+func SetupRouter(router *gin.Engine, robotService robot.RobotService, config Config) {
 
 	v1 := router.Group("/api/v1")
 
-	robotGroup := v1.Group("/robot")
-	{
-		// API endpoints for robot tasks
-		robotGroup.POST("/tasks", AddTask(robotService))
-		robotGroup.PUT("/tasks/:id/cancel", CancelTask(robotService))
-		robotGroup.GET("/state", GetState(robotService))
+	// Compress large JSON responses, streaming endpoints are excluded as they hijack the connection
+	v1.Use(Gzip(DefaultGzipMinSize, StreamingPaths...))
+	v1.GET("/openapi.json", OpenAPI())
+
+	bind := func(newHandler handlerFactory) gin.HandlerFunc {
+		return newHandler(robotService)
+	}
+	registerRobotRoutes(v1.Group("/robot"), bind, config)
+}
+
+// SetupZoneRouter registers the robot endpoints of every zone under /api/v1/warehouses/:zone/robot.
+// Requests for unknown zones are answered with 404.
+func SetupZoneRouter(router *gin.Engine, zones *robot.Zones, config Config) {
+	warehouses := router.Group("/api/v1/warehouses")
+	warehouses.Use(Gzip(DefaultGzipMinSize, StreamingPaths...))
+	warehouses.GET("", ListZones(zones))
+
+	// Resolve the zone per request, the handler is built for the zone's service
+	bind := func(newHandler handlerFactory) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			service, err := zones.Zone(c.Param("zone"))
+			if err != nil {
+				respondError(c, http.StatusNotFound, err)
+				return
+			}
+			newHandler(service)(c)
+		}
+	}
+	registerRobotRoutes(warehouses.Group("/:zone/robot"), bind, config)
+
+	// Broadcast tasks span zones, the robot middleware applies to them like to the zone routes
+	broadcast := warehouses.Group("/broadcast")
+	useRobotMiddleware(broadcast, config)
+	broadcast.POST("", requireWritable(requireStarted(BroadcastTask))(zones))
+}
+
+// useRobotMiddleware installs the middleware the configuration enables for robot endpoints on the group.
+func useRobotMiddleware(robotGroup *gin.RouterGroup, config Config) {
+	if config.RelaxedJSON {
+		robotGroup.Use(RelaxedJSON())
+	}
+	if config.PublicTaskView {
+		robotGroup.Use(PublicTaskView())
+	}
+	if config.CorrelationIDs {
+		robotGroup.Use(CorrelationID())
+	}
+	if config.ReadOnly {
+		robotGroup.Use(ReadOnly())
+	}
+	if config.CamelCaseJSON {
+		robotGroup.Use(CamelCaseJSON())
+	}
+	if len(config.CommandAllowlists) > 0 {
+		robotGroup.Use(CommandAllowlist(config.CommandAllowlists))
+	}
+}
+
+// registerRobotRoutes registers the robot endpoints on the group, bound to the group's service.
+func registerRobotRoutes(robotGroup *gin.RouterGroup, bind serviceBinder, config Config) {
+	useRobotMiddleware(robotGroup, config)
+
+	// Mutating endpoints are rejected in observer mode and until the service processes its queue
+	mutating := func(newHandler handlerFactory) gin.HandlerFunc {
+		return bind(requireWritable(requireStarted(newHandler)))
+	}
+
+	// API endpoints for robot tasks
+	robotGroup.POST("/tasks", mutating(AddTask))
+	robotGroup.GET("/tasks", bind(ListTasks))
+	robotGroup.GET("/aborts", bind(GetRecentAborts))
+	robotGroup.DELETE("/tasks", mutating(PurgeTasks))
+	robotGroup.POST("/tasks/batch", mutating(AddBatchTask))
+	robotGroup.POST("/tasks/patrol", mutating(AddPatrolTask))
+	robotGroup.POST("/tasks/run-to-wall", mutating(AddRunToWallTask))
+	robotGroup.POST("/tasks/ping", mutating(AddPingTask))
+	robotGroup.POST("/tasks/compact", mutating(AddCompactTask))
+	robotGroup.POST("/tasks/follow", mutating(AddFollowTask))
+	robotGroup.POST("/tasks/return-to-checkpoint", mutating(AddReturnToCheckpointTask))
+	robotGroup.POST("/tasks/import", mutating(ImportTasks))
+	robotGroup.POST("/tasks/status", bind(GetTaskStatuses))
+	robotGroup.PUT("/tasks/:id/cancel", mutating(CancelTask))
+	robotGroup.POST("/tasks/:id/uncancel", mutating(UncancelTask))
+	robotGroup.PUT("/tasks/:id/replace", mutating(ReplaceTask))
+	robotGroup.PUT("/tasks/:id/target", mutating(UpdateFollowTarget))
+	robotGroup.DELETE("/tasks/:id/target", mutating(ClearFollowTarget))
+	robotGroup.GET("/tasks/:id/trace.csv", bind(GetTaskTrace))
+	robotGroup.GET("/tasks/:id/webhook", bind(GetWebhookStatus))
+	robotGroup.GET("/groups/:id", bind(GetGroup))
+	robotGroup.PUT("/groups/:id/cancel", mutating(CancelGroup))
+	robotGroup.GET("/state", bind(GetState))
+	robotGroup.GET("/reachable", bind(GetReachable))
+	robotGroup.GET("/cell", bind(GetCell))
+	robotGroup.GET("/cell/history", bind(GetCellHistory))
+	robotGroup.GET("/grid", GridFormat(config.GridFormat), bind(GetGrid))
+	robotGroup.GET("/heatmap", bind(GetHeatmap))
+	robotGroup.POST("/reset", mutating(ResetRobot))
+	robotGroup.POST("/checkpoint", mutating(SetCheckpoint))
+	robotGroup.GET("/snapshot", bind(GetSnapshot))
+	robotGroup.POST("/restore", mutating(RestoreSnapshot))
+	robotGroup.POST("/quiesce", mutating(QuiesceService))
+	robotGroup.GET("/queue", bind(GetQueue))
+	robotGroup.GET("/queue/eta", bind(GetQueueETA))
+	robotGroup.GET("/stats", bind(GetStats))
+	robotGroup.GET("/config", bind(GetConfig(config)))
+
+	// Development-only endpoints, never exposed in production
+	if config.Debug {
+		robotGroup.PUT("/position", mutating(SetPosition))
+	}
+
+	// WebSocket endpoint for real-time task status updates
+	robotGroup.GET("/events", bind(TaskStatusWebSocket))
+
+	// WebSocket endpoint for the robot position at a fixed rate, independent of task events
+	robotGroup.GET("/telemetry", bind(TelemetryWebSocket(config.TelemetryInterval)))
+}
+
+// startable is what an endpoint is built for, a robot service or the zones, that reports whether it processes tasks.
+type startable interface {
+	Started() bool
+}
 
-		// WebSocket endpoint for real-time task status updates
-		robotGroup.GET("/events", TaskStatusWebSocket(robotService))
+// requireStarted wraps an endpoint to answer 503 until the robot service has started processing its task queue.
+// Without it, requests racing the service start at boot would be accepted but not executed.
+func requireStarted[S startable](newHandler func(S) gin.HandlerFunc) func(S) gin.HandlerFunc {
+	return func(service S) gin.HandlerFunc {
+		handler := newHandler(service)
+		return func(c *gin.Context) {
+			if !service.Started() {
+				respondError(c, http.StatusServiceUnavailable, robot.ErrNotStarted)
+				return
+			}
+			handler(c)
+		}
 	}
 }
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// Test that the zone routes reach the service of the requested zone only
+func TestSetupZoneRouter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	zones, err := robot.NewZones(ctx, robot.DefaultConfig(), "alpha", "beta")
+	if err != nil {
+		t.Fatalf("Failed to create zones: %v", err)
+	}
+	router := setupRouter()
+	SetupZoneRouter(router, zones, DefaultConfig())
+	alpha, _ := zones.Zone("alpha")
+	waitStarted(t, alpha)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/api/v1/warehouses/alpha/robot/tasks", `{"commands": "N E", "delay_between_commands": "1ms"}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d", http.StatusAccepted, w.Code)
+	}
+
+	// Wait for the task to complete in alpha
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		state, _ := zones.CurrentState("alpha")
+		if state.RobotState.X == 1 && state.RobotState.Y == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Robot in alpha did not reach (1, 1), got (%d, %d)", state.RobotState.X, state.RobotState.Y)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	var beta struct {
+		RobotState   robot.RobotState `json:"robot_state"`
+		CurTaskCount int              `json:"current_task_count"`
+	}
+	w = serve("GET", "/api/v1/warehouses/beta/robot/state", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &beta); err != nil {
+		t.Fatalf("Failed to parse response body: %v", err)
+	}
+	if beta.RobotState.X != 0 || beta.RobotState.Y != 0 || beta.CurTaskCount != 0 {
+		t.Errorf("Expected beta untouched, got robot at (%d, %d) with %d tasks", beta.RobotState.X, beta.RobotState.Y, beta.CurTaskCount)
+	}
+
+	w = serve("GET", "/api/v1/warehouses/gamma/robot/state", "")
+	var errorResponse ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	if w.Code != http.StatusNotFound || errorResponse.Code != CodeZoneNotFound {
+		t.Errorf("Expected 404 %s for unknown zone, got %d '%s'", CodeZoneNotFound, w.Code, errorResponse.Code)
+	}
+
+	w = serve("GET", "/api/v1/warehouses", "")
+	var list struct {
+		Zones []string `json:"zones"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Zones) != 2 || list.Zones[0] != "alpha" || list.Zones[1] != "beta" {
+		t.Errorf("Expected zones [alpha beta], got %v", list.Zones)
+	}
+}
+
+// Test that a broadcast task is enqueued in every zone whose robot can run it and rejected per zone otherwise
+func TestBroadcastTask(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A zone may be named like the broadcast route without shadowing its own routes
+	zones, _ := robot.NewZones(ctx, robot.DefaultConfig(), "alpha", "edge", "broadcast")
+	waitStarted(t, zones)
+	edge, _ := zones.Zone("edge")
+	edge.SetPosition(9, 0)
+	router := setupRouter()
+	SetupZoneRouter(router, zones, DefaultConfig())
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/api/v1/warehouses/broadcast", `{"commands": "E", "delay_between_commands": "1h"}`)
+	var response BroadcastResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	if response.Accepted != 2 || response.Rejected != 1 || response.Zones["edge"].Code != CodeOutOfBounds {
+		t.Errorf("Expected the task rejected with %s in edge only, got %+v", CodeOutOfBounds, response)
+	}
+	for _, zone := range []string{"alpha", "broadcast"} {
+		state, _ := zones.CurrentState(zone)
+		if _, exists := state.Tasks[response.Zones[zone].TaskID]; !exists {
+			t.Errorf("Expected the task enqueued in %s, got %+v", zone, response.Zones[zone])
+		}
+	}
+	if w := serve("GET", "/api/v1/warehouses/broadcast/robot/state", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected the state of the broadcast zone, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serve("POST", "/api/v1/warehouses/broadcast", `{"commands": "N", "zones": ["alpha", "gamma"]}`)
+	var errorResponse ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	if w.Code != http.StatusNotFound || errorResponse.Code != CodeZoneNotFound {
+		t.Errorf("Expected 404 %s for an unknown zone, got %d: %s", CodeZoneNotFound, w.Code, w.Body.String())
+	}
+	if state, _ := zones.CurrentState("alpha"); len(state.Tasks) != 1 {
+		t.Errorf("Expected nothing enqueued for a broadcast to an unknown zone, got %d tasks in alpha", len(state.Tasks))
+	}
+}
+
+// waitStarted waits until the service, or every zone, has started processing its task queue.
+func waitStarted(t *testing.T, service startable) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !service.Started() {
+		if time.Now().After(deadline) {
+			t.Fatal("Service did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Test that the broadcast route is refused in observer mode like the other mutating routes
+func TestBroadcastTask_ReadOnly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	zones, _ := robot.NewZones(ctx, robot.DefaultConfig(), "alpha")
+	waitStarted(t, zones)
+	config := DefaultConfig()
+	config.ReadOnly = true
+	router := setupRouter()
+	SetupZoneRouter(router, zones, config)
+
+	req, _ := http.NewRequest("POST", "/api/v1/warehouses/broadcast", strings.NewReader(`{"commands": "N"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var errorResponse ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	if w.Code != http.StatusForbidden || errorResponse.Code != CodeReadOnly {
+		t.Errorf("Expected status code %d %s, got %d: %s", http.StatusForbidden, CodeReadOnly, w.Code, w.Body.String())
+	}
+	if state, _ := zones.CurrentState("alpha"); len(state.Tasks) != 0 {
+		t.Errorf("Expected nothing enqueued in observer mode, got %d tasks", len(state.Tasks))
+	}
+}
+
+// Test that mutating endpoints are rejected until the service has started and read-only ones are not
+func TestRequireStarted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service := robot.NewService(ctx, make(chan string, 10))
+	router := setupRouter()
+	SetupRouter(router, service, DefaultConfig())
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, route := range [][2]string{{"POST", "/api/v1/robot/tasks"}, {"POST", "/api/v1/robot/reset"}, {"PUT", "/api/v1/robot/tasks/1/cancel"}} {
+		w := serve(route[0], route[1], `{"commands": "N"}`)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s %s: expected status code %d before start, got %d", route[0], route[1], http.StatusServiceUnavailable, w.Code)
+		}
+		var errorResponse ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &errorResponse)
+		if errorResponse.Code != CodeNotStarted {
+			t.Errorf("%s %s: expected code %s, got %s", route[0], route[1], CodeNotStarted, errorResponse.Code)
+		}
+	}
+	if w := serve("GET", "/api/v1/robot/state", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected the state before start, got status code %d", w.Code)
+	}
+
+	go service.Start()
+	waitStarted(t, service)
+	if w := serve("POST", "/api/v1/robot/tasks", `{"commands": "N", "delay_between_commands": "1ms"}`); w.Code != http.StatusAccepted {
+		t.Errorf("Expected status code %d after start, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+}
+
+// Test that observer mode blocks mutating endpoints and WebSocket actions while reads and events keep working
+func TestReadOnly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service := robot.NewService(ctx, make(chan string, 10))
+	go service.Start()
+	waitStarted(t, service)
+	taskID, _ := service.EnqueueTask("N E", "1h")
+
+	config := DefaultConfig()
+	config.ReadOnly = true
+	router := setupRouter()
+	SetupRouter(router, service, config)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, route := range [][2]string{
+		{"POST", "/api/v1/robot/tasks"}, {"POST", "/api/v1/robot/tasks/batch"}, {"DELETE", "/api/v1/robot/tasks"},
+		{"PUT", "/api/v1/robot/tasks/" + taskID + "/cancel"}, {"PUT", "/api/v1/robot/tasks/" + taskID + "/replace"},
+		{"POST", "/api/v1/robot/reset"}, {"POST", "/api/v1/robot/restore"}, {"POST", "/api/v1/robot/quiesce"},
+	} {
+		w := serve(route[0], route[1], `{"commands": "N"}`)
+		var errorResponse ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &errorResponse)
+		if w.Code != http.StatusForbidden || errorResponse.Code != CodeReadOnly {
+			t.Errorf("%s %s: expected status code %d %s, got %d %s", route[0], route[1], http.StatusForbidden, CodeReadOnly, w.Code, errorResponse.Code)
+		}
+	}
+	for _, path := range []string{"/api/v1/robot/state", "/api/v1/robot/stats", "/api/v1/robot/grid", "/api/v1/robot/queue", "/api/v1/robot/tasks"} {
+		if w := serve("GET", path, ""); w.Code != http.StatusOK {
+			t.Errorf("GET %s: expected status code %d, got %d: %s", path, http.StatusOK, w.Code, w.Body.String())
+		}
+	}
+
+	// Events are streamed, but the cancel action is refused
+	server := httptest.NewServer(router)
+	defer server.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/robot/events", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect WebSocket: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.WriteJSON(WebSocketRequest{ID: "msg-1", Action: "cancel", TaskID: taskID}); err != nil {
+		t.Fatalf("Failed to send cancel request: %v", err)
+	}
+	var ack WebSocketAck
+	if err := conn.ReadJSON(&ack); err != nil {
+		t.Fatalf("Failed to read ack: %v", err)
+	}
+	if ack.Type != "ack" || ack.ID != "msg-1" || ack.Code != CodeReadOnly {
+		t.Errorf("Expected the cancel refused with %s, got %+v", CodeReadOnly, ack)
+	}
+	if state, _ := service.GetTaskState(taskID); state == robot.Canceled || state == robot.RequestCancellation {
+		t.Errorf("Expected the task not to be canceled in observer mode, got %s", state)
+	}
+}
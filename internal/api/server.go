@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// StreamingPaths lists the path suffixes of endpoints that keep the connection open and must not be subject
+// to handler timeouts. Suffixes match the endpoint of the default robot as well as the one of every zone.
+var StreamingPaths = []string{"/robot/events", "/robot/telemetry"}
+
+// NewServer creates an HTTP server with the configured timeouts.
+// Synchronous handlers are cut off after the handler timeout, streaming endpoints are excluded.
+func NewServer(addr string, handler http.Handler, config Config) *http.Server {
+	if config.HandlerTimeout > 0 {
+		handler = TimeoutHandler(handler, config.HandlerTimeout, StreamingPaths...)
+	}
+
+	return &http.Server{
+		Addr:         addr,
+		Handler:      handler,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		IdleTimeout:  config.IdleTimeout,
+	}
+}
+
+// TimeoutHandler runs the handler with a context deadline of the given timeout.
+// If the handler does not finish in time the client receives 503 with an ErrorResponse body, coded REQUEST_EXPIRED.
+// Requests whose path ends with one of excludedPaths (e.g. WebSocket endpoints, which need to hijack
+// the connection) are not limited.
+func TimeoutHandler(handler http.Handler, timeout time.Duration, excludedPaths ...string) http.Handler {
+	body, _ := json.Marshal(ErrorResponse{Code: CodeRequestExpired, Error: "request timed out"})
+	limited := http.TimeoutHandler(handler, timeout, string(body))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hasPathSuffix(r.URL.Path, excludedPaths) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		limited.ServeHTTP(&timeoutResponseWriter{ResponseWriter: w}, r)
+	})
+}
+
+// timeoutResponseWriter marks the timeout response of http.TimeoutHandler as JSON. The timeout handler writes
+// its body without any header, while the responses of the handler come with the headers the handler set,
+// and every handler of the API sets its content type. So a 503 without a content type is the timeout response.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *timeoutResponseWriter) WriteHeader(code int) {
+	if code == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", binding.MIMEJSON)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// hasPathSuffix reports whether path ends with one of the suffixes.
+func hasPathSuffix(path string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Test that a slow handler is cut off at the configured deadline while excluded paths are not limited
+func TestTimeoutHandler(t *testing.T) {
+	router := setupRouter()
+	slow := func(c *gin.Context) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+			c.JSON(http.StatusOK, gin.H{"message": "done"})
+		case <-c.Request.Context().Done():
+		}
+	}
+	router.GET("/slow", slow)
+	router.GET("/stream", slow)
+	router.GET("/unavailable", func(c *gin.Context) {
+		c.String(http.StatusServiceUnavailable, "busy")
+	})
+
+	handler := TimeoutHandler(router, 50*time.Millisecond, "/stream")
+
+	t.Run("Slow handler is cut off", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/slow", nil)
+		w := httptest.NewRecorder()
+
+		start := time.Now()
+		handler.ServeHTTP(w, req)
+		elapsed := time.Since(start)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+		var errorResponse ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &errorResponse); err != nil || errorResponse.Code != CodeRequestExpired {
+			t.Errorf("Expected an ErrorResponse coded %s, got %q", CodeRequestExpired, w.Body.String())
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("Expected the content type application/json, got %q", contentType)
+		}
+		if elapsed >= 300*time.Millisecond {
+			t.Errorf("Expected handler to be cut off near the 50ms deadline, took %s", elapsed)
+		}
+	})
+
+	t.Run("Fast handler keeps its response", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/unavailable", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable || w.Header().Get("Content-Type") != "text/plain; charset=utf-8" || w.Body.String() != "busy" {
+			t.Errorf("Expected the 503 of the handler, got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	})
+
+	t.Run("Excluded path is not limited", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/stream", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+}
+
+// Test that an expired request context does not enqueue a task
+func TestAddTask_ExpiredRequestContext(t *testing.T) {
+	mockService := NewMockRobotService()
+	router := setupRouter()
+	router.POST("/robot/tasks", AddTask(mockService))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/robot/tasks", strings.NewReader(`{"commands": "N"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if len(mockService.enqueuedTasks) != 0 {
+		t.Errorf("Expected no enqueued tasks, got %d", len(mockService.enqueuedTasks))
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// MinTelemetryInterval bounds the telemetry rate, so a client cannot make the server write as fast as it can.
+const MinTelemetryInterval = 10 * time.Millisecond
+
+// TelemetryMessage reports the robot position at the time it was sent.
+// @Description Periodic robot position, sent at a fixed rate independent of task events
+type TelemetryMessage struct {
+	Type       string           `json:"type" example:"telemetry"` // Always "telemetry"
+	Timestamp  time.Time        `json:"timestamp"`                // Time the position was read
+	RobotState robot.RobotState `json:"robot_state"`              // Current position and heading of the robot
+}
+
+// ParseTelemetryInterval parses a telemetry interval, e.g. 500ms, rejecting intervals below MinTelemetryInterval.
+func ParseTelemetryInterval(raw string) (time.Duration, error) {
+	interval, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid telemetry interval %q: %v", raw, err)
+	}
+	if interval < MinTelemetryInterval {
+		return 0, fmt.Errorf("invalid telemetry interval %s, the minimum is %s", interval, MinTelemetryInterval)
+	}
+	return interval, nil
+}
+
+// TelemetryWebSocket returns the handler factory streaming the robot position at a fixed rate, e.g. for dashboards
+// plotting the robot. The stream is independent of the task events, and it sends the position even while
+// the robot stands still. Clients pass ?interval=500ms to override the default rate. The interval and the
+// timestamps follow the service clock, like the events, and a connection counts against the subscriber limit.
+// On shutdown the client gets a close frame once the service closes its subscriptions.
+// @Summary WebSocket endpoint for periodic robot telemetry
+// @Description Establishes a WebSocket connection sending the current robot position and heading at a fixed rate, whether or not tasks run. The first message is sent right away. Task events are not sent, use /robot/events for them. This endpoint requires a WebSocket client (not accessible via Swagger UI).
+// @Produce json
+// @Param interval query string false "Time between messages, e.g. 500ms, at least 10ms, defaults to the -telemetry-interval setting"
+// @Success 101 {object} TelemetryMessage "WebSocket connection established, positions will be sent as JSON"
+// @Failure 400 {object} ErrorResponse "Invalid interval or failed to upgrade connection"
+// @Failure 503 {object} ErrorResponse "Maximum number of subscribers reached"
+// @Router /robot/telemetry [get]
+// @Tags Robot Events
+func TelemetryWebSocket(defaultInterval time.Duration) handlerFactory {
+	return func(service robot.RobotService) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			interval := defaultInterval
+			if raw, set := c.GetQuery("interval"); set {
+				var err error
+				if interval, err = ParseTelemetryInterval(raw); err != nil {
+					respondJSON(c, http.StatusBadRequest, ErrorResponse{Code: CodeInvalidRequest, Error: err.Error()})
+					return
+				}
+			}
+
+			// Subscribe before upgrading, so we can still reply with a proper HTTP error
+			subscription, err := service.SubscribeTelemetry(interval)
+			if err != nil {
+				log.Printf("Rejected telemetry subscriber from %s: %v", c.ClientIP(), err)
+				respondError(c, http.StatusServiceUnavailable, err)
+				return
+			}
+			defer subscription.Close()
+
+			rawConn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+			if err != nil {
+				log.Printf("Failed to upgrade connection: %v", err)
+				respondJSON(c, http.StatusBadRequest, ErrorResponse{Code: CodeInvalidRequest, Error: "Failed to upgrade to WebSocket"})
+				return
+			}
+			conn := &wsConn{Conn: rawConn, camel: c.GetBool(camelCaseKey)}
+			defer conn.Close()
+			log.Printf("Telemetry connection established from %s, interval %s", c.ClientIP(), interval)
+
+			// The client sends nothing, reading only notices when it goes away
+			clientGone := make(chan struct{})
+			go func() {
+				defer close(clientGone)
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}()
+
+			goingAway := func() {
+				log.Printf("Closing telemetry connection to %s: %s", c.ClientIP(), wsShutdownMessage)
+				closeFrame := websocket.FormatCloseMessage(websocket.CloseGoingAway, wsShutdownMessage)
+				conn.WriteControl(websocket.CloseMessage, closeFrame, time.Now().Add(wsCloseTimeout))
+			}
+			for {
+				select {
+				case sample, ok := <-subscription.Samples():
+					if !ok {
+						goingAway() // Closed by the service on shutdown
+						return
+					}
+					message := TelemetryMessage{Type: "telemetry", Timestamp: sample.Timestamp, RobotState: sample.RobotState}
+					if err := conn.WriteJSON(message); err != nil {
+						log.Printf("Failed to send telemetry to WebSocket client: %v", err)
+						return
+					}
+				case <-service.Closing():
+					goingAway()
+					return
+				case <-clientGone:
+					log.Printf("Telemetry client disconnected: %s", c.ClientIP())
+					return
+				case <-c.Request.Context().Done():
+					log.Printf("Telemetry client disconnected: %s", c.ClientIP())
+					return
+				}
+			}
+		}
+	}
+}
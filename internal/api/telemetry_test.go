@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// dialTelemetry starts a test server serving the telemetry endpoint and connects a WebSocket client to it
+func dialTelemetry(t *testing.T, service robot.RobotService, query string) *websocket.Conn {
+	t.Helper()
+	router := setupRouter()
+	router.GET("/robot/telemetry", TelemetryWebSocket(time.Second)(service))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/robot/telemetry" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect WebSocket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	return conn
+}
+
+// Test that telemetry messages arrive at the requested rate while no task runs
+func TestTelemetryWebSocket_Rate(t *testing.T) {
+	service := robot.NewService(context.Background(), make(chan string, 1))
+	conn := dialTelemetry(t, service, "?interval=50ms")
+
+	var first, last TelemetryMessage
+	for i := 0; i < 5; i++ {
+		if err := conn.ReadJSON(&last); err != nil {
+			t.Fatalf("Failed to read telemetry message %d: %v", i+1, err)
+		}
+		if last.Type != "telemetry" {
+			t.Errorf("Expected type telemetry, got %q", last.Type)
+		}
+		if i == 0 {
+			first = last
+		}
+	}
+
+	// Four intervals passed between the first and the fifth message, allow for timer jitter
+	if elapsed := last.Timestamp.Sub(first.Timestamp); elapsed < 180*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected about 200ms between the first and the fifth message, got %s", elapsed)
+	}
+}
+
+// Test that telemetry reflects position changes without any task event
+func TestTelemetryWebSocket_PositionChanges(t *testing.T) {
+	service := robot.NewService(context.Background(), make(chan string, 1))
+	conn := dialTelemetry(t, service, "?interval=10ms")
+
+	var message TelemetryMessage
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("Failed to read telemetry: %v", err)
+	}
+	if message.RobotState.X != 0 || message.RobotState.Y != 0 {
+		t.Errorf("Expected the robot at the origin, got %+v", message.RobotState)
+	}
+
+	moved := robot.RobotState{X: 3, Y: 4, Heading: robot.HeadingEast}
+	service.SetRobotState(moved)
+	for message.RobotState != moved {
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatalf("Expected telemetry to report %+v, got %+v: %v", moved, message.RobotState, err)
+		}
+	}
+}
+
+// Test that an interval below the minimum is rejected before upgrading
+func TestTelemetryWebSocket_InvalidInterval(t *testing.T) {
+	router := setupRouter()
+	router.GET("/robot/telemetry", TelemetryWebSocket(time.Second)(NewMockRobotService()))
+
+	for _, interval := range []string{"1ms", "fast"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/robot/telemetry?interval="+interval, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), CodeInvalidRequest) {
+			t.Errorf("Expected 400 %s for interval %q, got %d: %s", CodeInvalidRequest, interval, w.Code, w.Body.String())
+		}
+	}
+}
+
+// fixedClock is a robot.Clock standing still at a given time
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time        { return c.now }
+func (c fixedClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// Test that telemetry is timestamped on the service clock and closed with a close frame on shutdown
+func TestTelemetryWebSocket_ClockAndShutdown(t *testing.T) {
+	config := robot.DefaultConfig()
+	config.Clock = fixedClock{now: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)}
+	service := robot.NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+	conn := dialTelemetry(t, service, "?interval=10ms")
+
+	var message TelemetryMessage
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("Failed to read telemetry: %v", err)
+	}
+	if !message.Timestamp.Equal(config.Clock.Now()) {
+		t.Errorf("Expected the timestamp of the service clock %s, got %s", config.Clock.Now(), message.Timestamp)
+	}
+
+	service.CloseSubscriptions()
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue // Messages sent before the shutdown
+		}
+		if !websocket.IsCloseError(err, websocket.CloseGoingAway) || !strings.Contains(err.Error(), wsShutdownMessage) {
+			t.Errorf("Expected a %d close frame with %q, got %v", websocket.CloseGoingAway, wsShutdownMessage, err)
+		}
+		return
+	}
+}
+
+// Test that telemetry connections count against the subscriber limit
+func TestTelemetryWebSocket_SubscriberLimit(t *testing.T) {
+	config := robot.DefaultConfig()
+	config.MaxSubscribers = 1
+	service := robot.NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+	conn := dialTelemetry(t, service, "?interval=10ms")
+	var message TelemetryMessage
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("Failed to read telemetry: %v", err)
+	}
+
+	router := setupRouter()
+	router.GET("/robot/telemetry", TelemetryWebSocket(time.Second)(service))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/robot/telemetry", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), CodeTooManySubscribers) {
+		t.Errorf("Expected 503 %s for a second telemetry client, got %d: %s", CodeTooManySubscribers, w.Code, w.Body.String())
+	}
+	if _, err := service.Subscribe(); !errors.Is(err, robot.ErrTooManySubscribers) {
+		t.Errorf("Expected the telemetry client to take the only subscriber slot, got %v", err)
+	}
+}
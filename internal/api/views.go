@@ -0,0 +1,166 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// publicTaskViewKey is the gin context key marking requests whose tasks are rendered with PublicTask.
+const publicTaskViewKey = "public_task_view"
+
+// PublicTaskView returns a middleware rendering the tasks in the responses of the routes it is applied to
+// as PublicTask, hiding internal fields from public-facing clients. The same fields are dropped from the
+// queue, the recent aborts and the WebSocket events. Snapshots keep them, as a restore needs them.
+func PublicTaskView() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(publicTaskViewKey, true)
+		c.Next()
+	}
+}
+
+// PublicTask is the public view of a robot.RobotTask, without the internal sequence number and raw error message.
+// @Description Robot task without internal fields
+type PublicTask struct {
+	ID                   string                 `json:"id"`
+	Commands             robot.RobotCommands    `json:"commands" swaggertype:"string" example:"N E S W"`
+	State                robot.TaskState        `json:"state" swaggertype:"string" example:"Pending"`
+	DelayBetweenCommands robot.CommandDuration  `json:"delay_between_commands" swaggertype:"string" example:"1s"`
+	Priority             int                    `json:"priority"`
+	GroupID              string                 `json:"group_id,omitempty"`
+	Labels               []string               `json:"labels,omitempty"`
+	CorrelationID        string                 `json:"correlation_id,omitempty"`
+	Ping                 bool                   `json:"ping,omitempty"`
+	Reason               robot.TransitionReason `json:"reason,omitempty"`
+	AbortCategory        robot.AbortCategory    `json:"abort_category,omitempty" swaggertype:"string" example:"Obstacle"`
+	Replanned            bool                   `json:"replanned,omitempty"`
+	Frame                robot.CommandFrame     `json:"frame,omitempty"`
+	RelativeTo           *robot.Heading         `json:"relative_to,omitempty" swaggertype:"string"`
+	Replaces             string                 `json:"replaces,omitempty"`
+	ReplacedBy           string                 `json:"replaced_by,omitempty"`
+	StartedAt            *time.Time             `json:"started_at,omitempty"`
+	Progress             *robot.TaskProgress    `json:"progress,omitempty"`
+}
+
+func newPublicTask(task robot.RobotTask) PublicTask {
+	return PublicTask{
+		ID:                   task.ID,
+		Commands:             task.Commands,
+		State:                task.State,
+		DelayBetweenCommands: task.DelayBetweenCommands,
+		Priority:             task.Priority,
+		GroupID:              task.GroupID,
+		Labels:               task.Labels,
+		CorrelationID:        task.CorrelationID,
+		Ping:                 task.Ping,
+		Reason:               task.Reason,
+		AbortCategory:        task.AbortCategory,
+		Replanned:            task.Replanned,
+		Frame:                task.Frame,
+		RelativeTo:           task.RelativeTo,
+		Replaces:             task.Replaces,
+		ReplacedBy:           task.ReplacedBy,
+		StartedAt:            task.StartedAt,
+		Progress:             task.Progress,
+	}
+}
+
+func newPublicTasks(tasks []robot.RobotTask) []PublicTask {
+	public := make([]PublicTask, 0, len(tasks))
+	for _, task := range tasks {
+		public = append(public, newPublicTask(task))
+	}
+	return public
+}
+
+// The public views of responses holding tasks shadow the embedded tasks field with the public tasks.
+type (
+	publicServiceState struct {
+		robot.ServiceState
+		Tasks map[string]PublicTask `json:"tasks"`
+	}
+	publicTaskPage struct {
+		robot.TaskPage
+		Tasks []PublicTask `json:"tasks"`
+	}
+	publicGroupSummary struct {
+		robot.GroupSummary
+		Tasks []PublicTask `json:"tasks"`
+	}
+)
+
+// stateView returns the state as rendered for the request.
+func stateView(c *gin.Context, state robot.ServiceState) any {
+	if !c.GetBool(publicTaskViewKey) {
+		return state
+	}
+	tasks := make(map[string]PublicTask, len(state.Tasks))
+	for id, task := range state.Tasks {
+		tasks[id] = newPublicTask(task)
+	}
+	return publicServiceState{ServiceState: state, Tasks: tasks}
+}
+
+// taskPageView returns the task page as rendered for the request.
+func taskPageView(c *gin.Context, page robot.TaskPage) any {
+	if !c.GetBool(publicTaskViewKey) {
+		return page
+	}
+	return publicTaskPage{TaskPage: page, Tasks: newPublicTasks(page.Tasks)}
+}
+
+// groupView returns the group summary as rendered for the request.
+func groupView(c *gin.Context, group robot.GroupSummary) any {
+	if !c.GetBool(publicTaskViewKey) {
+		return group
+	}
+	return publicGroupSummary{GroupSummary: group, Tasks: newPublicTasks(group.Tasks)}
+}
+
+// PublicQueuedTask is the public view of a robot.QueuedTask, without the internal sequence number.
+// @Description Pending task in dispatch order without internal fields
+type PublicQueuedTask struct {
+	TaskID         string    `json:"task_id" example:"12345"`
+	Priority       int       `json:"priority" example:"0"`
+	CommandCount   int       `json:"command_count" example:"4"`
+	EstimatedStart time.Time `json:"estimated_start" example:"2024-01-15T10:30:00Z"`
+}
+
+// queueView returns the pending queue as rendered for the request.
+func queueView(c *gin.Context, queue []robot.QueuedTask) any {
+	if !c.GetBool(publicTaskViewKey) {
+		return queue
+	}
+	public := make([]PublicQueuedTask, 0, len(queue))
+	for _, task := range queue {
+		public = append(public, PublicQueuedTask{
+			TaskID:         task.TaskID,
+			Priority:       task.Priority,
+			CommandCount:   task.CommandCount,
+			EstimatedStart: task.EstimatedStart,
+		})
+	}
+	return public
+}
+
+// abortsView returns the recent aborts as rendered for the request, without the raw error in the public view.
+func abortsView(c *gin.Context, aborts []robot.AbortSummary) []robot.AbortSummary {
+	if !c.GetBool(publicTaskViewKey) {
+		return aborts
+	}
+	public := make([]robot.AbortSummary, len(aborts))
+	for i, abort := range aborts {
+		abort.Error = ""
+		public[i] = abort
+	}
+	return public
+}
+
+// eventView returns the event as sent to a WebSocket client, without the raw error in the public view.
+func eventView(public bool, event robot.TaskStatusUpdateEvent) robot.TaskStatusUpdateEvent {
+	if public {
+		event.Error = ""
+	}
+	return event
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// Test that the public task view omits the internal task fields while the internal view includes them
+func TestPublicTaskView(t *testing.T) {
+	service := robot.NewService(context.Background(), make(chan string, 10))
+	taskID, _ := service.SubmitTask(robot.TaskSpec{Commands: "N E", GroupID: "batch"})
+	service.UpdateTaskError(taskID, "raw internal error")
+
+	for _, public := range []bool{false, true} {
+		router := setupRouter()
+		group := router.Group("/robot")
+		if public {
+			group.Use(PublicTaskView())
+		}
+		group.GET("/state", GetState(service))
+		group.GET("/tasks", ListTasks(service))
+		group.GET("/groups/:id", GetGroup(service))
+
+		for _, tt := range []struct {
+			path string
+			task func(body map[string]json.RawMessage) json.RawMessage
+		}{
+			{"/robot/state", func(body map[string]json.RawMessage) json.RawMessage {
+				var tasks map[string]json.RawMessage
+				json.Unmarshal(body["tasks"], &tasks)
+				return tasks[taskID]
+			}},
+			{"/robot/tasks", firstTask},
+			{"/robot/groups/batch", firstTask},
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: expected status code %d, got %d", tt.path, http.StatusOK, w.Code)
+			}
+			var body map[string]json.RawMessage
+			json.Unmarshal(w.Body.Bytes(), &body)
+			var task map[string]any
+			if err := json.Unmarshal(tt.task(body), &task); err != nil {
+				t.Fatalf("%s: failed to parse task: %v", tt.path, err)
+			}
+
+			for _, field := range []string{"sequence_num", "error"} {
+				if _, exists := task[field]; exists == public {
+					t.Errorf("%s public=%t: unexpected presence of %s: %v", tt.path, public, field, task)
+				}
+			}
+			if task["id"] != taskID || task["group_id"] != "batch" || task["commands"] != "N E" {
+				t.Errorf("%s public=%t: expected the public fields in both views, got %v", tt.path, public, task)
+			}
+		}
+	}
+}
+
+// firstTask returns the first task of a response with a tasks list.
+func firstTask(body map[string]json.RawMessage) json.RawMessage {
+	var tasks []json.RawMessage
+	json.Unmarshal(body["tasks"], &tasks)
+	if len(tasks) == 0 {
+		return nil
+	}
+	return tasks[0]
+}
+
+// Test that the configuration enables the public view for the robot routes
+func TestPublicTaskView_Config(t *testing.T) {
+	service := robot.NewService(context.Background(), make(chan string, 10))
+	service.EnqueueTask("N", "1s")
+
+	config := DefaultConfig()
+	config.PublicTaskView = true
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupRouter(router, service, config)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/robot/tasks", nil))
+	var body map[string]json.RawMessage
+	json.Unmarshal(w.Body.Bytes(), &body)
+	var task map[string]any
+	json.Unmarshal(firstTask(body), &task)
+	if _, exists := task["sequence_num"]; exists || task["id"] == nil {
+		t.Errorf("Expected a public task, got %s", w.Body.String())
+	}
+}
+
+// Test that the queue, the recent aborts, the restore response and the WebSocket events drop the internal
+// fields in the public view
+func TestPublicTaskView_Endpoints(t *testing.T) {
+	for _, public := range []bool{false, true} {
+		service := robot.NewService(context.Background(), make(chan string, 10))
+		router := setupRouter()
+		group := router.Group("/robot")
+		if public {
+			group.Use(PublicTaskView())
+		}
+		group.GET("/queue", GetQueue(service))
+		group.GET("/aborts", GetRecentAborts(service))
+		group.POST("/restore", RestoreSnapshot(service))
+		group.GET("/events", TaskStatusWebSocket(service))
+		server := httptest.NewServer(router)
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/robot/events", nil)
+		if err != nil {
+			t.Fatalf("Failed to connect WebSocket: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+		service.EnqueueTask("N", "1s")
+		aborted, _ := service.EnqueueTask("E", "1s")
+		service.UpdateTaskError(aborted, "raw internal error")
+		service.UpdateTaskState(aborted, robot.Aborted, robot.ReasonCommandFailed)
+		snapshot, _ := json.Marshal(service.Snapshot())
+
+		for _, tt := range []struct {
+			method, path string
+			body         []byte
+			hidden       string
+		}{
+			{"GET", "/robot/queue", nil, `"sequence_num"`},
+			{"GET", "/robot/aborts", nil, "raw internal error"},
+			{"POST", "/robot/restore", snapshot, `"sequence_num"`},
+			{"POST", "/robot/restore", snapshot, "raw internal error"},
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, bytes.NewReader(tt.body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: expected status code %d, got %d: %s", tt.path, http.StatusOK, w.Code, w.Body.String())
+			}
+			if exists := strings.Contains(w.Body.String(), tt.hidden); exists == public {
+				t.Errorf("%s public=%t: unexpected presence of %s: %s", tt.path, public, tt.hidden, w.Body.String())
+			}
+		}
+
+		// The Aborted event carries the error of the task
+		for {
+			var event map[string]any
+			if err := conn.ReadJSON(&event); err != nil {
+				t.Fatalf("Failed to read event: %v", err)
+			}
+			if event["task_id"] != aborted || event["state"] != robot.Aborted.String() {
+				continue
+			}
+			if _, exists := event["error"]; exists == public {
+				t.Errorf("events public=%t: unexpected presence of error: %v", public, event)
+			}
+			break
+		}
+		conn.Close()
+		server.Close()
+	}
+}
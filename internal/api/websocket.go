@@ -0,0 +1,345 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+const (
+	wsShutdownMessage = "server shutting down" // Reason sent in the close frame on shutdown
+	wsLaggingMessage  = "client too slow"      // Reason sent in the close frame to a client that fell behind
+	wsCloseTimeout    = time.Second            // Deadline for writing the close frame
+)
+
+// Event formats selectable with the format query parameter
+const (
+	wsFormatVerbose = "verbose" // Full robot.TaskStatusUpdateEvent per event
+	wsFormatDelta   = "delta"   // WebSocketDelta with only the changed fields
+)
+
+// WebSocket actions that can be sent by clients
+const (
+	wsActionCancel = "cancel"
+)
+
+// WebSocketRequest is a control message sent by a WebSocket client.
+// @Description Control message sent by a WebSocket client, e.g. {"action":"cancel","task_id":"..."}
+type WebSocketRequest struct {
+	ID     string `json:"id,omitempty" example:"msg-1"` // Optional client message ID, echoed in the ack
+	Action string `json:"action" example:"cancel"`      // Action to perform, currently only "cancel"
+	TaskID string `json:"task_id" example:"12345"`      // ID of the task the action applies to
+}
+
+// WebSocketAck is the reply to a WebSocketRequest.
+// @Description Reply to a WebSocket control message
+type WebSocketAck struct {
+	Type   string `json:"type" example:"ack"`                       // Always "ack", distinguishes acks from task events
+	ID     string `json:"id,omitempty" example:"msg-1"`             // Client message ID from the request
+	Action string `json:"action" example:"cancel"`                  // Action from the request
+	TaskID string `json:"task_id,omitempty" example:"12345"`        // Task ID from the request
+	State  string `json:"state,omitempty" example:"Canceled"`       // Resulting task state if the action succeeded
+	Code   string `json:"code,omitempty" example:"TASK_NOT_FOUND"`  // Machine-readable error code if the action failed
+	Error  string `json:"error,omitempty" example:"task not found"` // Error message if the action failed
+}
+
+// WebSocketResync tells a resuming client that the events it missed are no longer buffered.
+// The client must refetch the full state, live events follow.
+// @Description Sent instead of the replay when missed events are no longer available
+type WebSocketResync struct {
+	Type      string `json:"type" example:"resync"`     // Always "resync"
+	LatestSeq uint64 `json:"latest_seq" example:"1042"` // Sequence number of the latest published event
+}
+
+// WebSocketDelta is the compact form of an event, sent instead of the full event with ?format=delta.
+// Only the fields that changed are set: the state is omitted if it is the same as in the previous event
+// of the task on this connection, and move events only carry the robot position once the move finished.
+// @Description Compact event with only the changed fields, e.g. {"seq":7,"task_id":"...","position":{"x":0,"y":1}}
+type WebSocketDelta struct {
+	Seq      uint64              `json:"seq" example:"42"`                              // Sequence number of the event
+	TaskID   string              `json:"task_id" example:"12345"`                       // Task the change belongs to
+	State    string              `json:"state,omitempty" example:"Completed"`           // New task state, omitted if unchanged
+	Reason   string              `json:"reason,omitempty" example:"completed_normally"` // Why the task entered the new state, sent with the state
+	Error    string              `json:"error,omitempty" example:""`                    // Error message if any
+	Progress *robot.TaskProgress `json:"progress,omitempty"`                            // How far an aborted task got
+	Position *robot.Coord        `json:"position,omitempty"`                            // Robot position after a move
+
+	Heartbeat *robot.ProgressHeartbeat `json:"heartbeat,omitempty"` // Progress of a running task, sent as is
+
+	Activity string `json:"activity,omitempty" example:"idle"` // "busy" or "idle" when the dispatcher starts or runs out of work, sent with the task it carries
+}
+
+// deltaEncoder turns events into WebSocketDelta messages, remembering the last state sent per task.
+type deltaEncoder struct {
+	states map[string]robot.TaskState
+}
+
+func newDeltaEncoder() *deltaEncoder {
+	return &deltaEncoder{states: make(map[string]robot.TaskState)}
+}
+
+// encode returns the delta for the event, or false if the event changes nothing, e.g. the start of a move.
+func (e *deltaEncoder) encode(event robot.TaskStatusUpdateEvent) (WebSocketDelta, bool) {
+	delta := WebSocketDelta{Seq: event.Seq, TaskID: event.TaskID, Error: event.Error, Progress: event.Progress}
+	if event.Type == robot.EventTypeBusy || event.Type == robot.EventTypeIdle {
+		// The state of the carried task is not tracked, it also arrives with the task's own state event
+		delta.Activity = event.Type
+		return delta, true
+	}
+	if event.Type == robot.EventTypeMove {
+		if event.Move == nil || event.Move.Phase != robot.MoveFinished {
+			return delta, false
+		}
+		delta.Position = &event.Move.To
+	}
+	if event.Type == robot.EventTypeProgress {
+		delta.Heartbeat = event.Heartbeat
+	}
+
+	if last, seen := e.states[event.TaskID]; !seen || last != event.State {
+		delta.State = event.State.String()
+		delta.Reason = string(event.Reason)
+	}
+	switch event.State {
+	case robot.Completed, robot.Canceled, robot.Aborted:
+		delete(e.states, event.TaskID) // No further events for a finished task
+	default:
+		e.states[event.TaskID] = event.State
+	}
+	return delta, delta.State != "" || delta.Position != nil || delta.Error != "" || delta.Progress != nil || delta.Heartbeat != nil
+}
+
+// WebSocket upgrader configuration
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		// Allow connections from any origin in development
+		// TODO: In production, we should validate the origin properly
+		return true
+	},
+}
+
+// wsConn serializes writes to a WebSocket connection, which does not support concurrent writers.
+type wsConn struct {
+	*websocket.Conn
+	writeMu sync.Mutex
+	deltas  *deltaEncoder // Encoder for the delta format, nil for verbose events
+	camel   bool          // Name the fields of every message in camelCase, see CamelCaseJSON
+	public  bool          // Drop the internal fields of events, see PublicTaskView
+}
+
+func (conn *wsConn) WriteJSON(v any) error {
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+	if conn.camel {
+		message, err := marshalCamelCase(v)
+		if err != nil {
+			return err
+		}
+		return conn.Conn.WriteMessage(websocket.TextMessage, message)
+	}
+	return conn.Conn.WriteJSON(v)
+}
+
+// WriteEvent sends the event in the format selected by the client.
+func (conn *wsConn) WriteEvent(event robot.TaskStatusUpdateEvent) error {
+	event = eventView(conn.public, event)
+	if conn.deltas == nil {
+		return conn.WriteJSON(event)
+	}
+	delta, changed := conn.deltas.encode(event)
+	if !changed {
+		return nil
+	}
+	return conn.WriteJSON(delta)
+}
+
+// TaskStatusWebSocket handles WebSocket connections for real-time task status updates.
+// Reconnecting clients pass ?since=<seq> to replay the events they missed before live events are streamed.
+// With ?format=delta the events are sent as compact WebSocketDelta messages instead.
+// Clients may also send control messages, e.g. {"action":"cancel","task_id":"...","id":"msg-1"},
+// which are answered with a WebSocketAck.
+// @Summary WebSocket endpoint for real-time task status updates
+// @Description Establishes a WebSocket connection to receive real-time task status updates. Clients can cancel tasks by sending {"action":"cancel","task_id":"...","id":"optional"} and receive an ack. This endpoint requires a WebSocket client (not accessible via Swagger UI). Use tools like Postman, wscat, or the provided HTML test page.
+// @Produce json
+// @Param since query int false "Sequence number of the last event seen, missed events are replayed or a resync marker is sent"
+// @Param format query string false "Event format: verbose (default) for full events or delta for WebSocketDelta messages with only the changed fields"
+// @Success 101 {object} robot.TaskStatusUpdateEvent "WebSocket connection established, events will be sent as JSON"
+// @Failure 400 {object} ErrorResponse "Failed to upgrade connection, invalid since or invalid format"
+// @Failure 503 {object} ErrorResponse "Maximum number of subscribers reached"
+// @Router /robot/events [get]
+// @Tags Robot Events
+func TaskStatusWebSocket(service robot.RobotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", wsFormatVerbose)
+		if format != wsFormatVerbose && format != wsFormatDelta {
+			respondJSON(c, http.StatusBadRequest, ErrorResponse{Code: CodeInvalidRequest, Error: "invalid format: " + format})
+			return
+		}
+
+		// Register as subscriber before upgrading, so we can still reply with a proper HTTP error
+		var subscription robot.Subscription
+		var replay robot.EventReplay
+		var err error
+		rawSince, resuming := c.GetQuery("since")
+		if resuming {
+			since, parseErr := strconv.ParseUint(rawSince, 10, 64)
+			if parseErr != nil {
+				respondJSON(c, http.StatusBadRequest, ErrorResponse{Code: CodeInvalidRequest, Error: "invalid since: " + rawSince})
+				return
+			}
+			subscription, replay, err = service.SubscribeSince(since)
+		} else {
+			subscription, err = service.Subscribe()
+		}
+		if err != nil {
+			log.Printf("Rejected WebSocket subscriber from %s: %v", c.ClientIP(), err)
+			respondError(c, http.StatusServiceUnavailable, err)
+			return
+		}
+		defer subscription.Close()
+
+		// Upgrade HTTP connection to WebSocket
+		rawConn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			log.Printf("Failed to upgrade connection: %v", err)
+			respondJSON(c, http.StatusBadRequest, ErrorResponse{Code: CodeInvalidRequest, Error: "Failed to upgrade to WebSocket"})
+			return
+		}
+		conn := &wsConn{Conn: rawConn, camel: c.GetBool(camelCaseKey), public: c.GetBool(publicTaskViewKey)}
+		if format == wsFormatDelta {
+			conn.deltas = newDeltaEncoder()
+		}
+		defer conn.Close()
+		log.Printf("WebSocket connection established from %s", c.ClientIP())
+
+		// Replay missed events before streaming live, or ask the client to refetch the state if they are gone
+		if resuming {
+			if err := writeReplay(conn, replay); err != nil {
+				log.Printf("Failed to replay events to WebSocket client: %v", err)
+				return
+			}
+		}
+
+		// Handle control messages from the client, the reader stops when the client disconnects
+		clientGone := make(chan struct{})
+		go func() {
+			defer close(clientGone)
+			readWebSocketRequests(conn, service, c.GetBool(readOnlyKey))
+		}()
+
+		// Listen for task status events and send them to the WebSocket client
+		for {
+			select {
+			case event, ok := <-subscription.Events():
+				if !ok {
+					// Subscription closed by the service, tell the client why: it fell behind or the server is going away
+					code, message := websocket.CloseGoingAway, wsShutdownMessage
+					if errors.Is(subscription.Err(), robot.ErrSubscriberLagging) {
+						code, message = websocket.CloseTryAgainLater, wsLaggingMessage
+					}
+					log.Printf("Closing WebSocket connection to %s: %s", c.ClientIP(), message)
+					closeFrame := websocket.FormatCloseMessage(code, message)
+					conn.WriteControl(websocket.CloseMessage, closeFrame, time.Now().Add(wsCloseTimeout))
+					return
+				}
+
+				// Send the event to the WebSocket client
+				if err := conn.WriteEvent(event); err != nil {
+					log.Printf("Failed to send event to WebSocket client: %v", err)
+					return
+				}
+				log.Printf("Sent event to WebSocket client: task=%s, state=%s", event.TaskID, event.State)
+
+			case <-clientGone:
+				log.Printf("WebSocket client disconnected: %s", c.ClientIP())
+				return
+
+			case <-c.Request.Context().Done():
+				// Client disconnected
+				log.Printf("WebSocket client disconnected: %s", c.ClientIP())
+				return
+			}
+		}
+	}
+}
+
+// writeReplay sends the replayed events, or a resync marker if the replay is incomplete.
+func writeReplay(conn *wsConn, replay robot.EventReplay) error {
+	if !replay.Complete {
+		return conn.WriteJSON(WebSocketResync{Type: "resync", LatestSeq: replay.LatestSeq})
+	}
+	for _, event := range replay.Events {
+		if err := conn.WriteEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readWebSocketRequests reads control messages from the client and replies with acks until the connection fails.
+// In read-only observer mode every action is refused.
+func readWebSocketRequests(conn *wsConn, service robot.RobotService, readOnly bool) {
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return // Client disconnected or connection failed
+		}
+
+		ack := WebSocketAck{Type: "ack"}
+		var req WebSocketRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			ack.Code = CodeInvalidRequest
+			ack.Error = "invalid message: " + err.Error()
+		} else if readOnly {
+			ack = WebSocketAck{Type: "ack", ID: req.ID, Action: req.Action, TaskID: req.TaskID, Code: CodeReadOnly, Error: errReadOnly.Error()}
+		} else {
+			ack = handleWebSocketRequest(req, service)
+		}
+
+		if err := conn.WriteJSON(ack); err != nil {
+			log.Printf("Failed to send ack to WebSocket client: %v", err)
+			return
+		}
+	}
+}
+
+// handleWebSocketRequest performs the requested action and builds the ack.
+// Like the mutating HTTP endpoints, actions are refused until the service has started, see requireStarted.
+func handleWebSocketRequest(req WebSocketRequest, service robot.RobotService) WebSocketAck {
+	ack := WebSocketAck{Type: "ack", ID: req.ID, Action: req.Action, TaskID: req.TaskID}
+
+	switch req.Action {
+	case wsActionCancel:
+		if !service.Started() {
+			ack.Code = errorCode(robot.ErrNotStarted)
+			ack.Error = robot.ErrNotStarted.Error()
+			return ack
+		}
+		if req.TaskID == "" {
+			ack.Code = CodeInvalidRequest
+			ack.Error = "task ID is required"
+			return ack
+		}
+		if err := service.CancelTask(req.TaskID); err != nil && !errors.Is(err, robot.ErrAlreadyCanceled) {
+			ack.Code = errorCode(err)
+			ack.Error = err.Error()
+			return ack
+		}
+		if state, err := service.GetTaskState(req.TaskID); err == nil {
+			ack.State = state.String()
+		}
+	default:
+		ack.Code = CodeInvalidRequest
+		ack.Error = "unknown action: " + req.Action
+	}
+
+	return ack
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prasnitt/robot-challenge-prasnitt/internal/robot"
+)
+
+// dialTestWebSocket starts a test server serving the events endpoint and connects a WebSocket client to it
+func dialTestWebSocket(t *testing.T, service robot.RobotService) *websocket.Conn {
+	t.Helper()
+	return dialTestWebSocketQuery(t, service, "")
+}
+
+// dialTestWebSocketQuery is like dialTestWebSocket but appends the raw query to the endpoint URL
+func dialTestWebSocketQuery(t *testing.T, service robot.RobotService, query string) *websocket.Conn {
+	t.Helper()
+	router := setupRouter()
+	router.GET("/robot/events", TaskStatusWebSocket(service))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/robot/events" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect WebSocket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	return conn
+}
+
+// Test cancelling a task over the WebSocket connection and receiving an ack with the new state
+func TestTaskStatusWebSocket_CancelWithAck(t *testing.T) {
+	mockService := NewMockRobotService()
+	mockService.state.Tasks["task-1"] = robot.RobotTask{ID: "task-1", State: robot.Pending}
+	conn := dialTestWebSocket(t, mockService)
+
+	if err := conn.WriteJSON(WebSocketRequest{ID: "msg-1", Action: "cancel", TaskID: "task-1"}); err != nil {
+		t.Fatalf("Failed to send cancel request: %v", err)
+	}
+
+	var ack WebSocketAck
+	if err := conn.ReadJSON(&ack); err != nil {
+		t.Fatalf("Failed to read ack: %v", err)
+	}
+	if ack.Type != "ack" || ack.ID != "msg-1" || ack.TaskID != "task-1" {
+		t.Errorf("Ack not correlated with request: %+v", ack)
+	}
+	if ack.State != robot.Canceled.String() {
+		t.Errorf("Expected state %s in ack, got '%s'", robot.Canceled, ack.State)
+	}
+	if ack.Error != "" {
+		t.Errorf("Expected no error in ack, got '%s'", ack.Error)
+	}
+}
+
+// Test that a cancel is refused until the service has started, like the mutating HTTP endpoints
+func TestTaskStatusWebSocket_CancelNotStarted(t *testing.T) {
+	mockService := NewMockRobotService()
+	mockService.notStarted = true
+	mockService.state.Tasks["task-1"] = robot.RobotTask{ID: "task-1", State: robot.Pending}
+	conn := dialTestWebSocket(t, mockService)
+
+	if err := conn.WriteJSON(WebSocketRequest{ID: "msg-1", Action: "cancel", TaskID: "task-1"}); err != nil {
+		t.Fatalf("Failed to send cancel request: %v", err)
+	}
+	var ack WebSocketAck
+	if err := conn.ReadJSON(&ack); err != nil {
+		t.Fatalf("Failed to read ack: %v", err)
+	}
+	if ack.Code != CodeNotStarted || ack.State != "" {
+		t.Errorf("Expected code %s without a state, got %+v", CodeNotStarted, ack)
+	}
+	if state := mockService.state.Tasks["task-1"].State; state != robot.Pending {
+		t.Errorf("Expected the task to stay pending, got %s", state)
+	}
+}
+
+// Test that failed or unknown actions are acknowledged with an error and its code
+func TestTaskStatusWebSocket_CancelErrors(t *testing.T) {
+	mockService := NewMockRobotService()
+	mockService.shouldFailCancel = true
+	mockService.cancelError = fmt.Errorf("task not found")
+	conn := dialTestWebSocket(t, mockService)
+
+	tests := []struct {
+		name      string
+		message   string
+		wantError string
+		wantCode  string
+	}{
+		{"Service error", `{"id":"1","action":"cancel","task_id":"missing"}`, "task not found", CodeInvalidRequest},
+		{"Missing task ID", `{"id":"2","action":"cancel"}`, "required", CodeInvalidRequest},
+		{"Unknown action", `{"id":"3","action":"explode","task_id":"x"}`, "unknown action", CodeInvalidRequest},
+		{"Malformed message", `not json`, "invalid message", CodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(tt.message)); err != nil {
+				t.Fatalf("Failed to send message: %v", err)
+			}
+			var ack WebSocketAck
+			if err := conn.ReadJSON(&ack); err != nil {
+				t.Fatalf("Failed to read ack: %v", err)
+			}
+			if !strings.Contains(ack.Error, tt.wantError) {
+				t.Errorf("Expected error containing '%s', got '%s'", tt.wantError, ack.Error)
+			}
+			if ack.Code != tt.wantCode {
+				t.Errorf("Expected code %s, got '%s'", tt.wantCode, ack.Code)
+			}
+		})
+	}
+}
+
+// Test resuming with a sequence number inside the replay buffer, missed events come before live events
+func TestTaskStatusWebSocket_ResumeWithinBuffer(t *testing.T) {
+	mockService := NewMockRobotService()
+	mockService.replay = robot.EventReplay{
+		Complete:  true,
+		LatestSeq: 6,
+		Events: []robot.TaskStatusUpdateEvent{
+			{Seq: 5, TaskID: "task-1", State: robot.InProgress},
+			{Seq: 6, TaskID: "task-1", State: robot.Completed},
+		},
+	}
+	conn := dialTestWebSocketQuery(t, mockService, "?since=4")
+
+	// TaskState has no JSON decoder, only the fields under test are decoded
+	type wireEvent struct {
+		Seq    uint64 `json:"seq"`
+		TaskID string `json:"task_id"`
+	}
+
+	for _, want := range []uint64{5, 6} {
+		var event wireEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			t.Fatalf("Failed to read replayed event: %v", err)
+		}
+		if event.Seq != want {
+			t.Errorf("Expected replayed event %d, got %d", want, event.Seq)
+		}
+	}
+
+	go mockService.SendTestEvent("task-2", robot.Pending, "")
+	var live wireEvent
+	if err := conn.ReadJSON(&live); err != nil {
+		t.Fatalf("Failed to read live event: %v", err)
+	}
+	if live.TaskID != "task-2" {
+		t.Errorf("Expected live event for task-2, got %s", live.TaskID)
+	}
+}
+
+// Test resuming with a sequence number older than the replay buffer yields a resync marker
+func TestTaskStatusWebSocket_ResumeTooOld(t *testing.T) {
+	mockService := NewMockRobotService()
+	mockService.replay = robot.EventReplay{Complete: false, LatestSeq: 1042}
+	conn := dialTestWebSocketQuery(t, mockService, "?since=3")
+
+	var resync WebSocketResync
+	if err := conn.ReadJSON(&resync); err != nil {
+		t.Fatalf("Failed to read resync marker: %v", err)
+	}
+	if resync.Type != "resync" || resync.LatestSeq != 1042 {
+		t.Errorf("Expected resync marker with latest seq 1042, got %+v", resync)
+	}
+}
+
+// Test that an invalid since value is rejected before upgrading
+func TestTaskStatusWebSocket_InvalidSince(t *testing.T) {
+	router := setupRouter()
+	router.GET("/robot/events", TaskStatusWebSocket(NewMockRobotService()))
+
+	req := httptest.NewRequest("GET", "/robot/events?since=abc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+// Test that clients receive a going away close frame when the service closes the subscriptions on shutdown
+func TestTaskStatusWebSocket_ShutdownCloseFrame(t *testing.T) {
+	service := robot.NewService(context.Background(), make(chan string, 1))
+	conn := dialTestWebSocket(t, service)
+
+	// Wait until the handler has subscribed before shutting down
+	deadline := time.Now().Add(time.Second)
+	for service.SubscriberCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	service.CloseSubscriptions()
+
+	_, _, err := conn.ReadMessage()
+	closeErr, ok := err.(*websocket.CloseError)
+	if !ok {
+		t.Fatalf("Expected a close frame, got %v", err)
+	}
+	if closeErr.Code != websocket.CloseGoingAway {
+		t.Errorf("Expected close code %d, got %d", websocket.CloseGoingAway, closeErr.Code)
+	}
+	if closeErr.Text != "server shutting down" {
+		t.Errorf("Expected close reason 'server shutting down', got '%s'", closeErr.Text)
+	}
+
+	// New subscribers are rejected once shutdown started
+	if _, err := service.Subscribe(); err != robot.ErrShuttingDown {
+		t.Errorf("Expected ErrShuttingDown, got %v", err)
+	}
+}
+
+// Test that a client disconnected for falling behind receives a try again later close frame
+func TestTaskStatusWebSocket_LaggingCloseFrame(t *testing.T) {
+	mockService := NewMockRobotService()
+	mockService.closeError = robot.ErrSubscriberLagging
+	conn := dialTestWebSocket(t, mockService)
+	close(mockService.eventChan)
+
+	_, _, err := conn.ReadMessage()
+	closeErr, ok := err.(*websocket.CloseError)
+	if !ok {
+		t.Fatalf("Expected a close frame, got %v", err)
+	}
+	if closeErr.Code != websocket.CloseTryAgainLater {
+		t.Errorf("Expected close code %d, got %d", websocket.CloseTryAgainLater, closeErr.Code)
+	}
+	if closeErr.Text != "client too slow" {
+		t.Errorf("Expected close reason 'client too slow', got '%s'", closeErr.Text)
+	}
+}
+
+// Test that the delta format only carries the fields that changed
+func TestTaskStatusWebSocket_DeltaFormat(t *testing.T) {
+	mockService := NewMockRobotService()
+	conn := dialTestWebSocketQuery(t, mockService, "?format=delta")
+
+	move := func(seq uint64, phase string, to robot.Coord) robot.TaskStatusUpdateEvent {
+		return robot.TaskStatusUpdateEvent{
+			Seq: seq, Type: robot.EventTypeMove, TaskID: "task-1", State: robot.InProgress, Timestamp: time.Now(),
+			Move: &robot.MoveEvent{Phase: phase, Command: "N", From: robot.Coord{X: 0, Y: 0}, To: to},
+		}
+	}
+	go func() {
+		for _, event := range []robot.TaskStatusUpdateEvent{
+			{Seq: 1, TaskID: "task-1", State: robot.InProgress, Timestamp: time.Now()},
+			move(2, robot.MoveStarted, robot.Coord{X: 0, Y: 1}), // Changes nothing, not sent
+			move(3, robot.MoveFinished, robot.Coord{X: 0, Y: 1}),
+			{Seq: 4, TaskID: "task-1", State: robot.InProgress, Error: "retrying", Timestamp: time.Now()},
+			{Seq: 5, TaskID: "task-1", State: robot.Completed, Timestamp: time.Now()},
+		} {
+			mockService.eventChan <- event
+		}
+	}()
+
+	want := []string{
+		`{"seq":1,"task_id":"task-1","state":"InProgress"}`,
+		`{"seq":3,"task_id":"task-1","position":{"x":0,"y":1}}`,
+		`{"seq":4,"task_id":"task-1","error":"retrying"}`,
+		`{"seq":5,"task_id":"task-1","state":"Completed"}`,
+	}
+	for _, message := range want {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read delta: %v", err)
+		}
+		if got := strings.TrimSpace(string(data)); got != message {
+			t.Errorf("Expected delta %s, got %s", message, got)
+		}
+	}
+}
+
+// Test that an unknown event format is rejected before upgrading
+func TestTaskStatusWebSocket_InvalidFormat(t *testing.T) {
+	router := setupRouter()
+	router.GET("/robot/events", TaskStatusWebSocket(NewMockRobotService()))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/robot/events?format=xml", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,77 @@
+package robot
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	DefaultAbortLimit = 20  // Number of aborts listed when no limit is given
+	MaxAbortLimit     = 500 // Upper bound for the number of aborts listed
+)
+
+// AbortSummary describes an aborted task for triaging failures.
+// @Description Aborted task with the reason and the command it stopped at
+type AbortSummary struct {
+	TaskID           string           `json:"task_id" example:"12345"`                                         // ID of the aborted task
+	Reason           TransitionReason `json:"reason" swaggertype:"string" example:"obstacle"`                  // Why the task was aborted, see TransitionReason
+	Category         AbortCategory    `json:"abort_category" swaggertype:"string" example:"Obstacle"`          // Kind of failure, see AbortCategory
+	Error            string           `json:"error,omitempty" example:"Error executing command 'N': obstacle"` // Error message of the task
+	AbortedAt        *time.Time       `json:"aborted_at,omitempty" example:"2024-01-15T10:30:00Z"`             // Time of the abort, omitted for tasks restored from older snapshots
+	CommandsExecuted int              `json:"commands_executed" example:"2"`                                   // Number of commands executed before the abort
+	StoppedAt        string           `json:"stopped_at,omitempty" example:"N"`                                // First command not executed, omitted if the task stopped after its last one. For a path rejected at dispatch the error names the offending command
+	Position         *Coord           `json:"position,omitempty"`                                              // Robot position when the task stopped
+}
+
+// stampAborted records the abort time and the category of the reason on a task being aborted.
+// The reason must be set before.
+func (s *Service) stampAborted(task *RobotTask) {
+	now := s.config.Clock.Now()
+	task.AbortedAt = &now
+	task.AbortCategory = task.Reason.AbortCategory()
+}
+
+// RecentAborts returns up to limit aborted tasks, newest first. A limit <= 0 uses DefaultAbortLimit and limits
+// above MaxAbortLimit are capped. Purged tasks are not included.
+func (s *Service) RecentAborts(limit int) []AbortSummary {
+	if limit <= 0 {
+		limit = DefaultAbortLimit
+	}
+	limit = min(limit, MaxAbortLimit)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	aborted := []RobotTask{}
+	for _, task := range s.state.Tasks {
+		if task.State == Aborted {
+			aborted = append(aborted, task)
+		}
+	}
+	sort.Slice(aborted, func(i, j int) bool {
+		a, b := aborted[i].AbortedAt, aborted[j].AbortedAt
+		if a != nil && b != nil && !a.Equal(*b) {
+			return a.After(*b)
+		}
+		if (a == nil) != (b == nil) {
+			return a != nil // Tasks without abort time are the oldest
+		}
+		return aborted[i].SequenceNum > aborted[j].SequenceNum
+	})
+
+	summaries := make([]AbortSummary, 0, min(limit, len(aborted)))
+	for _, task := range aborted[:min(limit, len(aborted))] {
+		summary := AbortSummary{TaskID: task.ID, Reason: task.Reason, Category: task.Reason.AbortCategory(), Error: task.Error, AbortedAt: task.AbortedAt}
+		if task.Progress != nil {
+			executed := task.Progress.CommandsExecuted
+			summary.CommandsExecuted = executed
+			if executed < len(task.Commands) {
+				summary.StoppedAt = task.Commands[executed].String()
+			}
+			position := Coord{X: int(task.Progress.FinalPosition.X), Y: int(task.Progress.FinalPosition.Y)}
+			summary.Position = &position
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
@@ -0,0 +1,73 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestRecentAborts tests that aborts of different causes are listed newest first with their reason and failing command.
+func TestRecentAborts(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	config.Obstacles = []Coord{{X: 0, Y: 2}}
+	config.StuckAbortGrace = time.Second
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	// Rejected at dispatch, its second command would enter the obstacle
+	obstacle, _ := service.EnqueueTask("N N", "0s")
+	service.ExecuteTask(obstacle)
+	clock.Advance(time.Second)
+
+	// Fails at its second command while executing
+	service.cellBlocked = func(x, y int) bool { return x == 1 }
+	blocked, _ := service.EnqueueTask("N E", "0s")
+	service.ExecuteTask(blocked)
+	service.cellBlocked = nil
+	clock.Advance(time.Second)
+
+	// Overruns its expected run time
+	timeout, _ := service.EnqueueTask("E E", "0s")
+	service.markTaskStarted(timeout)
+	service.UpdateTaskState(timeout, InProgress, ReasonDispatched)
+	clock.Advance(time.Hour)
+	service.checkStuckTasks()
+
+	completed, _ := service.EnqueueTask("E", "0s")
+	service.ExecuteTask(completed)
+
+	aborts := service.RecentAborts(0)
+	want := []struct {
+		taskID    string
+		reason    TransitionReason
+		stoppedAt string
+		executed  int
+		position  Coord
+	}{
+		{timeout, ReasonTimeout, "", 0, Coord{}},
+		{blocked, ReasonCellBlocked, "E", 1, Coord{X: 0, Y: 1}},
+		{obstacle, ReasonObstacle, "N", 0, Coord{X: 0, Y: 0}},
+	}
+	if len(aborts) != len(want) {
+		t.Fatalf("Expected %d aborts, got %d: %+v", len(want), len(aborts), aborts)
+	}
+	for i, abort := range aborts {
+		if abort.TaskID != want[i].taskID || abort.Reason != want[i].reason || abort.StoppedAt != want[i].stoppedAt || abort.CommandsExecuted != want[i].executed {
+			t.Errorf("Abort %d: expected %s (%s) after %d commands at %q, got %+v", i, want[i].taskID, want[i].reason, want[i].executed, want[i].stoppedAt, abort)
+		}
+		if abort.Category != want[i].reason.AbortCategory() {
+			t.Errorf("Abort %d: expected the category %s, got %s", i, want[i].reason.AbortCategory(), abort.Category)
+		}
+		if abort.Position != nil && *abort.Position != want[i].position {
+			t.Errorf("Abort %d: expected the robot at %s, got %s", i, want[i].position, *abort.Position)
+		}
+		if abort.AbortedAt == nil || (i > 0 && abort.AbortedAt.After(*aborts[i-1].AbortedAt)) {
+			t.Errorf("Abort %d: expected an abort time not after the previous one, got %v", i, abort.AbortedAt)
+		}
+	}
+
+	if aborts := service.RecentAborts(1); len(aborts) != 1 || aborts[0].TaskID != timeout {
+		t.Errorf("Expected only the newest abort with limit 1, got %+v", aborts)
+	}
+}
@@ -0,0 +1,49 @@
+package robot
+
+import "log"
+
+// Event types of the dispatcher activity events in TaskStatusUpdateEvent.Type.
+const (
+	EventTypeBusy = "busy" // The dispatcher started work after being idle, the event carries the dispatched task
+	EventTypeIdle = "idle" // The dispatcher ran out of work, the event carries the last task and its final state
+)
+
+// isActivity reports whether the event type is a dispatcher activity event.
+func isActivity(eventType string) bool {
+	return eventType == EventTypeBusy || eventType == EventTypeIdle
+}
+
+// markBusy records that the dispatcher is about to execute the task and publishes a busy event
+// if it was idle before. The event is published before the task's InProgress event.
+func (s *Service) markBusy(taskID string) {
+	s.mu.Lock()
+	wasBusy := s.busy
+	s.busy, s.lastTask = true, taskID
+	s.mu.Unlock()
+
+	if !wasBusy {
+		log.Printf("Dispatcher busy, starting task %s", taskID)
+		s.publish(TaskStatusUpdateEvent{Type: EventTypeBusy, TaskID: taskID, State: InProgress, Reason: ReasonDispatched})
+	}
+}
+
+// checkIdle publishes an idle event once the dispatcher was busy and no task is pending or running anymore.
+func (s *Service) checkIdle() {
+	s.mu.Lock()
+	if !s.busy {
+		s.mu.Unlock()
+		return
+	}
+	for _, task := range s.state.Tasks {
+		if task.State == Pending || task.State == InProgress || task.State == RequestCancellation {
+			s.mu.Unlock()
+			return
+		}
+	}
+	s.busy = false
+	taskID, last := s.lastTask, s.state.Tasks[s.lastTask]
+	s.mu.Unlock()
+
+	log.Printf("Dispatcher idle, the last task %s is %s", taskID, last.State)
+	s.publish(TaskStatusUpdateEvent{Type: EventTypeIdle, TaskID: taskID, State: last.State, Reason: last.Reason})
+}
@@ -0,0 +1,85 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// waitForActivity returns the next busy or idle event, failing on any other activity event in between.
+func waitForActivity(t *testing.T, sub Subscription) TaskStatusUpdateEvent {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-sub.Events():
+			if isActivity(event.Type) {
+				return event
+			}
+		case <-timeout:
+			t.Fatal("No busy or idle event within timeout")
+		}
+	}
+}
+
+// TestActivityEvents tests that the dispatcher reports busy when it starts work and idle once it ran out of it,
+// and that a queue of several tasks is reported as one busy period.
+func TestActivityEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service, err := newEmbeddedService(ctx, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+
+	first, _ := service.EnqueueTask("N", "10ms")
+	second, _ := service.EnqueueTask("E", "10ms")
+	if event := waitForActivity(t, sub); event.Type != EventTypeBusy || event.TaskID != first || event.State != InProgress {
+		t.Errorf("Expected busy with task %s, got %+v", first, event)
+	}
+	if event := waitForActivity(t, sub); event.Type != EventTypeIdle || event.TaskID != second || event.State != Completed {
+		t.Errorf("Expected idle after task %s completed, got %+v", second, event)
+	}
+	for _, taskID := range []string{first, second} {
+		if state := service.CurrentState().Tasks[taskID].State; state != Completed {
+			t.Errorf("Expected task %s completed when idle, got %s", taskID, state)
+		}
+	}
+
+	third, _ := service.EnqueueTask("N", "0s")
+	if event := waitForActivity(t, sub); event.Type != EventTypeBusy || event.TaskID != third {
+		t.Errorf("Expected busy again with task %s, got %+v", third, event)
+	}
+	if event := waitForActivity(t, sub); event.Type != EventTypeIdle || event.TaskID != third {
+		t.Errorf("Expected idle after task %s, got %+v", third, event)
+	}
+}
+
+// TestActivityEvents_CanceledQueue tests that the dispatcher reports idle when the remaining queued task
+// is cancelled before it is dispatched.
+func TestActivityEvents_CanceledQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service, err := newEmbeddedService(ctx, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+
+	running, _ := service.EnqueueTask("N N", "50ms")
+	queued, _ := service.EnqueueTask("E", "0s")
+	if event := waitForActivity(t, sub); event.Type != EventTypeBusy || event.TaskID != running {
+		t.Fatalf("Expected busy with task %s, got %+v", running, event)
+	}
+	if err := service.CancelTask(queued); err != nil {
+		t.Fatalf("Failed to cancel the queued task: %v", err)
+	}
+	if event := waitForActivity(t, sub); event.Type != EventTypeIdle || event.TaskID != running || event.State != Completed {
+		t.Errorf("Expected idle after task %s completed, got %+v", running, event)
+	}
+}
@@ -0,0 +1,56 @@
+package robot
+
+// EventTypeMove marks move events in TaskStatusUpdateEvent.Type, task state events have no type.
+const EventTypeMove = "move"
+
+// Phases of a move event.
+const (
+	MoveStarted  = "start" // The robot starts moving to the next cell
+	MoveFinished = "end"   // The robot arrived in the next cell
+)
+
+// MoveEvent describes a single robot move, published around every command when move events are enabled
+// so that a frontend can animate the robot between cells.
+// @Description Robot move between two cells
+type MoveEvent struct {
+	Phase   string `json:"phase" example:"start"` // "start" before the move, "end" once the robot arrived
+	Command string `json:"command" example:"N"`   // Command being executed
+	From    Coord  `json:"from"`                  // Cell the robot leaves
+	To      Coord  `json:"to"`                    // Cell the robot moves to
+}
+
+// startMove publishes the start of a move and waits for the configured animation delay.
+// It does nothing unless move events are enabled.
+func (s *Service) startMove(taskID string, cmd RobotCommand, from RobotState) {
+	if !s.config.MoveEvents {
+		return
+	}
+	dx, dy := cmd.Delta()
+	step := s.config.StepSize
+	to := Coord{X: int(from.X) + dx*step, Y: int(from.Y) + dy*step}
+	s.publishMove(taskID, MoveStarted, cmd, from, to)
+	s.config.Clock.Sleep(s.config.MoveEventDelay)
+}
+
+// finishMove publishes the end of a successful move, if move events are enabled.
+func (s *Service) finishMove(taskID string, cmd RobotCommand, from RobotState) {
+	if !s.config.MoveEvents {
+		return
+	}
+	to := s.GetRobotState()
+	s.publishMove(taskID, MoveFinished, cmd, from, Coord{X: int(to.X), Y: int(to.Y)})
+}
+
+func (s *Service) publishMove(taskID, phase string, cmd RobotCommand, from RobotState, to Coord) {
+	s.publish(TaskStatusUpdateEvent{
+		Type:   EventTypeMove,
+		TaskID: taskID,
+		State:  InProgress,
+		Move: &MoveEvent{
+			Phase:   phase,
+			Command: cmd.String(),
+			From:    Coord{X: int(from.X), Y: int(from.Y)},
+			To:      to,
+		},
+	})
+}
@@ -0,0 +1,75 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestMoveEvents tests that move events are published around every command only when enabled.
+func TestMoveEvents(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		name := "Disabled"
+		if enabled {
+			name = "Enabled"
+		}
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			config := DefaultConfig()
+			config.MoveEvents = enabled
+			config.MoveEventDelay = 250 * time.Millisecond
+			config.Clock = clock
+			service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+			sub, _ := service.Subscribe()
+			defer sub.Close()
+
+			taskID, _ := service.EnqueueTask("N E", "0s")
+			start := clock.Now()
+			if err := service.ExecuteTask(taskID); err != nil {
+				t.Fatalf("Failed to execute task: %v", err)
+			}
+
+			var want []MoveEvent
+			wantDelay := time.Duration(0)
+			if enabled {
+				want = []MoveEvent{
+					{Phase: MoveStarted, Command: "N", From: Coord{0, 0}, To: Coord{0, 1}},
+					{Phase: MoveFinished, Command: "N", From: Coord{0, 0}, To: Coord{0, 1}},
+					{Phase: MoveStarted, Command: "E", From: Coord{0, 1}, To: Coord{1, 1}},
+					{Phase: MoveFinished, Command: "E", From: Coord{0, 1}, To: Coord{1, 1}},
+				}
+				wantDelay = 2 * config.MoveEventDelay
+			}
+			if waited := clock.Now().Sub(start); waited != wantDelay {
+				t.Errorf("Expected move animation delay %s, got %s", wantDelay, waited)
+			}
+
+			// Wait for the Completed event, the state events are published asynchronously
+			var got []MoveEvent
+			timeout := time.After(2 * time.Second)
+			for completed := false; !completed; {
+				select {
+				case event := <-sub.Events():
+					if event.Type == EventTypeMove {
+						if event.TaskID != taskID || event.Move == nil {
+							t.Fatalf("Unexpected move event %+v", event)
+						}
+						got = append(got, *event.Move)
+					}
+					completed = event.State == Completed
+				case <-timeout:
+					t.Fatal("Timeout waiting for the Completed event")
+				}
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("Expected %d move events, got %d: %+v", len(want), len(got), got)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("Move event %d: expected %+v, got %+v", i, want[i], got[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package robot
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// AuditEntry records a single successful robot move.
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`         // Time of the move
+	TaskID    string    `json:"task_id,omitempty"` // Task executing the move, empty for moves outside a task
+	From      Coord     `json:"from"`              // Cell before the move
+	To        Coord     `json:"to"`                // Cell after the move
+	Command   string    `json:"command"`           // Executed command, e.g. "N"
+
+	Zone string `json:"zone,omitempty"` // Zone of the robot, empty outside of zones, see NewZones
+}
+
+// AuditLogger receives an entry for every successful robot move, e.g. for compliance records.
+// Implementations must be safe for concurrent use.
+type AuditLogger interface {
+	LogMove(entry AuditEntry)
+}
+
+// JSONAuditLogger appends audit entries as JSON lines to a writer, e.g. a file opened in append mode or stdout.
+type JSONAuditLogger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONAuditLogger returns an AuditLogger writing one JSON object per line to w.
+func NewJSONAuditLogger(w io.Writer) *JSONAuditLogger {
+	return &JSONAuditLogger{enc: json.NewEncoder(w)}
+}
+
+func (l *JSONAuditLogger) LogMove(entry AuditEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.enc.Encode(entry); err != nil {
+		log.Printf("Failed to write audit entry for move %s -> %s: %v", entry.From, entry.To, err)
+	}
+}
+
+// zoneAuditLogger stamps the entries of one zone with its name before passing them to the shared logger.
+type zoneAuditLogger struct {
+	zone   string
+	logger AuditLogger
+}
+
+func (l zoneAuditLogger) LogMove(entry AuditEntry) {
+	entry.Zone = l.zone
+	l.logger.LogMove(entry)
+}
+
+// auditMove reports a successful move to the configured audit logger, attributed to the running task.
+func (s *Service) auditMove(cmd RobotCommand, from, to RobotState) {
+	if s.config.AuditLogger == nil {
+		return
+	}
+
+	s.mu.RLock()
+	task, _ := s.runningTaskLocked()
+	s.mu.RUnlock()
+
+	s.config.AuditLogger.LogMove(AuditEntry{
+		Timestamp: s.config.Clock.Now(),
+		TaskID:    task.ID,
+		From:      Coord{X: int(from.X), Y: int(from.Y)},
+		To:        Coord{X: int(to.X), Y: int(to.Y)},
+		Command:   cmd.String(),
+	})
+}
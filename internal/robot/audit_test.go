@@ -0,0 +1,91 @@
+package robot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestAuditLog tests that every successful move of a task is written to the audit log, failed moves are not.
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	config.AuditLogger = NewJSONAuditLogger(&buf)
+	service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+
+	taskID, _ := service.EnqueueTask("N N E S W", "0s")
+	if err := service.ExecuteTask(taskID); err != nil {
+		t.Fatalf("Failed to execute task: %v", err)
+	}
+	// A move outside a task is audited without a task ID, a rejected move is not audited
+	service.ExecuteRobotCommand(North)
+	service.SetRobotState(RobotState{X: 0, Y: 0})
+	service.ExecuteRobotCommand(South)
+
+	want := []AuditEntry{
+		{TaskID: taskID, From: Coord{0, 0}, To: Coord{0, 1}, Command: "N"},
+		{TaskID: taskID, From: Coord{0, 1}, To: Coord{0, 2}, Command: "N"},
+		{TaskID: taskID, From: Coord{0, 2}, To: Coord{1, 2}, Command: "E"},
+		{TaskID: taskID, From: Coord{1, 2}, To: Coord{1, 1}, Command: "S"},
+		{TaskID: taskID, From: Coord{1, 1}, To: Coord{0, 1}, Command: "W"},
+		{From: Coord{0, 1}, To: Coord{0, 2}, Command: "N"},
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	var got []AuditEntry
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse audit line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, entry)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d audit entries, got %d:\n%s", len(want), len(got), buf.String())
+	}
+	for i := range want {
+		if !got[i].Timestamp.Equal(clock.Now()) {
+			t.Errorf("Entry %d: expected timestamp %s, got %s", i, clock.Now(), got[i].Timestamp)
+		}
+		got[i].Timestamp = want[i].Timestamp
+		if got[i] != want[i] {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+// TestAuditLog_Zones tests that zones sharing an audit logger record the zone of every move.
+func TestAuditLog_Zones(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var buf bytes.Buffer
+	config := DefaultConfig()
+	config.AuditLogger = NewJSONAuditLogger(&buf)
+	zones, err := NewZones(ctx, config, "north", "south")
+	if err != nil {
+		t.Fatalf("Failed to create zones: %v", err)
+	}
+	for _, zone := range []string{"north", "south"} {
+		zones.zones[zone].ExecuteRobotCommand(North)
+	}
+
+	var zonesSeen []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse audit line %q: %v", scanner.Text(), err)
+		}
+		zonesSeen = append(zonesSeen, entry.Zone)
+	}
+	if !reflect.DeepEqual(zonesSeen, []string{"north", "south"}) {
+		t.Errorf("Expected one move per zone, got %v:\n%s", zonesSeen, buf.String())
+	}
+}
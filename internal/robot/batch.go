@@ -0,0 +1,121 @@
+package robot
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// SubmitBatch enqueues several tasks as one transaction: either all of them are enqueued or none is.
+// The tasks are validated together against the projected robot positions, i.e. every task of the batch must
+// stay inside the warehouse and off obstacles when started where the tasks dispatched before it leave the robot.
+// The projection starts from the current position and follows the remaining commands of the running task and
+// all pending tasks in dispatch order. Queued tasks with an invalid path are skipped, as they are aborted
+// without moving the robot. Tasks submitted later with a higher priority can still change the positions,
+// every task is checked again when it is dispatched.
+// The first invalid task rejects the whole batch with an error naming its index.
+func (s *Service) SubmitBatch(specs []TaskSpec) ([]string, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("%w: the batch has no tasks", ErrInvalidCommand)
+	}
+
+	batch := make([]RobotTask, len(specs))
+	for i, spec := range specs {
+		task, err := s.newTask(spec)
+		if err != nil {
+			return nil, fmt.Errorf("task %d of the batch: %w", i+1, err)
+		}
+		batch[i] = *task
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.acceptingLocked(); err != nil {
+		return nil, err
+	}
+	if s.config.MaxTasks > 0 && len(s.state.Tasks)+len(batch) > s.config.MaxTasks {
+		return nil, fmt.Errorf("%w: %d tasks stored, the batch of %d exceeds the limit of %d", ErrTaskLimit, len(s.state.Tasks), len(batch), s.config.MaxTasks)
+	}
+	if err := s.checkPendingLocked(len(batch)); err != nil {
+		return nil, err
+	}
+	if free := cap(s.taskIdQueue) - len(s.taskIdQueue); free < len(batch) {
+		return nil, fmt.Errorf("%w: %d free slots for a batch of %d tasks", ErrQueueFull, free, len(batch))
+	}
+
+	for i := range batch {
+		if err := s.assignFreeIDLocked(&batch[i]); err != nil {
+			return nil, err
+		}
+		batch[i].SequenceNum = s.state.CurTaskCount + i + 1
+	}
+	if err := s.checkBatchLocked(batch); err != nil {
+		return nil, err
+	}
+
+	// Queue the tokens before storing the tasks, so a failure leaves the state untouched.
+	// Tokens queued before a failure are harmless, a token without a pending task is skipped.
+	for _, task := range batch {
+		select {
+		case s.taskIdQueue <- task.ID:
+		default:
+			return nil, fmt.Errorf("%w: %d tasks waiting", ErrQueueFull, len(s.taskIdQueue))
+		}
+	}
+
+	taskIDs := make([]string, len(batch))
+	for i, task := range batch {
+		s.state.Tasks[task.ID] = task
+		taskIDs[i] = task.ID
+		s.publishEventAsync(task.ID, task.State, task.Reason, "")
+	}
+	s.state.CurTaskCount += len(batch)
+
+	log.Printf("Batch of %d tasks enqueued: %v", len(batch), taskIDs)
+	return taskIDs, nil
+}
+
+// checkBatchLocked simulates the running task, the pending tasks and the batch in dispatch order
+// and returns an error for the first task of the batch with an invalid path.
+// The caller must hold the service lock.
+func (s *Service) checkBatchLocked(batch []RobotTask) error {
+	cell := Coord{X: int(s.state.RobotState.X), Y: int(s.state.RobotState.Y)}
+	if running, ok := s.runningTaskLocked(); ok && running.State == InProgress {
+		remaining := running.Commands
+		if s.executing.taskID == running.ID {
+			remaining = remaining[s.executing.executed:]
+		}
+		if end, err := s.checkPathFrom(cell, remaining); err == nil {
+			cell = end
+		}
+		if running.FollowTarget != nil {
+			cell = *running.FollowTarget
+		}
+	}
+
+	index := make(map[string]int, len(batch))
+	for i, task := range batch {
+		index[task.ID] = i
+	}
+	tasks := append(s.pendingTasksLocked(), batch...)
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return s.dispatchesBefore(tasks[i], tasks[j])
+	})
+
+	for _, task := range tasks {
+		if task.FollowTarget != nil {
+			cell = *task.FollowTarget // A follow task ends on its target, as far as it is known now
+			continue
+		}
+		end, err := s.checkPathFrom(cell, task.Commands)
+		if err == nil {
+			cell = end
+			continue
+		}
+		if i, inBatch := index[task.ID]; inBatch {
+			return fmt.Errorf("task %d of the batch: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestSubmitBatch tests that a batch is validated against the projected positions and enqueued all together or not at all.
+func TestSubmitBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		pending   []string // Commands of the tasks queued before the batch
+		batch     []TaskSpec
+		queueSize int
+		wantErr   error
+		wantMsg   string
+	}{
+		{
+			name:  "Cumulative path",
+			batch: []TaskSpec{{Commands: "N N"}, {Commands: "S S"}, {Commands: "E"}},
+		},
+		{
+			name:    "Mid-batch task out of bounds",
+			batch:   []TaskSpec{{Commands: "N N"}, {Commands: "E"}, {Commands: "S S S"}, {Commands: "E"}},
+			wantErr: ErrOutOfBounds,
+			wantMsg: "task 3 of the batch: out of warehouse boundaries: command 3 (S) would move the robot to (1, -1)",
+		},
+		{
+			name:    "Projected from the pending tasks",
+			pending: []string{"N"},
+			batch:   []TaskSpec{{Commands: "S"}},
+		},
+		{
+			name:  "Projected in dispatch order",
+			batch: []TaskSpec{{Commands: "S"}, {Commands: "N", Priority: 1}},
+		},
+		{
+			name:    "Invalid command",
+			batch:   []TaskSpec{{Commands: "N"}, {Commands: "X"}},
+			wantErr: ErrInvalidCommand,
+			wantMsg: "task 2 of the batch",
+		},
+		{
+			name:      "Queue too small",
+			batch:     []TaskSpec{{Commands: "N"}, {Commands: "N"}},
+			queueSize: 1,
+			wantErr:   ErrQueueFull,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queueSize := tt.queueSize
+			if queueSize == 0 {
+				queueSize = 10
+			}
+			taskIdQueue := make(chan string, queueSize)
+			service := NewService(context.Background(), taskIdQueue)
+			for _, commands := range tt.pending {
+				if _, err := service.EnqueueTask(commands, "1s"); err != nil {
+					t.Fatalf("Failed to enqueue %q: %v", commands, err)
+				}
+			}
+
+			taskIDs, err := service.SubmitBatch(tt.batch)
+			state := service.CurrentState()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Fatalf("Expected error %v containing %q, got %v", tt.wantErr, tt.wantMsg, err)
+				}
+				// Nothing of the batch may be enqueued
+				if len(state.Tasks) != len(tt.pending) || len(taskIdQueue) != len(tt.pending) {
+					t.Errorf("Expected only the %d pending tasks, got %d tasks and %d queued IDs", len(tt.pending), len(state.Tasks), len(taskIdQueue))
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected the batch to be accepted, got %v", err)
+			}
+			if len(taskIDs) != len(tt.batch) || len(taskIdQueue) != len(tt.pending)+len(tt.batch) {
+				t.Fatalf("Expected %d task IDs and queued IDs, got %d and %d", len(tt.batch), len(taskIDs), len(taskIdQueue))
+			}
+			for i, taskID := range taskIDs {
+				task := state.Tasks[taskID]
+				if task.State != Pending || task.SequenceNum != len(tt.pending)+i+1 {
+					t.Errorf("Expected task %d pending with sequence number %d, got %s and %d", i+1, len(tt.pending)+i+1, task.State, task.SequenceNum)
+				}
+			}
+		})
+	}
+}
+
+// TestSubmitBatch_Executes tests that an accepted batch runs to completion along the projected path.
+func TestSubmitBatch_Executes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := NewService(ctx, make(chan string, 10))
+	sub, err := service.Subscribe()
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+	go service.Start()
+
+	taskIDs, err := service.SubmitBatch([]TaskSpec{
+		{Commands: "N E", DelayBetweenCommands: "1ms"},
+		{Commands: "S E", DelayBetweenCommands: "1ms"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to submit the batch: %v", err)
+	}
+
+	waitForState(t, sub, taskIDs[1], Completed)
+	if got := service.GetRobotState(); got.X != 2 || got.Y != 0 {
+		t.Errorf("Expected the robot at (2, 0), got (%d, %d)", got.X, got.Y)
+	}
+}
@@ -0,0 +1,63 @@
+package robot
+
+import "fmt"
+
+// The robot recharges its battery whenever it reaches the home cell at the origin.
+const homeX, homeY = 0, 0
+
+// batteryEnabled reports whether the battery simulation is configured.
+func (s *Service) batteryEnabled() bool {
+	return s.config.BatteryCapacity > 0
+}
+
+// checkBattery simulates the commands from the current robot position and battery level.
+// It returns an error wrapping ErrBatteryDepleted if the battery would reach zero away from home.
+func (s *Service) checkBattery(commands RobotCommands) error {
+	if !s.batteryEnabled() {
+		return nil
+	}
+
+	s.mu.RLock()
+	x, y := int(s.state.RobotState.X), int(s.state.RobotState.Y)
+	level := s.state.Battery
+	s.mu.RUnlock()
+
+	for i, cmd := range commands {
+		if _, hold := cmd.Hold(); hold {
+			continue // Holding uses no charge
+		}
+		dx, dy := cmd.Delta()
+		x, y = x+dx*s.config.StepSize, y+dy*s.config.StepSize
+		level--
+		if x == homeX && y == homeY {
+			level = s.config.BatteryCapacity
+			continue
+		}
+		if level <= 0 {
+			return fmt.Errorf("%w: battery would run out after %d of %d commands", ErrBatteryDepleted, i+1, len(commands))
+		}
+	}
+	return nil
+}
+
+// drainBattery consumes the charge for one move ending at robotState and recharges at the home cell.
+func (s *Service) drainBattery(robotState RobotState) {
+	if !s.batteryEnabled() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if robotState.X == homeX && robotState.Y == homeY {
+		s.state.Battery = s.config.BatteryCapacity
+		return
+	}
+	s.state.Battery--
+}
+
+// batteryLevel returns the current battery level.
+func (s *Service) batteryLevel() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state.Battery
+}
@@ -0,0 +1,99 @@
+package robot
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// newBatteryService returns a service with the battery simulation enabled.
+func newBatteryService(capacity int) *Service {
+	config := DefaultConfig()
+	config.BatteryCapacity = capacity
+	return NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+}
+
+// TestBatteryDrainAbortsTask tests that a task which would drain the battery away from home is aborted before moving.
+func TestBatteryDrainAbortsTask(t *testing.T) {
+	service := newBatteryService(3)
+
+	taskID, err := service.EnqueueTask("N N N E", "0s")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+	if err := service.ExecuteTask(taskID); err == nil {
+		t.Fatal("Expected task to fail on insufficient battery")
+	}
+
+	state := service.CurrentState()
+	task := state.Tasks[taskID]
+	if task.State != Aborted {
+		t.Errorf("Expected task to be Aborted, got %s", task.State)
+	}
+	if !strings.Contains(task.Error, "battery") {
+		t.Errorf("Expected battery error on task, got '%s'", task.Error)
+	}
+	if state.RobotState.X != 0 || state.RobotState.Y != 0 {
+		t.Errorf("Expected robot not to move, got (%d, %d)", state.RobotState.X, state.RobotState.Y)
+	}
+	if state.Battery != 3 {
+		t.Errorf("Expected battery to be untouched at 3, got %d", state.Battery)
+	}
+}
+
+// TestBatteryRechargesAtHome tests that moves drain the battery and returning home recharges it.
+func TestBatteryRechargesAtHome(t *testing.T) {
+	service := newBatteryService(4)
+
+	// Three moves away and back would need 6 charges, the battery is recharged on reaching home
+	outbound, _ := service.EnqueueTask("N E E", "0s")
+	if err := service.ExecuteTask(outbound); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if battery := service.CurrentState().Battery; battery != 1 {
+		t.Errorf("Expected battery 1 after three moves, got %d", battery)
+	}
+
+	// A further move away from home would drain the battery
+	away, _ := service.EnqueueTask("N", "0s")
+	if err := service.ExecuteTask(away); err == nil {
+		t.Error("Expected move away from home to be rejected")
+	}
+
+	inbound, _ := service.EnqueueTask("W W S", "0s")
+	if err := service.ExecuteTask(inbound); err == nil {
+		t.Error("Expected the return trip to be rejected with a single charge left")
+	}
+
+	// Returning one cell at a time is possible once the battery is recharged at home
+	service.SetRobotState(RobotState{X: 1, Y: 0})
+	home, _ := service.EnqueueTask("W", "0s")
+	if err := service.ExecuteTask(home); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if battery := service.CurrentState().Battery; battery != 4 {
+		t.Errorf("Expected battery recharged to 4 at home, got %d", battery)
+	}
+
+	// A round trip passing home midway only needs the charge for the longer leg
+	roundTrip, _ := service.EnqueueTask("N N S S E E E", "0s")
+	if err := service.ExecuteTask(roundTrip); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if battery := service.CurrentState().Battery; battery != 1 {
+		t.Errorf("Expected battery 1 after the round trip, got %d", battery)
+	}
+}
+
+// TestBatteryDisabled tests that the battery simulation is off by default.
+func TestBatteryDisabled(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 1))
+
+	taskID, _ := service.EnqueueTask("N N N N N N N N N", "0s")
+	if err := service.ExecuteTask(taskID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if battery := service.CurrentState().Battery; battery != 0 {
+		t.Errorf("Expected no battery level when disabled, got %d", battery)
+	}
+}
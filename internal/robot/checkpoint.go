@@ -0,0 +1,35 @@
+package robot
+
+import (
+	"fmt"
+	"log"
+)
+
+// SetCheckpoint records the current robot position as the checkpoint, replacing the previous one,
+// see ReturnToCheckpoint. It fails with ErrRobotOffGrid while the robot is outside the warehouse.
+func (s *Service) SetCheckpoint() (Coord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cell := Coord{X: int(s.state.RobotState.X), Y: int(s.state.RobotState.Y)}
+	if !cell.inWarehouse() {
+		return Coord{}, fmt.Errorf("%w: robot is at %s, a checkpoint must be inside the warehouse", ErrRobotOffGrid, cell)
+	}
+	s.checkpoint = &cell
+	log.Printf("Checkpoint set at %s", cell)
+	return cell, nil
+}
+
+// ReturnToCheckpoint enqueues a task moving the robot back to the checkpoint along the shortest path, planned
+// from wherever the robot is once the task is dispatched. The task is a follow task targeting the checkpoint,
+// see Follow. It fails with ErrNoCheckpoint if no checkpoint was set.
+func (s *Service) ReturnToCheckpoint(spec TaskSpec) (string, error) {
+	s.mu.RLock()
+	checkpoint := s.checkpoint
+	s.mu.RUnlock()
+
+	if checkpoint == nil {
+		return "", fmt.Errorf("%w: set a checkpoint before returning to it", ErrNoCheckpoint)
+	}
+	return s.Follow(*checkpoint, spec)
+}
@@ -0,0 +1,53 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestReturnToCheckpoint tests that the robot returns to the checkpoint along the shortest path after moving away.
+func TestReturnToCheckpoint(t *testing.T) {
+	config := DefaultConfig()
+	config.Clock = newFakeClock()
+	config.Obstacles = []Coord{{X: 2, Y: 3}}
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	if _, err := service.ReturnToCheckpoint(TaskSpec{DelayBetweenCommands: "0s"}); !errors.Is(err, ErrNoCheckpoint) {
+		t.Fatalf("Expected ErrNoCheckpoint without a checkpoint, got %v", err)
+	}
+
+	service.SetRobotState(RobotState{X: 2, Y: 2, Heading: HeadingNorth})
+	checkpoint, err := service.SetCheckpoint()
+	if err != nil || checkpoint != (Coord{X: 2, Y: 2}) {
+		t.Fatalf("Expected the checkpoint at (2, 2), got %s (%v)", checkpoint, err)
+	}
+
+	// Move away to the other side of the obstacle, so the way back has to go around it
+	away, _ := service.EnqueueTask("W N N E", "0s")
+	if err := service.ExecuteTask(away); err != nil {
+		t.Fatalf("Failed to move away: %v", err)
+	}
+	taskID, err := service.ReturnToCheckpoint(TaskSpec{DelayBetweenCommands: "0s"})
+	if err != nil {
+		t.Fatalf("Failed to enqueue the return: %v", err)
+	}
+	if err := service.ExecuteTask(taskID); err != nil {
+		t.Fatalf("Failed to return to the checkpoint: %v", err)
+	}
+
+	robotState := service.GetRobotState()
+	if (Coord{X: int(robotState.X), Y: int(robotState.Y)}) != checkpoint {
+		t.Errorf("Expected the robot back at %s, got (%d, %d)", checkpoint, robotState.X, robotState.Y)
+	}
+	task := service.CurrentState().Tasks[taskID]
+	if task.State != Completed || len(task.Trace) != 4 {
+		t.Errorf("Expected the return to complete in 4 moves around the obstacle, got %s after %d moves", task.State, len(task.Trace))
+	}
+
+	// A later checkpoint replaces the earlier one
+	service.SetRobotState(RobotState{X: 7, Y: 7, Heading: HeadingNorth})
+	if checkpoint, _ := service.SetCheckpoint(); checkpoint != (Coord{X: 7, Y: 7}) {
+		t.Errorf("Expected the checkpoint replaced by (7, 7), got %s", checkpoint)
+	}
+}
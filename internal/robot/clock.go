@@ -0,0 +1,46 @@
+package robot
+
+import "time"
+
+// Clock abstracts time so that time dependent behavior can be tested without real delays.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// realClock is the default Clock backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// ScaledClock runs a simulated time that passes Factor times faster than the wrapped clock,
+// e.g. for demos playing a 10 minute routine in a minute. Sleeps are shortened accordingly.
+type ScaledClock struct {
+	Clock  Clock   // Underlying clock, the real clock when nil
+	Factor float64 // How much faster the simulated time passes, must be positive
+
+	start time.Time // Time of the underlying clock when the simulation started
+}
+
+// NewScaledClock returns a clock running factor times faster than clock, starting at its current time.
+func NewScaledClock(clock Clock, factor float64) *ScaledClock {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &ScaledClock{Clock: clock, Factor: factor, start: clock.Now()}
+}
+
+func (c *ScaledClock) Now() time.Time {
+	elapsed := c.Clock.Now().Sub(c.start)
+	return c.start.Add(time.Duration(float64(elapsed) * c.Factor))
+}
+
+func (c *ScaledClock) Sleep(d time.Duration) {
+	c.Clock.Sleep(time.Duration(float64(d) / c.Factor))
+}
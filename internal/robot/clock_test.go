@@ -0,0 +1,77 @@
+package robot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually controlled Clock for tests, Sleep advances the clock instantly.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+// Advance moves the clock forward by d.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// TestScaledClock tests that the simulated time passes faster than the underlying clock and sleeps are shortened.
+func TestScaledClock(t *testing.T) {
+	base := newFakeClock()
+	start := base.Now()
+	clock := NewScaledClock(base, 10)
+
+	clock.Sleep(10 * time.Second)
+	if got := base.Now().Sub(start); got != time.Second {
+		t.Errorf("Expected the underlying clock to sleep 1s, got %s", got)
+	}
+	if got := clock.Now().Sub(start); got != 10*time.Second {
+		t.Errorf("Expected 10s of simulated time to pass, got %s", got)
+	}
+}
+
+// TestTimeScale tests that tasks complete proportionally faster under a 10x time scale without changing their delays.
+func TestTimeScale(t *testing.T) {
+	run := func(scale float64) time.Duration {
+		config := DefaultConfig()
+		config.TimeScale = scale
+		service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+		taskID, _ := service.EnqueueTask("N E N E N", "40ms")
+
+		start := time.Now()
+		if err := service.ExecuteTask(taskID); err != nil {
+			t.Fatalf("Failed to execute task: %v", err)
+		}
+		if task := service.CurrentState().Tasks[taskID]; task.DelayBetweenCommands != CommandDuration(40*time.Millisecond) {
+			t.Errorf("Expected the task delay to stay 40ms, got %s", task.DelayBetweenCommands)
+		}
+		return time.Since(start)
+	}
+
+	realTime, scaled := run(0), run(10)
+	if realTime < 200*time.Millisecond {
+		t.Errorf("Expected the real time run to take at least 200ms, took %s", realTime)
+	}
+	if scaled > realTime/4 {
+		t.Errorf("Expected the 10x run to be much faster than %s, took %s", realTime, scaled)
+	}
+}
@@ -0,0 +1,60 @@
+package robot
+
+import (
+	"log"
+	"time"
+)
+
+// coalesce decides whether the event is broadcast right away. With a coalescing window configured, a non-terminal
+// event is held back for the window and replaced by any later event of the same task arriving in the meantime,
+// so only the latest one is broadcast. Terminal events are always broadcast immediately and discard the held event.
+func (s *Service) coalesce(event TaskStatusUpdateEvent) bool {
+	window := s.config.EventCoalesceWindow
+	if window <= 0 {
+		return true
+	}
+	if isActivity(event.Type) {
+		return true // Busy and idle events describe the dispatcher, not the task they carry
+	}
+	if event.Type == "" && isFinished(event.State) {
+		s.coalesceMu.Lock()
+		delete(s.coalesced, event.TaskID)
+		s.coalesceMu.Unlock()
+		return true
+	}
+
+	// Events are published asynchronously, an update arriving after the task finished is stale
+	s.mu.RLock()
+	task, exists := s.state.Tasks[event.TaskID]
+	s.mu.RUnlock()
+	if exists && isFinished(task.State) {
+		return false
+	}
+
+	s.coalesceMu.Lock()
+	defer s.coalesceMu.Unlock()
+	if _, held := s.coalesced[event.TaskID]; !held {
+		time.AfterFunc(window, func() { s.flushCoalesced(event.TaskID) })
+	} else {
+		log.Printf("Coalesced event for task %s: state=%s", event.TaskID, event.State)
+	}
+	s.coalesced[event.TaskID] = event
+	return false
+}
+
+// flushCoalesced broadcasts the event held back for the task, if it was not discarded by a terminal event.
+func (s *Service) flushCoalesced(taskID string) {
+	s.coalesceMu.Lock()
+	event, held := s.coalesced[taskID]
+	delete(s.coalesced, taskID)
+	s.coalesceMu.Unlock()
+
+	if held {
+		s.broadcastAndLog(event)
+	}
+}
+
+// isFinished reports whether a task in the given state will not change anymore.
+func isFinished(state TaskState) bool {
+	return state == Completed || state == Canceled || state == Aborted
+}
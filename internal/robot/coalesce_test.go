@@ -0,0 +1,80 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// collectEvents receives events until none arrived for the given quiet period.
+func collectEvents(sub Subscription, quiet time.Duration) []TaskStatusUpdateEvent {
+	var events []TaskStatusUpdateEvent
+	for {
+		select {
+		case event := <-sub.Events():
+			events = append(events, event)
+		case <-time.After(quiet):
+			return events
+		}
+	}
+}
+
+// TestEventCoalescing tests that coalescing reduces the events of a task while its terminal event is still published.
+func TestEventCoalescing(t *testing.T) {
+	run := func(window time.Duration) []TaskStatusUpdateEvent {
+		config := DefaultConfig()
+		config.MoveEvents = true
+		config.EventCoalesceWindow = window
+		service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+		sub, _ := service.Subscribe()
+		defer sub.Close()
+
+		taskID, _ := service.EnqueueTask("N E N E N E", "0s")
+		if err := service.ExecuteTask(taskID); err != nil {
+			t.Fatalf("Failed to execute task: %v", err)
+		}
+		return collectEvents(sub, 100*time.Millisecond)
+	}
+
+	// Pending, InProgress, a start and end move event per command and Completed
+	if events := run(0); len(events) != 15 {
+		t.Errorf("Expected 15 events without coalescing, got %d", len(events))
+	}
+
+	// Every update is superseded within the window, only the terminal event is left
+	events := run(time.Hour)
+	if len(events) != 1 || events[0].State != Completed {
+		t.Errorf("Expected only the Completed event with coalescing, got %+v", events)
+	}
+}
+
+// TestEventCoalescing_Flush tests that the latest held back event is published once the window elapsed.
+func TestEventCoalescing_Flush(t *testing.T) {
+	config := DefaultConfig()
+	config.EventCoalesceWindow = 20 * time.Millisecond
+	service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+
+	service.publishEvent("task-1", Pending, "", "")
+	service.publishEvent("task-1", InProgress, "", "")
+	service.publishEvent("task-2", Pending, "", "")
+
+	events := collectEvents(sub, 100*time.Millisecond)
+	if len(events) != 2 {
+		t.Fatalf("Expected one event per task, got %+v", events)
+	}
+	for _, event := range events {
+		if event.TaskID == "task-1" && event.State != InProgress {
+			t.Errorf("Expected the latest event of task-1 to be InProgress, got %s", event.State)
+		}
+	}
+
+	// A terminal event is published right away and discards the held back one
+	service.publishEvent("task-1", RequestCancellation, "", "")
+	service.publishEvent("task-1", Canceled, "", "")
+	events = collectEvents(sub, 100*time.Millisecond)
+	if len(events) != 1 || events[0].State != Canceled {
+		t.Errorf("Expected only the Canceled event, got %+v", events)
+	}
+}
@@ -1,6 +1,11 @@
 package robot
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
 
 type RobotCommand int
 
@@ -13,17 +18,180 @@ const (
 	South
 )
 
+// commandSpec describes how a command is written in a command sequence and how it moves the robot.
+type commandSpec struct {
+	Token  string // Token used in command sequences, e.g. "N"
+	Name   string // Human readable name used in error messages, e.g. "north"
+	DeltaX int    // Change in X coordinate when the command is executed
+	DeltaY int    // Change in Y coordinate when the command is executed
+}
+
+// commandTable is the single source of truth for the supported commands.
+// Parsing, delta computation, execution and String() are all driven by this table,
+// so adding a new command only requires a new enum value and a new entry here.
+// Holds are the exception: they carry a duration, see HoldCommand, and have no entry. Code looking up
+// a command must check RobotCommand.Hold first, a hold moves the robot by zero cells and has no compact code.
+var commandTable = map[RobotCommand]commandSpec{
+	North: {Token: "N", Name: "north", DeltaX: 0, DeltaY: 1},
+	West:  {Token: "W", Name: "west", DeltaX: -1, DeltaY: 0},
+	East:  {Token: "E", Name: "east", DeltaX: 1, DeltaY: 0},
+	South: {Token: "S", Name: "south", DeltaX: 0, DeltaY: -1},
+}
+
+// Hold commands keep the robot in place for the duration written after the token, e.g. "HOLD 2s" to let a door open.
+// A hold carries its duration in whole milliseconds above holdBase, so a command sequence stays a slice of RobotCommand.
+const (
+	holdToken                    = "HOLD"
+	holdBase        RobotCommand = 1 << 24
+	MaxHoldDuration              = time.Hour // Longest hold a command may ask for
+)
+
+// HoldCommand returns the command holding the robot in place for the duration. The duration must be positive,
+// in whole milliseconds and at most MaxHoldDuration.
+func HoldCommand(duration time.Duration) (RobotCommand, error) {
+	if duration <= 0 || duration > MaxHoldDuration || duration%time.Millisecond != 0 {
+		return 0, fmt.Errorf("%w: invalid hold duration %s, expected whole milliseconds between 1ms and %s", ErrInvalidCommand, duration, MaxHoldDuration)
+	}
+	return holdBase + RobotCommand(duration/time.Millisecond), nil
+}
+
+// parseHold parses the duration argument of a hold command. The argument may be upper case, e.g. for
+// case-insensitive tasks, which are converted to upper case before parsing.
+func parseHold(arg string) (RobotCommand, error) {
+	if arg == "" {
+		return 0, fmt.Errorf("%w: %s needs a duration, e.g. %s 2s", ErrInvalidCommand, holdToken, holdToken)
+	}
+	duration, err := time.ParseDuration(strings.ToLower(arg))
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid hold duration %q", ErrInvalidCommand, arg)
+	}
+	return HoldCommand(duration)
+}
+
+// Hold returns the duration of a hold command, false for commands moving the robot.
+func (c RobotCommand) Hold() (time.Duration, bool) {
+	if c < holdBase {
+		return 0, false
+	}
+	return time.Duration(c-holdBase) * time.Millisecond, true
+}
+
+// commandsByToken maps a command token back to its command, it is derived from commandTable.
+var commandsByToken = func() map[string]RobotCommand {
+	byToken := make(map[string]RobotCommand, len(commandTable))
+	for cmd, spec := range commandTable {
+		byToken[spec.Token] = cmd
+	}
+	return byToken
+}()
+
+// lookupCommand returns the command for the given token.
+func lookupCommand(token string) (RobotCommand, bool) {
+	cmd, ok := commandsByToken[token]
+	return cmd, ok
+}
+
+// moveCommands lists every command moving the robot.
+var moveCommands = []RobotCommand{North, West, East, South}
+
+// ParseCommandSet parses a comma separated set of command tokens, e.g. "N,S", as used for allowed commands.
+// Tokens are case-insensitive and duplicates are ignored, an empty string is the empty set.
+func ParseCommandSet(raw string) ([]RobotCommand, error) {
+	commands := []RobotCommand{}
+	for _, token := range strings.Split(raw, ",") {
+		if token = strings.TrimSpace(token); token == "" {
+			continue
+		}
+		cmd, ok := lookupCommand(strings.ToUpper(token))
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCommand, token)
+		}
+		if !slices.Contains(commands, cmd) {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands, nil
+}
+
+// checkAllowed returns an error for the first command that is not in allowed. A nil allowed set allows every command.
+func checkAllowed(commands []RobotCommand, allowed []RobotCommand) error {
+	if allowed == nil {
+		return nil
+	}
+	for i, cmd := range commands {
+		if _, hold := cmd.Hold(); hold {
+			continue // Holding does not move the robot, so it is always allowed
+		}
+		if !slices.Contains(allowed, cmd) {
+			return fmt.Errorf("%w: command %d (%s) is not in the allowed set %s", ErrCommandNotAllowed, i+1, cmd, RobotCommands(allowed))
+		}
+	}
+	return nil
+}
+
+// Delta returns the change in X and Y coordinates caused by the command, zero for a hold.
+func (c RobotCommand) Delta() (int, int) {
+	spec := commandTable[c]
+	return spec.DeltaX, spec.DeltaY
+}
+
 func (c RobotCommand) String() string {
-	switch c {
-	case North:
-		return "N"
-	case West:
-		return "W"
-	case East:
-		return "E"
-	case South:
-		return "S"
-	default:
-		return fmt.Sprintf("Unknown Command %d", c)
+	if spec, ok := commandTable[c]; ok {
+		return spec.Token
+	}
+	if duration, ok := c.Hold(); ok {
+		return holdToken + " " + duration.String()
+	}
+	return fmt.Sprintf("Unknown Command %d", c)
+}
+
+// maxSuggestionDistance is the largest edit distance for which an unknown token gets a suggestion.
+const maxSuggestionDistance = 2
+
+// suggestCommand returns the command closest to an unknown token, comparing case-insensitively
+// against both the token and the name of every command, e.g. "Nort" suggests N.
+// Ties go to the command starting with the same letter, e.g. "Est" suggests E rather than W.
+// Tokens that differ in every character from all commands, e.g. "X", get no suggestion.
+func suggestCommand(token string) (RobotCommand, bool) {
+	token = strings.ToLower(token)
+	best, bestScore, found := RobotCommand(0), 0, false
+	// Iterate in enum order rather than over the map, so remaining ties are resolved deterministically
+	for cmd := North; cmd <= South; cmd++ {
+		spec := commandTable[cmd]
+		for _, candidate := range []string{strings.ToLower(spec.Token), spec.Name} {
+			distance := editDistance(token, candidate)
+			if distance >= len(token) || distance > maxSuggestionDistance {
+				continue
+			}
+			score := 2 * distance
+			if token[0] != candidate[0] {
+				score++
+			}
+			if !found || score < bestScore {
+				best, bestScore, found = cmd, score, true
+			}
+		}
+	}
+	return best, found
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
 	}
+	return previous[len(b)]
 }
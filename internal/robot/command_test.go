@@ -1,6 +1,12 @@
 package robot
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestRobotCommand_String(t *testing.T) {
 	tests := []struct {
@@ -22,3 +28,117 @@ func TestRobotCommand_String(t *testing.T) {
 		})
 	}
 }
+
+// TestCommandTable verifies that the table driven commands behave like the original hard-coded ones.
+func TestCommandTable(t *testing.T) {
+	tests := []struct {
+		token          string
+		want           RobotCommand
+		deltaX, deltaY int
+	}{
+		{"N", North, 0, 1},
+		{"W", West, -1, 0},
+		{"E", East, 1, 0},
+		{"S", South, 0, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			cmd, ok := lookupCommand(tt.token)
+			if !ok || cmd != tt.want {
+				t.Fatalf("lookupCommand(%s) = %v, %v, want %v", tt.token, cmd, ok, tt.want)
+			}
+			if cmd.String() != tt.token {
+				t.Errorf("String() = %s, want %s", cmd.String(), tt.token)
+			}
+			dx, dy := cmd.Delta()
+			if dx != tt.deltaX || dy != tt.deltaY {
+				t.Errorf("Delta() = (%d, %d), want (%d, %d)", dx, dy, tt.deltaX, tt.deltaY)
+			}
+		})
+	}
+
+	// Every table entry must be reachable through its token
+	for cmd, spec := range commandTable {
+		if got, ok := lookupCommand(spec.Token); !ok || got != cmd {
+			t.Errorf("Token %s does not map back to command %d", spec.Token, cmd)
+		}
+	}
+
+	if _, ok := lookupCommand("X"); ok {
+		t.Error("Unknown token X must not be accepted")
+	}
+}
+
+// TestCommandSuggestions tests that near-miss tokens get the closest command suggested and unrelated tokens get none.
+func TestCommandSuggestions(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string // Suggested command, empty for no suggestion
+	}{
+		{"n", "N"},
+		{"No", "N"},
+		{"Nort", "N"},
+		{"north", "N"},
+		{"Est", "E"},
+		{"sout", "S"},
+		{"WEST", "W"},
+		{"wset", "W"},
+		{"X", ""},
+		{"Q", ""},
+		{"forward", ""},
+		{"123", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			_, _, _, err := parseCommands("N " + tt.token)
+			if !errors.Is(err, ErrInvalidCommand) {
+				t.Fatalf("Expected ErrInvalidCommand, got %v", err)
+			}
+			hint := fmt.Sprintf("did you mean '%s'?", tt.want)
+			if tt.want != "" && !strings.Contains(err.Error(), hint) {
+				t.Errorf("Expected suggestion %q in %q", hint, err)
+			}
+			if tt.want == "" && strings.Contains(err.Error(), "did you mean") {
+				t.Errorf("Expected no suggestion in %q", err)
+			}
+		})
+	}
+}
+
+// TestHoldCommand tests parsing, validating and printing hold commands.
+func TestHoldCommand(t *testing.T) {
+	commands, deltaX, deltaY, err := parseCommands("N HOLD 2s  HOLD 1.5s E")
+	if err != nil {
+		t.Fatalf("Failed to parse holds: %v", err)
+	}
+	if len(commands) != 4 || deltaX != 1 || deltaY != 1 {
+		t.Fatalf("Expected 4 commands moving (1, 1), got %v moving (%d, %d)", commands, deltaX, deltaY)
+	}
+	if hold, ok := commands[1].Hold(); !ok || hold != 2*time.Second {
+		t.Errorf("Expected a hold of 2s, got %s (%t)", hold, ok)
+	}
+	if dx, dy := commands[2].Delta(); dx != 0 || dy != 0 {
+		t.Errorf("Expected a hold not to move the robot, got (%d, %d)", dx, dy)
+	}
+	if _, ok := North.Hold(); ok {
+		t.Error("Expected N not to be a hold")
+	}
+	if got := RobotCommands(commands).String(); got != "N HOLD 2s HOLD 1.5s E" {
+		t.Errorf("Expected the holds to be printed with their duration, got %q", got)
+	}
+
+	for _, raw := range []string{"HOLD", "N HOLD", "HOLD soon", "HOLD 0s", "HOLD -1s", "HOLD 2h", "HOLD 100us"} {
+		if _, _, _, err := parseCommands(raw); !errors.Is(err, ErrInvalidCommand) {
+			t.Errorf("Expected %q to be rejected with ErrInvalidCommand, got %v", raw, err)
+		}
+	}
+
+	// Case-insensitive tasks are upper cased before parsing, including the duration
+	task, err := newTask(TaskSpec{Commands: "hold 250ms n", CaseInsensitive: true}, UUIDGenerator{}, false)
+	if err != nil {
+		t.Fatalf("Expected a lower case hold to be accepted case-insensitively, got %v", err)
+	}
+	if task.Commands.String() != "HOLD 250ms N" {
+		t.Errorf("Expected commands HOLD 250ms N, got %s", task.Commands)
+	}
+}
@@ -0,0 +1,75 @@
+package robot
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+)
+
+// The compact command encoding packs every command into 2 bits, for clients sending many commands at a high rate.
+// A stream is the number of commands as an unsigned varint, followed by the commands packed four per byte,
+// most significant bits first, with the unused bits of the last byte set to zero. It is transported as
+// standard base64. Every command has a fixed code, so the format does not depend on the order of the enum.
+var compactCodes = map[RobotCommand]byte{
+	North: 0,
+	East:  1,
+	South: 2,
+	West:  3,
+}
+
+// compactCommands maps a 2 bit code back to its command, it is derived from compactCodes.
+var compactCommands = func() [4]RobotCommand {
+	var byCode [4]RobotCommand
+	for cmd, code := range compactCodes {
+		byCode[code] = cmd
+	}
+	return byCode
+}()
+
+// maxCompactCommands bounds the decoded length, so a forged count cannot make the decoder allocate huge slices.
+const maxCompactCommands = 1 << 20
+
+// EncodeCompactCommands returns the base64 compact encoding of the commands. Only the four moves have a code,
+// a sequence with a hold fails with ErrInvalidCommand.
+func EncodeCompactCommands(commands []RobotCommand) (string, error) {
+	stream := binary.AppendUvarint(nil, uint64(len(commands)))
+	packed := make([]byte, (len(commands)+3)/4)
+	for i, cmd := range commands {
+		code, ok := compactCodes[cmd]
+		if !ok {
+			return "", fmt.Errorf("%w: command %d (%s) has no compact encoding", ErrInvalidCommand, i+1, cmd)
+		}
+		packed[i/4] |= code << (6 - 2*(i%4))
+	}
+	return base64.StdEncoding.EncodeToString(append(stream, packed...)), nil
+}
+
+// DecodeCompactCommands decodes a base64 compact command stream, see EncodeCompactCommands.
+// Malformed streams fail with ErrInvalidCommand.
+func DecodeCompactCommands(encoded string) (RobotCommands, error) {
+	stream, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: compact commands are not valid base64: %v", ErrInvalidCommand, err)
+	}
+
+	count, n := binary.Uvarint(stream)
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: compact commands lack a valid command count", ErrInvalidCommand)
+	}
+	if count > maxCompactCommands {
+		return nil, fmt.Errorf("%w: compact commands hold %d commands, at most %d are allowed", ErrInvalidCommand, count, maxCompactCommands)
+	}
+	packed := stream[n:]
+	if want := (int(count) + 3) / 4; len(packed) != want {
+		return nil, fmt.Errorf("%w: %d compact commands need %d bytes, got %d", ErrInvalidCommand, count, want, len(packed))
+	}
+
+	commands := make(RobotCommands, count)
+	for i := range commands {
+		commands[i] = compactCommands[(packed[i/4]>>(6-2*(i%4)))&0b11]
+	}
+	if unused := 2 * (len(packed)*4 - int(count)); unused > 0 && packed[len(packed)-1]&(1<<unused-1) != 0 {
+		return nil, fmt.Errorf("%w: compact commands have non-zero padding bits", ErrInvalidCommand)
+	}
+	return commands, nil
+}
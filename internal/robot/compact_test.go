@@ -0,0 +1,74 @@
+package robot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCompactCommandsRoundTrip tests that command sequences survive the compact encoding unchanged.
+func TestCompactCommandsRoundTrip(t *testing.T) {
+	for _, raw := range []string{"N", "N E S W", "W W W W W", "E N E N E N E N E", strings.Repeat("S E N W ", 150)} {
+		commands, _, _, err := parseCommands(raw)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", raw, err)
+		}
+
+		encoded, err := EncodeCompactCommands(commands)
+		if err != nil {
+			t.Fatalf("Failed to encode %q: %v", raw, err)
+		}
+		decoded, err := DecodeCompactCommands(encoded)
+		if err != nil {
+			t.Fatalf("Failed to decode %q: %v", raw, err)
+		}
+		if decoded.String() != RobotCommands(commands).String() {
+			t.Errorf("Expected %q after the round trip, got %q", RobotCommands(commands), decoded)
+		}
+	}
+}
+
+// TestCompactCommandsFormat tests the byte layout of the encoding, which clients implement on their own.
+func TestCompactCommandsFormat(t *testing.T) {
+	// 5 commands: count 0x05, then N E S W = 00 01 10 11 = 0x1B and E padded = 01 000000 = 0x40
+	if got, _ := EncodeCompactCommands([]RobotCommand{North, East, South, West, East}); got != "BRtA" {
+		t.Errorf("Expected encoding %q, got %q", "BRtA", got)
+	}
+}
+
+// TestEncodeCompactCommands_Hold tests that a hold is rejected instead of being encoded as another command.
+func TestEncodeCompactCommands_Hold(t *testing.T) {
+	hold, _ := HoldCommand(time.Second)
+	if encoded, err := EncodeCompactCommands([]RobotCommand{North, hold}); !errors.Is(err, ErrInvalidCommand) {
+		t.Errorf("Expected ErrInvalidCommand for a hold, got %q (%v)", encoded, err)
+	}
+}
+
+// TestDecodeCompactCommands_Errors tests that malformed streams are rejected with a clear error.
+func TestDecodeCompactCommands_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantMsg string
+	}{
+		{"Not base64", "!!!", "not valid base64"},
+		{"Empty stream", "", "command count"},
+		{"Missing commands", "BQ==", "need 2 bytes, got 0"},   // Count 5 without packed bytes
+		{"Extra bytes", "ARtA", "need 1 bytes, got 2"},        // Count 1 with two packed bytes
+		{"Non-zero padding", "BRtB", "non-zero padding bits"}, // Last byte 0x41
+		{"Huge count", "gICAgAE=", "at most"},                 // Count 2^28
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeCompactCommands(tt.encoded)
+			if !errors.Is(err, ErrInvalidCommand) {
+				t.Fatalf("Expected ErrInvalidCommand, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantMsg, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package robot
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Config holds the tunable settings of the robot service.
+// Use DefaultConfig to get a configuration with sensible defaults and override only what is needed.
+type Config struct {
+	QueueSize       int `json:"queue_size"`        // Capacity of the task queue created by NewEmbeddedService
+	MaxTasks        int `json:"max_tasks"`         // Maximum number of tasks kept by the service including finished ones, 0 means unlimited
+	MaxPendingTasks int `json:"max_pending_tasks"` // Maximum number of pending tasks regardless of the queue capacity, 0 means unlimited
+	MaxSubscribers  int `json:"max_subscribers"`   // Maximum number of concurrent event subscribers, 0 means unlimited
+	EventBufferSize int `json:"event_buffer_size"` // Number of recent events kept for replay on reconnect, 0 disables replay
+
+	// Abort the oldest in-progress task when the queue is full and a task with a higher priority arrives
+	PreemptOnOverload bool `json:"preempt_on_overload"`
+	// Order of pending tasks with equal priority, fifo if empty
+	TieBreak TieBreak `json:"tie_break"`
+
+	// Accept tasks without commands, which complete immediately as a no-op (e.g. heartbeats), instead of rejecting them
+	AllowEmptyTasks bool `json:"allow_empty_tasks"`
+	// Accept commands in any case, e.g. "n e s w", for every task. Commands are case-sensitive by default
+	CaseInsensitiveCommands bool `json:"case_insensitive_commands"`
+
+	// Number of cells the robot moves per command, for larger robots that cover several cells per tick
+	StepSize int `json:"step_size"`
+	// Maximum number of cells a task may traverse, its commands times the step size, 0 for no limit.
+	// Unlike a limit on the commands, it bounds how far the robot travels however the commands are scaled
+	MaxPathLength int `json:"max_path_length"`
+
+	// Reject and abort tasks while the robot is outside the warehouse, e.g. after a manual override for recovery
+	RejectOffGrid bool `json:"reject_off_grid"`
+
+	InitialHeading Heading      `json:"initial_heading"` // Heading of the robot at construction and after a reset
+	CommandFrame   CommandFrame `json:"command_frame"`   // Frame of the commands of tasks that do not choose one, absolute if empty
+	Obstacles      []Coord      `json:"obstacles"`       // Cells the robot can never enter
+
+	// Number of moves the robot can make on a full battery, recharged at the origin. 0 disables the battery simulation
+	BatteryCapacity int `json:"battery_capacity"`
+
+	// Publish a move event before and after every command, so a frontend can animate the robot
+	MoveEvents bool `json:"move_events"`
+	// Delay between the start and end event of a move, on top of the delay between commands
+	MoveEventDelay time.Duration `json:"move_event_delay"`
+
+	// Publish a progress heartbeat with the commands executed and the robot position every so many commands
+	// of a running task, 0 disables it
+	ProgressEveryCommands int `json:"progress_every_commands"`
+	// Publish a progress heartbeat once this much time passed since the previous one, checked after every command.
+	// 0 disables it
+	ProgressInterval time.Duration `json:"progress_interval"`
+
+	// Number of times a command failing with a transient error (e.g. a blocked cell) is retried before the task is aborted
+	CommandRetries int `json:"command_retries"`
+	// Delay before the first retry of a command, doubled for every further retry
+	CommandRetryBackoff time.Duration `json:"command_retry_backoff"`
+	// Extra delays before the first commands of every task while the robot speeds up, see SpeedRamp
+	SpeedRamp SpeedRamp `json:"speed_ramp"`
+
+	// Number of consecutive events a subscriber with a full buffer may miss before it is disconnected,
+	// so one slow client cannot keep falling behind. 0 keeps slow subscribers connected, they only miss events
+	MaxSubscriberLag int `json:"max_subscriber_lag"`
+
+	// Report the service as unhealthy in the stats once an event was dropped for a slow subscriber
+	DroppedEventsUnhealthy bool `json:"dropped_events_unhealthy"`
+
+	// Minimum delay between the commands of a task while events have subscribers, e.g. 1ms, so tasks without
+	// delay cannot flood the subscriber buffers. 0 disables it
+	MinSubscribedDelay time.Duration `json:"min_subscribed_delay"`
+
+	// Window within which non-terminal events of the same task are coalesced into the latest one, 0 disables coalescing
+	EventCoalesceWindow time.Duration `json:"event_coalesce_window"`
+
+	// Deliver events through a single writer in the order of the state changes, and disconnect a subscriber
+	// with a full buffer instead of letting it miss an event, so every client sees gapless sequence numbers
+	StrictSequence bool `json:"strict_sequence"`
+
+	// Sliding window over which the command and task throughput is computed
+	ThroughputWindow time.Duration `json:"throughput_window"`
+
+	// A task running longer than its expected run time multiplied by this factor is reported as stuck, must be positive
+	StuckSafetyFactor float64 `json:"stuck_safety_factor"`
+	// A stuck task overrunning by more than this grace period is aborted, 0 disables auto-abort
+	StuckAbortGrace time.Duration `json:"stuck_abort_grace"`
+	// A task still in RequestCancellation this long after the request is forced to Canceled, 0 disables it
+	CancelGrace time.Duration `json:"cancel_grace"`
+	// A pending task cancelled by a client can be restored with UncancelTask this long after the cancel,
+	// 0 makes every cancel final right away
+	UncancelWindow time.Duration `json:"uncancel_window"`
+
+	// URL receiving a POST with the terminal event of every task, no webhooks when empty
+	WebhookURL string `json:"webhook_url"`
+	// Number of delivery attempts per webhook before it is dropped
+	WebhookMaxAttempts int `json:"webhook_max_attempts"`
+	// Delay before the first retry of a failed webhook, doubled for every further retry
+	WebhookRetryBackoff time.Duration `json:"webhook_retry_backoff"`
+	// Capacity of the webhook delivery queue, deliveries not fitting are dropped
+	WebhookQueueSize int `json:"webhook_queue_size"`
+
+	TaskIDPrefix string `json:"task_id_prefix"` // Prefix of every task ID, e.g. "whA-"
+
+	// Factor by which the service time runs faster than the clock, e.g. 10 plays every delay ten times faster. 0 or 1 is real time
+	TimeScale float64 `json:"time_scale"`
+
+	IDGenerator IDGenerator `json:"-"` // Generator for task IDs, random UUIDs when nil
+	Clock       Clock       `json:"-"` // Source of time, the real clock when nil
+	AuditLogger AuditLogger `json:"-"` // Receives an entry for every successful move, no audit log when nil
+}
+
+// DefaultConfig returns the configuration used when no explicit configuration is provided.
+func DefaultConfig() Config {
+	return Config{
+		QueueSize:           100,
+		MaxSubscribers:      100,
+		EventBufferSize:     256,
+		StuckSafetyFactor:   2,
+		ThroughputWindow:    time.Minute,
+		CommandRetryBackoff: 100 * time.Millisecond,
+		InitialHeading:      HeadingNorth,
+		CommandFrame:        FrameAbsolute,
+		TieBreak:            TieBreakFIFO,
+		StepSize:            1,
+		RejectOffGrid:       true,
+		WebhookMaxAttempts:  5,
+		WebhookRetryBackoff: time.Second,
+		WebhookQueueSize:    100,
+	}
+}
+
+// Validate checks that the configuration values are usable.
+func (c Config) Validate() error {
+	if !c.InitialHeading.Valid() {
+		return fmt.Errorf("invalid initial heading: %s", c.InitialHeading)
+	}
+	if _, err := ParseCommandFrame(string(c.CommandFrame)); err != nil {
+		return err
+	}
+	if _, err := ParseTieBreak(string(c.TieBreak)); err != nil {
+		return err
+	}
+	for _, obstacle := range c.Obstacles {
+		if !obstacle.inWarehouse() {
+			return fmt.Errorf("obstacle %s is outside the warehouse", obstacle)
+		}
+		if obstacle == (Coord{X: homeX, Y: homeY}) {
+			return fmt.Errorf("obstacle %s blocks the robot's home cell", obstacle)
+		}
+	}
+	if strings.ContainsAny(c.TaskIDPrefix, "/?# \t") {
+		return fmt.Errorf("invalid task ID prefix %q: task IDs are used in URL paths", c.TaskIDPrefix)
+	}
+	if c.StepSize < 0 {
+		return fmt.Errorf("invalid step size: %d", c.StepSize)
+	}
+	if c.MaxPathLength < 0 {
+		return fmt.Errorf("invalid max path length: %d", c.MaxPathLength)
+	}
+	if !(c.StuckSafetyFactor > 0) { // Also rejects NaN
+		return fmt.Errorf("invalid stuck safety factor: %g", c.StuckSafetyFactor)
+	}
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL: %s", c.WebhookURL)
+		}
+	}
+	if c.WebhookMaxAttempts < 0 || c.WebhookRetryBackoff < 0 || c.WebhookQueueSize < 0 {
+		return fmt.Errorf("invalid webhook retry settings: %d attempts, %s backoff, queue of %d", c.WebhookMaxAttempts, c.WebhookRetryBackoff, c.WebhookQueueSize)
+	}
+	if c.TimeScale < 0 {
+		return fmt.Errorf("invalid time scale: %g", c.TimeScale)
+	}
+	if c.QueueSize < 0 {
+		return fmt.Errorf("invalid queue size: %d", c.QueueSize)
+	}
+	if c.MaxTasks < 0 {
+		return fmt.Errorf("invalid max tasks: %d", c.MaxTasks)
+	}
+	if c.MaxPendingTasks < 0 {
+		return fmt.Errorf("invalid max pending tasks: %d", c.MaxPendingTasks)
+	}
+	if c.CommandRetries < 0 {
+		return fmt.Errorf("invalid command retries: %d", c.CommandRetries)
+	}
+	if c.EventCoalesceWindow < 0 {
+		return fmt.Errorf("invalid event coalesce window: %s", c.EventCoalesceWindow)
+	}
+	if c.CancelGrace < 0 {
+		return fmt.Errorf("invalid cancel grace: %s", c.CancelGrace)
+	}
+	if c.UncancelWindow < 0 {
+		return fmt.Errorf("invalid uncancel window: %s", c.UncancelWindow)
+	}
+	if err := c.SpeedRamp.validate(); err != nil {
+		return err
+	}
+	if c.StrictSequence && c.EventCoalesceWindow > 0 {
+		return fmt.Errorf("strict sequence mode delivers every event, it cannot be combined with an event coalesce window")
+	}
+	if c.MoveEventDelay < 0 {
+		return fmt.Errorf("invalid move event delay: %s", c.MoveEventDelay)
+	}
+	if c.ProgressEveryCommands < 0 {
+		return fmt.Errorf("invalid progress event cadence: %d commands", c.ProgressEveryCommands)
+	}
+	if c.MinSubscribedDelay < 0 {
+		return fmt.Errorf("invalid minimum subscribed delay: %s", c.MinSubscribedDelay)
+	}
+	if c.ProgressInterval < 0 {
+		return fmt.Errorf("invalid progress event interval: %s", c.ProgressInterval)
+	}
+	if c.MaxSubscriberLag < 0 {
+		return fmt.Errorf("invalid max subscriber lag: %d", c.MaxSubscriberLag)
+	}
+	if c.BatteryCapacity < 0 {
+		return fmt.Errorf("invalid battery capacity: %d", c.BatteryCapacity)
+	}
+	return nil
+}
+
+// redactedValue replaces secrets in reported configurations.
+const redactedValue = "REDACTED"
+
+// Redacted returns a copy of the configuration safe to report, e.g. to operators. Credentials and query parameters
+// of the webhook URL, which may carry an API key, are replaced with REDACTED.
+func (c Config) Redacted() Config {
+	if c.WebhookURL == "" {
+		return c
+	}
+	webhookURL, err := url.Parse(c.WebhookURL)
+	if err != nil {
+		c.WebhookURL = redactedValue
+		return c
+	}
+	if webhookURL.User != nil {
+		webhookURL.User = url.User(redactedValue)
+	}
+	if webhookURL.RawQuery != "" {
+		webhookURL.RawQuery = redactedValue
+	}
+	c.WebhookURL = webhookURL.String()
+	return c
+}
+
+// RuntimeConfig is the configuration a service runs with, including the values that cannot be configured.
+// @Description Effective configuration of the robot service, with secrets redacted
+type RuntimeConfig struct {
+	WarehouseSize        int             `json:"warehouse_size" example:"10"`                                      // Number of cells per row and column
+	DelayBetweenCommands CommandDuration `json:"default_delay_between_commands" swaggertype:"string" example:"1s"` // Delay of tasks that do not set one
+	Config               Config          `json:"config"`                                                           // Effective configuration, see Config.Redacted
+}
+
+// RuntimeConfig returns the configuration the service runs with, with secrets redacted.
+func (s *Service) RuntimeConfig() RuntimeConfig {
+	return RuntimeConfig{WarehouseSize: warehouseSize, DelayBetweenCommands: defaultDelayBetweenCommands, Config: s.config.Redacted()}
+}
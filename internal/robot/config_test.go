@@ -0,0 +1,34 @@
+package robot
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConfigValidateLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(config *Config)
+		wantErr bool
+	}{
+		{"Default", func(config *Config) {}, false},
+		{"Negative step size", func(config *Config) { config.StepSize = -1 }, true},
+		{"Negative max path length", func(config *Config) { config.MaxPathLength = -1 }, true},
+		{"Stuck safety factor below one", func(config *Config) { config.StuckSafetyFactor = 0.5 }, false},
+		{"Zero stuck safety factor", func(config *Config) { config.StuckSafetyFactor = 0 }, true},
+		{"Negative stuck safety factor", func(config *Config) { config.StuckSafetyFactor = -2 }, true},
+		{"NaN stuck safety factor", func(config *Config) { config.StuckSafetyFactor = math.NaN() }, true},
+		{"Negative time scale", func(config *Config) { config.TimeScale = -1 }, true},
+		{"Zero queue size", func(config *Config) { config.QueueSize = 0 }, false},
+		{"Negative queue size", func(config *Config) { config.QueueSize = -1 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(&config)
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Package robot implements the warehouse robot service: task parsing, queueing, dispatch, execution
+// and the task status event stream. It has no dependency on the HTTP layer, so the programs of this
+// module can run it on its own; as an internal package it cannot be imported from other modules:
+//
+//	service, err := robot.NewEmbeddedService(ctx, robot.DefaultConfig())
+//	if err != nil {
+//		return err
+//	}
+//
+//	subscription, _ := service.Subscribe()
+//	defer subscription.Close()
+//
+//	taskID, err := service.EnqueueTask("N E S W", "100ms")
+//	for event := range subscription.Events() {
+//		if event.TaskID == taskID && event.State == robot.Completed {
+//			break
+//		}
+//	}
+//
+// The service stops processing tasks when ctx is cancelled.
+package robot
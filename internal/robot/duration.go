@@ -0,0 +1,65 @@
+package robot
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DurationFormat selects how CommandDuration values are serialized to JSON.
+type DurationFormat int32
+
+const (
+	DurationString DurationFormat = iota // Go duration string, e.g. "1.5s" (default)
+	DurationMillis                       // Integer number of milliseconds, e.g. 1500
+)
+
+// durationFormat is the process wide serialization format, selected once at startup.
+var durationFormat atomic.Int32
+
+// SetDurationFormat selects how CommandDuration values are serialized to JSON.
+// It is meant to be called once at startup, before any response is written.
+func SetDurationFormat(format DurationFormat) {
+	durationFormat.Store(int32(format))
+}
+
+// ParseDurationFormat parses "string" or "ms" into a DurationFormat.
+func ParseDurationFormat(raw string) (DurationFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "string":
+		return DurationString, nil
+	case "ms", "millis":
+		return DurationMillis, nil
+	default:
+		return DurationString, fmt.Errorf("invalid duration format: %s, expected 'string' or 'ms'", raw)
+	}
+}
+
+func (cd CommandDuration) MarshalJSON() ([]byte, error) {
+	if DurationFormat(durationFormat.Load()) == DurationMillis {
+		return json.Marshal(time.Duration(cd).Milliseconds())
+	}
+	return json.Marshal(cd.String())
+}
+
+// UnmarshalJSON accepts both serialization formats: a Go duration string or a number of milliseconds.
+func (cd *CommandDuration) UnmarshalJSON(data []byte) error {
+	var millis int64
+	if err := json.Unmarshal(data, &millis); err == nil {
+		*cd = CommandDuration(time.Duration(millis) * time.Millisecond)
+		return nil
+	}
+
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("duration must be a string or a number of milliseconds: %s", data)
+	}
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		return fmt.Errorf("invalid duration: %v", err)
+	}
+	*cd = CommandDuration(duration)
+	return nil
+}
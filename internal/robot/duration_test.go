@@ -0,0 +1,74 @@
+package robot
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestCommandDurationRoundTrip tests that both serialization formats round-trip through the unmarshaler.
+func TestCommandDurationRoundTrip(t *testing.T) {
+	defer SetDurationFormat(DurationString)
+
+	tests := []struct {
+		name     string
+		format   DurationFormat
+		duration CommandDuration
+		wantJSON string
+	}{
+		{"String format", DurationString, CommandDuration(1500 * time.Millisecond), `"1.5s"`},
+		{"String format zero", DurationString, 0, `"0s"`},
+		{"Millis format", DurationMillis, CommandDuration(1500 * time.Millisecond), `1500`},
+		{"Millis format zero", DurationMillis, 0, `0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDurationFormat(tt.format)
+
+			data, err := json.Marshal(tt.duration)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tt.wantJSON {
+				t.Errorf("Expected JSON %s, got %s", tt.wantJSON, data)
+			}
+
+			var decoded CommandDuration
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if decoded != tt.duration {
+				t.Errorf("Expected %s after round trip, got %s", tt.duration, decoded)
+			}
+		})
+	}
+}
+
+func TestCommandDurationUnmarshalInvalid(t *testing.T) {
+	for _, raw := range []string{`"abc"`, `true`, `{}`} {
+		var decoded CommandDuration
+		if err := json.Unmarshal([]byte(raw), &decoded); err == nil {
+			t.Errorf("Expected error for %s", raw)
+		}
+	}
+}
+
+func TestParseDurationFormat(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    DurationFormat
+		wantErr bool
+	}{
+		{"string", DurationString, false},
+		{"ms", DurationMillis, false},
+		{"MS", DurationMillis, false},
+		{"seconds", DurationString, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDurationFormat(tt.raw)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDurationFormat(%q) = %v, %v, want %v, wantErr %v", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
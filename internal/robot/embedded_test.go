@@ -0,0 +1,98 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// waitForState waits until an event with the given state is received for the task.
+func waitForState(t *testing.T, sub Subscription, taskID string, want TaskState) {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				t.Fatalf("Subscription closed before task %s reached %s", taskID, want)
+			}
+			if event.TaskID == taskID && event.State == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Task %s did not reach %s within timeout", taskID, want)
+		}
+	}
+}
+
+// TestEmbeddedServiceEndToEnd runs tasks through the embedded service using only the robot package API.
+func TestEmbeddedServiceEndToEnd(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service, err := NewEmbeddedService(ctx, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	sub, err := service.Subscribe()
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	first, err := service.EnqueueTask("N E N E", "1ms")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+	waitForState(t, sub, first, Completed)
+
+	second, err := service.EnqueueTask("N N N N N N", "50ms")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+	waitForState(t, sub, second, InProgress)
+	if err := service.CancelTask(second); err != nil {
+		t.Fatalf("Failed to cancel task: %v", err)
+	}
+	waitForState(t, sub, second, Canceled)
+
+	state := service.CurrentState()
+	if state.Tasks[first].State != Completed || state.Tasks[second].State != Canceled {
+		t.Errorf("Unexpected task states: first=%s second=%s", state.Tasks[first].State, state.Tasks[second].State)
+	}
+	if state.RobotState.X != 2 || state.RobotState.Y < 2 {
+		t.Errorf("Unexpected robot position (%d, %d)", state.RobotState.X, state.RobotState.Y)
+	}
+}
+
+// TestCurrentStateIsSnapshot tests that mutating the returned state does not affect the service.
+func TestCurrentStateIsSnapshot(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	taskID, err := service.EnqueueTask("N", "10ms")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+
+	state := service.CurrentState()
+	delete(state.Tasks, taskID)
+
+	if _, exists := service.CurrentState().Tasks[taskID]; !exists {
+		t.Error("Mutating the returned state must not change the service state")
+	}
+}
+
+// TestEmbeddedServiceInvalidConfig tests that the embedded service and the zones reject an invalid configuration.
+func TestEmbeddedServiceInvalidConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultConfig()
+	config.QueueSize = -1
+	if service, err := NewEmbeddedService(ctx, config); err == nil || service != nil {
+		t.Errorf("Expected an error and no service for a negative queue size, got %v", err)
+	}
+	if zones, err := NewZones(ctx, config, "north"); err == nil || zones != nil {
+		t.Errorf("Expected NewZones to fail for a negative queue size, got %v", err)
+	}
+}
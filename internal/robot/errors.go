@@ -0,0 +1,34 @@
+package robot
+
+import "errors"
+
+// Sentinel errors returned by the robot service, wrapped with details about the failure.
+// Use errors.Is to check for them.
+var (
+	ErrInvalidCommand  = errors.New("invalid command")             // The command sequence or delay cannot be parsed
+	ErrTaskNotFound    = errors.New("task not found")              // No task with the given ID exists
+	ErrQuiescing       = errors.New("service is quiescing")        // New tasks are rejected while the queue drains
+	ErrQueueFull       = errors.New("task queue is full")          // The task queue has no free slot
+	ErrTaskLimit       = errors.New("task limit reached")          // The service holds the maximum number of tasks, finished tasks must be purged
+	ErrPendingLimit    = errors.New("pending task limit reached")  // The service holds the maximum number of pending tasks, retry once some are dispatched
+	ErrOutOfBounds     = errors.New("out of warehouse boundaries") // The robot would leave the warehouse
+	ErrInvalidState    = errors.New("invalid state")               // The operation is not allowed in the current task or robot state
+	ErrZoneNotFound    = errors.New("zone not found")              // No warehouse zone with the given name exists
+	ErrGroupNotFound   = errors.New("group not found")             // No task belongs to the given group
+	ErrObstacle        = errors.New("obstacle")                    // The target cell holds a permanent obstacle
+	ErrCellBlocked     = errors.New("cell blocked")                // The target cell is temporarily occupied, the move may be retried
+	ErrBatteryDepleted = errors.New("battery depleted")            // The robot battery would run out away from the home cell
+	ErrAlreadyCanceled = errors.New("task already canceled")       // The task was cancelled before, cancelling it again has no effect
+
+	ErrCommandNotAllowed = errors.New("command not allowed")     // The command is valid but not in the submitter's allowed set
+	ErrNotStarted        = errors.New("service not started yet") // The service has not started processing its task queue
+	ErrRobotOffGrid      = errors.New("robot off grid")          // The robot is outside the warehouse and must be moved back first
+	ErrNoPath            = errors.New("no path")                 // Obstacles wall the target cell off from the robot
+	ErrPathTooLong       = errors.New("path too long")           // The task would traverse more cells than allowed
+	ErrNoCheckpoint      = errors.New("no checkpoint")           // No checkpoint was set to return to
+)
+
+// isTransient reports whether a failed command may succeed when retried.
+func isTransient(err error) bool {
+	return errors.Is(err, ErrCellBlocked)
+}
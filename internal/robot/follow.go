@@ -0,0 +1,193 @@
+package robot
+
+import (
+	"fmt"
+	"log"
+)
+
+// pathCommands is the order in which PathTo tries the moves, so equally short paths are chosen deterministically.
+var pathCommands = []RobotCommand{North, East, South, West}
+
+// PathTo returns the shortest command sequence moving the robot from its current position to the target,
+// honoring the warehouse bounds, obstacles and step size. It fails with ErrOutOfBounds or ErrObstacle
+// for a target the robot can never stand on, and with ErrNoPath if the target cannot be reached.
+func (s *Service) PathTo(target Coord) ([]RobotCommand, error) {
+	if err := s.checkTarget(target); err != nil {
+		return nil, err
+	}
+	robotState := s.GetRobotState()
+	start := Coord{X: int(robotState.X), Y: int(robotState.Y)}
+
+	// Breadth-first search, remembering the move leading to every visited cell
+	cameBy := map[Coord]RobotCommand{}
+	previous := map[Coord]Coord{}
+	visited := map[Coord]bool{start: true}
+	frontier := []Coord{start}
+	for len(frontier) > 0 && !visited[target] {
+		cell := frontier[0]
+		frontier = frontier[1:]
+		for _, cmd := range pathCommands {
+			spec := commandTable[cmd]
+			next, ok := s.stepFrom(cell, spec.DeltaX, spec.DeltaY)
+			if !ok || visited[next] {
+				continue
+			}
+			visited[next] = true
+			cameBy[next] = cmd
+			previous[next] = cell
+			frontier = append(frontier, next)
+		}
+	}
+	if !visited[target] {
+		return nil, fmt.Errorf("%w: %s cannot be reached from %s", ErrNoPath, target, start)
+	}
+
+	path := []RobotCommand{}
+	for cell := target; cell != start; cell = previous[cell] {
+		path = append([]RobotCommand{cameBy[cell]}, path...)
+	}
+	return path, nil
+}
+
+// checkTarget returns an error if the robot can never stand on the target cell.
+func (s *Service) checkTarget(target Coord) error {
+	if !target.inWarehouse() {
+		return fmt.Errorf("%w: target %s is outside the warehouse", ErrOutOfBounds, target)
+	}
+	if s.isObstacle(target) {
+		return fmt.Errorf("%w: target %s is an obstacle", ErrObstacle, target)
+	}
+	return nil
+}
+
+// Follow enqueues a follow task. Once dispatched, it moves the robot one cell at a time toward the target,
+// re-planning the path after every move, so clients can move the target while the robot chases it,
+// see UpdateFollowTarget. The task completes when the robot reaches the target or the target is cleared.
+// The spec sets the options of the task, e.g. its delay and allowed commands.
+func (s *Service) Follow(target Coord, spec TaskSpec) (string, error) {
+	if err := s.checkTarget(target); err != nil {
+		return "", err
+	}
+	spec.FollowTarget = &target
+	return s.SubmitTask(spec)
+}
+
+// UpdateFollowTarget moves the target of a pending or running follow task, a nil target clears it,
+// which stops the task before its next move. It fails with ErrInvalidState for other or finished tasks.
+func (s *Service) UpdateFollowTarget(taskID string, target *Coord) error {
+	if target != nil {
+		if err := s.checkTarget(*target); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, exists := s.state.Tasks[taskID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	if !task.Follow {
+		return fmt.Errorf("%w: task %s is not a follow task", ErrInvalidState, taskID)
+	}
+	if task.State != Pending && task.State != InProgress {
+		return fmt.Errorf("%w: follow task %s is '%s'", ErrInvalidState, taskID, task.State)
+	}
+
+	if target != nil {
+		target := *target
+		task.FollowTarget = &target
+		log.Printf("Follow task %s now targets %s", taskID, target)
+	} else {
+		task.FollowTarget = nil
+		log.Printf("Follow task %s target cleared", taskID)
+	}
+	s.state.Tasks[taskID] = task
+	return nil
+}
+
+// followTarget returns the current target of the follow task, false once it was cleared.
+func (s *Service) followTarget(taskID string) (Coord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	task := s.state.Tasks[taskID]
+	if task.FollowTarget == nil {
+		return Coord{}, false
+	}
+	return *task.FollowTarget, true
+}
+
+// executeFollow runs a dispatched follow task, making one move toward the latest target at a time.
+// The path is planned after the delay before every move, so a target moved meanwhile is followed right away.
+func (s *Service) executeFollow(task RobotTask) error {
+	cadence := s.newProgressCadence()
+	for executed := 0; ; executed++ {
+		if finished, err := s.finishFollow(task.ID, executed); finished {
+			return err
+		}
+		started := s.config.Clock.Now()
+		s.config.Clock.Sleep(s.stepDelay(task, executed))
+		if finished, err := s.finishFollow(task.ID, executed); finished {
+			return err
+		}
+
+		target, _ := s.followTarget(task.ID)
+		robotState := s.GetRobotState()
+		path, err := s.PathTo(target)
+		if err == nil {
+			cmd := path[0]
+			s.startMove(task.ID, cmd, robotState)
+			if err = s.executeWithRetry(task.ID, cmd); err == nil {
+				s.recordCommand()
+				s.recordStep(task.ID, executed, cmd, started)
+				s.markExecuted(task.ID, executed+1)
+				s.finishMove(task.ID, cmd, robotState)
+				s.reportProgress(task.ID, cadence, executed+1, 0)
+				continue
+			}
+		}
+
+		s.UpdateTaskError(task.ID, fmt.Sprintf("Error following %s: %v", target, err))
+		s.recordProgress(task.ID, executed)
+		s.UpdateTaskState(task.ID, Aborted, abortReason(err))
+		return fmt.Errorf("Error following %s for task %s: %w", target, task.ID, err)
+	}
+}
+
+// finishFollow ends the follow task if it was cancelled or aborted, its target was cleared or the robot reached it.
+// It reports whether the task is finished and the error to return for it.
+func (s *Service) finishFollow(taskID string, executed int) (bool, error) {
+	state, err := s.GetTaskState(taskID)
+	if err != nil {
+		return true, fmt.Errorf("Error getting task state for %s: %v", taskID, err)
+	}
+	switch state {
+	case RequestCancellation:
+		s.finishCancellation(taskID)
+		return true, nil
+	case Aborted:
+		s.recordProgress(taskID, executed)
+		return true, fmt.Errorf("Task %s was aborted during execution", taskID) // Aborted by the stuck task monitor or preempted
+	case Canceled:
+		return true, nil // Force-canceled by the monitor
+	}
+
+	target, following := s.followTarget(taskID)
+	if !following {
+		if s.completeTask(taskID, ReasonTargetCleared) == Completed {
+			s.recordTaskCompleted()
+			log.Printf("Follow task %s stopped, its target was cleared", taskID)
+		}
+		return true, nil
+	}
+	robotState := s.GetRobotState()
+	if (Coord{X: int(robotState.X), Y: int(robotState.Y)}) == target {
+		if s.completeTask(taskID, ReasonCompleted) == Completed {
+			s.recordTaskCompleted()
+			log.Printf("Follow task %s reached %s", taskID, target)
+		}
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,148 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// sleepHookClock is a fake clock calling a hook on every sleep, i.e. before every move of a task.
+type sleepHookClock struct {
+	*fakeClock
+	onSleep func()
+}
+
+func (c *sleepHookClock) Sleep(d time.Duration) {
+	c.onSleep()
+	c.fakeClock.Sleep(d)
+}
+
+// newFollowService returns a service whose clock calls onSleep with the service before every move.
+func newFollowService(config Config, onSleep func(service *Service)) *Service {
+	clock := &sleepHookClock{fakeClock: newFakeClock()}
+	config.Clock = clock
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	clock.onSleep = func() { onSleep(service) }
+	return service
+}
+
+// TestPathTo tests that the shortest path goes around obstacles and that unreachable targets are rejected.
+func TestPathTo(t *testing.T) {
+	config := DefaultConfig()
+	config.Obstacles = []Coord{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 8, Y: 9}, {X: 9, Y: 8}}
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	path, err := service.PathTo(Coord{X: 0, Y: 2})
+	if err != nil {
+		t.Fatalf("Failed to find a path: %v", err)
+	}
+	if got := RobotCommands(path).String(); got != "E E N N W W" {
+		t.Errorf("Expected the path around the obstacles, got %q", got)
+	}
+
+	for _, tt := range []struct {
+		target  Coord
+		wantErr error
+	}{
+		{Coord{X: 10, Y: 0}, ErrOutOfBounds},
+		{Coord{X: 1, Y: 1}, ErrObstacle},
+		{Coord{X: 9, Y: 9}, ErrNoPath}, // Walled off by the obstacles in the corner
+	} {
+		if _, err := service.PathTo(tt.target); !errors.Is(err, tt.wantErr) {
+			t.Errorf("Target %s: expected %v, got %v", tt.target, tt.wantErr, err)
+		}
+	}
+}
+
+// TestFollow_Retarget tests that the robot re-plans toward a target moved while it follows.
+func TestFollow_Retarget(t *testing.T) {
+	var taskID string
+	service := newFollowService(DefaultConfig(), func(service *Service) {
+		// Once the robot got halfway to the first target, the target moves east
+		if service.GetRobotState().Y == 2 {
+			target := Coord{X: 3, Y: 1}
+			if err := service.UpdateFollowTarget(taskID, &target); err != nil {
+				t.Errorf("Failed to move the target: %v", err)
+			}
+		}
+	})
+
+	var err error
+	taskID, err = service.Follow(Coord{X: 0, Y: 5}, TaskSpec{DelayBetweenCommands: "1s"})
+	if err != nil {
+		t.Fatalf("Failed to enqueue the follow task: %v", err)
+	}
+	if err := service.ExecuteTask(taskID); err != nil {
+		t.Fatalf("Follow task failed: %v", err)
+	}
+
+	task := service.CurrentState().Tasks[taskID]
+	if task.State != Completed || task.Reason != ReasonCompleted {
+		t.Errorf("Expected the follow task completed normally, got %s (%s)", task.State, task.Reason)
+	}
+	if got := service.GetRobotState(); got.X != 3 || got.Y != 1 {
+		t.Errorf("Expected the robot on the new target (3, 1), got (%d, %d)", got.X, got.Y)
+	}
+
+	trace, _ := service.TaskTrace(taskID)
+	commands := make(RobotCommands, len(trace))
+	for i, step := range trace {
+		commands[i] = step.Command
+		if step.Position.Y > 2 {
+			t.Errorf("Expected the robot to turn toward the new target at y=2, got step %d at (%d, %d)", i, step.Position.X, step.Position.Y)
+		}
+	}
+	if got := commands.String(); got != "N N E E E S" {
+		t.Errorf("Expected the moves N N E E E S, got %q", got)
+	}
+}
+
+// TestFollow_Clear tests that clearing the target stops the follow task where the robot is.
+func TestFollow_Clear(t *testing.T) {
+	var taskID string
+	moves := 0
+	service := newFollowService(DefaultConfig(), func(service *Service) {
+		if moves++; moves == 3 {
+			if err := service.UpdateFollowTarget(taskID, nil); err != nil {
+				t.Errorf("Failed to clear the target: %v", err)
+			}
+		}
+	})
+
+	taskID, _ = service.Follow(Coord{X: 9, Y: 0}, TaskSpec{DelayBetweenCommands: "1s"})
+	if err := service.ExecuteTask(taskID); err != nil {
+		t.Fatalf("Follow task failed: %v", err)
+	}
+
+	task := service.CurrentState().Tasks[taskID]
+	if task.State != Completed || task.Reason != ReasonTargetCleared {
+		t.Errorf("Expected the follow task completed as cleared, got %s (%s)", task.State, task.Reason)
+	}
+	// The target was cleared while waiting for the third move, which is not made anymore
+	if got := service.GetRobotState(); got.X != 2 || got.Y != 0 {
+		t.Errorf("Expected the robot to stop at (2, 0), got (%d, %d)", got.X, got.Y)
+	}
+
+	if err := service.UpdateFollowTarget(taskID, nil); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected ErrInvalidState for a finished follow task, got %v", err)
+	}
+}
+
+// TestFollow_Invalid tests that follow tasks reject targets the robot can never stand on
+// and that only follow tasks can be retargeted.
+func TestFollow_Invalid(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	if _, err := service.Follow(Coord{X: -1, Y: 0}, TaskSpec{DelayBetweenCommands: "1s"}); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("Expected ErrOutOfBounds, got %v", err)
+	}
+
+	taskID, _ := service.EnqueueTask("N", "1s")
+	target := Coord{X: 1, Y: 1}
+	if err := service.UpdateFollowTarget(taskID, &target); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected ErrInvalidState for a regular task, got %v", err)
+	}
+	if err := service.UpdateFollowTarget("missing", &target); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
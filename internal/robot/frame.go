@@ -0,0 +1,73 @@
+package robot
+
+import (
+	"fmt"
+	"log"
+)
+
+// CommandFrame decides how the commands N, E, S and W of a task are interpreted. The robot has no turn
+// commands, its heading only changes with the initial heading or a forced robot state, never during a task.
+type CommandFrame string
+
+// Frames for the commands of a task
+const (
+	FrameAbsolute CommandFrame = "absolute" // N, E, S and W are grid directions, the default
+	FrameRelative CommandFrame = "relative" // N, E, S and W are forward, right, back and left of the robot's heading
+)
+
+// ParseCommandFrame parses a frame name, an empty name is the default FrameAbsolute.
+func ParseCommandFrame(raw string) (CommandFrame, error) {
+	switch frame := CommandFrame(raw); frame {
+	case "":
+		return FrameAbsolute, nil
+	case FrameAbsolute, FrameRelative:
+		return frame, nil
+	default:
+		return "", fmt.Errorf("invalid command frame: %s, expected absolute or relative", raw)
+	}
+}
+
+// headingCommands are the commands moving the robot in the direction of every heading, in Heading order.
+var headingCommands = [...]RobotCommand{HeadingNorth: North, HeadingEast: East, HeadingSouth: South, HeadingWest: West}
+
+// turnsClockwise is the number of quarter turns clockwise from forward every command stands for in the relative frame.
+var turnsClockwise = map[RobotCommand]int{North: 0, East: 1, South: 2, West: 3}
+
+// relativeTo returns the grid commands moving the robot like the commands interpreted relative to the heading,
+// e.g. N is E for a robot heading east.
+func (c RobotCommands) relativeTo(heading Heading) RobotCommands {
+	resolved := make(RobotCommands, len(c))
+	for i, cmd := range c {
+		if _, hold := cmd.Hold(); hold {
+			resolved[i] = cmd // A hold has no direction
+			continue
+		}
+		resolved[i] = headingCommands[(int(heading)+turnsClockwise[cmd])%len(headingCommands)]
+	}
+	return resolved
+}
+
+// resolveFrame converts the commands of a task in the relative frame to grid commands for the current heading
+// once and stores them, the task keeps the heading in RelativeTo. The extent of a command sequence checked at
+// submission does not depend on its orientation, so only the checks at dispatch see the difference.
+func (s *Service) resolveFrame(task RobotTask) RobotTask {
+	if task.Frame != FrameRelative || task.RelativeTo != nil {
+		return task
+	}
+	heading := s.GetRobotState().Heading
+
+	s.mu.Lock()
+	stored := s.state.Tasks[task.ID]
+	stored.Commands = task.Commands.relativeTo(heading)
+	stored.DeltaX, stored.DeltaY = 0, 0
+	for _, cmd := range stored.Commands {
+		dx, dy := cmd.Delta()
+		stored.DeltaX, stored.DeltaY = stored.DeltaX+dx, stored.DeltaY+dy
+	}
+	stored.RelativeTo = &heading
+	s.state.Tasks[task.ID] = stored
+	s.mu.Unlock()
+
+	log.Printf("Task %s commands %s resolved relative to heading %s: %s", task.ID, task.Commands, heading, stored.Commands)
+	return stored
+}
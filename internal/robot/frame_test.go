@@ -0,0 +1,79 @@
+package robot
+
+import (
+	"context"
+	"testing"
+)
+
+// TestCommandFrame tests both interpretations of the same commands from a non-North heading.
+func TestCommandFrame(t *testing.T) {
+	tests := []struct {
+		name         string
+		defaultFrame CommandFrame
+		frame        string
+		heading      Heading
+		wantCommands string
+		wantPosition Coord
+	}{
+		{"Absolute by default", "", "", HeadingEast, "N N E", Coord{X: 3, Y: 4}},
+		{"Absolute", FrameRelative, "absolute", HeadingEast, "N N E", Coord{X: 3, Y: 4}},
+		{"Relative heading east", "", "relative", HeadingEast, "E E S", Coord{X: 4, Y: 1}},
+		{"Relative heading south", "", "relative", HeadingSouth, "S S W", Coord{X: 1, Y: 0}},
+		{"Relative heading west", "", "relative", HeadingWest, "W W N", Coord{X: 0, Y: 3}},
+		{"Relative service default", FrameRelative, "", HeadingWest, "W W N", Coord{X: 0, Y: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Clock = newFakeClock()
+			config.CommandFrame = tt.defaultFrame
+			service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+			service.SetRobotState(RobotState{X: 2, Y: 2, Heading: tt.heading})
+
+			taskID, err := service.SubmitTask(TaskSpec{Commands: "N N E", DelayBetweenCommands: "1s", Frame: tt.frame})
+			if err != nil {
+				t.Fatalf("Failed to submit task: %v", err)
+			}
+			if err := service.ExecuteTask(taskID); err != nil {
+				t.Fatalf("Failed to execute task: %v", err)
+			}
+
+			task := service.CurrentState().Tasks[taskID]
+			if task.Commands.String() != tt.wantCommands {
+				t.Errorf("Expected commands %q, got %q", tt.wantCommands, task.Commands)
+			}
+			if relative := task.Frame == FrameRelative; relative != (task.RelativeTo != nil) || (relative && *task.RelativeTo != tt.heading) {
+				t.Errorf("Expected a %s task resolved relative to %s, got %v", task.Frame, tt.heading, task.RelativeTo)
+			}
+			if robotState := service.GetRobotState(); (Coord{X: int(robotState.X), Y: int(robotState.Y)}) != tt.wantPosition || robotState.Heading != tt.heading {
+				t.Errorf("Expected the robot at %s facing %s, got %+v", tt.wantPosition, tt.heading, robotState)
+			}
+		})
+	}
+}
+
+// TestCommandFrame_Invalid tests that the relative frame is checked against the bounds at dispatch
+// and that unknown frames are rejected.
+func TestCommandFrame_Invalid(t *testing.T) {
+	config := DefaultConfig()
+	config.Clock = newFakeClock()
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	// Forward for a robot heading west at the origin leaves the warehouse, although N is valid on the grid
+	service.SetRobotState(RobotState{X: 0, Y: 0, Heading: HeadingWest})
+	taskID, _ := service.SubmitTask(TaskSpec{Commands: "N", Frame: "relative"})
+	if err := service.ExecuteTask(taskID); err == nil {
+		t.Error("Expected the relative task to be aborted")
+	}
+	if task := service.CurrentState().Tasks[taskID]; task.State != Aborted || task.Reason != ReasonOutOfBounds {
+		t.Errorf("Expected the task aborted as out of bounds, got %s (%s)", task.State, task.Reason)
+	}
+
+	if _, err := service.SubmitTask(TaskSpec{Commands: "N", Frame: "polar"}); err == nil {
+		t.Error("Expected a task with an unknown frame to be rejected")
+	}
+	config.CommandFrame = "polar"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected an unknown default frame to be rejected")
+	}
+}
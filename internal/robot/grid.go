@@ -0,0 +1,86 @@
+package robot
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// gridCellPixels is the edge length of a cell in the SVG rendering.
+const gridCellPixels = 40
+
+// Grid is a snapshot of the warehouse floor with the robot and the obstacles, for rendering.
+// @Description Warehouse floor with the robot position and the obstacles
+type Grid struct {
+	Size      int     `json:"size" example:"10"` // Number of cells per row and column
+	Robot     Coord   `json:"robot"`             // Cell of the robot
+	Obstacles []Coord `json:"obstacles"`         // Obstacle cells ordered by row, then column
+}
+
+// Grid returns the current warehouse floor.
+func (s *Service) Grid() Grid {
+	robotState := s.GetRobotState()
+	obstacles := make([]Coord, 0, len(s.obstacles))
+	for cell := range s.obstacles {
+		obstacles = append(obstacles, cell)
+	}
+	sort.Slice(obstacles, func(i, j int) bool {
+		if obstacles[i].Y != obstacles[j].Y {
+			return obstacles[i].Y < obstacles[j].Y
+		}
+		return obstacles[i].X < obstacles[j].X
+	})
+	return Grid{
+		Size:      warehouseSize,
+		Robot:     Coord{X: int(robotState.X), Y: int(robotState.Y)},
+		Obstacles: obstacles,
+	}
+}
+
+// RenderASCII renders the grid as text with north at the top, one line per row:
+// 'R' marks the robot, '#' an obstacle and '.' a free cell.
+func (g Grid) RenderASCII() []byte {
+	obstacles := newObstacleSet(g.Obstacles)
+	var buf bytes.Buffer
+	for y := g.Size - 1; y >= 0; y-- {
+		for x := 0; x < g.Size; x++ {
+			cell := Coord{X: x, Y: y}
+			_, obstacle := obstacles[cell]
+			switch {
+			case cell == g.Robot:
+				buf.WriteByte('R')
+			case obstacle:
+				buf.WriteByte('#')
+			default:
+				buf.WriteByte('.')
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// RenderSVG renders the grid as an SVG image with north at the top. Obstacles are filled squares and the robot
+// is a circle, both carry their cell as data-x and data-y attributes.
+func (g Grid) RenderSVG() []byte {
+	size := g.Size * gridCellPixels
+	// top converts a row to the y pixel of its top edge, as SVG y grows downwards
+	top := func(y int) int { return (g.Size - 1 - y) * gridCellPixels }
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", size, size, size, size)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="white" stroke="black"/>`+"\n", size, size)
+	for i := 1; i < g.Size; i++ {
+		offset := i * gridCellPixels
+		fmt.Fprintf(&buf, `<line x1="%d" y1="0" x2="%d" y2="%d" stroke="lightgray"/>`+"\n", offset, offset, size)
+		fmt.Fprintf(&buf, `<line x1="0" y1="%d" x2="%d" y2="%d" stroke="lightgray"/>`+"\n", offset, size, offset)
+	}
+	for _, obstacle := range g.Obstacles {
+		fmt.Fprintf(&buf, `<rect class="obstacle" data-x="%d" data-y="%d" x="%d" y="%d" width="%d" height="%d" fill="dimgray"/>`+"\n",
+			obstacle.X, obstacle.Y, obstacle.X*gridCellPixels, top(obstacle.Y), gridCellPixels, gridCellPixels)
+	}
+	fmt.Fprintf(&buf, `<circle class="robot" data-x="%d" data-y="%d" cx="%d" cy="%d" r="%d" fill="orangered"/>`+"\n",
+		g.Robot.X, g.Robot.Y, g.Robot.X*gridCellPixels+gridCellPixels/2, top(g.Robot.Y)+gridCellPixels/2, gridCellPixels*2/5)
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,49 @@
+package robot
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestGrid tests the grid snapshot and its renderings with the robot and an obstacle.
+func TestGrid(t *testing.T) {
+	config := DefaultConfig()
+	config.Obstacles = []Coord{{X: 5, Y: 0}, {X: 1, Y: 3}}
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	service.SetPosition(2, 1)
+
+	grid := service.Grid()
+	if grid.Size != 10 || grid.Robot != (Coord{X: 2, Y: 1}) {
+		t.Fatalf("Expected a 10x10 grid with the robot at (2, 1), got %+v", grid)
+	}
+	if len(grid.Obstacles) != 2 || grid.Obstacles[0] != (Coord{X: 5, Y: 0}) {
+		t.Errorf("Expected the obstacles ordered by row, got %v", grid.Obstacles)
+	}
+
+	rows := strings.Split(strings.TrimSuffix(string(grid.RenderASCII()), "\n"), "\n")
+	if len(rows) != 10 {
+		t.Fatalf("Expected 10 rows, got %d", len(rows))
+	}
+	// North is at the top, so row y is line 9-y
+	for _, want := range []struct {
+		y   int
+		row string
+	}{{3, ".#........"}, {1, "..R......."}, {0, ".....#...."}} {
+		if got := rows[9-want.y]; got != want.row {
+			t.Errorf("Expected row %d to be %q, got %q", want.y, want.row, got)
+		}
+	}
+
+	svg := string(grid.RenderSVG())
+	if !strings.HasPrefix(svg, "<svg ") {
+		t.Errorf("Expected an SVG document, got %q", svg)
+	}
+	// The robot circle is centered in its cell, 40 pixels wide, with y growing downwards
+	if !strings.Contains(svg, `<circle class="robot" data-x="2" data-y="1" cx="100" cy="340"`) {
+		t.Errorf("Expected the robot marker at (2, 1), got %s", svg)
+	}
+	if !strings.Contains(svg, `<rect class="obstacle" data-x="1" data-y="3" x="40" y="240"`) {
+		t.Errorf("Expected the obstacle at (1, 3), got %s", svg)
+	}
+}
@@ -0,0 +1,79 @@
+package robot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// GroupSummary reports the states of the tasks in a task group.
+// @Description States of the tasks in a task group
+type GroupSummary struct {
+	GroupID string         `json:"group_id" example:"batch-42"` // ID of the group
+	Total   int            `json:"total" example:"3"`           // Number of tasks in the group
+	States  map[string]int `json:"states"`                      // Number of tasks per state, e.g. {"Completed": 2, "Pending": 1}
+	Done    bool           `json:"done" example:"false"`        // Whether no task of the group is pending or running anymore
+	Tasks   []RobotTask    `json:"tasks"`                       // Tasks of the group ordered by sequence number
+}
+
+// Group returns the summary of the task group, or ErrGroupNotFound if no task belongs to it.
+func (s *Service) Group(groupID string) (GroupSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.groupLocked(groupID)
+}
+
+// CancelGroup cancels all cancellable tasks of the group, like CancelTask does for a single task.
+// Tasks that already finished or were already canceled are left alone.
+// It returns the number of tasks that were canceled or whose cancellation was requested.
+func (s *Service) CancelGroup(groupID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, err := s.groupLocked(groupID)
+	if err != nil {
+		return 0, err
+	}
+
+	canceled := 0
+	for _, task := range group.Tasks {
+		err := s.cancelLocked(task, ReasonUserCancel)
+		if err == nil {
+			canceled++
+		} else if !errors.Is(err, ErrAlreadyCanceled) && !errors.Is(err, ErrInvalidState) {
+			return canceled, err
+		}
+	}
+	log.Printf("Group %s: cancelled %d of %d tasks", groupID, canceled, group.Total)
+	return canceled, nil
+}
+
+// groupLocked collects the tasks of the group. The caller must hold the service lock.
+func (s *Service) groupLocked(groupID string) (GroupSummary, error) {
+	group := GroupSummary{GroupID: groupID, States: make(map[string]int), Done: true}
+	if groupID == "" {
+		return group, fmt.Errorf("%w: empty group ID", ErrGroupNotFound)
+	}
+
+	for _, task := range s.state.Tasks {
+		if task.GroupID != groupID {
+			continue
+		}
+		group.Tasks = append(group.Tasks, task)
+		group.States[task.State.String()]++
+		if task.State == Pending || task.State == InProgress || task.State == RequestCancellation || task.State == CancelPending {
+			group.Done = false
+		}
+	}
+	if len(group.Tasks) == 0 {
+		return group, fmt.Errorf("%w: %s", ErrGroupNotFound, groupID)
+	}
+
+	sort.Slice(group.Tasks, func(i, j int) bool {
+		return group.Tasks[i].SequenceNum < group.Tasks[j].SequenceNum
+	})
+	group.Total = len(group.Tasks)
+	return group, nil
+}
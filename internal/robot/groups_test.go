@@ -0,0 +1,78 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestCancelGroup tests that cancelling a group changes only the cancellable tasks of that group.
+func TestCancelGroup(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+
+	first, err := service.SubmitTask(TaskSpec{Commands: "N", NewGroup: true})
+	if err != nil {
+		t.Fatalf("Failed to submit task: %v", err)
+	}
+	group := service.CurrentState().Tasks[first].GroupID
+	if group != first {
+		t.Fatalf("Expected new group to be named after task %s, got %q", first, group)
+	}
+	running, _ := service.SubmitTask(TaskSpec{Commands: "E", GroupID: group})
+	done, _ := service.SubmitTask(TaskSpec{Commands: "S", GroupID: group})
+	other, _ := service.SubmitTask(TaskSpec{Commands: "W", GroupID: "other"})
+	loose, _ := service.EnqueueTask("N", "0s")
+	service.UpdateTaskState(running, InProgress, ReasonDispatched)
+	service.UpdateTaskState(done, Completed, ReasonCompleted)
+
+	canceled, err := service.CancelGroup(group)
+	if err != nil {
+		t.Fatalf("Failed to cancel group: %v", err)
+	}
+	if canceled != 2 {
+		t.Errorf("Expected 2 cancelled tasks, got %d", canceled)
+	}
+
+	want := map[string]TaskState{
+		first:   Canceled,
+		running: RequestCancellation,
+		done:    Completed,
+		other:   Pending,
+		loose:   Pending,
+	}
+	tasks := service.CurrentState().Tasks
+	for taskID, state := range want {
+		if tasks[taskID].State != state {
+			t.Errorf("Expected task %s to be '%s', got '%s'", taskID, state, tasks[taskID].State)
+		}
+	}
+
+	summary, err := service.Group(group)
+	if err != nil {
+		t.Fatalf("Failed to get group: %v", err)
+	}
+	if summary.Total != 3 || summary.States["Canceled"] != 1 || summary.States["RequestCancellation"] != 1 || summary.States["Completed"] != 1 {
+		t.Errorf("Unexpected group summary %+v", summary)
+	}
+	if summary.Done {
+		t.Error("Expected group with a running task not to be done")
+	}
+	if summary.Tasks[0].ID != first || summary.Tasks[2].ID != done {
+		t.Error("Expected group tasks ordered by sequence number")
+	}
+}
+
+// TestGroupErrors tests unknown groups and conflicting group options.
+func TestGroupErrors(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+
+	if _, err := service.Group("missing"); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound, got %v", err)
+	}
+	if _, err := service.CancelGroup("missing"); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound, got %v", err)
+	}
+	if _, err := service.SubmitTask(TaskSpec{Commands: "N", GroupID: "a", NewGroup: true}); err == nil {
+		t.Error("Expected error for a task joining a group and starting a new one")
+	}
+}
@@ -0,0 +1,72 @@
+package robot
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Heading represents the direction the robot is facing.
+// @Description Direction the robot is facing
+// @Enum N E S W
+type Heading int
+
+const (
+	HeadingNorth Heading = iota
+	HeadingEast
+	HeadingSouth
+	HeadingWest
+)
+
+func (h Heading) String() string {
+	switch h {
+	case HeadingNorth:
+		return "N"
+	case HeadingEast:
+		return "E"
+	case HeadingSouth:
+		return "S"
+	case HeadingWest:
+		return "W"
+	default:
+		return fmt.Sprintf("Unknown Heading %d", h)
+	}
+}
+
+// Valid reports whether h is one of the four supported headings.
+func (h Heading) Valid() bool {
+	return h >= HeadingNorth && h <= HeadingWest
+}
+
+// ParseHeading parses a heading given as "N", "E", "S", "W" or its full name, case insensitive.
+func ParseHeading(raw string) (Heading, error) {
+	switch strings.ToUpper(strings.TrimSpace(raw)) {
+	case "N", "NORTH":
+		return HeadingNorth, nil
+	case "E", "EAST":
+		return HeadingEast, nil
+	case "S", "SOUTH":
+		return HeadingSouth, nil
+	case "W", "WEST":
+		return HeadingWest, nil
+	default:
+		return HeadingNorth, fmt.Errorf("invalid heading: %s", raw)
+	}
+}
+
+func (h Heading) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+func (h *Heading) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	heading, err := ParseHeading(raw)
+	if err != nil {
+		return err
+	}
+	*h = heading
+	return nil
+}
@@ -0,0 +1,72 @@
+package robot
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseHeading(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Heading
+		wantErr bool
+	}{
+		{"N", HeadingNorth, false},
+		{"east", HeadingEast, false},
+		{" S ", HeadingSouth, false},
+		{"West", HeadingWest, false},
+		{"X", HeadingNorth, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseHeading(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHeading() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseHeading() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestInitialHeading tests that a custom initial heading is applied at construction and restored by Reset.
+func TestInitialHeading(t *testing.T) {
+	config := DefaultConfig()
+	config.InitialHeading = HeadingWest
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	if heading := service.CurrentState().RobotState.Heading; heading != HeadingWest {
+		t.Fatalf("Expected initial heading W, got %s", heading)
+	}
+
+	service.SetRobotState(RobotState{X: 4, Y: 7, Heading: HeadingSouth})
+	robotState, err := service.Reset()
+	if err != nil {
+		t.Fatalf("Unexpected reset error: %v", err)
+	}
+	if robotState != (RobotState{X: 0, Y: 0, Heading: HeadingWest}) {
+		t.Errorf("Expected robot at origin facing W after reset, got %+v", robotState)
+	}
+
+	// Reset is rejected while a task is running
+	taskID, err := service.EnqueueTask("N", "10ms")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+	service.UpdateTaskState(taskID, InProgress, ReasonDispatched)
+	if _, err := service.Reset(); err == nil {
+		t.Error("Expected reset to fail while a task is in progress")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := DefaultConfig()
+	if err := config.Validate(); err != nil {
+		t.Errorf("Default config must be valid, got %v", err)
+	}
+	config.InitialHeading = Heading(42)
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for invalid initial heading")
+	}
+}
@@ -0,0 +1,73 @@
+package robot
+
+import (
+	"sync"
+	"time"
+)
+
+// dwellTracker accumulates how long the robot stayed in every cell. The time in a cell is booked when the robot
+// leaves it, the time in the current cell is added on demand.
+type dwellTracker struct {
+	mu     sync.Mutex
+	cell   Coord                   // Cell the robot is in
+	since  time.Time               // Time at which the robot entered the cell
+	totals map[Coord]time.Duration // Time spent in every cell left so far
+}
+
+func newDwellTracker(cell Coord, now time.Time) *dwellTracker {
+	return &dwellTracker{cell: cell, since: now, totals: make(map[Coord]time.Duration)}
+}
+
+// enter records that the robot is in the cell at the given time, booking the time spent in the cell it left.
+func (d *dwellTracker) enter(cell Coord, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if cell == d.cell {
+		return
+	}
+	d.totals[d.cell] += now.Sub(d.since)
+	d.cell, d.since = cell, now
+}
+
+// totalsAt returns the time spent in every cell up to the given time, including the current cell.
+func (d *dwellTracker) totalsAt(now time.Time) map[Coord]time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	totals := make(map[Coord]time.Duration, len(d.totals)+1)
+	for cell, total := range d.totals {
+		totals[cell] = total
+	}
+	totals[d.cell] += now.Sub(d.since)
+	return totals
+}
+
+// Heatmap reports how long the robot spent in every cell of the warehouse since the service started.
+// @Description Cumulative time the robot spent in every cell of the warehouse
+type Heatmap struct {
+	Size         int         `json:"size" example:"10"`            // Number of cells per row and column
+	DwellSeconds [][]float64 `json:"dwell_seconds"`                // Seconds spent per cell, indexed [y][x] with row 0 the southernmost
+	TotalSeconds float64     `json:"total_seconds" example:"42.5"` // Sum of all cells
+}
+
+// Heatmap returns the time the robot spent in every warehouse cell, measured with the service clock.
+// Time spent outside the warehouse, e.g. after a manual override, is not included.
+func (s *Service) Heatmap() Heatmap {
+	heatmap := Heatmap{Size: warehouseSize, DwellSeconds: make([][]float64, warehouseSize)}
+	for y := range heatmap.DwellSeconds {
+		heatmap.DwellSeconds[y] = make([]float64, warehouseSize)
+	}
+	for cell, total := range s.dwell.totalsAt(s.config.Clock.Now()) {
+		if cell.inWarehouse() {
+			heatmap.DwellSeconds[cell.Y][cell.X] += total.Seconds()
+			heatmap.TotalSeconds += total.Seconds()
+		}
+	}
+	return heatmap
+}
+
+// trackDwellLocked books the dwell time when the robot changes cells. The caller must hold the service lock,
+// so concurrent position updates are booked in order.
+func (s *Service) trackDwellLocked() {
+	cell := Coord{X: int(s.state.RobotState.X), Y: int(s.state.RobotState.Y)}
+	s.dwell.enter(cell, s.config.Clock.Now())
+}
@@ -0,0 +1,40 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestHeatmap tests that the dwell times of a task sum up to its execution time and are booked per cell.
+func TestHeatmap(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	start := clock.Now()
+	taskID, _ := service.EnqueueTask("N N E", "1s")
+	if err := service.ExecuteTask(taskID); err != nil {
+		t.Fatalf("Failed to execute task: %v", err)
+	}
+
+	heatmap := service.Heatmap()
+	if elapsed := clock.Now().Sub(start).Seconds(); heatmap.TotalSeconds != elapsed || elapsed != 3 {
+		t.Errorf("Expected the dwell times to sum up to the execution time of 3s, got %g for %gs", heatmap.TotalSeconds, elapsed)
+	}
+	for _, want := range []struct {
+		cell    Coord
+		seconds float64
+	}{{Coord{X: 0, Y: 0}, 1}, {Coord{X: 0, Y: 1}, 1}, {Coord{X: 0, Y: 2}, 1}, {Coord{X: 1, Y: 2}, 0}} {
+		if got := heatmap.DwellSeconds[want.cell.Y][want.cell.X]; got != want.seconds {
+			t.Errorf("Expected %gs in %s, got %g", want.seconds, want.cell, got)
+		}
+	}
+
+	// The current cell accumulates time while the robot stays there
+	clock.Advance(2 * time.Second)
+	if got := service.Heatmap().DwellSeconds[2][1]; got != 2 {
+		t.Errorf("Expected 2s in the current cell, got %g", got)
+	}
+}
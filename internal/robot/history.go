@@ -0,0 +1,72 @@
+package robot
+
+import (
+	"log"
+	"sort"
+)
+
+const (
+	DefaultTaskPageLimit = 50  // Number of tasks per page when no limit is given
+	MaxTaskPageLimit     = 500 // Upper bound for the number of tasks per page
+)
+
+// TaskPage is a page of tasks ordered by sequence number.
+// @Description Page of tasks ordered by sequence number
+type TaskPage struct {
+	Tasks      []RobotTask `json:"tasks"`                              // Tasks on this page, oldest first
+	Total      int         `json:"total" example:"120"`                // Total number of tasks known to the service
+	NextCursor int         `json:"next_cursor,omitempty" example:"50"` // Cursor for the next page, omitted on the last page
+}
+
+// ListTasks returns up to limit tasks with a sequence number greater than cursor, oldest first.
+// A cursor of 0 starts at the first task. A limit <= 0 uses DefaultTaskPageLimit and limits above
+// MaxTaskPageLimit are capped. Paging by sequence number keeps pages stable while new tasks are added.
+func (s *Service) ListTasks(cursor, limit int) TaskPage {
+	if limit <= 0 {
+		limit = DefaultTaskPageLimit
+	}
+	if limit > MaxTaskPageLimit {
+		limit = MaxTaskPageLimit
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := make([]RobotTask, 0, len(s.state.Tasks))
+	for _, task := range s.state.Tasks {
+		if task.SequenceNum > cursor {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].SequenceNum < tasks[j].SequenceNum
+	})
+
+	page := TaskPage{Total: len(s.state.Tasks)}
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		page.NextCursor = tasks[limit-1].SequenceNum
+	}
+	page.Tasks = tasks
+	return page
+}
+
+// PurgeTasks removes all finished tasks, i.e. Completed, Canceled and Aborted ones, and returns how many were removed.
+// Pending and running tasks are kept. Sequence numbers are not reused.
+func (s *Service) PurgeTasks() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for taskID, task := range s.state.Tasks {
+		if isFinished(task.State) {
+			delete(s.state.Tasks, taskID)
+			if s.webhook != nil {
+				s.webhook.forget(taskID)
+			}
+			purged++
+		}
+	}
+	log.Printf("Purged %d finished tasks, %d tasks left", purged, len(s.state.Tasks))
+	return purged
+}
@@ -0,0 +1,153 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestListTasksPaging tests that paging through all tasks returns each task exactly once in sequence order.
+func TestListTasksPaging(t *testing.T) {
+	const taskCount = 237
+
+	service := NewService(context.Background(), make(chan string, taskCount))
+	for i := 0; i < taskCount; i++ {
+		if _, err := service.EnqueueTask("N S", "0s"); err != nil {
+			t.Fatalf("Failed to enqueue task: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		limit     int
+		wantPages int
+	}{
+		{"Default limit", 0, 5},
+		{"Small pages", 10, 24},
+		{"Exact multiple", 79, 3},
+		{"Limit capped", 10000, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := make(map[string]bool)
+			cursor, pages, lastSeq := 0, 0, 0
+			for {
+				page := service.ListTasks(cursor, tt.limit)
+				pages++
+				if page.Total != taskCount {
+					t.Fatalf("Expected total %d, got %d", taskCount, page.Total)
+				}
+				for _, task := range page.Tasks {
+					if seen[task.ID] {
+						t.Fatalf("Task %s returned twice", task.ID)
+					}
+					if task.SequenceNum != lastSeq+1 {
+						t.Fatalf("Gap in sequence: expected %d, got %d", lastSeq+1, task.SequenceNum)
+					}
+					seen[task.ID] = true
+					lastSeq = task.SequenceNum
+				}
+				if page.NextCursor == 0 {
+					break
+				}
+				cursor = page.NextCursor
+			}
+
+			if len(seen) != taskCount {
+				t.Errorf("Expected %d tasks across all pages, got %d", taskCount, len(seen))
+			}
+			if pages != tt.wantPages {
+				t.Errorf("Expected %d pages, got %d", tt.wantPages, pages)
+			}
+		})
+	}
+}
+
+// TestListTasksStableWhileAdding tests that tasks added during paging do not shift already returned pages.
+func TestListTasksStableWhileAdding(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	for i := 0; i < 4; i++ {
+		service.EnqueueTask("N", "0s")
+	}
+
+	first := service.ListTasks(0, 2)
+	service.EnqueueTask("E", "0s")
+	second := service.ListTasks(first.NextCursor, 2)
+
+	if second.Tasks[0].SequenceNum != 3 || second.Tasks[1].SequenceNum != 4 {
+		t.Errorf("Expected sequence numbers 3 and 4, got %d and %d", second.Tasks[0].SequenceNum, second.Tasks[1].SequenceNum)
+	}
+	if second.NextCursor != 4 {
+		t.Errorf("Expected next cursor 4, got %d", second.NextCursor)
+	}
+	if second.Total != 5 {
+		t.Errorf("Expected total 5, got %d", second.Total)
+	}
+}
+
+// TestTaskLimit tests that the task limit rejects new tasks until finished tasks are purged.
+func TestTaskLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxTasks = 2
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	done, _ := service.EnqueueTask("N", "0s")
+	running, _ := service.EnqueueTask("E", "0s")
+	service.UpdateTaskState(done, Completed, ReasonCompleted)
+	service.UpdateTaskState(running, InProgress, ReasonDispatched)
+
+	if _, err := service.EnqueueTask("S", "0s"); !errors.Is(err, ErrTaskLimit) {
+		t.Fatalf("Expected ErrTaskLimit, got %v", err)
+	}
+
+	if purged := service.PurgeTasks(); purged != 1 {
+		t.Errorf("Expected 1 purged task, got %d", purged)
+	}
+	tasks := service.CurrentState().Tasks
+	if _, exists := tasks[running]; !exists || len(tasks) != 1 {
+		t.Errorf("Expected only the running task to be kept, got %v", tasks)
+	}
+
+	taskID, err := service.EnqueueTask("S", "0s")
+	if err != nil {
+		t.Fatalf("Expected task to be accepted after the purge, got %v", err)
+	}
+	if seq := service.CurrentState().Tasks[taskID].SequenceNum; seq != 3 {
+		t.Errorf("Expected sequence number 3, got %d", seq)
+	}
+	if _, err := service.EnqueueTask("W", "0s"); !errors.Is(err, ErrTaskLimit) {
+		t.Errorf("Expected ErrTaskLimit once the limit is reached again, got %v", err)
+	}
+}
+
+// TestPendingLimit tests that the pending task limit rejects new tasks while the queue still has room.
+func TestPendingLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxPendingTasks = 2
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	first, _ := service.EnqueueTask("N", "0s")
+	second, err := service.EnqueueTask("E", "0s")
+	if err != nil {
+		t.Fatalf("Expected the tasks up to the limit to be accepted, got %v", err)
+	}
+	if _, err := service.EnqueueTask("S", "0s"); !errors.Is(err, ErrPendingLimit) {
+		t.Fatalf("Expected ErrPendingLimit, got %v", err)
+	}
+	if _, err := service.SubmitBatch([]TaskSpec{{Commands: "S"}}); !errors.Is(err, ErrPendingLimit) {
+		t.Errorf("Expected ErrPendingLimit for a batch, got %v", err)
+	}
+	if _, err := service.ReplaceTask(second, TaskSpec{Commands: "W"}); err != nil {
+		t.Errorf("Expected replacing a pending task to be accepted at the limit, got %v", err)
+	}
+
+	// A running task no longer counts as pending
+	service.UpdateTaskState(first, InProgress, ReasonDispatched)
+	if _, err := service.EnqueueTask("S", "0s"); err != nil {
+		t.Errorf("Expected a task to be accepted once a pending task runs, got %v", err)
+	}
+	if _, err := service.EnqueueTask("W", "0s"); !errors.Is(err, ErrPendingLimit) {
+		t.Errorf("Expected ErrPendingLimit once the limit is reached again, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package robot
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator generates unique task IDs.
+type IDGenerator interface {
+	NewID() string
+}
+
+// UUIDGenerator generates random UUID based task IDs, it is the default ID generator.
+type UUIDGenerator struct{}
+
+func (UUIDGenerator) NewID() string {
+	return uuid.New().String()
+}
+
+// SequentialIDGenerator generates predictable task IDs (task-1, task-2, ...), useful for tests.
+type SequentialIDGenerator struct {
+	counter atomic.Uint64
+}
+
+// NewSequentialIDGenerator returns a generator producing task-1, task-2, ...
+func NewSequentialIDGenerator() *SequentialIDGenerator {
+	return &SequentialIDGenerator{}
+}
+
+func (g *SequentialIDGenerator) NewID() string {
+	return fmt.Sprintf("task-%d", g.counter.Add(1))
+}
+
+// PrefixedIDGenerator prepends a fixed prefix to the IDs of another generator, e.g. "whA-" to make IDs
+// of several services feeding a shared store self-describing and collision free.
+type PrefixedIDGenerator struct {
+	Prefix    string
+	Generator IDGenerator
+}
+
+func (g PrefixedIDGenerator) NewID() string {
+	return g.Prefix + g.Generator.NewID()
+}
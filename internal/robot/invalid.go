@@ -0,0 +1,54 @@
+package robot
+
+import (
+	"fmt"
+	"log"
+)
+
+// InvalidTaskPolicy decides what happens to a task that is valid when it is submitted but no longer when it is
+// dispatched, because the tasks before it moved the robot to a cell from which its commands would leave the
+// warehouse or enter an obstacle.
+type InvalidTaskPolicy string
+
+// Policies for tasks invalid at dispatch
+const (
+	InvalidAbort  InvalidTaskPolicy = "abort"  // Abort the task, the default
+	InvalidSkip   InvalidTaskPolicy = "skip"   // Cancel the task with reason skipped_invalid, so it does not count as a failure
+	InvalidReplan InvalidTaskPolicy = "replan" // Move the robot by the displacement of the commands along the shortest valid path
+)
+
+// ParseInvalidTaskPolicy parses a policy name, an empty name is the default InvalidAbort.
+func ParseInvalidTaskPolicy(raw string) (InvalidTaskPolicy, error) {
+	switch policy := InvalidTaskPolicy(raw); policy {
+	case "":
+		return InvalidAbort, nil
+	case InvalidAbort, InvalidSkip, InvalidReplan:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid policy for invalid tasks: %s, expected abort, skip or replan", raw)
+	}
+}
+
+// replan returns the shortest valid commands moving the robot by the displacement of the task's commands
+// from its current position, and stores them as the new commands of the task.
+func (s *Service) replan(task RobotTask) (RobotCommands, error) {
+	robotState := s.GetRobotState()
+	target := Coord{
+		X: int(robotState.X) + task.DeltaX*s.config.StepSize,
+		Y: int(robotState.Y) + task.DeltaY*s.config.StepSize,
+	}
+	path, err := s.PathTo(target)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	stored := s.state.Tasks[task.ID]
+	stored.Commands = path
+	stored.Replanned = true
+	s.state.Tasks[task.ID] = stored
+	s.mu.Unlock()
+
+	log.Printf("Task %s re-planned to reach %s: %s", task.ID, target, RobotCommands(path))
+	return path, nil
+}
@@ -0,0 +1,85 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestInvalidTaskPolicy tests what happens to a task made invalid by the task before it, for every policy.
+func TestInvalidTaskPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		onInvalid    string
+		wantState    TaskState
+		wantReason   TransitionReason
+		wantCommands string
+		wantPosition Coord
+	}{
+		{"Default aborts", "", Aborted, ReasonObstacle, "N N E", Coord{X: 1, Y: 0}},
+		{"Abort", "abort", Aborted, ReasonObstacle, "N N E", Coord{X: 1, Y: 0}},
+		{"Skip", "skip", Canceled, ReasonSkippedInvalid, "N N E", Coord{X: 1, Y: 0}},
+		{"Replan", "replan", Completed, ReasonCompleted, "E N N", Coord{X: 2, Y: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Clock = newFakeClock()
+			config.Obstacles = []Coord{{X: 1, Y: 1}}
+			service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+			// "N N E" is valid from the origin, but runs into the obstacle once the first task moved the robot east
+			first, _ := service.EnqueueTask("E", "1s")
+			second, err := service.SubmitTask(TaskSpec{Commands: "N N E", DelayBetweenCommands: "1s", OnInvalid: tt.onInvalid})
+			if err != nil {
+				t.Fatalf("Failed to submit task: %v", err)
+			}
+			if err := service.ExecuteTask(first); err != nil {
+				t.Fatalf("Failed to execute the first task: %v", err)
+			}
+			err = service.ExecuteTask(second)
+			if (err != nil) != (tt.wantState == Aborted) {
+				t.Errorf("Unexpected error executing the second task: %v", err)
+			}
+
+			task := service.CurrentState().Tasks[second]
+			if task.State != tt.wantState || task.Reason != tt.wantReason {
+				t.Errorf("Expected %s (%s), got %s (%s)", tt.wantState, tt.wantReason, task.State, task.Reason)
+			}
+			if task.Commands.String() != tt.wantCommands || task.Replanned != (tt.onInvalid == "replan") {
+				t.Errorf("Expected commands %q, got %q (replanned %t)", tt.wantCommands, task.Commands, task.Replanned)
+			}
+			if robotState := service.GetRobotState(); (Coord{X: int(robotState.X), Y: int(robotState.Y)}) != tt.wantPosition {
+				t.Errorf("Expected the robot at %s, got (%d, %d)", tt.wantPosition, robotState.X, robotState.Y)
+			}
+		})
+	}
+}
+
+// TestInvalidTaskPolicy_ReplanImpossible tests that a task is aborted if the displacement cannot be re-planned.
+func TestInvalidTaskPolicy_ReplanImpossible(t *testing.T) {
+	config := DefaultConfig()
+	config.Clock = newFakeClock()
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	service.SetRobotState(RobotState{X: 9, Y: 0, Heading: HeadingNorth})
+	taskID, _ := service.SubmitTask(TaskSpec{Commands: "E", OnInvalid: "replan"})
+	if err := service.ExecuteTask(taskID); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("Expected ErrOutOfBounds, got %v", err)
+	}
+	if task := service.CurrentState().Tasks[taskID]; task.State != Aborted || task.Reason != ReasonOutOfBounds || task.Replanned {
+		t.Errorf("Expected the task aborted as out of bounds without re-planning, got %+v", task)
+	}
+}
+
+func TestParseInvalidTaskPolicy(t *testing.T) {
+	if policy, err := ParseInvalidTaskPolicy(""); err != nil || policy != InvalidAbort {
+		t.Errorf("Expected the default policy abort, got %q (%v)", policy, err)
+	}
+	if _, err := ParseInvalidTaskPolicy("retry"); err == nil {
+		t.Error("Expected an unknown policy to fail")
+	}
+	if _, err := NewService(context.Background(), make(chan string, 10)).SubmitTask(TaskSpec{Commands: "N", OnInvalid: "retry"}); err == nil {
+		t.Error("Expected a task with an unknown policy to be rejected")
+	}
+}
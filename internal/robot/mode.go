@@ -0,0 +1,83 @@
+package robot
+
+import (
+	"fmt"
+	"log"
+)
+
+// ServiceMode describes whether the service accepts new tasks.
+type ServiceMode string
+
+const (
+	ModeRunning   ServiceMode = "Running"   // Accepting and processing tasks
+	ModeQuiescing ServiceMode = "Quiescing" // Rejecting new tasks, still processing the queued ones
+	ModeQuiesced  ServiceMode = "Quiesced"  // Rejecting new tasks, the queue has been drained
+)
+
+// Quiesce stops accepting new tasks while the queued and running tasks are processed to completion.
+// Once the queue is drained the mode switches to Quiesced and the Drained channel is closed.
+// Calling Quiesce again has no effect.
+func (s *Service) Quiesce() {
+	s.mu.Lock()
+	if s.state.Mode == ModeRunning {
+		log.Println("Quiescing: new tasks are rejected, queued tasks are still processed")
+		s.state.Mode = ModeQuiescing
+	}
+	s.mu.Unlock()
+
+	s.checkDrained()
+}
+
+// Drained returns a channel that is closed once the service quiesced and finished all queued tasks,
+// e.g. to shut down the process afterwards.
+func (s *Service) Drained() <-chan struct{} {
+	return s.drained
+}
+
+// acceptingLocked returns an error if new tasks are rejected. The caller must hold the service lock.
+func (s *Service) acceptingLocked() error {
+	// The dispatcher has stopped with the context, a queued task would never run
+	if err := s.ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrShuttingDown, err)
+	}
+	if s.state.Mode != ModeRunning {
+		return ErrQuiescing
+	}
+	return s.offGridLocked()
+}
+
+// offGridLocked returns ErrRobotOffGrid if the off-grid guard is enabled and the robot is outside the warehouse,
+// e.g. because a manual override placed it there during recovery. The caller must hold the service lock.
+func (s *Service) offGridLocked() error {
+	cell := Coord{X: int(s.state.RobotState.X), Y: int(s.state.RobotState.Y)}
+	if s.config.RejectOffGrid && !cell.inWarehouse() {
+		return fmt.Errorf("%w: robot is at %s, move it back into the warehouse first", ErrRobotOffGrid, cell)
+	}
+	return nil
+}
+
+// checkOnGrid returns ErrRobotOffGrid if tasks must not run because the robot is outside the warehouse.
+func (s *Service) checkOnGrid() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.offGridLocked()
+}
+
+// checkDrained completes the quiesce once no task is pending or running anymore.
+func (s *Service) checkDrained() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state.Mode != ModeQuiescing {
+		return
+	}
+	for _, task := range s.state.Tasks {
+		if task.State == Pending || task.State == InProgress || task.State == RequestCancellation {
+			return
+		}
+	}
+
+	log.Println("Quiesced: all queued tasks are finished")
+	s.state.Mode = ModeQuiesced
+	close(s.drained)
+}
@@ -0,0 +1,117 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestQuiesce tests that a quiesced service rejects new tasks while the queued tasks still complete.
+func TestQuiesce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service, err := newEmbeddedService(ctx, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+
+	queued := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		taskID, err := service.EnqueueTask("N S", "5ms")
+		if err != nil {
+			t.Fatalf("Failed to enqueue task: %v", err)
+		}
+		queued = append(queued, taskID)
+	}
+
+	service.Quiesce()
+	if mode := service.CurrentState().Mode; mode != ModeQuiescing && mode != ModeQuiesced {
+		t.Errorf("Expected mode Quiescing, got %s", mode)
+	}
+	if _, err := service.EnqueueTask("E", "0s"); !errors.Is(err, ErrQuiescing) {
+		t.Errorf("Expected ErrQuiescing, got %v", err)
+	}
+
+	for _, taskID := range queued {
+		waitForState(t, sub, taskID, Completed)
+	}
+
+	select {
+	case <-service.Drained():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the drained signal after the queue finished")
+	}
+	if mode := service.CurrentState().Mode; mode != ModeQuiesced {
+		t.Errorf("Expected mode Quiesced, got %s", mode)
+	}
+	if count := len(service.CurrentState().Tasks); count != 3 {
+		t.Errorf("Expected only the 3 queued tasks, got %d", count)
+	}
+}
+
+// TestQuiesceIdle tests that quiescing an idle service drains right away.
+func TestQuiesceIdle(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 1))
+	if mode := service.CurrentState().Mode; mode != ModeRunning {
+		t.Fatalf("Expected initial mode Running, got %s", mode)
+	}
+
+	service.Quiesce()
+	service.Quiesce() // Idempotent
+
+	select {
+	case <-service.Drained():
+	default:
+		t.Fatal("Expected an idle service to be drained right away")
+	}
+}
+
+// TestRejectOffGrid tests that tasks are rejected and aborted while the robot is outside the warehouse,
+// and accepted again once it is back in a valid cell.
+func TestRejectOffGrid(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	queued, err := service.EnqueueTask("N", "0s")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+
+	// A manual override places the robot outside the warehouse
+	service.SetRobotState(RobotState{X: 12, Y: 3, Heading: HeadingNorth})
+	if _, err := service.EnqueueTask("W", "0s"); !errors.Is(err, ErrRobotOffGrid) {
+		t.Errorf("Expected ErrRobotOffGrid, got %v", err)
+	}
+	if _, err := service.SubmitBatch([]TaskSpec{{Commands: "W"}}); !errors.Is(err, ErrRobotOffGrid) {
+		t.Errorf("Expected ErrRobotOffGrid for a batch, got %v", err)
+	}
+
+	// A task queued before is aborted instead of run
+	if err := service.ExecuteTask(queued); !errors.Is(err, ErrRobotOffGrid) {
+		t.Errorf("Expected the queued task to fail with ErrRobotOffGrid, got %v", err)
+	}
+	if task := service.CurrentState().Tasks[queued]; task.State != Aborted || task.Reason != ReasonOffGrid {
+		t.Errorf("Expected the queued task aborted as off grid, got %s (%s)", task.State, task.Reason)
+	}
+
+	if _, err := service.Reset(); err != nil {
+		t.Fatalf("Failed to reset the robot: %v", err)
+	}
+	if _, err := service.EnqueueTask("N", "0s"); err != nil {
+		t.Errorf("Expected tasks to be accepted once the robot is back, got %v", err)
+	}
+}
+
+// TestRejectOffGrid_Disabled tests that the guard can be turned off.
+func TestRejectOffGrid_Disabled(t *testing.T) {
+	config := DefaultConfig()
+	config.RejectOffGrid = false
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	service.SetRobotState(RobotState{X: 12, Y: 3, Heading: HeadingNorth})
+	if _, err := service.EnqueueTask("W", "0s"); err != nil {
+		t.Errorf("Expected the task to be accepted with the guard disabled, got %v", err)
+	}
+}
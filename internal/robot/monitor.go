@@ -0,0 +1,157 @@
+package robot
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+const (
+	// stuckDetectionSlack is added to the expected run time so that tasks without delay are not flagged immediately
+	stuckDetectionSlack = time.Second
+
+	// stuckMonitorInterval is how often the stuck task monitor checks the running tasks
+	stuckMonitorInterval = time.Second
+)
+
+// ServiceStats reports health and diagnostic information about the robot service.
+// @Description Health and diagnostic information about the robot service
+type ServiceStats struct {
+	Stuck       bool   `json:"stuck" example:"false"`                 // True if a task runs much longer than expected
+	StuckTaskID string `json:"stuck_task_id,omitempty" example:"123"` // ID of the stuck task, if any
+	QueueLen    int    `json:"queue_len" example:"3"`                 // Number of tasks waiting in the queue
+	QueueCap    int    `json:"queue_cap" example:"100"`               // Capacity of the queue, queue_len reaching it means backpressure
+
+	CommandsPerSecond float64 `json:"commands_per_second" example:"0.8"` // Executed commands per second over the throughput window
+	TasksPerMinute    float64 `json:"tasks_per_minute" example:"12"`     // Completed tasks per minute over the throughput window
+
+	EventsDropped   uint64 `json:"events_dropped_total" example:"0"`   // Events not delivered to subscribers with a full buffer
+	WebhooksDropped uint64 `json:"webhooks_dropped_total" example:"0"` // Webhooks given up after all attempts failed or with a full queue
+	// True if the service is configured to treat dropped events as fatal and an event was dropped
+	Unhealthy bool `json:"unhealthy" example:"false"`
+
+	// IDs of the stored tasks forced to Canceled because their cancellation request went unnoticed, see Config.CancelGrace
+	ForcedCancellations []string `json:"forced_cancellations,omitempty"`
+}
+
+// Stats returns health and diagnostic information about the service.
+func (s *Service) Stats() ServiceStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.config.Clock.Now()
+	queue := s.QueueStats()
+	stats := ServiceStats{
+		QueueLen:          queue.Len,
+		QueueCap:          queue.Cap,
+		CommandsPerSecond: s.commandRate.rate(now, s.config.ThroughputWindow, time.Second),
+		TasksPerMinute:    s.taskRate.rate(now, s.config.ThroughputWindow, time.Minute),
+		EventsDropped:     s.eventsDropped.Load(),
+	}
+	if s.webhook != nil {
+		stats.WebhooksDropped = s.webhook.dropped.Load()
+	}
+	stats.Unhealthy = s.config.DroppedEventsUnhealthy && stats.EventsDropped > 0
+	if task, _, stuck := s.stuckTaskLocked(now); stuck {
+		stats.Stuck = true
+		stats.StuckTaskID = task.ID
+	}
+	for _, task := range s.state.Tasks {
+		if task.Reason == ReasonCancelTimeout {
+			stats.ForcedCancellations = append(stats.ForcedCancellations, task.ID)
+		}
+	}
+	sort.Strings(stats.ForcedCancellations)
+	return stats
+}
+
+// expectedRunTime returns how long the task may run before it is considered stuck.
+func (s *Service) expectedRunTime(task RobotTask) time.Duration {
+	return time.Duration(float64(s.taskDuration(task))*s.config.StuckSafetyFactor) + stuckDetectionSlack
+}
+
+// stuckTaskLocked returns the in-progress task that overran its expected run time and by how much.
+// The caller must hold the service lock.
+func (s *Service) stuckTaskLocked(now time.Time) (RobotTask, time.Duration, bool) {
+	for _, task := range s.state.Tasks {
+		// A follow task runs as long as its target keeps moving, it has no expected run time
+		if task.State != InProgress || task.StartedAt == nil || task.Follow {
+			continue
+		}
+		if overrun := now.Sub(*task.StartedAt) - s.expectedRunTime(task); overrun > 0 {
+			return task, overrun, true
+		}
+	}
+	return RobotTask{}, 0, false
+}
+
+// checkStuckTasks aborts a stuck task once it overran its expected run time by more than the configured grace period.
+func (s *Service) checkStuckTasks() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, overrun, stuck := s.stuckTaskLocked(s.config.Clock.Now())
+	if !stuck || overrun <= s.config.StuckAbortGrace {
+		return
+	}
+
+	log.Printf("Task %s is stuck, overran expected run time by %s, marking as Aborted", task.ID, overrun)
+	task.State = Aborted
+	task.Reason = ReasonTimeout
+	s.stampAborted(&task)
+	task.Error = fmt.Sprintf("Task exceeded its expected run time by %s", overrun)
+	s.state.Tasks[task.ID] = task
+
+	s.publishEventAsync(task.ID, task.State, task.Reason, task.Error)
+}
+
+// checkStuckCancellations forces tasks to Canceled whose cancellation was requested more than the configured grace
+// period ago, e.g. because the executor already left its command loop and never observes the request.
+func (s *Service) checkStuckCancellations() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.config.Clock.Now()
+	for _, task := range s.state.Tasks {
+		if task.State != RequestCancellation || task.CancelRequestedAt == nil {
+			continue
+		}
+		waited := now.Sub(*task.CancelRequestedAt)
+		if waited <= s.config.CancelGrace {
+			continue
+		}
+
+		log.Printf("Task %s is stuck in %s for %s, forcing it to Canceled", task.ID, RequestCancellation, waited)
+		task.State = Canceled
+		task.Reason = ReasonCancelTimeout
+		task.Error = fmt.Sprintf("Cancellation was not acted on within %s, forced to Canceled", s.config.CancelGrace)
+		s.state.Tasks[task.ID] = task
+
+		s.publishEventAsync(task.ID, task.State, task.Reason, task.Error)
+	}
+}
+
+// monitorStuckTasks periodically checks for stuck tasks and cancellations, and makes held cancels final once
+// their uncancel window passed, until the service context is cancelled.
+func (s *Service) monitorStuckTasks() {
+	ticker := time.NewTicker(stuckMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if s.config.StuckAbortGrace > 0 {
+				s.checkStuckTasks()
+			}
+			if s.config.CancelGrace > 0 {
+				s.checkStuckCancellations()
+			}
+			if s.config.UncancelWindow > 0 {
+				s.finishHeldCancels()
+			}
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestStuckTaskDetection tests that a task overrunning its expected run time is flagged and then aborted.
+func TestStuckTaskDetection(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	config.StuckAbortGrace = 5 * time.Second
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	// Two commands with 1s delay and a safety factor of 2 give an expected run time of 4s plus slack
+	taskID, err := service.EnqueueTask("N E", "1s")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+	service.markTaskStarted(taskID)
+	service.UpdateTaskState(taskID, InProgress, ReasonDispatched)
+
+	clock.Advance(4 * time.Second)
+	if stats := service.Stats(); stats.Stuck {
+		t.Fatalf("Task must not be flagged within its expected run time, got %+v", stats)
+	}
+
+	clock.Advance(2 * time.Second)
+	stats := service.Stats()
+	if !stats.Stuck || stats.StuckTaskID != taskID {
+		t.Fatalf("Expected task %s to be flagged as stuck, got %+v", taskID, stats)
+	}
+
+	// Within the grace period the task keeps running
+	service.checkStuckTasks()
+	if state, _ := service.GetTaskState(taskID); state != InProgress {
+		t.Fatalf("Expected task to stay InProgress within grace period, got %s", state)
+	}
+
+	clock.Advance(5 * time.Second)
+	service.checkStuckTasks()
+	if state, _ := service.GetTaskState(taskID); state != Aborted {
+		t.Errorf("Expected stuck task to be Aborted after grace period, got %s", state)
+	}
+	if stats := service.Stats(); stats.Stuck {
+		t.Errorf("Aborted task must no longer be reported as stuck, got %+v", stats)
+	}
+}
+
+// TestStuckTaskAbortedDuringExecution tests that the executor stops a task aborted by the monitor.
+func TestStuckTaskAbortedDuringExecution(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	service.SetRobotState(RobotState{X: 5, Y: 5})
+
+	taskID, err := service.EnqueueTask("N N N", "20ms")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- service.ExecuteTask(taskID)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	service.UpdateTaskState(taskID, Aborted, ReasonCommandFailed)
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected an error for a task aborted during execution")
+		}
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("Task execution did not stop within timeout")
+	}
+
+	if state, _ := service.GetTaskState(taskID); state != Aborted {
+		t.Errorf("Expected task state Aborted, got %s", state)
+	}
+}
+
+// TestStatsQueueFill tests that the reported queue length matches the enqueued but not yet dispatched tasks.
+func TestStatsQueueFill(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 5))
+
+	for i := 1; i <= 3; i++ {
+		if _, err := service.EnqueueTask("N", "0s"); err != nil {
+			t.Fatalf("Failed to enqueue task: %v", err)
+		}
+		stats := service.Stats()
+		if stats.QueueLen != i || stats.QueueCap != 5 {
+			t.Errorf("After %d tasks: expected queue %d/5, got %d/%d", i, i, stats.QueueLen, stats.QueueCap)
+		}
+	}
+
+	// Picking up a dispatch token the way Start does shrinks the queue
+	<-service.taskIdQueue
+	if queue := service.QueueStats(); queue.Len != 2 {
+		t.Errorf("Expected queue length 2 after dispatch, got %d", queue.Len)
+	}
+}
+
+// TestStatsThroughput tests the rolling command and task rates for a burst of tasks driven by the fake clock.
+func TestStatsThroughput(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	config.ThroughputWindow = 10 * time.Second
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	// Three tasks of four commands, one command per second: commands at 1s..12s, tasks done at 4s, 8s and 12s
+	for i := 0; i < 3; i++ {
+		taskID, _ := service.EnqueueTask("N E S W", "1s")
+		if err := service.ExecuteTask(taskID); err != nil {
+			t.Fatalf("Failed to execute task: %v", err)
+		}
+	}
+
+	// The window covers 3s..12s: ten commands and all three tasks
+	stats := service.Stats()
+	if stats.CommandsPerSecond != 1.0 {
+		t.Errorf("Expected 1.0 commands per second, got %v", stats.CommandsPerSecond)
+	}
+	if stats.TasksPerMinute != 18.0 {
+		t.Errorf("Expected 18 tasks per minute, got %v", stats.TasksPerMinute)
+	}
+
+	// Half of the window later only the second half of the burst counts
+	clock.Advance(5 * time.Second)
+	stats = service.Stats()
+	if stats.CommandsPerSecond != 0.5 {
+		t.Errorf("Expected 0.5 commands per second, got %v", stats.CommandsPerSecond)
+	}
+	if stats.TasksPerMinute != 12.0 {
+		t.Errorf("Expected 12 tasks per minute, got %v", stats.TasksPerMinute)
+	}
+
+	// Once the window passed the burst the rates drop to zero
+	clock.Advance(10 * time.Second)
+	stats = service.Stats()
+	if stats.CommandsPerSecond != 0 || stats.TasksPerMinute != 0 {
+		t.Errorf("Expected zero rates after the window, got %v/s and %v/min", stats.CommandsPerSecond, stats.TasksPerMinute)
+	}
+}
+
+// TestStuckCancellation tests that a task lingering in RequestCancellation is forced to Canceled after the grace period
+// and reported in the stats.
+func TestStuckCancellation(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	config.CancelGrace = 5 * time.Second
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	taskID, _ := service.EnqueueTask("N E", "1s")
+	service.markTaskStarted(taskID)
+	service.UpdateTaskState(taskID, InProgress, ReasonDispatched)
+	if err := service.CancelTask(taskID); err != nil {
+		t.Fatalf("Failed to cancel task: %v", err)
+	}
+
+	clock.Advance(5 * time.Second)
+	service.checkStuckCancellations()
+	if state, _ := service.GetTaskState(taskID); state != RequestCancellation {
+		t.Fatalf("Expected the task to stay in RequestCancellation within the grace period, got %s", state)
+	}
+	if stats := service.Stats(); len(stats.ForcedCancellations) != 0 {
+		t.Fatalf("Expected no forced cancellations within the grace period, got %v", stats.ForcedCancellations)
+	}
+
+	clock.Advance(time.Second)
+	service.checkStuckCancellations()
+	if task := service.CurrentState().Tasks[taskID]; task.State != Canceled || task.Reason != ReasonCancelTimeout {
+		t.Errorf("Expected the task forced to Canceled with reason %s, got %s (%s)", ReasonCancelTimeout, task.State, task.Reason)
+	}
+	if stats := service.Stats(); len(stats.ForcedCancellations) != 1 || stats.ForcedCancellations[0] != taskID {
+		t.Errorf("Expected task %s in the forced cancellations, got %v", taskID, stats.ForcedCancellations)
+	}
+}
+
+// TestStuckCancellationDuringExecution tests that the executor stops a task force-canceled by the monitor
+// without overwriting its state.
+func TestStuckCancellationDuringExecution(t *testing.T) {
+	config := DefaultConfig()
+	config.CancelGrace = time.Second
+	var service *Service
+	clock := &sleepHookClock{fakeClock: newFakeClock()}
+	var taskID string
+	clock.onSleep = func() {
+		if state, _ := service.GetTaskState(taskID); state == InProgress {
+			service.CancelTask(taskID)
+			clock.Advance(2 * time.Second) // The request goes unnoticed past the grace period
+			service.checkStuckCancellations()
+		}
+	}
+	config.Clock = clock
+	service = NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	taskID, _ = service.EnqueueTask("N N N", "1s")
+	if err := service.ExecuteTask(taskID); err != nil {
+		t.Fatalf("Expected the executor to stop without error, got %v", err)
+	}
+	if task := service.CurrentState().Tasks[taskID]; task.State != Canceled || task.Reason != ReasonCancelTimeout {
+		t.Errorf("Expected the task to stay force-canceled, got %s (%s)", task.State, task.Reason)
+	}
+	if robotState := service.GetRobotState(); robotState.Y > 1 {
+		t.Errorf("Expected the executor to stop after the current command, the robot moved to (%d, %d)", robotState.X, robotState.Y)
+	}
+}
@@ -0,0 +1,90 @@
+package robot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Coord is a cell of the warehouse grid.
+// @Description Cell of the warehouse grid
+type Coord struct {
+	X int `json:"x" example:"3"` // X coordinate, growing eastwards
+	Y int `json:"y" example:"4"` // Y coordinate, growing northwards
+}
+
+func (c Coord) String() string {
+	return fmt.Sprintf("(%d, %d)", c.X, c.Y)
+}
+
+// inWarehouse reports whether the cell lies inside the warehouse grid.
+func (c Coord) inWarehouse() bool {
+	return c.X >= 0 && c.X < warehouseSize && c.Y >= 0 && c.Y < warehouseSize
+}
+
+// ParseCoord parses a cell given as "x,y".
+func ParseCoord(raw string) (Coord, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 2 {
+		return Coord{}, fmt.Errorf("invalid cell %q, expected x,y", raw)
+	}
+	x, errX := strconv.Atoi(strings.TrimSpace(parts[0]))
+	y, errY := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if errX != nil || errY != nil {
+		return Coord{}, fmt.Errorf("invalid cell %q, expected x,y", raw)
+	}
+	return Coord{X: x, Y: y}, nil
+}
+
+// newObstacleSet indexes the obstacle cells for fast lookup.
+func newObstacleSet(obstacles []Coord) map[Coord]struct{} {
+	set := make(map[Coord]struct{}, len(obstacles))
+	for _, obstacle := range obstacles {
+		set[obstacle] = struct{}{}
+	}
+	return set
+}
+
+// isObstacle reports whether the cell is permanently blocked.
+func (s *Service) isObstacle(cell Coord) bool {
+	_, blocked := s.obstacles[cell]
+	return blocked
+}
+
+// stepFrom returns the cell one command moves the robot to from cell, given the unit delta of the command.
+// It reports false if any cell passed on the way, which depends on the step size, is outside the warehouse or an obstacle.
+func (s *Service) stepFrom(cell Coord, dx, dy int) (Coord, bool) {
+	for step := 0; step < s.config.StepSize; step++ {
+		cell = Coord{X: cell.X + dx, Y: cell.Y + dy}
+		if !cell.inWarehouse() || s.isObstacle(cell) {
+			return cell, false
+		}
+	}
+	return cell, true
+}
+
+// CellInfo reports what occupies a cell of the warehouse.
+// @Description Occupancy of a warehouse cell
+type CellInfo struct {
+	Cell     Coord       `json:"cell"`                     // The queried cell
+	InBounds bool        `json:"in_bounds" example:"true"` // Whether the cell lies inside the warehouse
+	Obstacle bool        `json:"obstacle" example:"false"` // Whether the cell holds a permanent obstacle
+	Occupied bool        `json:"occupied" example:"false"` // Whether the robot is currently in the cell
+	Robot    *RobotState `json:"robot,omitempty"`          // The robot occupying the cell, if any
+}
+
+// CellInfo returns whether the cell is inside the warehouse, an obstacle or occupied by the robot.
+func (s *Service) CellInfo(cell Coord) CellInfo {
+	info := CellInfo{Cell: cell, InBounds: cell.inWarehouse()}
+	if !info.InBounds {
+		return info
+	}
+
+	info.Obstacle = s.isObstacle(cell)
+	robotState := s.GetRobotState()
+	if (Coord{X: int(robotState.X), Y: int(robotState.Y)}) == cell {
+		info.Occupied = true
+		info.Robot = &robotState
+	}
+	return info
+}
@@ -0,0 +1,76 @@
+package robot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnqueuePatrol enqueues a task that moves the robot along a closed rectangular loop of the given size,
+// starting and ending at the robot's current position.
+// The loop direction is chosen so the rectangle fits in the warehouse, an error is returned if it does not fit at all
+// or crosses an obstacle. The spec sets the options of the task, e.g. its delay and allowed commands, the commands
+// are generated.
+func (s *Service) EnqueuePatrol(width, height uint, spec TaskSpec) (string, error) {
+	start := s.GetRobotState()
+	commands, err := patrolCommands(start, width, height, s.config.StepSize)
+	if err != nil {
+		return "", err
+	}
+	parsed, _, _, err := parseCommands(commands)
+	if err != nil {
+		return "", err
+	}
+	// The rectangle fits the warehouse, but the path may still cross an obstacle
+	if _, err := s.checkPathFrom(Coord{X: int(start.X), Y: int(start.Y)}, parsed); err != nil {
+		return "", fmt.Errorf("patrol rectangle of %dx%d from %s: %w", width, height, Coord{X: int(start.X), Y: int(start.Y)}, err)
+	}
+	spec.Commands = commands
+	return s.SubmitTask(spec)
+}
+
+// patrolCommands generates the command sequence for a closed rectangle of width x height commands starting at start,
+// every command moving the robot stepSize cells. It prefers going east and north, and falls back to west and/or south
+// when the rectangle does not fit.
+func patrolCommands(start RobotState, width, height uint, stepSize int) (string, error) {
+	if width == 0 || height == 0 {
+		return "", fmt.Errorf("%w: patrol width and height must be greater than zero", ErrInvalidCommand)
+	}
+	// A leg longer than the warehouse never fits, rejecting it first keeps the arithmetic below from overflowing
+	if maxLeg := uint((warehouseSize - 1) / stepSize); width > maxLeg || height > maxLeg {
+		return "", fmt.Errorf("%w: patrol rectangle of %dx%d at %d cells per command is larger than the warehouse, at most %d commands per side",
+			ErrOutOfBounds, width, height, stepSize, maxLeg)
+	}
+
+	horizontal, horizontalBack, ok := patrolAxis(start.X, width*uint(stepSize), East, West)
+	if !ok {
+		return "", fmt.Errorf("%w: patrol rectangle of width %d at %d cells per command does not fit from x=%d", ErrOutOfBounds, width, stepSize, start.X)
+	}
+	vertical, verticalBack, ok := patrolAxis(start.Y, height*uint(stepSize), North, South)
+	if !ok {
+		return "", fmt.Errorf("%w: patrol rectangle of height %d at %d cells per command does not fit from y=%d", ErrOutOfBounds, height, stepSize, start.Y)
+	}
+
+	tokens := make([]string, 0, 2*(width+height))
+	for _, leg := range []struct {
+		cmd   RobotCommand
+		count uint
+	}{{horizontal, width}, {vertical, height}, {horizontalBack, width}, {verticalBack, height}} {
+		for i := uint(0); i < leg.count; i++ {
+			tokens = append(tokens, leg.cmd.String())
+		}
+	}
+	return strings.Join(tokens, " "), nil
+}
+
+// patrolAxis picks the direction along one axis in which a leg of the given length in cells stays inside the warehouse.
+// It returns the outbound and return commands.
+func patrolAxis(position, length uint, forward, backward RobotCommand) (RobotCommand, RobotCommand, bool) {
+	switch {
+	case position+length < warehouseSize:
+		return forward, backward, true
+	case length <= position:
+		return backward, forward, true
+	default:
+		return forward, backward, false
+	}
+}
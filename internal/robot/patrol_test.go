@@ -0,0 +1,112 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestPatrolCommands tests that the generated patrol is a closed loop that stays inside the warehouse.
+func TestPatrolCommands(t *testing.T) {
+	tests := []struct {
+		name          string
+		start         RobotState
+		width, height uint
+		stepSize      int
+		wantErr       bool
+	}{
+		{"From origin", RobotState{X: 0, Y: 0}, 3, 2, 1, false},
+		{"Near north east corner folds back", RobotState{X: 9, Y: 9}, 4, 4, 1, false},
+		{"Full width from west edge", RobotState{X: 0, Y: 5}, warehouseSize - 1, 1, 1, false},
+		{"Too wide", RobotState{X: 5, Y: 5}, warehouseSize, 1, 1, true},
+		{"Too tall from the middle", RobotState{X: 5, Y: 5}, 1, 6, 1, true},
+		{"Zero width", RobotState{X: 5, Y: 5}, 0, 2, 1, true},
+		{"Larger steps fold back", RobotState{X: 5, Y: 0}, 2, 2, 2, false},
+		{"Too wide with larger steps", RobotState{X: 5, Y: 5}, 3, 1, 2, true},
+		{"Full width with larger steps", RobotState{X: 0, Y: 0}, 3, 3, 3, false},
+		{"Huge width", RobotState{X: 5, Y: 5}, math.MaxUint64, 1, 1, true},
+		{"Huge height wrapping with larger steps", RobotState{X: 5, Y: 5}, 1, math.MaxUint64/2 + 1, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := patrolCommands(tt.start, tt.width, tt.height, tt.stepSize)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("patrolCommands() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			commands, _, _, err := parseCommands(raw)
+			if err != nil {
+				t.Fatalf("Generated commands do not parse: %v", err)
+			}
+			if want := int(2 * (tt.width + tt.height)); len(commands) != want {
+				t.Errorf("Expected %d commands, got %d", want, len(commands))
+			}
+
+			// Walk the path and check every cell is inside the warehouse
+			x, y := int(tt.start.X), int(tt.start.Y)
+			for i, cmd := range commands {
+				dx, dy := cmd.Delta()
+				x, y = x+dx*tt.stepSize, y+dy*tt.stepSize
+				if x < 0 || x >= warehouseSize || y < 0 || y >= warehouseSize {
+					t.Fatalf("Step %d leaves the warehouse at (%d, %d)", i, x, y)
+				}
+			}
+			if x != int(tt.start.X) || y != int(tt.start.Y) {
+				t.Errorf("Patrol is not a closed loop, ended at (%d, %d)", x, y)
+			}
+		})
+	}
+}
+
+// TestEnqueuePatrol tests that a patrol task is enqueued and completes back at the start.
+func TestEnqueuePatrol(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	service.SetRobotState(RobotState{X: 8, Y: 2})
+
+	taskID, err := service.EnqueuePatrol(3, 2, TaskSpec{DelayBetweenCommands: "0s"})
+	if err != nil {
+		t.Fatalf("Failed to enqueue patrol: %v", err)
+	}
+	if err := service.ExecuteTask(taskID); err != nil {
+		t.Fatalf("Failed to execute patrol: %v", err)
+	}
+	if robotState := service.GetRobotState(); robotState.X != 8 || robotState.Y != 2 {
+		t.Errorf("Expected robot back at (8, 2), got (%d, %d)", robotState.X, robotState.Y)
+	}
+
+	if _, err := service.EnqueuePatrol(warehouseSize, 1, TaskSpec{DelayBetweenCommands: "0s"}); err == nil {
+		t.Error("Expected error for a patrol that does not fit")
+	}
+	if _, err := service.EnqueuePatrol(math.MaxUint64, math.MaxUint64, TaskSpec{DelayBetweenCommands: "0s"}); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("Expected a huge patrol to fail with %v, got %v", ErrOutOfBounds, err)
+	}
+}
+
+// TestEnqueuePatrol_InvalidPath tests that a patrol is checked with the configured step size and obstacles when enqueued.
+func TestEnqueuePatrol_InvalidPath(t *testing.T) {
+	config := DefaultConfig()
+	config.Obstacles = []Coord{{X: 3, Y: 1}}
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	if _, err := service.EnqueuePatrol(3, 2, TaskSpec{}); !errors.Is(err, ErrObstacle) {
+		t.Errorf("Expected a patrol crossing the obstacle to fail with %v, got %v", ErrObstacle, err)
+	}
+	if _, err := service.EnqueuePatrol(1, 1, TaskSpec{}); err != nil {
+		t.Errorf("Expected a patrol around the obstacle to be accepted, got %v", err)
+	}
+
+	config = DefaultConfig()
+	config.StepSize = 3
+	service = NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	if _, err := service.EnqueuePatrol(4, 1, TaskSpec{}); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("Expected a patrol of 12 cells to fail with %v, got %v", ErrOutOfBounds, err)
+	}
+	if len(service.CurrentState().Tasks) != 0 {
+		t.Errorf("Expected rejected patrols not to be stored")
+	}
+}
@@ -0,0 +1,8 @@
+package robot
+
+// EnqueuePing enqueues a ping task without commands. It goes through the same queue, dispatch and
+// event lifecycle as any other task but never moves the robot, so monitors can check the whole pipeline.
+// Ping tasks are accepted even if empty tasks are not allowed.
+func (s *Service) EnqueuePing() (string, error) {
+	return s.SubmitTask(TaskSpec{Ping: true})
+}
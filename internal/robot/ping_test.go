@@ -0,0 +1,59 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestPingTask tests that a ping task runs through the whole lifecycle and completes without moving the robot.
+func TestPingTask(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service, err := NewEmbeddedService(ctx, DefaultConfig()) // Empty tasks are not allowed by default
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	sub, err := service.Subscribe()
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	taskID, err := service.EnqueuePing()
+	if err != nil {
+		t.Fatalf("Failed to enqueue ping: %v", err)
+	}
+
+	// Events are published concurrently, only the set of states is asserted
+	seen := make(map[TaskState]bool)
+	timeout := time.After(2 * time.Second)
+	for !seen[Pending] || !seen[InProgress] || !seen[Completed] {
+		select {
+		case event := <-sub.Events():
+			if event.TaskID == taskID {
+				seen[event.State] = true
+			}
+		case <-timeout:
+			t.Fatalf("Expected Pending, InProgress and Completed events, got %v", seen)
+		}
+	}
+
+	task := service.CurrentState().Tasks[taskID]
+	if !task.Ping || len(task.Commands) != 0 || task.State != Completed {
+		t.Errorf("Expected a completed ping task without commands, got %+v", task)
+	}
+	if got := service.CurrentState().RobotState; got.X != 0 || got.Y != 0 {
+		t.Errorf("Expected the robot not to move, got (%d, %d)", got.X, got.Y)
+	}
+}
+
+// TestPingTask_WithCommands tests that a ping task cannot carry commands.
+func TestPingTask_WithCommands(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 1))
+	if _, err := service.SubmitTask(TaskSpec{Commands: "N", Ping: true}); !errors.Is(err, ErrInvalidCommand) {
+		t.Errorf("Expected ErrInvalidCommand, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package robot
+
+import "log"
+
+// preemptedReason is the error recorded on a task aborted to make room for a higher priority task.
+const preemptedReason = "preempted"
+
+// preemptForLocked aborts the oldest in-progress task if its priority is lower than the given priority.
+// It reports whether a task was preempted. The caller must hold the service lock.
+func (s *Service) preemptForLocked(priority int) bool {
+	var oldest *RobotTask
+	for _, task := range s.state.Tasks {
+		if task.State != InProgress || task.Priority >= priority {
+			continue
+		}
+		if oldest == nil || task.SequenceNum < oldest.SequenceNum {
+			candidate := task
+			oldest = &candidate
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+
+	log.Printf("Preempting task %s (priority %d) for a task with priority %d", oldest.ID, oldest.Priority, priority)
+	oldest.State = Aborted
+	oldest.Reason = ReasonPreempted
+	s.stampAborted(oldest)
+	oldest.Error = preemptedReason
+	s.state.Tasks[oldest.ID] = *oldest
+	s.publishEventAsync(oldest.ID, Aborted, oldest.Reason, preemptedReason)
+	return true
+}
+
+// dispatchWhenFree queues the dispatch token of a task accepted while the queue was full.
+// The preempted task stops at its next command, after which the dispatcher frees a slot.
+func (s *Service) dispatchWhenFree(taskID string) {
+	select {
+	case s.taskIdQueue <- taskID:
+	case <-s.ctx.Done():
+	}
+}
@@ -0,0 +1,76 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fillQueueWithRunningTask returns a service with a queue of one slot, a running task and a queued task.
+func fillQueueWithRunningTask(t *testing.T, preempt bool) (*Service, string) {
+	t.Helper()
+	config := DefaultConfig()
+	config.PreemptOnOverload = preempt
+	service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+
+	running, err := service.EnqueueTask("N E", "0s")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+	<-service.taskIdQueue // Dispatch the first task the way Start does
+	service.UpdateTaskState(running, InProgress, ReasonDispatched)
+
+	if _, err := service.EnqueueTask("N", "0s"); err != nil {
+		t.Fatalf("Failed to fill the queue: %v", err)
+	}
+	return service, running
+}
+
+// TestPreemptOnOverload tests that a high priority arrival on a full queue preempts the running task.
+func TestPreemptOnOverload(t *testing.T) {
+	service, running := fillQueueWithRunningTask(t, true)
+
+	urgent, err := service.SubmitTask(TaskSpec{Commands: "S", DelayBetweenCommands: "0s", Priority: 5})
+	if err != nil {
+		t.Fatalf("Expected the urgent task to be accepted, got %v", err)
+	}
+
+	task := service.CurrentState().Tasks[running]
+	if task.State != Aborted || task.Error != "preempted" {
+		t.Errorf("Expected running task Aborted with reason 'preempted', got %s '%s'", task.State, task.Error)
+	}
+
+	// The urgent task's token is queued once the dispatcher frees a slot
+	<-service.taskIdQueue
+	select {
+	case <-service.taskIdQueue:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the urgent task's dispatch token to be queued")
+	}
+	if next, _ := service.nextPendingTask(); next != urgent {
+		t.Errorf("Expected the urgent task to be dispatched next, got %s", next)
+	}
+
+	// An arrival that does not outrank the running task is still rejected
+	service.UpdateTaskState(urgent, InProgress, ReasonDispatched)
+	service.EnqueueTask("W", "0s")
+	if _, err := service.SubmitTask(TaskSpec{Commands: "E", DelayBetweenCommands: "0s", Priority: 5}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull for equal priority, got %v", err)
+	}
+}
+
+// TestPreemptOnOverloadDisabled tests that the preemption policy is off by default.
+func TestPreemptOnOverloadDisabled(t *testing.T) {
+	if DefaultConfig().PreemptOnOverload {
+		t.Fatal("Expected preemption to be disabled by default")
+	}
+
+	service, running := fillQueueWithRunningTask(t, false)
+	if _, err := service.SubmitTask(TaskSpec{Commands: "S", DelayBetweenCommands: "0s", Priority: 5}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+	if state, _ := service.GetTaskState(running); state != InProgress {
+		t.Errorf("Expected running task to stay InProgress, got %s", state)
+	}
+}
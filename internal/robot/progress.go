@@ -0,0 +1,53 @@
+package robot
+
+import "time"
+
+// EventTypeProgress marks progress heartbeats in TaskStatusUpdateEvent.Type.
+const EventTypeProgress = "progress"
+
+// ProgressHeartbeat reports how far a running task got, published periodically when progress events are enabled
+// so clients of long tasks see the robot advance between state changes.
+// @Description Periodic progress of a running task
+type ProgressHeartbeat struct {
+	CommandsExecuted int   `json:"commands_executed" example:"20"` // Number of commands executed so far
+	TotalCommands    int   `json:"total_commands" example:"50"`    // Number of commands of the task, 0 for follow tasks whose path is not known in advance
+	Position         Coord `json:"position"`                       // Robot position after the latest command
+}
+
+// progressCadence decides when the next heartbeat of a running task is due.
+type progressCadence struct {
+	commands int       // Commands executed at the latest heartbeat
+	at       time.Time // Time of the latest heartbeat, or of the dispatch before the first one
+}
+
+func (s *Service) newProgressCadence() *progressCadence {
+	return &progressCadence{at: s.config.Clock.Now()}
+}
+
+// reportProgress publishes a heartbeat once ProgressEveryCommands commands or ProgressInterval passed since the
+// latest one. It is called after every command, so the interval is checked at command boundaries. Nothing is
+// published after the last command of a task, its Completed event follows right away.
+func (s *Service) reportProgress(taskID string, cadence *progressCadence, executed, total int) {
+	every, interval := s.config.ProgressEveryCommands, s.config.ProgressInterval
+	if (every == 0 && interval == 0) || executed == total {
+		return
+	}
+	now := s.config.Clock.Now()
+	due := (every > 0 && executed-cadence.commands >= every) || (interval > 0 && now.Sub(cadence.at) >= interval)
+	if !due {
+		return
+	}
+	cadence.commands, cadence.at = executed, now
+
+	robotState := s.GetRobotState()
+	s.publish(TaskStatusUpdateEvent{
+		Type:   EventTypeProgress,
+		TaskID: taskID,
+		State:  InProgress,
+		Heartbeat: &ProgressHeartbeat{
+			CommandsExecuted: executed,
+			TotalCommands:    total,
+			Position:         Coord{X: int(robotState.X), Y: int(robotState.Y)},
+		},
+	})
+}
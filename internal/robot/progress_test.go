@@ -0,0 +1,65 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestProgressEvents tests that progress heartbeats arrive at the configured cadence during a long task.
+func TestProgressEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		every    int
+		interval time.Duration
+		want     []int // Commands executed at every heartbeat
+	}{
+		{"Disabled", 0, 0, nil},
+		{"Every 3 commands", 3, 0, []int{3, 6}},
+		{"Every 2.5 seconds", 0, 2500 * time.Millisecond, []int{3, 6}},
+		{"Commands or interval", 4, 2 * time.Second, []int{2, 4, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Clock = newFakeClock()
+			config.ProgressEveryCommands = tt.every
+			config.ProgressInterval = tt.interval
+			service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+			sub, _ := service.Subscribe()
+			defer sub.Close()
+
+			taskID, _ := service.EnqueueTask("N N N N N N N", "1s")
+			if err := service.ExecuteTask(taskID); err != nil {
+				t.Fatalf("Failed to execute task: %v", err)
+			}
+
+			var got []ProgressHeartbeat
+			timeout := time.After(2 * time.Second)
+			for completed := false; !completed; {
+				select {
+				case event := <-sub.Events():
+					if event.Type == EventTypeProgress {
+						if event.TaskID != taskID || event.Heartbeat == nil {
+							t.Fatalf("Unexpected progress event %+v", event)
+						}
+						got = append(got, *event.Heartbeat)
+					}
+					completed = event.State == Completed
+				case <-timeout:
+					t.Fatal("Timeout waiting for the Completed event")
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d progress events, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i, executed := range tt.want {
+				want := ProgressHeartbeat{CommandsExecuted: executed, TotalCommands: 7, Position: Coord{X: 0, Y: executed}}
+				if got[i] != want {
+					t.Errorf("Progress event %d: expected %+v, got %+v", i, want, got[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package robot
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// QueuedTask describes a pending task in dispatch order.
+// @Description Pending task in dispatch order with its estimated start time
+type QueuedTask struct {
+	TaskID         string    `json:"task_id" example:"12345"`                        // Unique identifier for the task
+	SequenceNum    int       `json:"sequence_num" example:"3"`                       // Sequence number of the task
+	Priority       int       `json:"priority" example:"0"`                           // Priority of the task
+	CommandCount   int       `json:"command_count" example:"4"`                      // Number of commands in the task
+	EstimatedStart time.Time `json:"estimated_start" example:"2024-01-15T10:30:00Z"` // Estimated time at which the task starts
+}
+
+// QueueStats reports the fill level of the task queue.
+type QueueStats struct {
+	Len int // Number of queued dispatch tokens not yet picked up
+	Cap int // Capacity of the task queue
+}
+
+// QueueStats returns the current fill level of the task queue.
+// Callers should use it instead of inspecting the channel, so the queue implementation can change.
+func (s *Service) QueueStats() QueueStats {
+	return QueueStats{Len: len(s.taskIdQueue), Cap: cap(s.taskIdQueue)}
+}
+
+// TieBreak decides the dispatch order of pending tasks with equal priority.
+type TieBreak string
+
+// Orders of tasks with equal priority
+const (
+	TieBreakFIFO     TieBreak = "fifo"     // In submission order, the default
+	TieBreakShortest TieBreak = "shortest" // Fewest commands first, in submission order for equal counts
+)
+
+// ParseTieBreak parses a tie-break name, an empty name is the default TieBreakFIFO.
+func ParseTieBreak(raw string) (TieBreak, error) {
+	switch tieBreak := TieBreak(raw); tieBreak {
+	case "":
+		return TieBreakFIFO, nil
+	case TieBreakFIFO, TieBreakShortest:
+		return tieBreak, nil
+	default:
+		return "", fmt.Errorf("invalid tie-break: %s, expected fifo or shortest", raw)
+	}
+}
+
+// dispatchesBefore reports whether task a must be dispatched before task b.
+// Higher priority tasks go first, tasks with equal priority are ordered by the configured TieBreak.
+func (s *Service) dispatchesBefore(a, b RobotTask) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if s.config.TieBreak == TieBreakShortest && len(a.Commands) != len(b.Commands) {
+		return len(a.Commands) < len(b.Commands)
+	}
+	return a.dispatchSeq() < b.dispatchSeq()
+}
+
+// dispatchSeq returns the position of the task among pending tasks of equal priority.
+func (t RobotTask) dispatchSeq() int {
+	if t.QueueSeq != 0 {
+		return t.QueueSeq // A replacement takes the place of the replaced task
+	}
+	return t.SequenceNum
+}
+
+// pendingTasksLocked returns the pending tasks sorted in dispatch order.
+// The caller must hold the service lock.
+func (s *Service) pendingTasksLocked() []RobotTask {
+	pending := make([]RobotTask, 0)
+	for _, task := range s.state.Tasks {
+		if task.State == Pending {
+			pending = append(pending, task)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return s.dispatchesBefore(pending[i], pending[j])
+	})
+	return pending
+}
+
+// nextPendingTask returns the ID of the task that must be dispatched next.
+// It returns false if there is no pending task.
+func (s *Service) nextPendingTask() (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pending := s.pendingTasksLocked()
+	if len(pending) == 0 {
+		return "", false
+	}
+	return pending[0].ID, true
+}
+
+// PendingQueue returns the pending tasks in the order in which they will be dispatched,
+// along with the estimated start time of each task.
+func (s *Service) PendingQueue() []QueuedTask {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.config.Clock.Now()
+	start := now.Add(s.remainingInProgressLocked(now))
+
+	pending := s.pendingTasksLocked()
+	queue := make([]QueuedTask, 0, len(pending))
+	for _, task := range pending {
+		queue = append(queue, QueuedTask{
+			TaskID:         task.ID,
+			SequenceNum:    task.SequenceNum,
+			Priority:       task.Priority,
+			CommandCount:   len(task.Commands),
+			EstimatedStart: start,
+		})
+		start = start.Add(s.taskDuration(task))
+	}
+	return queue
+}
+
+// QueueETA estimates how long it takes until the queue is empty.
+// @Description Estimated time until all running and pending tasks are finished
+type QueueETA struct {
+	Remaining       CommandDuration `json:"remaining" swaggertype:"string" example:"42s"`    // Remaining run time of the running task plus the run time of all pending tasks
+	EstimatedFinish time.Time       `json:"estimated_finish" example:"2024-01-15T10:30:42Z"` // Time at which the queue is expected to be empty
+	PendingTasks    int             `json:"pending_tasks" example:"3"`                       // Number of tasks waiting to be dispatched
+}
+
+// QueueETA returns the estimated time until the running task and all pending tasks are finished,
+// using the same estimates as the start times of PendingQueue.
+func (s *Service) QueueETA() QueueETA {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.config.Clock.Now()
+	remaining := s.remainingInProgressLocked(now)
+	pending := s.pendingTasksLocked()
+	for _, task := range pending {
+		remaining += s.taskDuration(task)
+	}
+	return QueueETA{Remaining: CommandDuration(remaining), EstimatedFinish: now.Add(remaining), PendingTasks: len(pending)}
+}
+
+// remainingInProgressLocked returns the estimated remaining run time of the tasks currently being executed.
+// The caller must hold the service lock.
+func (s *Service) remainingInProgressLocked(now time.Time) time.Duration {
+	var remaining time.Duration
+	for _, task := range s.state.Tasks {
+		if task.State != InProgress && task.State != RequestCancellation {
+			continue
+		}
+		left := s.taskDuration(task)
+		if task.StartedAt != nil {
+			left -= now.Sub(*task.StartedAt)
+		}
+		if left > 0 {
+			remaining += left
+		}
+	}
+	return remaining
+}
+
+// markTaskStarted records the start time of the task.
+func (s *Service) markTaskStarted(taskID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task, exists := s.state.Tasks[taskID]; exists {
+		startedAt := s.config.Clock.Now()
+		task.StartedAt = &startedAt
+		s.state.Tasks[taskID] = task
+	}
+}
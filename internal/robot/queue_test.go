@@ -0,0 +1,175 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// drainDispatchOrder dispatches all pending tasks the same way Start does and returns the executed task IDs.
+func drainDispatchOrder(t *testing.T, service *Service) []string {
+	t.Helper()
+	executed := make([]string, 0)
+	for {
+		taskID, ok := service.nextPendingTask()
+		if !ok {
+			return executed
+		}
+		if err := service.ExecuteTask(taskID); err != nil {
+			t.Fatalf("Failed to execute task %s: %v", taskID, err)
+		}
+		executed = append(executed, taskID)
+	}
+}
+
+// TestPendingQueueMatchesDispatchOrder tests that the reported queue order is the order in which tasks are dispatched.
+func TestPendingQueueMatchesDispatchOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities []int
+		wantOrder  []int // Indexes of the submitted tasks in expected dispatch order
+	}{
+		{"FIFO with equal priorities", []int{0, 0, 0, 0}, []int{0, 1, 2, 3}},
+		{"Higher priority first", []int{0, 5, 1, 5}, []int{1, 3, 2, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(context.Background(), make(chan string, 10))
+
+			ids := make([]string, 0, len(tt.priorities))
+			for _, priority := range tt.priorities {
+				taskID, err := service.SubmitTask(TaskSpec{Commands: "N S", DelayBetweenCommands: "0s", Priority: priority})
+				if err != nil {
+					t.Fatalf("Failed to submit task: %v", err)
+				}
+				ids = append(ids, taskID)
+			}
+
+			queue := service.PendingQueue()
+			if len(queue) != len(ids) {
+				t.Fatalf("Expected %d queued tasks, got %d", len(ids), len(queue))
+			}
+			for i, want := range tt.wantOrder {
+				if queue[i].TaskID != ids[want] {
+					t.Errorf("Queue position %d: expected task %d, got %s", i, want, queue[i].TaskID)
+				}
+			}
+
+			executed := drainDispatchOrder(t, service)
+			for i := range queue {
+				if executed[i] != queue[i].TaskID {
+					t.Errorf("Dispatch position %d: expected %s, got %s", i, queue[i].TaskID, executed[i])
+				}
+			}
+		})
+	}
+}
+
+// TestQueueETA tests that the estimate sums the remaining time of the running task and the run time of all pending tasks.
+// TestTieBreak tests the dispatch order of tasks with equal priority for every tie-break policy.
+func TestTieBreak(t *testing.T) {
+	tasks := []struct {
+		commands string
+		priority int
+	}{
+		{"N S N S", 0},
+		{"E W", 0},
+		{"N S N S N S", 1},
+		{"N", 0},
+		{"E W", 0},
+	}
+	tests := []struct {
+		tieBreak  TieBreak
+		wantOrder []int // Indexes of the submitted tasks in expected dispatch order
+	}{
+		{"", []int{2, 0, 1, 3, 4}},
+		{TieBreakFIFO, []int{2, 0, 1, 3, 4}},
+		{TieBreakShortest, []int{2, 3, 1, 4, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.tieBreak), func(t *testing.T) {
+			config := DefaultConfig()
+			config.TieBreak = tt.tieBreak
+			config.Clock = newFakeClock()
+			service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+			ids := make([]string, 0, len(tasks))
+			for _, task := range tasks {
+				taskID, err := service.SubmitTask(TaskSpec{Commands: task.commands, DelayBetweenCommands: "0s", Priority: task.priority})
+				if err != nil {
+					t.Fatalf("Failed to submit task: %v", err)
+				}
+				ids = append(ids, taskID)
+			}
+
+			queue := service.PendingQueue()
+			executed := drainDispatchOrder(t, service)
+			for i, index := range tt.wantOrder {
+				if queue[i].TaskID != ids[index] || executed[i] != ids[index] {
+					t.Errorf("Position %d: expected task %d (%s), queued %s and dispatched %s", i, index, tasks[index].commands, queue[i].TaskID, executed[i])
+				}
+			}
+		})
+	}
+
+	if _, err := ParseTieBreak("random"); err == nil {
+		t.Error("Expected an unknown tie-break to be rejected")
+	}
+}
+
+func TestQueueETA(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	if eta := service.QueueETA(); eta.Remaining != 0 || eta.PendingTasks != 0 || !eta.EstimatedFinish.Equal(clock.Now()) {
+		t.Errorf("Expected an empty queue to finish now, got %+v", eta)
+	}
+
+	// A running task with 4 commands of 1s, started 1.5s ago
+	running, _ := service.EnqueueTask("N E S W", "1s")
+	service.markTaskStarted(running)
+	service.UpdateTaskState(running, InProgress, ReasonDispatched)
+	clock.Advance(1500 * time.Millisecond)
+
+	service.EnqueueTask("N S", "2s")      // 4s
+	service.EnqueueTask("N E N", "500ms") // 1.5s
+	service.EnqueueTask("E W E W", "0s")  // 0s
+
+	eta := service.QueueETA()
+	want := 2500*time.Millisecond + 4*time.Second + 1500*time.Millisecond
+	if time.Duration(eta.Remaining) != want {
+		t.Errorf("Expected remaining %s, got %s", want, time.Duration(eta.Remaining))
+	}
+	if !eta.EstimatedFinish.Equal(clock.Now().Add(want)) {
+		t.Errorf("Expected finish at %s, got %s", clock.Now().Add(want), eta.EstimatedFinish)
+	}
+	if eta.PendingTasks != 3 {
+		t.Errorf("Expected 3 pending tasks, got %d", eta.PendingTasks)
+	}
+}
+
+// TestPendingQueueEstimatedStart tests that start estimates accumulate the run time of the preceding tasks.
+func TestPendingQueueEstimatedStart(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+
+	for _, commands := range []string{"N S", "N E S W"} {
+		if _, err := service.EnqueueTask(commands, "1s"); err != nil {
+			t.Fatalf("Failed to enqueue task: %v", err)
+		}
+	}
+
+	queue := service.PendingQueue()
+	if len(queue) != 2 {
+		t.Fatalf("Expected 2 queued tasks, got %d", len(queue))
+	}
+	if queue[0].CommandCount != 2 || queue[1].CommandCount != 4 {
+		t.Errorf("Unexpected command counts: %d, %d", queue[0].CommandCount, queue[1].CommandCount)
+	}
+	if gap := queue[1].EstimatedStart.Sub(queue[0].EstimatedStart); gap != 2*time.Second {
+		t.Errorf("Expected second task to start 2s after the first, got %s", gap)
+	}
+}
@@ -0,0 +1,81 @@
+package robot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SpeedRamp is the acceleration profile of the robot: the robot speeds up at the start of every task, so its first
+// commands take longer. The i-th command of a task waits SpeedRamp[i] in addition to the task's delay between
+// commands, commands beyond the profile wait only the task's delay, the robot's top speed.
+// The extra delays must not increase, e.g. 300ms, 200ms, 100ms.
+type SpeedRamp []time.Duration
+
+// ParseSpeedRamp parses a comma separated list of extra delays, e.g. "300ms,200ms,100ms".
+// An empty string is no ramp, the robot moves at full speed right away.
+func ParseSpeedRamp(raw string) (SpeedRamp, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var ramp SpeedRamp
+	for _, part := range strings.Split(raw, ",") {
+		delay, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid speed ramp %q: %v", raw, err)
+		}
+		ramp = append(ramp, delay)
+	}
+	return ramp, ramp.validate()
+}
+
+// validate returns an error unless the extra delays are non-negative and do not increase.
+func (r SpeedRamp) validate() error {
+	for i, delay := range r {
+		if delay < 0 {
+			return fmt.Errorf("invalid speed ramp: negative delay %s at step %d", delay, i)
+		}
+		if i > 0 && delay > r[i-1] {
+			return fmt.Errorf("invalid speed ramp: delay %s at step %d exceeds the delay %s before it, the robot must not slow down", delay, i, r[i-1])
+		}
+	}
+	return nil
+}
+
+// extra returns the extra delay before the command with the given index.
+func (r SpeedRamp) extra(step int) time.Duration {
+	if step < len(r) {
+		return r[step]
+	}
+	return 0
+}
+
+// total returns the extra time the ramp adds to a task with the given number of commands.
+func (r SpeedRamp) total(commands int) time.Duration {
+	var total time.Duration
+	for step := 0; step < commands && step < len(r); step++ {
+		total += r[step]
+	}
+	return total
+}
+
+// stepDelay returns the delay before the command with the given index of the task, following the speed ramp.
+func (s *Service) stepDelay(task RobotTask, step int) time.Duration {
+	return s.commandDelay(task) + s.config.SpeedRamp.extra(step)
+}
+
+// taskDuration returns the expected run time of the task including the speed ramp and its holds.
+func (s *Service) taskDuration(task RobotTask) time.Duration {
+	return time.Duration(len(task.Commands))*s.commandDelay(task) + s.config.SpeedRamp.total(len(task.Commands)) + task.Commands.holdTime()
+}
+
+// commandDelay returns the delay between the commands of the task. While events have subscribers it is at least
+// Config.MinSubscribedDelay, so a task without delay cannot fill the subscriber buffers faster than they are read
+// and push out the events of other tasks.
+func (s *Service) commandDelay(task RobotTask) time.Duration {
+	delay := time.Duration(task.DelayBetweenCommands)
+	if minDelay := s.config.MinSubscribedDelay; delay < minDelay && s.activeSubscribers.Load() > 0 {
+		return minDelay
+	}
+	return delay
+}
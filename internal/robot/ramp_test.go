@@ -0,0 +1,88 @@
+package robot
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingClock is a fake clock remembering every sleep.
+type recordingClock struct {
+	*fakeClock
+	mu     sync.Mutex
+	sleeps []time.Duration
+}
+
+func (c *recordingClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	c.sleeps = append(c.sleeps, d)
+	c.mu.Unlock()
+	c.fakeClock.Sleep(d)
+}
+
+// TestSpeedRamp tests that the delays before the first commands follow the ramp down to the task's delay,
+// and that the task takes as long as the delays of the profile add up to.
+func TestSpeedRamp(t *testing.T) {
+	ms := time.Millisecond
+	ramp := SpeedRamp{300 * ms, 200 * ms, 100 * ms}
+	tests := []struct {
+		name       string
+		commands   string
+		wantSleeps []time.Duration
+	}{
+		{"Longer than the ramp", "N N N N N", []time.Duration{350 * ms, 250 * ms, 150 * ms, 50 * ms, 50 * ms}},
+		{"Shorter than the ramp", "N N", []time.Duration{350 * ms, 250 * ms}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &recordingClock{fakeClock: newFakeClock()}
+			config := DefaultConfig()
+			config.Clock = clock
+			config.SpeedRamp = ramp
+			service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+			taskID, _ := service.EnqueueTask(tt.commands, "50ms")
+			task := service.CurrentState().Tasks[taskID]
+			start := clock.Now()
+			if err := service.ExecuteTask(taskID); err != nil {
+				t.Fatalf("Failed to execute the task: %v", err)
+			}
+
+			if !reflect.DeepEqual(clock.sleeps, tt.wantSleeps) {
+				t.Errorf("Expected the delays %v, got %v", tt.wantSleeps, clock.sleeps)
+			}
+			var want time.Duration
+			for step := range task.Commands {
+				want += 50*ms + ramp.extra(step)
+			}
+			if took := clock.Now().Sub(start); took != want || service.taskDuration(task) != want {
+				t.Errorf("Expected the task to take %s, got %s with an estimate of %s", want, took, service.taskDuration(task))
+			}
+		})
+	}
+}
+
+func TestParseSpeedRamp(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    SpeedRamp
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"300ms, 200ms,200ms", SpeedRamp{300 * time.Millisecond, 200 * time.Millisecond, 200 * time.Millisecond}, false},
+		{"100ms,200ms", nil, true},
+		{"-1s", nil, true},
+		{"fast", nil, true},
+	}
+	for _, tt := range tests {
+		ramp, err := ParseSpeedRamp(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSpeedRamp(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+		}
+		if !tt.wantErr && !reflect.DeepEqual(ramp, tt.want) {
+			t.Errorf("ParseSpeedRamp(%q) = %v, want %v", tt.raw, ramp, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package robot
+
+import "sort"
+
+// Reachable returns the cells the robot can reach from its current position within the given number of moves,
+// including the current cell, honoring the warehouse bounds and obstacles. Cells are ordered by row, then column.
+func (s *Service) Reachable(steps int) []Coord {
+	robotState := s.GetRobotState()
+	start := Coord{X: int(robotState.X), Y: int(robotState.Y)}
+
+	// Breadth-first search, the distance of every visited cell is its minimum number of moves
+	distance := map[Coord]int{start: 0}
+	frontier := []Coord{start}
+	for len(frontier) > 0 {
+		cell := frontier[0]
+		frontier = frontier[1:]
+		if distance[cell] == steps {
+			continue
+		}
+
+		for _, spec := range commandTable {
+			next, ok := s.stepFrom(cell, spec.DeltaX, spec.DeltaY)
+			if _, visited := distance[next]; visited || !ok {
+				continue
+			}
+			distance[next] = distance[cell] + 1
+			frontier = append(frontier, next)
+		}
+	}
+
+	cells := make([]Coord, 0, len(distance))
+	for cell := range distance {
+		cells = append(cells, cell)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Y != cells[j].Y {
+			return cells[i].Y < cells[j].Y
+		}
+		return cells[i].X < cells[j].X
+	})
+	return cells
+}
@@ -0,0 +1,124 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestReachableOpenGrid tests that the reachable area on an open grid is the diamond clipped by the bounds.
+func TestReachableOpenGrid(t *testing.T) {
+	tests := []struct {
+		name      string
+		position  RobotState
+		steps     int
+		wantCount int
+	}{
+		{"No moves", RobotState{X: 5, Y: 5}, 0, 1},
+		{"Diamond in the middle", RobotState{X: 5, Y: 5}, 2, 13},
+		{"Diamond clipped by the corner", RobotState{X: 0, Y: 0}, 2, 6},
+		{"Whole grid", RobotState{X: 0, Y: 0}, 2 * warehouseSize, warehouseSize * warehouseSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(context.Background(), make(chan string, 1))
+			service.SetRobotState(tt.position)
+
+			cells := service.Reachable(tt.steps)
+			if len(cells) != tt.wantCount {
+				t.Fatalf("Expected %d reachable cells, got %d: %v", tt.wantCount, len(cells), cells)
+			}
+			start := Coord{X: int(tt.position.X), Y: int(tt.position.Y)}
+			for _, cell := range cells {
+				distance := abs(cell.X-start.X) + abs(cell.Y-start.Y)
+				if distance > tt.steps || !cell.inWarehouse() {
+					t.Errorf("Cell %s is not reachable within %d moves", cell, tt.steps)
+				}
+			}
+		})
+	}
+}
+
+// TestReachableWithObstacle tests that an obstacle removes itself and the cells only reachable through it.
+func TestReachableWithObstacle(t *testing.T) {
+	config := DefaultConfig()
+	config.Obstacles = []Coord{{X: 1, Y: 0}}
+	service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+
+	want := []Coord{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 0, Y: 2}}
+	cells := service.Reachable(2)
+	if len(cells) != len(want) {
+		t.Fatalf("Expected cells %v, got %v", want, cells)
+	}
+	for i := range want {
+		if cells[i] != want[i] {
+			t.Errorf("Position %d: expected %s, got %s", i, want[i], cells[i])
+		}
+	}
+
+	// The robot cannot move onto the obstacle either
+	if err := service.ExecuteRobotCommand(East); !errors.Is(err, ErrObstacle) {
+		t.Errorf("Expected ErrObstacle, got %v", err)
+	}
+}
+
+func TestConfigValidateObstacles(t *testing.T) {
+	tests := []struct {
+		name      string
+		obstacles []Coord
+		wantErr   bool
+	}{
+		{"Inside the warehouse", []Coord{{X: 3, Y: 4}}, false},
+		{"Outside the warehouse", []Coord{{X: warehouseSize, Y: 0}}, true},
+		{"On the home cell", []Coord{{X: 0, Y: 0}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Obstacles = tt.obstacles
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// TestCellInfo tests the occupancy reported for empty, outside, obstacle and robot cells.
+func TestCellInfo(t *testing.T) {
+	config := DefaultConfig()
+	config.Obstacles = []Coord{{X: 2, Y: 2}}
+	service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+	service.SetRobotState(RobotState{X: 4, Y: 1, Heading: HeadingEast})
+	robot := service.GetRobotState()
+
+	tests := []struct {
+		name string
+		cell Coord
+		want CellInfo
+	}{
+		{"Empty cell", Coord{X: 5, Y: 5}, CellInfo{Cell: Coord{X: 5, Y: 5}, InBounds: true}},
+		{"Outside", Coord{X: -1, Y: 3}, CellInfo{Cell: Coord{X: -1, Y: 3}}},
+		{"Beyond the far wall", Coord{X: 3, Y: warehouseSize}, CellInfo{Cell: Coord{X: 3, Y: warehouseSize}}},
+		{"Obstacle", Coord{X: 2, Y: 2}, CellInfo{Cell: Coord{X: 2, Y: 2}, InBounds: true, Obstacle: true}},
+		{"Robot", Coord{X: 4, Y: 1}, CellInfo{Cell: Coord{X: 4, Y: 1}, InBounds: true, Occupied: true, Robot: &robot}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := service.CellInfo(tt.cell)
+			if got.Cell != tt.want.Cell || got.InBounds != tt.want.InBounds || got.Obstacle != tt.want.Obstacle || got.Occupied != tt.want.Occupied {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if (got.Robot == nil) != (tt.want.Robot == nil) || (got.Robot != nil && *got.Robot != *tt.want.Robot) {
+				t.Errorf("Expected robot %v, got %v", tt.want.Robot, got.Robot)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package robot
+
+import "errors"
+
+// TransitionReason tells why a task entered its current state, for audit and debugging.
+// Unlike the error message, it is one of a fixed set of values clients can switch on.
+type TransitionReason string
+
+// Reasons of the task state transitions
+const (
+	ReasonSubmitted        TransitionReason = "submitted"          // Pending: the task was accepted
+	ReasonUncanceled       TransitionReason = "user_uncancel"      // Pending: a client reversed the cancel within the uncancel window
+	ReasonDispatched       TransitionReason = "dispatched"         // InProgress: the dispatcher started the task
+	ReasonCompleted        TransitionReason = "completed_normally" // Completed: every command was executed, or a follow task reached its target
+	ReasonTargetCleared    TransitionReason = "target_cleared"     // Completed: the target of a follow task was cleared
+	ReasonUserCancel       TransitionReason = "user_cancel"        // RequestCancellation, CancelPending, Canceled: a client cancelled the task or its group
+	ReasonReplaced         TransitionReason = "replaced"           // RequestCancellation, Canceled: a client replaced the task with a new one
+	ReasonSkippedInvalid   TransitionReason = "skipped_invalid"    // Canceled: the task was invalid when dispatched and its policy is to skip it
+	ReasonCancelTimeout    TransitionReason = "cancel_timeout"     // Canceled: the executor did not act on a cancellation request within the grace period
+	ReasonOutOfBounds      TransitionReason = "out_of_bounds"      // Aborted: the robot would leave the warehouse
+	ReasonObstacle         TransitionReason = "obstacle"           // Aborted: the robot would enter an obstacle
+	ReasonCellBlocked      TransitionReason = "cell_blocked"       // Aborted: the target cell stayed occupied after all retries
+	ReasonBatteryDepleted  TransitionReason = "battery_depleted"   // Aborted: the battery would run out away from the origin
+	ReasonOffGrid          TransitionReason = "off_grid"           // Aborted: the robot was outside the warehouse when the task was dispatched
+	ReasonNoPath           TransitionReason = "no_path"            // Aborted: obstacles wall the target of a follow task off
+	ReasonNotAllowed       TransitionReason = "not_allowed"        // Aborted: the commands resolved at dispatch are outside the allowed set of the submitter
+	ReasonCommandFailed    TransitionReason = "command_failed"     // Aborted: a command failed for another reason
+	ReasonTimeout          TransitionReason = "timeout"            // Aborted: the task overran its expected run time
+	ReasonPreempted        TransitionReason = "preempted"          // Aborted: a higher priority task took its place
+	ReasonDispatcherFailed TransitionReason = "dispatcher_failed"  // Aborted: the dispatch loop died while running the task
+	ReasonRestored         TransitionReason = "restored"           // Aborted: a snapshot restore replaced the running task
+	ReasonShutdown         TransitionReason = "shutdown"           // Aborted: the service shut down during a hold of the task
+)
+
+// AbortCategory groups the reasons of aborted tasks into the broad kinds of failure, so clients can decide
+// how to recover without handling every reason.
+type AbortCategory string
+
+// Categories of the abort reasons
+const (
+	AbortOutOfBounds    AbortCategory = "OutOfBounds"    // out_of_bounds, off_grid: the robot would leave, or already left, the warehouse
+	AbortObstacle       AbortCategory = "Obstacle"       // obstacle, no_path: an obstacle is in the way of the robot
+	AbortExecutionError AbortCategory = "ExecutionError" // cell_blocked, battery_depleted, not_allowed, command_failed: the commands could not be executed
+	AbortTimeout        AbortCategory = "Timeout"        // timeout: the task overran its expected run time
+	AbortInterrupted    AbortCategory = "Interrupted"    // preempted, dispatcher_failed, restored, shutdown: the service stopped the task
+)
+
+// abortCategories maps the abort reasons to their category.
+var abortCategories = map[TransitionReason]AbortCategory{
+	ReasonOutOfBounds:      AbortOutOfBounds,
+	ReasonOffGrid:          AbortOutOfBounds,
+	ReasonObstacle:         AbortObstacle,
+	ReasonNoPath:           AbortObstacle,
+	ReasonCellBlocked:      AbortExecutionError,
+	ReasonBatteryDepleted:  AbortExecutionError,
+	ReasonNotAllowed:       AbortExecutionError,
+	ReasonCommandFailed:    AbortExecutionError,
+	ReasonTimeout:          AbortTimeout,
+	ReasonPreempted:        AbortInterrupted,
+	ReasonDispatcherFailed: AbortInterrupted,
+	ReasonRestored:         AbortInterrupted,
+	ReasonShutdown:         AbortInterrupted,
+}
+
+// AbortCategory returns the category of an abort reason, empty for the reasons of the other states.
+func (r TransitionReason) AbortCategory() AbortCategory {
+	return abortCategories[r]
+}
+
+// abortReasons maps the sentinel errors of failed commands to the reason of the abort.
+var abortReasons = []struct {
+	err    error
+	reason TransitionReason
+}{
+	{ErrOutOfBounds, ReasonOutOfBounds},
+	{ErrObstacle, ReasonObstacle},
+	{ErrCellBlocked, ReasonCellBlocked},
+	{ErrBatteryDepleted, ReasonBatteryDepleted},
+	{ErrRobotOffGrid, ReasonOffGrid},
+	{ErrNoPath, ReasonNoPath},
+	{ErrCommandNotAllowed, ReasonNotAllowed},
+}
+
+// abortReason returns the reason for aborting a task because of err.
+func abortReason(err error) TransitionReason {
+	for _, mapping := range abortReasons {
+		if errors.Is(err, mapping.err) {
+			return mapping.reason
+		}
+	}
+	return ReasonCommandFailed
+}
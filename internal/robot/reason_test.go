@@ -0,0 +1,179 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestTransitionReasons tests that every kind of transition records its reason on the task and in the event.
+func TestTransitionReasons(t *testing.T) {
+	tests := []struct {
+		name       string
+		commands   string
+		obstacles  []Coord
+		blocked    bool                              // The target cells are occupied
+		transition func(service *Service, id string) // Drives the task to its final state
+		wantState  TaskState
+		wantReason TransitionReason
+
+		wantCategory AbortCategory // Category of an aborted task, empty for the other states
+	}{
+		{
+			name:       "Submitted",
+			commands:   "N",
+			transition: func(service *Service, id string) {},
+			wantState:  Pending,
+			wantReason: ReasonSubmitted,
+		},
+		{
+			name:       "Completed normally",
+			commands:   "N E",
+			transition: func(service *Service, id string) { service.ExecuteTask(id) },
+			wantState:  Completed,
+			wantReason: ReasonCompleted,
+		},
+		{
+			name:       "User cancel",
+			commands:   "N",
+			transition: func(service *Service, id string) { service.CancelTask(id) },
+			wantState:  Canceled,
+			wantReason: ReasonUserCancel,
+		},
+		{
+			name:       "Out of bounds",
+			commands:   "S",
+			transition: func(service *Service, id string) { service.ExecuteTask(id) },
+			wantState:  Aborted,
+			wantReason: ReasonOutOfBounds,
+
+			wantCategory: AbortOutOfBounds,
+		},
+		{
+			name:       "Obstacle",
+			commands:   "N",
+			obstacles:  []Coord{{X: 0, Y: 1}},
+			transition: func(service *Service, id string) { service.ExecuteTask(id) },
+			wantState:  Aborted,
+			wantReason: ReasonObstacle,
+
+			wantCategory: AbortObstacle,
+		},
+		{
+			name:       "Cell blocked",
+			commands:   "N",
+			blocked:    true,
+			transition: func(service *Service, id string) { service.ExecuteTask(id) },
+			wantState:  Aborted,
+			wantReason: ReasonCellBlocked,
+
+			wantCategory: AbortExecutionError,
+		},
+		{
+			name:     "Timeout",
+			commands: "N",
+			transition: func(service *Service, id string) {
+				service.markTaskStarted(id)
+				service.UpdateTaskState(id, InProgress, ReasonDispatched)
+				service.config.Clock.(*fakeClock).Advance(time.Hour)
+				service.checkStuckTasks()
+			},
+			wantState:  Aborted,
+			wantReason: ReasonTimeout,
+
+			wantCategory: AbortTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Clock = newFakeClock()
+			config.Obstacles = tt.obstacles
+			config.StuckAbortGrace = time.Second
+			service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+			if tt.blocked {
+				service.cellBlocked = func(x, y int) bool { return true }
+			}
+			sub, err := service.Subscribe()
+			if err != nil {
+				t.Fatalf("Failed to subscribe: %v", err)
+			}
+			defer sub.Close()
+
+			taskID, err := service.EnqueueTask(tt.commands, "0s")
+			if err != nil {
+				t.Fatalf("Failed to enqueue task: %v", err)
+			}
+			tt.transition(service, taskID)
+
+			task := service.CurrentState().Tasks[taskID]
+			if task.State != tt.wantState || task.Reason != tt.wantReason {
+				t.Errorf("Expected %s with reason %q, got %s with reason %q", tt.wantState, tt.wantReason, task.State, task.Reason)
+			}
+			if task.AbortCategory != tt.wantCategory {
+				t.Errorf("Expected the abort category %q, got %q", tt.wantCategory, task.AbortCategory)
+			}
+
+			// Events are published asynchronously, wait for the one of the final state
+			timeout := time.After(2 * time.Second)
+			for {
+				select {
+				case event := <-sub.Events():
+					if event.TaskID != taskID || event.State != tt.wantState {
+						continue
+					}
+					if event.Reason != tt.wantReason {
+						t.Errorf("Expected the %s event with reason %q, got %q", tt.wantState, tt.wantReason, event.Reason)
+					}
+					if event.AbortCategory != tt.wantCategory {
+						t.Errorf("Expected the %s event with abort category %q, got %q", tt.wantState, tt.wantCategory, event.AbortCategory)
+					}
+					return
+				case <-timeout:
+					t.Fatalf("No %s event for task %s", tt.wantState, taskID)
+				}
+			}
+		})
+	}
+}
+
+// TestAbortReason tests the mapping of command errors to abort reasons.
+func TestAbortReason(t *testing.T) {
+	if got := abortReason(ErrBatteryDepleted); got != ReasonBatteryDepleted {
+		t.Errorf("Expected %q, got %q", ReasonBatteryDepleted, got)
+	}
+	if got := abortReason(ErrInvalidCommand); got != ReasonCommandFailed {
+		t.Errorf("Expected %q for an unmapped error, got %q", ReasonCommandFailed, got)
+	}
+}
+
+// TestAbortCategory tests that every abort reason has a category and the other reasons have none.
+func TestAbortCategory(t *testing.T) {
+	tests := []struct {
+		reason TransitionReason
+		want   AbortCategory
+	}{
+		{ReasonOutOfBounds, AbortOutOfBounds},
+		{ReasonOffGrid, AbortOutOfBounds},
+		{ReasonObstacle, AbortObstacle},
+		{ReasonNoPath, AbortObstacle},
+		{ReasonCellBlocked, AbortExecutionError},
+		{ReasonBatteryDepleted, AbortExecutionError},
+		{ReasonNotAllowed, AbortExecutionError},
+		{ReasonCommandFailed, AbortExecutionError},
+		{ReasonTimeout, AbortTimeout},
+		{ReasonPreempted, AbortInterrupted},
+		{ReasonDispatcherFailed, AbortInterrupted},
+		{ReasonRestored, AbortInterrupted},
+		{ReasonShutdown, AbortInterrupted},
+		{ReasonCompleted, ""},
+		{ReasonUserCancel, ""},
+		{ReasonCancelTimeout, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.reason.AbortCategory(); got != tt.want {
+			t.Errorf("%q.AbortCategory() = %q, want %q", tt.reason, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package robot
+
+import (
+	"fmt"
+	"log"
+)
+
+// ReplaceTask cancels a pending or running task and enqueues a new task with the commands of the spec in its place.
+// The replacement keeps the priority and the dispatch position of the replaced task, so no other task is
+// dispatched in between: it replaces a pending task in the queue and runs right after a running task stopped,
+// unless a higher priority task is waiting. Both happen under one lock, so either both take effect or neither:
+// every admission check runs before the task is cancelled, and the replacement never preempts a task to get in.
+// It fails with ErrTaskNotFound for unknown tasks and with ErrInvalidState for tasks that finished or are
+// already being cancelled.
+func (s *Service) ReplaceTask(taskID string, spec TaskSpec) (string, error) {
+	task, err := s.newTask(spec)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, exists := s.state.Tasks[taskID]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	if old.State != Pending && old.State != InProgress {
+		return "", fmt.Errorf("%w: task %s is '%s', only pending and running tasks can be replaced", ErrInvalidState, taskID, old.State)
+	}
+
+	task.Priority = old.Priority
+	task.QueueSeq = old.dispatchSeq()
+	task.Replaces = old.ID
+	if err := s.checkAdmissionLocked(task); err != nil {
+		return "", err
+	}
+	select {
+	case s.taskIdQueue <- task.ID:
+	default:
+		// The token of a replaced pending task stays queued and dispatches the replacement in its place
+		if old.State != Pending {
+			return "", fmt.Errorf("%w: %d tasks waiting", ErrQueueFull, len(s.taskIdQueue))
+		}
+	}
+	if err := s.cancelLocked(old, ReasonReplaced); err != nil {
+		return "", err
+	}
+	old = s.state.Tasks[taskID]
+	old.ReplacedBy = task.ID
+	s.state.Tasks[taskID] = old
+	s.storeTaskLocked(task)
+
+	log.Printf("Task %s replaced by task %s", taskID, task.ID)
+	return task.ID, nil
+}
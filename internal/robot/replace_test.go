@@ -0,0 +1,137 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestReplaceTask_Pending tests that a pending task is canceled and its replacement takes its place in the queue.
+func TestReplaceTask_Pending(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	first, _ := service.EnqueueTask("N", "0s")
+	second, _ := service.SubmitTask(TaskSpec{Commands: "N", Priority: 1})
+	third, _ := service.SubmitTask(TaskSpec{Commands: "N", Priority: 1})
+
+	replacement, err := service.ReplaceTask(second, TaskSpec{Commands: "E E", DelayBetweenCommands: "0s"})
+	if err != nil {
+		t.Fatalf("Failed to replace task: %v", err)
+	}
+
+	tasks := service.CurrentState().Tasks
+	if old := tasks[second]; old.State != Canceled || old.Reason != ReasonReplaced || old.ReplacedBy != replacement {
+		t.Errorf("Expected the replaced task canceled (%s) and replaced by %s, got %s (%s) replaced by %q", ReasonReplaced, replacement, old.State, old.Reason, old.ReplacedBy)
+	}
+	if task := tasks[replacement]; task.State != Pending || task.Replaces != second || task.Priority != 1 || task.Commands.String() != "E E" {
+		t.Errorf("Expected a pending replacement with priority 1 and commands E E, got %+v", task)
+	}
+
+	queue := service.PendingQueue()
+	var order []string
+	for _, queued := range queue {
+		order = append(order, queued.TaskID)
+	}
+	if len(order) != 3 || order[0] != replacement || order[1] != third || order[2] != first {
+		t.Errorf("Expected the dispatch order %v, got %v", []string{replacement, third, first}, order)
+	}
+}
+
+// TestReplaceTask_InProgress tests that a running task is canceled and its replacement runs right after it stopped,
+// before tasks that were already waiting.
+func TestReplaceTask_InProgress(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := NewService(ctx, make(chan string, 10))
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+	go service.Start()
+
+	running, _ := service.EnqueueTask("N N N N N", "50ms")
+	waitForState(t, sub, running, InProgress)
+	waiting, _ := service.EnqueueTask("N", "0s")
+
+	replacement, err := service.ReplaceTask(running, TaskSpec{Commands: "E", DelayBetweenCommands: "0s"})
+	if err != nil {
+		t.Fatalf("Failed to replace task: %v", err)
+	}
+
+	// Events are delivered concurrently and may arrive out of order, the start times record the dispatch order
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(time.Millisecond) {
+		tasks := service.CurrentState().Tasks
+		if tasks[replacement].State == Completed && tasks[waiting].State == Completed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the tasks to complete, got %s and %s", tasks[replacement].State, tasks[waiting].State)
+		}
+	}
+	tasks := service.CurrentState().Tasks
+	if started := tasks[replacement].StartedAt; !started.Before(*tasks[waiting].StartedAt) {
+		t.Errorf("Expected the replacement dispatched before the waiting task, started at %s and %s", started, tasks[waiting].StartedAt)
+	}
+	if task := tasks[running]; task.State != Canceled || task.Reason != ReasonReplaced {
+		t.Errorf("Expected the replaced task canceled (%s), got %s (%s)", ReasonReplaced, task.State, task.Reason)
+	}
+}
+
+// TestReplaceTask_Rejected tests that finished, unknown and invalid replacements leave the tasks untouched.
+func TestReplaceTask_Rejected(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	completed, _ := service.EnqueueTask("N", "0s")
+	service.UpdateTaskState(completed, Completed, ReasonCompleted)
+	pending, _ := service.EnqueueTask("N", "0s")
+
+	tests := []struct {
+		name    string
+		taskID  string
+		spec    TaskSpec
+		wantErr error
+	}{
+		{"Completed task", completed, TaskSpec{Commands: "E"}, ErrInvalidState},
+		{"Unknown task", "unknown", TaskSpec{Commands: "E"}, ErrTaskNotFound},
+		{"Invalid commands", pending, TaskSpec{Commands: "X"}, ErrInvalidCommand},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(service.CurrentState().Tasks)
+			if _, err := service.ReplaceTask(tt.taskID, tt.spec); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if after := len(service.CurrentState().Tasks); after != before {
+				t.Errorf("Expected no task to be added, got %d tasks instead of %d", after, before)
+			}
+		})
+	}
+	if state, _ := service.GetTaskState(pending); state != Pending {
+		t.Errorf("Expected the task to stay pending, got %s", state)
+	}
+}
+
+// TestReplaceTask_QueueFull tests that a replacement rejected by a full queue leaves the replaced task running,
+// even under load shedding, while a pending task is replaced through its queued token.
+func TestReplaceTask_QueueFull(t *testing.T) {
+	service, running := fillQueueWithRunningTask(t, true)
+	before := len(service.CurrentState().Tasks)
+
+	if _, err := service.ReplaceTask(running, TaskSpec{Commands: "E", DelayBetweenCommands: "0s", Priority: 5}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Expected ErrQueueFull, got %v", err)
+	}
+	task := service.CurrentState().Tasks[running]
+	if task.State != InProgress || task.ReplacedBy != "" {
+		t.Errorf("Expected the task to keep running, got %s replaced by '%s'", task.State, task.ReplacedBy)
+	}
+	if after := len(service.CurrentState().Tasks); after != before {
+		t.Errorf("Expected no task to be added, got %d tasks instead of %d", after, before)
+	}
+
+	pending, _ := service.nextPendingTask()
+	replacement, err := service.ReplaceTask(pending, TaskSpec{Commands: "E", DelayBetweenCommands: "0s"})
+	if err != nil {
+		t.Fatalf("Expected the pending task to be replaced, got %v", err)
+	}
+	<-service.taskIdQueue
+	if next, _ := service.nextPendingTask(); next != replacement {
+		t.Errorf("Expected the queued token to dispatch the replacement, got %s", next)
+	}
+}
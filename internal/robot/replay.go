@@ -0,0 +1,75 @@
+package robot
+
+// EventReplay holds the buffered events a resuming subscriber missed.
+type EventReplay struct {
+	Events    []TaskStatusUpdateEvent // Missed events in sequence order
+	Complete  bool                    // False if missed events are no longer buffered and the client must refetch the full state
+	LatestSeq uint64                  // Sequence number of the latest published event
+}
+
+// eventRing is a fixed size ring buffer of the most recently published events.
+type eventRing struct {
+	events []TaskStatusUpdateEvent
+	start  int // Index of the oldest event
+	count  int // Number of buffered events
+}
+
+func newEventRing(size int) *eventRing {
+	if size < 0 {
+		size = 0
+	}
+	return &eventRing{events: make([]TaskStatusUpdateEvent, size)}
+}
+
+// push appends the event, overwriting the oldest one when the buffer is full.
+func (r *eventRing) push(event TaskStatusUpdateEvent) {
+	if len(r.events) == 0 {
+		return
+	}
+	if r.count < len(r.events) {
+		r.events[(r.start+r.count)%len(r.events)] = event
+		r.count++
+		return
+	}
+	r.events[r.start] = event
+	r.start = (r.start + 1) % len(r.events)
+}
+
+// since returns the buffered events with a sequence number greater than seq.
+// It returns false if events after seq have already been overwritten.
+func (r *eventRing) since(seq, latestSeq uint64) ([]TaskStatusUpdateEvent, bool) {
+	if seq > latestSeq {
+		return nil, false // Sequence from a previous server run
+	}
+	if seq == latestSeq {
+		return []TaskStatusUpdateEvent{}, true
+	}
+	if r.count == 0 || r.events[r.start].Seq > seq+1 {
+		return nil, false
+	}
+
+	missed := make([]TaskStatusUpdateEvent, 0, latestSeq-seq)
+	for i := 0; i < r.count; i++ {
+		event := r.events[(r.start+i)%len(r.events)]
+		if event.Seq > seq {
+			missed = append(missed, event)
+		}
+	}
+	return missed, true
+}
+
+// SubscribeSince registers a new event subscriber resuming after the event with sequence number since.
+// The returned replay holds the buffered events the subscriber missed, live events follow on the subscription
+// without gaps or duplicates. If the missed events are no longer buffered, replay.Complete is false.
+func (s *Service) SubscribeSince(since uint64) (Subscription, EventReplay, error) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	sub, err := s.subscribeLocked()
+	if err != nil {
+		return nil, EventReplay{}, err
+	}
+
+	events, complete := s.eventLog.since(since, s.lastSeq)
+	return sub, EventReplay{Events: events, Complete: complete, LatestSeq: s.lastSeq}, nil
+}
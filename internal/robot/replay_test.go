@@ -0,0 +1,86 @@
+package robot
+
+import (
+	"context"
+	"testing"
+)
+
+// publishN publishes n events for the given task.
+func publishN(service *Service, taskID string, n int) {
+	for i := 0; i < n; i++ {
+		service.publishEvent(taskID, InProgress, "", "")
+	}
+}
+
+// TestSubscribeSinceWithinBuffer tests that a resuming subscriber gets exactly the missed events followed by live events.
+func TestSubscribeSinceWithinBuffer(t *testing.T) {
+	config := DefaultConfig()
+	config.EventBufferSize = 8
+	service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+	publishN(service, "task-1", 12)
+
+	sub, replay, err := service.SubscribeSince(7)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer sub.Close()
+
+	if !replay.Complete {
+		t.Fatal("Expected complete replay within buffer")
+	}
+	if replay.LatestSeq != 12 {
+		t.Errorf("Expected latest seq 12, got %d", replay.LatestSeq)
+	}
+	if len(replay.Events) != 5 {
+		t.Fatalf("Expected 5 replayed events, got %d", len(replay.Events))
+	}
+	for i, event := range replay.Events {
+		if event.Seq != uint64(8+i) {
+			t.Errorf("Replay position %d: expected seq %d, got %d", i, 8+i, event.Seq)
+		}
+	}
+
+	service.publishEvent("task-2", Completed, "", "")
+	live := <-sub.Events()
+	if live.Seq != 13 {
+		t.Errorf("Expected live event seq 13, got %d", live.Seq)
+	}
+}
+
+// TestSubscribeSinceTooOld tests that resuming from an overwritten or unknown sequence requires a resync.
+func TestSubscribeSinceTooOld(t *testing.T) {
+	config := DefaultConfig()
+	config.EventBufferSize = 4
+	service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+	publishN(service, "task-1", 10)
+
+	tests := []struct {
+		name         string
+		since        uint64
+		wantComplete bool
+		wantEvents   int
+	}{
+		{"Oldest buffered event missed", 6, true, 4},
+		{"Older than buffer", 5, false, 0},
+		{"From the start", 0, false, 0},
+		{"Up to date", 10, true, 0},
+		{"Sequence from a previous run", 99, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, replay, err := service.SubscribeSince(tt.since)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer sub.Close()
+
+			if replay.Complete != tt.wantComplete {
+				t.Errorf("Expected complete %v, got %v", tt.wantComplete, replay.Complete)
+			}
+			if len(replay.Events) != tt.wantEvents {
+				t.Errorf("Expected %d replayed events, got %d", tt.wantEvents, len(replay.Events))
+			}
+		})
+	}
+}
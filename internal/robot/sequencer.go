@@ -0,0 +1,55 @@
+package robot
+
+import "sync"
+
+// eventSequencer is the queue of the event writer in strict sequence mode. Events are queued while the state
+// change they report is applied, usually under the service lock, so the queue holds them in the order of the
+// changes. Queueing never blocks, the queue grows until the writer catches up.
+type eventSequencer struct {
+	mu     sync.Mutex
+	queue  []TaskStatusUpdateEvent
+	signal chan struct{} // Holds a token while the queue may be non-empty
+}
+
+func newEventSequencer() *eventSequencer {
+	return &eventSequencer{signal: make(chan struct{}, 1)}
+}
+
+// push appends the event to the queue and wakes the writer.
+func (q *eventSequencer) push(event TaskStatusUpdateEvent) {
+	q.mu.Lock()
+	q.queue = append(q.queue, event)
+	q.mu.Unlock()
+
+	select {
+	case q.signal <- struct{}{}:
+	default: // The writer is already woken
+	}
+}
+
+// drain removes and returns all queued events in order.
+func (q *eventSequencer) drain() []TaskStatusUpdateEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := q.queue
+	q.queue = nil
+	return events
+}
+
+// runEventWriter delivers the queued events one at a time until the service context is cancelled.
+// Being the only goroutine delivering events, it assigns the sequence numbers in queue order.
+func (s *Service) runEventWriter() {
+	for {
+		select {
+		case <-s.sequencer.signal:
+			for _, event := range s.sequencer.drain() {
+				s.deliver(event)
+			}
+		case <-s.ctx.Done():
+			for _, event := range s.sequencer.drain() {
+				s.deliver(event) // Flush the events of the last state changes
+			}
+			return
+		}
+	}
+}
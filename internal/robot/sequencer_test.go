@@ -0,0 +1,107 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestStrictSequence tests that in strict sequence mode concurrent state changes are delivered with gapless,
+// strictly increasing sequence numbers, in the order in which the changes of every task were made.
+func TestStrictSequence(t *testing.T) {
+	config := DefaultConfig()
+	config.StrictSequence = true
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+
+	const tasks, updates = 4, 20
+	taskIDs := make([]string, tasks)
+	for i := range taskIDs {
+		taskIDs[i], _ = service.EnqueueTask("N", "0s")
+	}
+
+	var wg sync.WaitGroup
+	for _, taskID := range taskIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service.UpdateTaskState(taskID, InProgress, ReasonDispatched)
+			for i := 0; i < updates; i++ {
+				service.UpdateTaskError(taskID, strconv.Itoa(i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Per task: Pending, InProgress and then the errors 0 to updates-1
+	want := tasks * (updates + 2)
+	next := make(map[string]int)
+	var last TaskStatusUpdateEvent
+	for received := 0; received < want; received++ {
+		var event TaskStatusUpdateEvent
+		select {
+		case event = <-sub.Events():
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out after %d of %d events", received, want)
+		}
+		if received > 0 && (event.Seq != last.Seq+1 || event.Timestamp.Before(last.Timestamp)) {
+			t.Fatalf("Expected event %d after event %d at %s, got event %d at %s", last.Seq+1, last.Seq, last.Timestamp, event.Seq, event.Timestamp)
+		}
+		last = event
+
+		step := next[event.TaskID]
+		next[event.TaskID]++
+		switch {
+		case step == 0 && event.State == Pending:
+		case step == 1 && event.State == InProgress && event.Error == "":
+		case step >= 2 && event.Error == strconv.Itoa(step-2):
+		default:
+			t.Fatalf("Unexpected event %d for task %s at step %d: state=%s error=%q", event.Seq, event.TaskID, step, event.State, event.Error)
+		}
+	}
+}
+
+// TestStrictSequence_FullBuffer tests that in strict sequence mode a subscriber with a full buffer is disconnected
+// right away instead of missing an event, so the events it received have no gap.
+func TestStrictSequence_FullBuffer(t *testing.T) {
+	config := DefaultConfig()
+	config.StrictSequence = true
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+
+	for i := 0; i <= subscriberBufferSize; i++ {
+		service.publishEvent("task-1", InProgress, "", "")
+	}
+
+	// Events are delivered by the writer, wait for it to disconnect the subscriber before reading any
+	for deadline := time.Now().Add(time.Second); sub.Err() == nil && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if !errors.Is(sub.Err(), ErrSubscriberLagging) {
+		t.Fatalf("Expected ErrSubscriberLagging, got %v", sub.Err())
+	}
+	var seqs []uint64
+	for event := range sub.Events() {
+		seqs = append(seqs, event.Seq)
+	}
+	if len(seqs) != subscriberBufferSize || seqs[0] != 1 || seqs[len(seqs)-1] != subscriberBufferSize {
+		t.Errorf("Expected events 1 to %d, got %d events: %v", subscriberBufferSize, len(seqs), seqs)
+	}
+}
+
+func TestStrictSequence_Config(t *testing.T) {
+	config := DefaultConfig()
+	config.StrictSequence = true
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected strict sequence mode to be valid, got %v", err)
+	}
+	config.EventCoalesceWindow = time.Second
+	if err := config.Validate(); err == nil {
+		t.Error("Expected strict sequence mode with an event coalesce window to be rejected")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,86 +19,440 @@ const (
 type RobotService interface {
 	EnqueueTask(commands string, delayBetweenCommands string) (taskID string, err error)
 
+	SubmitTask(spec TaskSpec) (taskID string, err error)
+	// SubmitTaskContext is SubmitTask for a caller that may give up, the task is not enqueued once ctx is done
+	SubmitTaskContext(ctx context.Context, spec TaskSpec) (taskID string, err error)
+	// SubmitBatch enqueues all tasks or none, validating them together against the projected robot positions
+	SubmitBatch(specs []TaskSpec) (taskIDs []string, err error)
+
+	// EnqueuePatrol enqueues a closed rectangular loop, the spec sets the options but not the commands of the task
+	EnqueuePatrol(width, height uint, spec TaskSpec) (taskID string, err error)
+	// EnqueuePing enqueues a task without commands that completes without moving the robot, for health checks
+	EnqueuePing() (taskID string, err error)
+	// Follow enqueues a task chasing a target cell, re-planning the path after every move
+	Follow(target Coord, spec TaskSpec) (taskID string, err error)
+	// UpdateFollowTarget moves the target of a follow task, nil clears it and stops the task
+	UpdateFollowTarget(taskID string, target *Coord) error
+	// EnqueueRunToWall enqueues the moves from the current position to the warehouse boundary or an obstacle in a direction
+	EnqueueRunToWall(direction string, spec TaskSpec) (taskID string, err error)
+	// SetCheckpoint records the current robot position as the checkpoint
+	SetCheckpoint() (Coord, error)
+	// ReturnToCheckpoint enqueues a task moving the robot back to the checkpoint along the shortest path
+	ReturnToCheckpoint(spec TaskSpec) (taskID string, err error)
+
 	CancelTask(taskID string) error
+	// UncancelTask restores a cancelled pending task within the uncancel window
+	UncancelTask(taskID string) error
+	// ReplaceTask cancels a pending or running task and enqueues a new one in its place in one step
+	ReplaceTask(taskID string, spec TaskSpec) (newTaskID string, err error)
+	// CancelGroup cancels all cancellable tasks of a group and returns how many were cancelled
+	CancelGroup(groupID string) (canceled int, err error)
+	// Group summarizes the states of the tasks of a group
+	Group(groupID string) (GroupSummary, error)
+	// WebhookStatus returns the delivery status of the completion webhook of a task
+	WebhookStatus(taskID string) (DeliveryStatus, error)
+	// TaskTrace returns the executed commands of a task with the robot position after each of them
+	TaskTrace(taskID string) ([]TaskStep, error)
+
+	Reset() (RobotState, error)
+	// Quiesce stops accepting new tasks while the queued tasks are processed to completion
+	Quiesce()
+	// Reachable returns the cells reachable within the given number of moves from the current position
+	Reachable(steps int) []Coord
+	// CellInfo reports whether a cell is inside the warehouse, an obstacle or occupied by the robot
+	CellInfo(cell Coord) CellInfo
+	// CellHistory returns the moves of tasks that brought the robot onto a cell, oldest first
+	CellHistory(cell Coord) ([]CellVisit, error)
+	// Grid returns the warehouse floor with the robot and the obstacles, see Grid.RenderASCII and Grid.RenderSVG
+	Grid() Grid
+	// Heatmap returns the cumulative time the robot spent in every cell
+	Heatmap() Heatmap
+	// RuntimeConfig returns the configuration the service runs with, with secrets redacted
+	RuntimeConfig() RuntimeConfig
+	// SetPosition moves the robot directly to the given cell, intended for tests and demos only
+	SetPosition(x, y int) (RobotState, error)
+	// Snapshot returns the complete serializable service state
+	Snapshot() Snapshot
+	// Restore replaces the robot state and all tasks with a snapshot, failing while a task is running
+	Restore(snapshot Snapshot) error
 
 	CurrentState() ServiceState
-
-	GetEventChannel() <-chan TaskStatusUpdateEvent
+	// GetRobotState returns the robot position and heading without copying the tasks
+	GetRobotState() RobotState
+	// TaskStates returns the states of the given tasks, unknown task IDs are left out
+	TaskStates(taskIDs []string) map[string]TaskState
+	// GetTaskState returns the state of a task, or ErrTaskNotFound
+	GetTaskState(taskID string) (TaskState, error)
+
+	PendingQueue() []QueuedTask
+	// QueueETA estimates how long it takes until the running and pending tasks are finished
+	QueueETA() QueueETA
+	// ListTasks returns a page of tasks with a sequence number greater than cursor
+	ListTasks(cursor, limit int) TaskPage
+	// RecentAborts returns the latest aborted tasks with their reasons, newest first
+	RecentAborts(limit int) []AbortSummary
+	// PurgeTasks removes all finished tasks and returns how many were removed
+	PurgeTasks() int
+
+	Stats() ServiceStats
+	// Started reports whether the service is processing its task queue
+	Started() bool
+
+	Subscribe() (Subscription, error)
+	// SubscribeSince subscribes and returns the buffered events published after the given sequence number
+	SubscribeSince(since uint64) (Subscription, EventReplay, error)
+	// SubscribeTelemetry streams the robot state once per interval of the service clock, see Config.Clock
+	SubscribeTelemetry(interval time.Duration) (TelemetrySubscription, error)
+	// Closing returns a channel closed once the subscriptions are closed for shutdown
+	Closing() <-chan struct{}
 }
 
 // Websocket response for task status updates.
 // @Description Websocket response for task status updates.
 type TaskStatusUpdateEvent struct {
-	TaskID    string    `json:"task_id" example:"12345"`                         // Unique identifier for the task
-	State     TaskState `json:"state" swaggertype:"string" example:"InProgress"` // Current state of the task
-	Error     string    `json:"error,omitempty" example:""`                      // Error message if any
-	Timestamp time.Time `json:"timestamp" example:"2024-01-15T10:30:00Z"`        // Timestamp when the event occurred
+	Seq       uint64           `json:"seq" example:"42"`                                           // Sequence number of the event, increases by one per event
+	TaskID    string           `json:"task_id" example:"12345"`                                    // Unique identifier for the task
+	State     TaskState        `json:"state" swaggertype:"string" example:"InProgress"`            // Current state of the task
+	Error     string           `json:"error,omitempty" example:""`                                 // Error message if any
+	Reason    TransitionReason `json:"reason,omitempty" swaggertype:"string" example:"dispatched"` // Why the task entered the state, see TransitionReason
+	Timestamp time.Time        `json:"timestamp" example:"2024-01-15T10:30:00Z"`                   // Timestamp when the event occurred
+
+	CorrelationID string `json:"correlation_id,omitempty" example:"req-7f3a"` // Request ID of the API call that created the task, if recorded
+
+	AbortCategory AbortCategory `json:"abort_category,omitempty" swaggertype:"string" example:"Obstacle"` // Kind of failure, only set on the Aborted event, see AbortCategory
+
+	Progress *TaskProgress `json:"progress,omitempty"` // How far an aborted task got, only set on the Aborted event
+
+	Type      string             `json:"type,omitempty" example:"move"` // "move", "progress", "busy" or "idle" for move events, heartbeats and dispatcher activity, omitted for task state events
+	Move      *MoveEvent         `json:"move,omitempty"`                // The move of a move event
+	Heartbeat *ProgressHeartbeat `json:"heartbeat,omitempty"`           // The progress of a progress heartbeat
 }
 
 type Service struct {
-	mu          sync.RWMutex               // Mutex for concurrent access
-	ctx         context.Context            // Context for cancellation
-	state       ServiceState               // Current state of the robot service
-	taskIdQueue chan string                // Channel for incoming tasks
-	eventChan   chan TaskStatusUpdateEvent // Channel for broadcasting task status updates
+	mu          sync.RWMutex    // Mutex for concurrent access
+	ctx         context.Context // Context for cancellation
+	config      Config          // Service configuration
+	state       ServiceState    // Current state of the robot service
+	taskIdQueue chan string     // Channel for incoming tasks
+	started     atomic.Bool     // Set once Start entered the dispatch loop, see Started
+
+	subMu             sync.Mutex               // Mutex protecting the subscribers set and the event log
+	subscribers       map[*subscriber]struct{} // Active event subscribers
+	activeSubscribers atomic.Int64             // Number of active event subscribers
+	eventsDropped     atomic.Uint64            // Number of events not delivered to a subscriber with a full buffer
+	subClosed         bool                     // Set once the subscriptions are closed for shutdown
+	closing           chan struct{}            // Closed together with the subscriptions, see Closing
+	lastSeq           uint64                   // Sequence number of the latest published event
+
+	obstacles map[Coord]struct{} // Cells the robot can never enter
+	drained   chan struct{}      // Closed once a quiesce finished all queued tasks
+	busy      bool               // Set while the dispatcher has work, see markBusy and checkIdle
+	lastTask  string             // ID of the task dispatched most recently
+
+	commandRate rateCounter         // Executed commands within the throughput window
+	taskRate    rateCounter         // Completed tasks within the throughput window
+	cellBlocked func(x, y int) bool // Reports whether a cell is temporarily occupied, nil if cells are never blocked
+	executing   executingTask       // Progress of the running task, kept for aborting it if the dispatch loop dies
+	eventLog    *eventRing          // Recent events kept for replay on reconnect
+	dwell       *dwellTracker       // Time the robot spent per cell, see Heatmap
+	checkpoint  *Coord              // Cell ReturnToCheckpoint moves the robot back to, nil until set
+
+	webhook *webhookNotifier // Posts the terminal event of every task, nil without a webhook URL
+
+	uncancels map[string]time.Time // Times pending tasks were cancelled, reversible for the uncancel window, see UncancelTask
+
+	coalesceMu sync.Mutex                       // Mutex protecting the coalesced events
+	coalesced  map[string]TaskStatusUpdateEvent // Latest held back event per task, see coalesce
+
+	sequencer *eventSequencer // Queue of the single event writer in strict sequence mode, nil otherwise
 }
 
-// NewService initializes a new robot service with an empty state and a task channel.
+// NewService initializes a new robot service with an empty state, a task channel and the default configuration.
 func NewService(ctx context.Context, taskIdQueue chan string) *Service {
-	return &Service{
+	return NewServiceWithConfig(ctx, taskIdQueue, DefaultConfig())
+}
+
+// NewServiceWithConfig initializes a new robot service with an empty state, a task channel and the given configuration.
+// The caller validates the configuration, see Config.Validate.
+func NewServiceWithConfig(ctx context.Context, taskIdQueue chan string, config Config) *Service {
+	if config.IDGenerator == nil {
+		config.IDGenerator = UUIDGenerator{} // Random task IDs by default
+	}
+	if config.TaskIDPrefix != "" {
+		config.IDGenerator = PrefixedIDGenerator{Prefix: config.TaskIDPrefix, Generator: config.IDGenerator}
+	}
+	if config.Clock == nil {
+		config.Clock = realClock{}
+	}
+	if config.TimeScale > 0 && config.TimeScale != 1 {
+		config.Clock = NewScaledClock(config.Clock, config.TimeScale) // Simulated time for demos
+	}
+	if config.WebhookMaxAttempts <= 0 {
+		config.WebhookMaxAttempts = 1 // At least the first attempt is made
+	}
+	if config.StepSize == 0 {
+		config.StepSize = 1 // One cell per command by default
+	}
+
+	state := NewServiceState(config.InitialHeading) // Initialize the service state
+	state.Battery = config.BatteryCapacity          // Start with a full battery
+
+	service := &Service{
 		ctx:         ctx,
-		state:       NewServiceState(),                     // Initialize the service state
-		taskIdQueue: taskIdQueue,                           // Buffered channel for tasks
-		eventChan:   make(chan TaskStatusUpdateEvent, 100), // Buffered channel for events
+		config:      config,
+		state:       state,
+		taskIdQueue: taskIdQueue,                          // Buffered channel for tasks
+		subscribers: make(map[*subscriber]struct{}),       // Event subscribers
+		eventLog:    newEventRing(config.EventBufferSize), // Recent events for replay
+		obstacles:   newObstacleSet(config.Obstacles),     // Permanently blocked cells
+		drained:     make(chan struct{}),
+		closing:     make(chan struct{}),
+		coalesced:   make(map[string]TaskStatusUpdateEvent), // Held back events per task
+		uncancels:   make(map[string]time.Time),
+		dwell:       newDwellTracker(Coord{X: int(state.RobotState.X), Y: int(state.RobotState.Y)}, config.Clock.Now()),
+	}
+	if config.WebhookURL != "" {
+		service.webhook = newWebhookNotifier(config)
+	}
+	if config.StrictSequence {
+		service.sequencer = newEventSequencer()
+		go service.runEventWriter()
+	}
+	return service
+}
+
+// NewEmbeddedService creates a robot service that owns its task queue and starts processing tasks right away.
+// It is meant for running the service without the HTTP layer, and fails if the configuration does not pass Validate.
+// The service stops when ctx is cancelled.
+func NewEmbeddedService(ctx context.Context, config Config) (RobotService, error) {
+	service, err := newEmbeddedService(ctx, config)
+	if err != nil {
+		return nil, err // Not the nil *Service, which makes a non-nil interface
+	}
+	return service, nil
+}
+
+// newEmbeddedService is NewEmbeddedService returning the concrete service.
+func newEmbeddedService(ctx context.Context, config Config) (*Service, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultConfig().QueueSize
 	}
+
+	service := NewServiceWithConfig(ctx, make(chan string, queueSize), config)
+	go service.Start()
+	return service, nil
 }
 
-// Start begins processing tasks from the task queue.
+// Start begins processing tasks from the task queue until the context is cancelled.
+// Every pending task is immediately eligible, so the loop blocks on the queue until a task is submitted
+// and never polls while idle. The dispatch loop is restarted if it dies unexpectedly, see superviseDispatchLoop.
 func (s *Service) Start() {
 	log.Println("Robot Service Started...")
 
+	if s.config.StuckAbortGrace > 0 || s.config.CancelGrace > 0 || s.config.UncancelWindow > 0 {
+		go s.monitorStuckTasks()
+	}
+	if s.webhook != nil {
+		go s.webhook.run(s.ctx)
+	}
+
+	s.superviseDispatchLoop()
+}
+
+// Started reports whether Start has entered the dispatch loop. Tasks enqueued before are kept in the queue,
+// but nothing executes them yet, so callers can hold back work until the service has started.
+func (s *Service) Started() bool {
+	return s.started.Load()
+}
+
+// dispatchLoop executes one pending task per queued token until the context is cancelled.
+func (s *Service) dispatchLoop() {
+	s.started.Store(true)
 	for {
 		select {
 		case <-s.ctx.Done():
 			log.Println("Robot Service Stopping...")
 			return // Exit if the context is cancelled
-		case taskId := <-s.taskIdQueue:
+		case <-s.taskIdQueue:
+			// Every queued ID is a token for one dispatch, the next task is picked by priority
+			taskId, ok := s.nextPendingTask()
+			if !ok {
+				s.checkDrained()
+				s.checkIdle()
+				continue // The queued task has been cancelled meanwhile
+			}
+			s.markBusy(taskId)
 			err := s.ExecuteTask(taskId) // Process incoming tasks
 			if err != nil {
 				log.Printf("Error handling task %s: %v", taskId, err)
 			}
+			s.checkDrained()
+			s.checkIdle()
 		}
 	}
 }
 
-// GetState returns the current state of the robot service.
+// CurrentState returns a snapshot of the current state of the robot service.
+// The returned state is a copy and can be used freely while the service keeps running.
 func (s *Service) CurrentState() ServiceState {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.state
+
+	state := s.state
+	state.Tasks = make(map[string]RobotTask, len(s.state.Tasks))
+	for id, task := range s.state.Tasks {
+		state.Tasks[id] = task
+	}
+	return state
 }
 
 func (s *Service) EnqueueTask(commands string, delayBetweenCommands string) (string, error) {
-	task, err := NewTask(commands, delayBetweenCommands)
+	return s.SubmitTask(TaskSpec{Commands: commands, DelayBetweenCommands: delayBetweenCommands})
+}
+
+// SubmitTask creates a task from the given spec and adds it to the queue.
+func (s *Service) SubmitTask(spec TaskSpec) (string, error) {
+	return s.SubmitTaskContext(context.Background(), spec)
+}
+
+// SubmitTaskContext submits a task unless ctx is done. The context is checked again once the lock is held,
+// a caller that gave up while waiting for it does not leave a task behind.
+func (s *Service) SubmitTaskContext(ctx context.Context, spec TaskSpec) (string, error) {
+	task, err := s.newTask(spec)
 	if err != nil {
 		return "", err
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	// Update the service state with the new task
 
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("task not submitted: %w", err)
+	}
+	if err := s.admitLocked(task); err != nil {
+		return "", err
+	}
+	s.storeTaskLocked(task)
+	return task.ID, nil
+}
+
+// newTask creates a task from the spec with the service's ID generator and checks it against the service limits.
+func (s *Service) newTask(spec TaskSpec) (*RobotTask, error) {
+	spec.CaseInsensitive = spec.CaseInsensitive || s.config.CaseInsensitiveCommands
+	task, err := newTask(spec, s.config.IDGenerator, s.config.AllowEmptyTasks)
+	if err != nil {
+		return nil, err
+	}
+	if task.Frame == "" {
+		task.Frame, _ = ParseCommandFrame(string(s.config.CommandFrame))
+	}
+	// Relative commands are checked as they would run now, the heading at dispatch is checked again in ExecuteTask
+	resolved := task.Commands
+	if task.Frame == FrameRelative {
+		resolved = resolved.relativeTo(s.GetRobotState().Heading)
+	}
+	if err := checkAllowed(resolved, task.AllowedCommands); err != nil {
+		return nil, err
+	}
+	// Every command moves the robot StepSize cells, so few commands can still make a long path
+	if length := task.Commands.moves() * s.config.StepSize; s.config.MaxPathLength > 0 && length > s.config.MaxPathLength {
+		return nil, fmt.Errorf("%w: %d commands traverse %d cells at %d cells per command, at most %d are allowed",
+			ErrPathTooLong, task.Commands.moves(), length, s.config.StepSize, s.config.MaxPathLength)
+	}
+	return task, nil
+}
+
+// admitLocked checks that the service takes the task and queues its dispatch token, so a rejected task
+// leaves the state untouched. The task must be stored with storeTaskLocked before the lock is released.
+// The caller must hold the service lock.
+func (s *Service) admitLocked(task *RobotTask) error {
+	if err := s.checkAdmissionLocked(task); err != nil {
+		return err
+	}
+
+	// Send the task to the queue first, so a full queue leaves the state untouched.
+	// The dispatcher cannot pick the token up before the task is stored, as it needs the lock.
+	select {
+	case s.taskIdQueue <- task.ID:
+	default:
+		// Under load shedding a higher priority task may take the place of the running one
+		if !s.config.PreemptOnOverload || !s.preemptForLocked(task.Priority) {
+			return fmt.Errorf("%w: %d tasks waiting", ErrQueueFull, len(s.taskIdQueue))
+		}
+		go s.dispatchWhenFree(task.ID)
+	}
+	return nil
+}
+
+// checkAdmissionLocked returns the error admitLocked fails with before the task reaches the queue, without
+// changing the state. It gives the task a free ID if its ID is taken. The caller must hold the service lock.
+func (s *Service) checkAdmissionLocked(task *RobotTask) error {
+	if err := s.acceptingLocked(); err != nil {
+		return err
+	}
+	if err := s.assignFreeIDLocked(task); err != nil {
+		return err
+	}
+	if s.config.MaxTasks > 0 && len(s.state.Tasks) >= s.config.MaxTasks {
+		return fmt.Errorf("%w: %d tasks stored, purge finished tasks first", ErrTaskLimit, len(s.state.Tasks))
+	}
+	// A replacement takes the place of a replaced pending task, so it does not add one
+	if replaced, exists := s.state.Tasks[task.Replaces]; !exists || replaced.State != Pending {
+		if err := s.checkPendingLocked(1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignFreeIDLocked draws new IDs for the task while its ID is taken, e.g. by a task restored from a snapshot
+// of an earlier run numbering its tasks with a SequentialIDGenerator. A generator that keeps producing taken IDs
+// fails with ErrInvalidState instead of overwriting a stored task. The caller must hold the service lock.
+func (s *Service) assignFreeIDLocked(task *RobotTask) error {
+	for attempt := 0; ; attempt++ {
+		if _, taken := s.state.Tasks[task.ID]; !taken {
+			return nil
+		}
+		if attempt > len(s.state.Tasks) {
+			return fmt.Errorf("%w: task ID %s is already taken", ErrInvalidState, task.ID)
+		}
+		task.ID = s.config.IDGenerator.NewID()
+	}
+}
+
+// checkPendingLocked returns ErrPendingLimit if adding the given number of pending tasks exceeds MaxPendingTasks.
+// The caller must hold the service lock.
+func (s *Service) checkPendingLocked(added int) error {
+	if s.config.MaxPendingTasks == 0 {
+		return nil
+	}
+	pending := 0
+	for _, task := range s.state.Tasks {
+		if task.State == Pending {
+			pending++
+		}
+	}
+	if pending+added > s.config.MaxPendingTasks {
+		return fmt.Errorf("%w: %d tasks pending, %d more exceed the limit of %d", ErrPendingLimit, pending, added, s.config.MaxPendingTasks)
+	}
+	return nil
+}
+
+// storeTaskLocked assigns the next sequence number to an admitted task and adds it to the state.
+// The caller must hold the service lock.
+func (s *Service) storeTaskLocked(task *RobotTask) {
 	s.state.CurTaskCount++                  // Increment the current task count
 	task.SequenceNum = s.state.CurTaskCount // Assign a sequence number to the task
 	s.state.Tasks[task.ID] = *task
-	s.taskIdQueue <- task.ID // Send the task to the queue
 
-	log.Printf("Task %s enqueued with commands: '%s', delay between commands: '%s' ", task.ID, commands, task.DelayBetweenCommands)
+	log.Printf("Task %s enqueued with commands: '%s', delay between commands: '%s', priority: %d", task.ID, task.Commands, task.DelayBetweenCommands, task.Priority)
 
 	// Publish event for new task creation
-	go s.publishEvent(task.ID, task.State, "")
-
-	return task.ID, nil
+	s.publishEventAsync(task.ID, task.State, task.Reason, "")
 }
 
 func (s *Service) CancelTask(taskID string) error {
@@ -106,43 +461,103 @@ func (s *Service) CancelTask(taskID string) error {
 
 	task, exists := s.state.Tasks[taskID]
 	if !exists {
-		return fmt.Errorf("task with ID %s not found", taskID)
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
 	}
+	return s.cancelLocked(task, ReasonUserCancel)
+}
 
+// cancelLocked cancels a pending task or requests the cancellation of a running one for the reason.
+// Only a user cancel of a pending task can be reversed, see UncancelTask. The caller must hold the service lock.
+func (s *Service) cancelLocked(task RobotTask, reason TransitionReason) error {
+	taskID := task.ID
 	switch task.State {
 	case InProgress:
 		// Update the task state to RequestCancellation
 		log.Printf("Task %s is in progress, requesting cancellation", taskID)
 		task.State = RequestCancellation
+		task.Reason = reason
+		requestedAt := s.config.Clock.Now()
+		task.CancelRequestedAt = &requestedAt
 		s.state.Tasks[taskID] = task // Update the task in the state
 
 		// Publish event for cancellation request
-		go s.publishEvent(taskID, RequestCancellation, "")
+		s.publishEventAsync(taskID, RequestCancellation, task.Reason, "")
 
 	case Pending:
+		task.Error = "Pending Task cancelled by user"
+		task.Reason = reason
+		if reason == ReasonUserCancel && s.config.UncancelWindow > 0 {
+			s.holdCancelLocked(task)
+			return nil
+		}
+
 		// If the task is pending, we simply mark it as Canceled
 		log.Printf("Task %s is pending, marking as Canceled", taskID)
-		task.Error = "Pending Task cancelled by user"
 		task.State = Canceled
 		s.state.Tasks[taskID] = task // Update the task in the state
 
 		// Publish event for immediate cancellation
-		go s.publishEvent(taskID, Canceled, task.Error)
+		s.publishEventAsync(taskID, Canceled, task.Reason, task.Error)
+
+	case Canceled, RequestCancellation, CancelPending:
+		// Cancelling twice is harmless, callers may treat this as success
+		return fmt.Errorf("%w: task %s is '%s'", ErrAlreadyCanceled, taskID, task.State)
 
 	default:
-		return fmt.Errorf("task %s is '%s' state and cannot be cancelled", taskID, task.State)
+		return fmt.Errorf("%w: task %s is '%s' state and cannot be cancelled", ErrInvalidState, taskID, task.State)
 	}
 
 	return nil
 }
 
+// Reset moves the robot back to the origin facing the configured initial heading.
+// It fails while a task is being executed, queued tasks are kept.
+func (s *Service) Reset() (RobotState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task, running := s.runningTaskLocked(); running {
+		return s.state.RobotState, fmt.Errorf("%w: cannot reset while task %s is '%s'", ErrInvalidState, task.ID, task.State)
+	}
+
+	s.state.RobotState = NewServiceState(s.config.InitialHeading).RobotState
+	s.state.Battery = s.config.BatteryCapacity // The origin is the home cell, recharge
+	s.trackDwellLocked()
+	log.Printf("Robot reset to (%d, %d) facing %s", s.state.RobotState.X, s.state.RobotState.Y, s.state.RobotState.Heading)
+	return s.state.RobotState, nil
+}
+
+// runningTaskLocked returns the task being executed, if any. The caller must hold the service lock.
+func (s *Service) runningTaskLocked() (RobotTask, bool) {
+	for _, task := range s.state.Tasks {
+		if task.State == InProgress || task.State == RequestCancellation {
+			return task, true
+		}
+	}
+	return RobotTask{}, false
+}
+
+// SetPosition moves the robot directly to the given cell without executing commands, keeping its heading.
+// It is meant for tests and demos and fails if the cell is outside the warehouse.
+func (s *Service) SetPosition(x, y int) (RobotState, error) {
+	if x < 0 || x >= warehouseSize || y < 0 || y >= warehouseSize {
+		return s.GetRobotState(), fmt.Errorf("%w: position (%d, %d) is outside the %dx%d warehouse", ErrOutOfBounds, x, y, warehouseSize, warehouseSize)
+	}
+
+	robotState := s.GetRobotState()
+	robotState.X, robotState.Y = uint(x), uint(y)
+	s.SetRobotState(robotState)
+	log.Printf("Robot position forced to (%d, %d)", x, y)
+	return robotState, nil
+}
+
 func (s *Service) ExecuteTask(taskId string) error {
 	s.mu.RLock()
 	task, exists := s.state.Tasks[taskId]
 	s.mu.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("Task %s not found in service state", taskId)
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskId)
 	}
 
 	// The task should be in pending state when it is handled
@@ -150,20 +565,76 @@ func (s *Service) ExecuteTask(taskId string) error {
 		return fmt.Errorf("Task %s is not in Pending state, current state: %s", task.ID, task.State)
 	}
 
+	// A cancel may have raced the dispatch since the state was read
+	if !s.swapTaskState(task.ID, Pending, InProgress, ReasonDispatched) {
+		return fmt.Errorf("Task %s left the Pending state before it started", task.ID)
+	}
 	log.Println("Started task:", task.ID)
-	s.UpdateTaskState(task.ID, InProgress)
+	s.markTaskStarted(task.ID)
+	s.markExecuted(task.ID, 0)
+
+	// A robot placed outside the warehouse must be moved back before it runs tasks again
+	if err := s.checkOnGrid(); err != nil {
+		s.recordProgress(task.ID, 0)
+		s.UpdateTaskState(task.ID, Aborted, abortReason(err))
+		s.UpdateTaskError(task.ID, fmt.Sprintf("Task cannot run: %v, marking as Aborted", err))
+		return fmt.Errorf("Task %s cannot be processed: %w", task.ID, err)
+	}
+
+	// Relative commands depend on the heading of the robot when the task starts
+	task = s.resolveFrame(task)
+
+	// Check if task can be processed, robot must not cross the warehouse boundaries or enter an obstacle on the way.
+	// The tasks before it may have moved the robot since the task was submitted, its policy decides what happens then
+	if err := s.checkPath(task.Commands); err != nil {
+		switch task.OnInvalid {
+		case InvalidSkip:
+			s.UpdateTaskState(task.ID, Canceled, ReasonSkippedInvalid)
+			s.UpdateTaskError(task.ID, fmt.Sprintf("Task is invalid: %v, skipped", err))
+			log.Printf("Task %s skipped, it is invalid from the current position: %v", task.ID, err)
+			return nil
+		case InvalidReplan:
+			commands, replanErr := s.replan(task)
+			if replanErr != nil {
+				err = fmt.Errorf("%w, re-planning failed: %v", err, replanErr)
+				break
+			}
+			task.Commands, err = commands, nil
+		}
+		if err != nil {
+			s.recordProgress(task.ID, 0)
+			s.UpdateTaskState(task.ID, Aborted, abortReason(err))
+			s.UpdateTaskError(task.ID, fmt.Sprintf("Task is invalid: %v, marking as Aborted", err))
+			return fmt.Errorf("Task %s is invalid and cannot be processed: %w", task.ID, err)
+		}
+	}
+
+	// The frame and a re-plan may have changed the commands since the allowed set was checked at submission
+	if err := checkAllowed(task.Commands, task.AllowedCommands); err != nil {
+		s.recordProgress(task.ID, 0)
+		s.UpdateTaskState(task.ID, Aborted, abortReason(err))
+		s.UpdateTaskError(task.ID, fmt.Sprintf("Task is not allowed: %v, marking as Aborted", err))
+		return fmt.Errorf("Task %s is not allowed and cannot be processed: %w", task.ID, err)
+	}
+
+	// The robot must not run out of battery away from the home cell
+	if err := s.checkBattery(task.Commands); err != nil {
+		s.recordProgress(task.ID, 0)
+		s.UpdateTaskState(task.ID, Aborted, abortReason(err))
+		s.UpdateTaskError(task.ID, fmt.Sprintf("Task is invalid: %v, marking as Aborted", err))
+		return fmt.Errorf("Task %s cannot be processed: %w", task.ID, err)
+	}
 
-	// Check if task can be processed, robot must not be crossing the warehouse boundaries
-	if !s.IsTaskValid(task) {
-		s.UpdateTaskState(task.ID, Aborted)
-		s.UpdateTaskError(task.ID, "Task is invalid: out of warehouse boundaries, marking as Aborted")
-		return fmt.Errorf("Task %s is invalid and cannot be processed", task.ID)
+	// A follow task plans its moves while it runs
+	if task.Follow {
+		return s.executeFollow(task)
 	}
 
 	// Run the task processing logic here
 	// Keep on updating the robot state based on the commands in the task
 	log.Printf("Processing task %s with commands: %s", task.ID, task.Commands)
-	for _, cmd := range task.Commands {
+	cadence := s.newProgressCadence()
+	for executed, cmd := range task.Commands {
 
 		// Make sure if the task is requested for cancellation, we stop processing
 		state, err := s.GetTaskState(task.ID)
@@ -173,85 +644,211 @@ func (s *Service) ExecuteTask(taskId string) error {
 
 		if state == RequestCancellation {
 			log.Printf("Task %s has been requested for cancellation", task.ID)
-			s.UpdateTaskError(task.ID, "Task cancellation requested by user")
-			s.UpdateTaskState(task.ID, Canceled)
+			s.finishCancellation(task.ID)
 			return nil // Stop processing the task if cancellation is requested
 		}
 
-		// Simulate delay between commands
-		time.Sleep(time.Duration(task.DelayBetweenCommands))
+		if state == Aborted {
+			s.recordProgress(task.ID, executed)
+			return fmt.Errorf("Task %s was aborted during execution", task.ID) // Aborted by the stuck task monitor
+		}
+
+		if state == Canceled {
+			return nil // Force-canceled by the monitor after the cancellation request went unnoticed too long
+		}
+
+		// Simulate delay between commands, longer for the first ones while the robot speeds up
+		started := s.config.Clock.Now()
+		s.config.Clock.Sleep(s.stepDelay(task, executed))
+
+		// A hold keeps the robot in place for its duration instead of moving it
+		if hold, ok := cmd.Hold(); ok {
+			if !s.waitHold(task.ID, hold) {
+				if s.ctx.Err() != nil {
+					s.UpdateTaskError(task.ID, fmt.Sprintf("Command '%s' interrupted by the service shutdown", cmd))
+					s.recordProgress(task.ID, executed)
+					s.UpdateTaskState(task.ID, Aborted, ReasonShutdown)
+					return fmt.Errorf("Task %s was interrupted by the service shutdown", task.ID)
+				}
+				continue // Cancelled or aborted, the checks before the next command or the completion handle it
+			}
+			s.recordCommand()
+			s.recordStep(task.ID, executed, cmd, started)
+			s.markExecuted(task.ID, executed+1)
+			s.reportProgress(task.ID, cadence, executed+1, len(task.Commands))
+			log.Printf("Command '%s' Executed Robot held in place", cmd)
+			continue
+		}
 
-		// Execute each command in the task
-		err = s.ExecuteRobotCommand(cmd)
+		// Execute each command in the task, transient failures are retried
+		from := s.GetRobotState()
+		s.startMove(task.ID, cmd, from)
+		err = s.executeWithRetry(task.ID, cmd)
 
 		if err != nil {
 			s.UpdateTaskError(task.ID, fmt.Sprintf("Error executing command '%s': %v", cmd, err))
-			s.UpdateTaskState(task.ID, Aborted) // Update the task state to Aborted
+			s.recordProgress(task.ID, executed)
+			s.UpdateTaskState(task.ID, Aborted, abortReason(err)) // Update the task state to Aborted
 			return fmt.Errorf("Error executing command '%s' for task %s: %v", cmd, task.ID, err)
 		}
 
+		s.recordCommand()
+		s.recordStep(task.ID, executed, cmd, started)
+		s.markExecuted(task.ID, executed+1)
+		s.finishMove(task.ID, cmd, from)
+		s.reportProgress(task.ID, cadence, executed+1, len(task.Commands))
 		robotState := s.GetRobotState() // Get the current robot state after executing the command
 		log.Printf("Command '%s' Executed Robot moved to position: (%d, %d)", cmd, robotState.X, robotState.Y)
 	}
 
-	// Update the task state to Completed
-	s.UpdateTaskState(task.ID, Completed)
-	log.Printf("Task %s completed successfully", task.ID)
+	// Complete the task in one step, it may have been aborted, force-canceled or asked to cancel
+	// while executing the last command
+	switch s.completeTask(task.ID, ReasonCompleted) {
+	case Aborted:
+		s.recordProgress(task.ID, len(task.Commands))
+		return fmt.Errorf("Task %s was aborted during execution", task.ID)
+	case Completed:
+		s.recordTaskCompleted()
+		log.Printf("Task %s completed successfully", task.ID)
+	}
 
 	return nil
 }
 
+// holdCheckInterval is how often a hold checks whether its task was cancelled or the service is shutting down.
+const holdCheckInterval = 100 * time.Millisecond
+
+// waitHold keeps the robot in place for the hold and reports whether the hold ran to its end. It stops early
+// once the task leaves InProgress, e.g. when it is asked to cancel, or when the service context is cancelled,
+// so a long hold does not delay either of them.
+func (s *Service) waitHold(taskID string, hold time.Duration) bool {
+	for remaining := hold; remaining > 0; {
+		step := min(remaining, holdCheckInterval)
+		s.config.Clock.Sleep(step)
+		remaining -= step
+
+		if s.ctx.Err() != nil {
+			return false
+		}
+		if state, _ := s.GetTaskState(taskID); state != InProgress {
+			return false
+		}
+	}
+	return true
+}
+
 // Execute a robot command and update the robot's position
 func (s *Service) ExecuteRobotCommand(cmd RobotCommand) error {
+	spec, ok := commandTable[cmd]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %d", ErrInvalidCommand, cmd)
+	}
+
+	if s.batteryEnabled() && s.batteryLevel() <= 0 {
+		return fmt.Errorf("%w: robot cannot move %s", ErrBatteryDepleted, spec.Name)
+	}
 
 	robotState := s.GetRobotState() // Get the current robot state
-	switch cmd {
-	case North:
-		if robotState.Y >= warehouseSize {
-			return fmt.Errorf("robot cannot move north, out of warehouse boundaries")
-		}
-		robotState.Y++
-	case South:
-		if robotState.Y == 0 {
-			return fmt.Errorf("robot cannot move south, out of warehouse boundaries")
-		}
-		robotState.Y--
-	case East:
-		if robotState.X >= warehouseSize {
-			return fmt.Errorf("robot cannot move east, out of warehouse boundaries")
+	from := robotState
+	newX, newY := int(robotState.X), int(robotState.Y)
+	// A larger step passes through every cell on the way, none of them may be blocked
+	for step := 0; step < s.config.StepSize; step++ {
+		newX += spec.DeltaX
+		newY += spec.DeltaY
+		cell := Coord{X: newX, Y: newY}
+		if !cell.inWarehouse() {
+			return fmt.Errorf("%w: robot cannot move %s", ErrOutOfBounds, spec.Name)
 		}
-		robotState.X++
-	case West:
-		if robotState.X == 0 {
-			return fmt.Errorf("robot cannot move west, out of warehouse boundaries")
+		if s.isObstacle(cell) {
+			return fmt.Errorf("%w: robot cannot move %s to (%d, %d)", ErrObstacle, spec.Name, newX, newY)
 		}
-		robotState.X--
 	}
+	if s.cellBlocked != nil && s.cellBlocked(newX, newY) {
+		return fmt.Errorf("%w: robot cannot move %s to (%d, %d)", ErrCellBlocked, spec.Name, newX, newY)
+	}
+	robotState.X = uint(newX)
+	robotState.Y = uint(newY)
 
 	s.SetRobotState(robotState) // Update the robot state in the service
+	s.drainBattery(robotState)
+	s.auditMove(cmd, from, robotState)
 	return nil
 }
 
+// recordProgress stores how many commands of the task were executed and where the robot ended up.
+func (s *Service) recordProgress(taskID string, executed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task, exists := s.state.Tasks[taskID]; exists {
+		task.Progress = &TaskProgress{CommandsExecuted: executed, FinalPosition: s.state.RobotState}
+		s.state.Tasks[taskID] = task
+	}
+}
+
+// executeWithRetry executes the command, retrying transient failures with exponential backoff
+// up to the configured number of retries. Permanent failures are returned right away.
+func (s *Service) executeWithRetry(taskID string, cmd RobotCommand) error {
+	backoff := s.config.CommandRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := s.ExecuteRobotCommand(cmd)
+		if err == nil || !isTransient(err) || attempt >= s.config.CommandRetries {
+			return err
+		}
+
+		log.Printf("Command '%s' of task %s failed, retry %d/%d in %s: %v", cmd, taskID, attempt+1, s.config.CommandRetries, backoff, err)
+		s.config.Clock.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// TaskStates returns the states of the given tasks looked up under a single lock, unknown task IDs are left out.
+func (s *Service) TaskStates(taskIDs []string) map[string]TaskState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make(map[string]TaskState, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if task, exists := s.state.Tasks[taskID]; exists {
+			states[taskID] = task.State
+		}
+	}
+	return states
+}
+
 func (s *Service) GetTaskState(taskID string) (TaskState, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	if task, exists := s.state.Tasks[taskID]; exists {
 		return task.State, nil
 	}
-	return Invalid, fmt.Errorf("task with ID %s not found", taskID)
+	return Invalid, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
 }
 
-func (s *Service) UpdateTaskState(taskID string, state TaskState) {
+// UpdateTaskState moves the task to the state, recording the reason of the transition, and publishes the change.
+func (s *Service) UpdateTaskState(taskID string, state TaskState, reason TransitionReason) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if task, exists := s.state.Tasks[taskID]; exists {
+		if !legalTransition(task.State, state) {
+			log.Printf("Task %s stays %s, ignoring the late update to %s (%s)", taskID, task.State, state, reason)
+			return
+		}
 		task.State = state
+		task.Reason = reason
+		if state == Aborted {
+			s.stampAborted(&task)
+		}
 		s.state.Tasks[taskID] = task // Update the task in the state
-		log.Printf("Task %s updated to state: %s", taskID, state)
+		log.Printf("Task %s updated to state: %s (%s)", taskID, state, reason)
 
-		// Publish event for WebSocket clients
-		go s.publishEvent(taskID, state, task.Error)
+		// Publish event for WebSocket clients, an aborted task reports how far it got
+		event := TaskStatusUpdateEvent{TaskID: taskID, State: state, Reason: reason, Error: task.Error}
+		if state == Aborted {
+			event.Progress = task.Progress
+		}
+		s.publishAsync(event)
 	} else {
 		log.Printf("Task %s not found for state update", taskID)
 	}
@@ -267,7 +864,7 @@ func (s *Service) UpdateTaskError(taskID string, errMsg string) {
 		log.Printf("Task %s updated with error: %s", taskID, errMsg)
 
 		// Publish event for WebSocket clients with error information
-		go s.publishEvent(taskID, task.State, errMsg)
+		s.publishEventAsync(taskID, task.State, task.Reason, errMsg)
 	} else {
 		log.Printf("Task %s not found for error update", taskID)
 	}
@@ -284,45 +881,97 @@ func (s *Service) SetRobotState(state RobotState) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.state.RobotState = state // Update the robot state in the service state
+	s.trackDwellLocked()
 }
 
-// Check if a task can be processed based on the robot's current position and warehouse boundaries.
-// if the task is valid, it will return true, otherwise false.
+// Check if a task can be processed based on the robot's current position, the warehouse boundaries and obstacles.
+// Every step of the task is checked, not only its destination. If the task is valid, it will return true, otherwise false.
 func (s *Service) IsTaskValid(task RobotTask) bool {
-	robotState := s.GetRobotState()
+	if err := s.checkPath(task.Commands); err != nil {
+		log.Printf("Task %s is invalid: %v", task.ID, err)
+		return false
+	}
+	return true
+}
 
-	destinationX := int(robotState.X) + task.DeltaX
-	destinationY := int(robotState.Y) + task.DeltaY
+// checkPath simulates the commands from the robot's current position and returns an error for the first command
+// that would take the robot outside the warehouse or onto an obstacle, including the cells passed with a larger step size.
+func (s *Service) checkPath(commands RobotCommands) error {
+	robotState := s.GetRobotState()
+	_, err := s.checkPathFrom(Coord{X: int(robotState.X), Y: int(robotState.Y)}, commands)
+	return err
+}
 
-	if destinationX < 0 || destinationX >= warehouseSize || destinationY < 0 || destinationY >= warehouseSize {
-		log.Printf("Task %s is invalid: out of warehouse boundaries", task.ID)
-		return false
+// checkPathFrom simulates the commands from the start cell like checkPath and returns the cell they end on.
+func (s *Service) checkPathFrom(cell Coord, commands RobotCommands) (Coord, error) {
+	for i, cmd := range commands {
+		spec := commandTable[cmd]
+		for step := 0; step < s.config.StepSize; step++ {
+			cell = Coord{X: cell.X + spec.DeltaX, Y: cell.Y + spec.DeltaY}
+			if !cell.inWarehouse() {
+				return cell, fmt.Errorf("%w: command %d (%s) would move the robot to %s", ErrOutOfBounds, i+1, cmd, cell)
+			}
+			if s.isObstacle(cell) {
+				return cell, fmt.Errorf("%w: command %d (%s) would move the robot onto the obstacle at %s", ErrObstacle, i+1, cmd, cell)
+			}
+		}
 	}
+	return cell, nil
+}
 
-	return true
+// publishEvent sends a task status update event to all subscribers.
+// This method is non-blocking and will drop events for subscribers whose buffer is full.
+func (s *Service) publishEvent(taskID string, state TaskState, reason TransitionReason, errorMsg string) {
+	s.publish(TaskStatusUpdateEvent{TaskID: taskID, State: state, Reason: reason, Error: errorMsg})
 }
 
-// GetEventChannel returns the channel for task status update events.
-// This channel can be used by WebSocket handlers to listen for real-time updates.
-func (s *Service) GetEventChannel() <-chan TaskStatusUpdateEvent {
-	return s.eventChan
+// publishEventAsync publishes a task status event without blocking, see publishAsync.
+func (s *Service) publishEventAsync(taskID string, state TaskState, reason TransitionReason, errorMsg string) {
+	s.publishAsync(TaskStatusUpdateEvent{TaskID: taskID, State: state, Reason: reason, Error: errorMsg})
 }
 
-// publishEvent sends a task status update event to the event channel.
-// This method is non-blocking and will drop events if the channel is full.
-func (s *Service) publishEvent(taskID string, state TaskState, errorMsg string) {
-	event := TaskStatusUpdateEvent{
-		TaskID:    taskID,
-		State:     state,
-		Error:     errorMsg,
-		Timestamp: time.Now(),
+// correlationID returns the correlation ID of the task, empty for unknown tasks or tasks created without one.
+func (s *Service) correlationID(taskID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state.Tasks[taskID].CorrelationID
+}
+
+// publish sends the event to all subscribers. In strict sequence mode it is queued for the event writer instead.
+func (s *Service) publish(event TaskStatusUpdateEvent) {
+	if s.sequencer != nil {
+		s.sequencer.push(event)
+		return
 	}
+	s.deliver(event)
+}
 
-	// Non-blocking send to avoid deadlocks
-	select {
-	case s.eventChan <- event:
-		log.Printf("Published event for task %s: state=%s at %s", taskID, state, event.Timestamp.Format(time.RFC3339))
-	default:
-		log.Printf("Event channel full, dropped event for task %s", taskID)
+// publishAsync publishes the event without blocking, so it may be called while holding the service lock.
+// In strict sequence mode the event is queued right away, so events are delivered in the order of the calls.
+func (s *Service) publishAsync(event TaskStatusUpdateEvent) {
+	if s.sequencer != nil {
+		s.sequencer.push(event)
+		return
+	}
+	go s.deliver(event)
+}
+
+// deliver timestamps the event, tags it with the correlation ID of its task and sends it to all subscribers, unless it is coalesced with later events.
+func (s *Service) deliver(event TaskStatusUpdateEvent) {
+	event.Timestamp = s.config.Clock.Now()
+	event.CorrelationID = s.correlationID(event.TaskID)
+	if event.Type == "" && event.State == Aborted {
+		event.AbortCategory = event.Reason.AbortCategory()
+	}
+	if s.coalesce(event) {
+		s.broadcastAndLog(event)
+	}
+}
+
+func (s *Service) broadcastAndLog(event TaskStatusUpdateEvent) {
+	event = s.broadcast(event)
+	if s.webhook != nil && event.Type == "" && isFinished(event.State) {
+		s.webhook.notifyFinished(event)
 	}
+	log.Printf("Published event %d for task %s: state=%s at %s", event.Seq, event.TaskID, event.State, event.Timestamp.Format(time.RFC3339))
 }
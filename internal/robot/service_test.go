@@ -2,6 +2,10 @@ package robot
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -186,7 +190,7 @@ func TestCancelTask(t *testing.T) {
 		<-taskIdQueue
 
 		// Manually set task to InProgress
-		service.UpdateTaskState(taskID, InProgress)
+		service.UpdateTaskState(taskID, InProgress, ReasonDispatched)
 
 		err = service.CancelTask(taskID)
 		if err != nil {
@@ -211,7 +215,7 @@ func TestCancelTask(t *testing.T) {
 		<-taskIdQueue
 
 		// Manually set task to Completed
-		service.UpdateTaskState(taskID, Completed)
+		service.UpdateTaskState(taskID, Completed, ReasonCompleted)
 
 		err = service.CancelTask(taskID)
 		if err == nil {
@@ -308,29 +312,39 @@ func TestExecuteRobotCommand(t *testing.T) {
 func TestIsTaskValid(t *testing.T) {
 	ctx := context.Background()
 	taskIdQueue := make(chan string, 10)
-	service := NewService(ctx, taskIdQueue)
+	config := DefaultConfig()
+	config.Obstacles = []Coord{{X: 3, Y: 3}}
+	service := NewServiceWithConfig(ctx, taskIdQueue, config)
 
 	tests := []struct {
 		name           string
 		startX, startY uint
-		deltaX, deltaY int
+		commands       string
 		expectValid    bool
 	}{
-		{"Valid task within bounds", 5, 5, 2, 2, true},
-		{"Valid task at origin", 0, 0, 5, 5, true},
-		{"Invalid task - exceeds X boundary", 8, 5, 5, 0, false},
-		{"Invalid task - exceeds Y boundary", 5, 8, 0, 5, false},
-		{"Invalid task - negative X", 2, 5, -5, 0, false},
-		{"Invalid task - negative Y", 5, 2, 0, -5, false},
+		{"Valid task within bounds", 5, 5, "E E N N", true},
+		{"Valid task at origin", 0, 0, "E E E E E N N N N N", true},
+		{"Invalid task - exceeds X boundary", 8, 5, "E E E E E", false},
+		{"Invalid task - exceeds Y boundary", 5, 8, "N N N N N", false},
+		{"Invalid task - negative X", 2, 5, "W W W W W", false},
+		{"Invalid task - negative Y", 5, 2, "S S S S S", false},
+		{"Invalid task - destination fine but path leaves the warehouse", 0, 5, "W E E", false},
+		{"Invalid task - destination fine but path crosses an obstacle", 2, 3, "E E S", false},
+		{"Valid task - path goes around an obstacle", 2, 3, "N E E S", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			service.SetRobotState(RobotState{X: tt.startX, Y: tt.startY})
 
+			commands, deltaX, deltaY, err := parseCommands(tt.commands)
+			if err != nil {
+				t.Fatalf("Failed to parse commands: %v", err)
+			}
 			task := RobotTask{
-				DeltaX: tt.deltaX,
-				DeltaY: tt.deltaY,
+				Commands: commands,
+				DeltaX:   deltaX,
+				DeltaY:   deltaY,
 			}
 
 			isValid := service.IsTaskValid(task)
@@ -341,6 +355,88 @@ func TestIsTaskValid(t *testing.T) {
 	}
 }
 
+// TestIsTaskValid_Overshoot tests that a task overshooting the boundary before returning is rejected up front,
+// so validation matches execution instead of aborting the task halfway.
+func TestIsTaskValid_Overshoot(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+
+	tests := []struct {
+		name     string
+		commands string
+	}{
+		{"East past the boundary and back", strings.Repeat("E ", 6) + strings.Repeat("W ", 4)},
+		{"North past the boundary and back", strings.Repeat("N ", 6) + "S"},
+		{"South below the origin and back", strings.Repeat("S ", 6) + "N"},
+	}
+
+	// Paths spanning more than the warehouse are already rejected when parsing, start in the middle to overshoot
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service.SetRobotState(RobotState{X: 5, Y: 5})
+			taskID, err := service.EnqueueTask(tt.commands, "0s")
+			if err != nil {
+				t.Fatalf("Failed to enqueue task: %v", err)
+			}
+			task := service.CurrentState().Tasks[taskID]
+			if service.IsTaskValid(task) {
+				t.Errorf("Expected task with an in-bounds destination but out of bounds path to be invalid")
+			}
+
+			service.ExecuteTask(taskID)
+			if state, _ := service.GetTaskState(taskID); state != Aborted {
+				t.Errorf("Expected task state Aborted, got %s", state)
+			}
+			if got := service.GetRobotState(); got.X != 5 || got.Y != 5 {
+				t.Errorf("Expected the robot not to move, got (%d, %d)", got.X, got.Y)
+			}
+		})
+	}
+}
+
+// TestCheckPath tests that the first command leaving the warehouse or hitting an obstacle is reported.
+func TestCheckPath(t *testing.T) {
+	config := DefaultConfig()
+	config.Obstacles = []Coord{{X: 2, Y: 0}}
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	tests := []struct {
+		name     string
+		commands string
+		wantErr  error
+		wantMsg  string
+	}{
+		{"Valid path", "N E E E S", nil, ""},
+		{"Out of bounds in the middle", "N S S N E", ErrOutOfBounds, "command 3 (S) would move the robot to (0, -1)"},
+		{"Obstacle in the middle", "E E W", ErrObstacle, "command 2 (E) would move the robot onto the obstacle at (2, 0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands, _, _, _ := parseCommands(tt.commands)
+			err := service.checkPath(commands)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantMsg, err.Error())
+			}
+		})
+	}
+
+	// A task with a bad intermediate step is aborted before the robot moves
+	taskID, _ := service.EnqueueTask("N S S N E", "0s")
+	if err := service.ExecuteTask(taskID); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("Expected ErrOutOfBounds from ExecuteTask, got %v", err)
+	}
+	task := service.CurrentState().Tasks[taskID]
+	if task.State != Aborted || task.Progress == nil || task.Progress.CommandsExecuted != 0 {
+		t.Errorf("Expected task aborted without moving, got state %s progress %+v", task.State, task.Progress)
+	}
+	if !strings.Contains(task.Error, "command 3 (S)") {
+		t.Errorf("Expected the first bad step in the task error, got %q", task.Error)
+	}
+}
+
 // TestConcurrentAccess tests concurrent access to service methods.
 func TestConcurrentAccess(t *testing.T) {
 	ctx := context.Background()
@@ -416,13 +512,13 @@ func TestHandleTaskCancellation(t *testing.T) {
 	// Start processing the task in a goroutine
 	go func() {
 		// Simulate starting task processing
-		service.UpdateTaskState(taskID, InProgress)
+		service.UpdateTaskState(taskID, InProgress, ReasonDispatched)
 
 		// Simulate some processing time
 		time.Sleep(10 * time.Millisecond)
 
 		// Request cancellation
-		service.UpdateTaskState(taskID, RequestCancellation)
+		service.UpdateTaskState(taskID, RequestCancellation, ReasonUserCancel)
 	}()
 
 	// Wait for the task to be in RequestCancellation state
@@ -495,7 +591,7 @@ func TestExecuteTask(t *testing.T) {
 		}
 
 		// Set task to completed state
-		service.UpdateTaskState(taskID, Completed)
+		service.UpdateTaskState(taskID, Completed, ReasonCompleted)
 
 		// Try to execute
 		err = service.ExecuteTask(taskID)
@@ -551,7 +647,7 @@ func TestExecuteTaskErrorHandling(t *testing.T) {
 
 		// Wait a bit then request cancellation
 		time.Sleep(10 * time.Millisecond)
-		service.UpdateTaskState(taskID, RequestCancellation)
+		service.UpdateTaskState(taskID, RequestCancellation, ReasonUserCancel)
 
 		// Wait for execution to complete
 		select {
@@ -570,3 +666,656 @@ func TestExecuteTaskErrorHandling(t *testing.T) {
 		}
 	})
 }
+
+// TestDeterministicTaskIDs tests that an injected ID generator produces predictable task IDs.
+func TestDeterministicTaskIDs(t *testing.T) {
+	config := DefaultConfig()
+	config.IDGenerator = NewSequentialIDGenerator()
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	for i, want := range []string{"task-1", "task-2", "task-3"} {
+		taskID, err := service.EnqueueTask("N", "10ms")
+		if err != nil {
+			t.Fatalf("Failed to enqueue task %d: %v", i, err)
+		}
+		if taskID != want {
+			t.Errorf("Expected task ID %s, got %s", want, taskID)
+		}
+		if _, exists := service.CurrentState().Tasks[want]; !exists {
+			t.Errorf("Expected task %s to exist in service state", want)
+		}
+	}
+
+	// Rejected tasks must not consume an ID from the sequence
+	if _, err := service.EnqueueTask("X", "10ms"); err == nil {
+		t.Fatal("Expected error for invalid commands")
+	}
+	taskID, _ := service.EnqueueTask("N", "10ms")
+	if taskID != "task-4" {
+		t.Errorf("Expected task ID task-4 after a rejected task, got %s", taskID)
+	}
+}
+
+// TestSetPosition tests forcing the robot position inside and outside the warehouse.
+func TestSetPosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		x, y    int
+		wantErr bool
+	}{
+		{"Origin", 0, 0, false},
+		{"Far corner", warehouseSize - 1, warehouseSize - 1, false},
+		{"X outside", warehouseSize, 0, true},
+		{"Negative Y", 0, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(context.Background(), make(chan string, 1))
+			service.SetRobotState(RobotState{X: 3, Y: 3, Heading: HeadingEast})
+
+			got, err := service.SetPosition(tt.x, tt.y)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetPosition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			want := RobotState{X: 3, Y: 3, Heading: HeadingEast}
+			if !tt.wantErr {
+				want = RobotState{X: uint(tt.x), Y: uint(tt.y), Heading: HeadingEast}
+			}
+			if got != want || service.GetRobotState() != want {
+				t.Errorf("Expected robot state %+v, got %+v", want, service.GetRobotState())
+			}
+		})
+	}
+}
+
+// TestSentinelErrors tests that service errors wrap the matching sentinel error.
+func TestSentinelErrors(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 1))
+
+	if _, err := service.EnqueueTask("N X", "0s"); !errors.Is(err, ErrInvalidCommand) {
+		t.Errorf("Expected ErrInvalidCommand, got %v", err)
+	}
+	if err := service.CancelTask("missing"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+	if _, err := service.SetPosition(warehouseSize, 0); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("Expected ErrOutOfBounds, got %v", err)
+	}
+
+	// The queue holds a single task, the second one is rejected without being stored
+	taskID, err := service.EnqueueTask("N", "0s")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+	if _, err := service.EnqueueTask("N", "0s"); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+	if count := len(service.CurrentState().Tasks); count != 1 {
+		t.Errorf("Expected the rejected task not to be stored, got %d tasks", count)
+	}
+
+	service.UpdateTaskState(taskID, Completed, ReasonCompleted)
+	if err := service.CancelTask(taskID); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected ErrInvalidState, got %v", err)
+	}
+}
+
+// TestCommandRetry tests that commands blocked by a temporarily occupied cell are retried with backoff.
+func TestCommandRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		retries     int
+		blockedFor  int // Number of attempts the target cell stays blocked
+		wantState   TaskState
+		wantBackoff time.Duration
+	}{
+		{"Blocked twice then free", 3, 2, Completed, 300 * time.Millisecond},
+		{"Free right away", 3, 0, Completed, 0},
+		{"Blocked longer than retries", 2, 5, Aborted, 300 * time.Millisecond},
+		{"Retries disabled", 0, 1, Aborted, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			config := DefaultConfig()
+			config.CommandRetries = tt.retries
+			config.CommandRetryBackoff = 100 * time.Millisecond
+			config.Clock = clock
+			service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+
+			attempts := 0
+			service.cellBlocked = func(x, y int) bool {
+				attempts++
+				return attempts <= tt.blockedFor
+			}
+
+			taskID, _ := service.EnqueueTask("N", "0s")
+			start := clock.Now()
+			service.ExecuteTask(taskID)
+
+			if state, _ := service.GetTaskState(taskID); state != tt.wantState {
+				t.Errorf("Expected task state %s, got %s", tt.wantState, state)
+			}
+			if waited := clock.Now().Sub(start); waited != tt.wantBackoff {
+				t.Errorf("Expected total backoff %s, got %s", tt.wantBackoff, waited)
+			}
+		})
+	}
+}
+
+// TestCommandRetryPermanentFailure tests that permanent failures abort the task without retrying.
+func TestCommandRetryPermanentFailure(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.CommandRetries = 3
+	config.Clock = clock
+	service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+
+	service.SetRobotState(RobotState{X: 0, Y: 0})
+	start := clock.Now()
+	if err := service.executeWithRetry("task", South); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("Expected ErrOutOfBounds, got %v", err)
+	}
+	if waited := clock.Now().Sub(start); waited != 0 {
+		t.Errorf("Expected no retry for a permanent failure, waited %s", waited)
+	}
+}
+
+// TestAbortProgress tests that a task failing at the 3rd of 5 commands reports its progress in the task and the Aborted event.
+func TestAbortProgress(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 1))
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+
+	// The 3rd command moves east into a blocked cell
+	service.cellBlocked = func(x, y int) bool { return x == 1 }
+
+	taskID, _ := service.EnqueueTask("N N E N N", "0s")
+	if err := service.ExecuteTask(taskID); err == nil {
+		t.Fatal("Expected task to fail")
+	}
+
+	want := TaskProgress{CommandsExecuted: 2, FinalPosition: RobotState{X: 0, Y: 2, Heading: HeadingNorth}}
+	task := service.CurrentState().Tasks[taskID]
+	if task.State != Aborted {
+		t.Fatalf("Expected task to be Aborted, got %s", task.State)
+	}
+	if task.Progress == nil || *task.Progress != want {
+		t.Errorf("Expected task progress %+v, got %+v", want, task.Progress)
+	}
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-sub.Events():
+			if event.State != Aborted {
+				continue
+			}
+			if event.Progress == nil || *event.Progress != want {
+				t.Errorf("Expected Aborted event progress %+v, got %+v", want, event.Progress)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Did not receive the Aborted event")
+		}
+	}
+}
+
+// TestEmptyTaskPolicy tests that tasks without commands are rejected by default and complete as a no-op when allowed.
+func TestEmptyTaskPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowEmpty bool
+		commands   string
+		wantErr    bool
+	}{
+		{"Rejected by default", false, "", true},
+		{"Whitespace rejected by default", false, "   ", true},
+		{"Allowed", true, "", false},
+		{"Whitespace allowed", true, "  ", false},
+		{"Invalid commands still rejected", true, "X", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.AllowEmptyTasks = tt.allowEmpty
+			service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+			service.SetRobotState(RobotState{X: 2, Y: 3, Heading: HeadingEast})
+
+			taskID, err := service.EnqueueTask(tt.commands, "1s")
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCommand) {
+					t.Errorf("Expected ErrInvalidCommand, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to enqueue empty task: %v", err)
+			}
+
+			// The task completes right away without touching the robot
+			start := time.Now()
+			if err := service.ExecuteTask(taskID); err != nil {
+				t.Fatalf("Failed to execute empty task: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("Expected empty task to complete immediately, took %s", elapsed)
+			}
+			if state, _ := service.GetTaskState(taskID); state != Completed {
+				t.Errorf("Expected task state Completed, got %s", state)
+			}
+			if got, want := service.GetRobotState(), (RobotState{X: 2, Y: 3, Heading: HeadingEast}); got != want {
+				t.Errorf("Expected robot state %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+// TestAllowedCommands tests that a task using a command outside the allowed set is rejected, while nil allows everything.
+func TestAllowedCommands(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	eastWest := []RobotCommand{East, West}
+
+	tests := []struct {
+		name     string
+		commands string
+		allowed  []RobotCommand
+		wantErr  error
+	}{
+		{"All commands allowed by default", "N E S W", nil, nil},
+		{"Only allowed commands", "E E W", eastWest, nil},
+		{"Forbidden command", "E N W", eastWest, ErrCommandNotAllowed},
+		{"Nothing allowed", "E", []RobotCommand{}, ErrCommandNotAllowed},
+		{"Parse errors take precedence", "E X", eastWest, ErrInvalidCommand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(service.CurrentState().Tasks)
+			_, err := service.SubmitTask(TaskSpec{Commands: tt.commands, DelayBetweenCommands: "1s", AllowedCommands: tt.allowed})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil && len(service.CurrentState().Tasks) != before {
+				t.Errorf("Expected a rejected task not to be stored")
+			}
+		})
+	}
+}
+
+// TestAllowedCommands_GeneratedTasks tests that tasks generating their commands honor the allowed set of their spec.
+func TestAllowedCommands_GeneratedTasks(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	eastWest := TaskSpec{DelayBetweenCommands: "1s", AllowedCommands: []RobotCommand{East, West}}
+
+	if _, err := service.EnqueuePatrol(2, 2, eastWest); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Errorf("Expected a patrol moving north to be rejected with %v, got %v", ErrCommandNotAllowed, err)
+	}
+	if _, err := service.EnqueueRunToWall("E", eastWest); err != nil {
+		t.Errorf("Expected a run east to be accepted, got %v", err)
+	}
+	if _, err := service.Follow(Coord{X: 3, Y: 0}, eastWest); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Errorf("Expected a follow task to need every move, got %v", err)
+	}
+	if _, err := service.Follow(Coord{X: 3, Y: 0}, TaskSpec{AllowedCommands: moveCommands}); err != nil {
+		t.Errorf("Expected a follow task allowed every move to be accepted, got %v", err)
+	}
+}
+
+// TestAllowedCommands_RelativeFrame tests that the allowed set applies to the commands as they run,
+// i.e. resolved against the heading, at submission and again at dispatch.
+func TestAllowedCommands_RelativeFrame(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	northOnly := TaskSpec{Commands: "N", DelayBetweenCommands: "0s", Frame: string(FrameRelative), AllowedCommands: []RobotCommand{North}}
+
+	service.SetRobotState(RobotState{Heading: HeadingEast})
+	if _, err := service.SubmitTask(northOnly); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Errorf("Expected forward heading east to be rejected with %v, got %v", ErrCommandNotAllowed, err)
+	}
+
+	// The heading changes between the submission and the dispatch
+	service.SetRobotState(RobotState{Heading: HeadingNorth})
+	taskID, err := service.SubmitTask(northOnly)
+	if err != nil {
+		t.Fatalf("Expected forward heading north to be accepted, got %v", err)
+	}
+	service.SetRobotState(RobotState{Heading: HeadingEast})
+	if err := service.ExecuteTask(taskID); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Errorf("Expected the dispatch to fail with %v, got %v", ErrCommandNotAllowed, err)
+	}
+	if task := service.CurrentState().Tasks[taskID]; task.State != Aborted || task.Reason != ReasonNotAllowed {
+		t.Errorf("Expected the task Aborted (%s), got %s (%s)", ReasonNotAllowed, task.State, task.Reason)
+	}
+	if robotState := service.GetRobotState(); robotState.X != 0 || robotState.Y != 0 {
+		t.Errorf("Expected the robot not to move, got (%d, %d)", robotState.X, robotState.Y)
+	}
+}
+
+func TestParseCommandSet(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    []RobotCommand
+		wantErr bool
+	}{
+		{"N,S", []RobotCommand{North, South}, false},
+		{" e , w,E ", []RobotCommand{East, West}, false},
+		{"", []RobotCommand{}, false},
+		{"N,X", nil, true},
+		{"HOLD", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCommandSet(tt.raw)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("ParseCommandSet(%q) = %v, %v, want %v, error %t", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestStepSize tests that every command moves the robot by the configured step size and that the bounds are checked accordingly.
+func TestStepSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    RobotState
+		commands string
+		want     RobotState
+		wantErr  error
+	}{
+		{"Moves two cells per command", RobotState{X: 0, Y: 0}, "N E E", RobotState{X: 4, Y: 2}, nil},
+		{"Reaches the far edge", RobotState{X: 5, Y: 7}, "N", RobotState{X: 5, Y: 9}, nil},
+		{"Stops short of the far edge", RobotState{X: 5, Y: 8}, "N", RobotState{X: 5, Y: 8}, ErrOutOfBounds},
+		{"Stops short of the origin", RobotState{X: 1, Y: 0}, "W", RobotState{X: 1, Y: 0}, ErrOutOfBounds},
+		{"Cannot jump over an obstacle", RobotState{X: 3, Y: 5}, "E", RobotState{X: 3, Y: 5}, ErrObstacle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.StepSize = 2
+			config.Obstacles = []Coord{{X: 4, Y: 5}}
+			service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+			service.SetRobotState(tt.start)
+
+			commands, _, _, err := parseCommands(tt.commands)
+			if err != nil {
+				t.Fatalf("Failed to parse commands: %v", err)
+			}
+			for _, cmd := range commands {
+				err = service.ExecuteRobotCommand(cmd)
+				if err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got := service.GetRobotState(); got.X != tt.want.X || got.Y != tt.want.Y {
+				t.Errorf("Expected position (%d, %d), got (%d, %d)", tt.want.X, tt.want.Y, got.X, got.Y)
+			}
+		})
+	}
+}
+
+// TestStepSize_IsTaskValid tests that task validation scales every step by the step size.
+func TestStepSize_IsTaskValid(t *testing.T) {
+	config := DefaultConfig()
+	config.StepSize = 2
+	config.Obstacles = []Coord{{X: 4, Y: 5}}
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	tests := []struct {
+		name        string
+		start       RobotState
+		commands    string
+		expectValid bool
+	}{
+		{"Scaled destination within bounds", RobotState{X: 1, Y: 1}, "E E E E N N N", true},
+		{"Scaled destination exceeds X boundary", RobotState{X: 1, Y: 1}, "E E E E E", false},
+		{"Scaled destination exceeds Y boundary", RobotState{X: 1, Y: 1}, "N N N N N", false},
+		{"Scaled destination below the origin", RobotState{X: 1, Y: 1}, "W", false},
+		{"Cell passed by a step is an obstacle", RobotState{X: 3, Y: 5}, "E W", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service.SetRobotState(tt.start)
+			commands, _, _, _ := parseCommands(tt.commands)
+			if isValid := service.IsTaskValid(RobotTask{Commands: commands}); isValid != tt.expectValid {
+				t.Errorf("Expected validity %t, got %t", tt.expectValid, isValid)
+			}
+		})
+	}
+}
+
+// TestEnqueueAfterShutdown tests that tasks are rejected once the service context is cancelled, without touching the queue.
+func TestEnqueueAfterShutdown(t *testing.T) {
+	for _, queueSize := range []int{0, 10} {
+		t.Run(fmt.Sprintf("Queue size %d", queueSize), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			taskIdQueue := make(chan string, queueSize)
+			service := NewService(ctx, taskIdQueue)
+			cancel()
+
+			if _, err := service.EnqueueTask("N", "0s"); !errors.Is(err, ErrShuttingDown) {
+				t.Errorf("Expected ErrShuttingDown, got %v", err)
+			}
+			if len(taskIdQueue) != 0 {
+				t.Errorf("Expected no queued task IDs, got %d", len(taskIdQueue))
+			}
+			if count := len(service.CurrentState().Tasks); count != 0 {
+				t.Errorf("Expected the rejected task not to be stored, got %d tasks", count)
+			}
+		})
+	}
+}
+
+// TestSubmitTaskContext_ExpiredWhileWaiting tests that a submission whose context is done by the time
+// it gets the service lock is rejected without storing or queueing the task.
+func TestSubmitTaskContext_ExpiredWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	taskIdQueue := make(chan string, 10)
+	service := NewService(ctx, taskIdQueue)
+
+	reqCtx, expire := context.WithCancel(context.Background())
+	service.mu.Lock()
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := service.SubmitTaskContext(reqCtx, TaskSpec{Commands: "N"})
+		errCh <- err
+	}()
+	// The submission blocks on the lock until the request has expired
+	time.Sleep(10 * time.Millisecond)
+	expire()
+	service.mu.Unlock()
+
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(taskIdQueue) != 0 {
+		t.Errorf("Expected no queued task IDs, got %d", len(taskIdQueue))
+	}
+	if count := len(service.CurrentState().Tasks); count != 0 {
+		t.Errorf("Expected the expired task not to be stored, got %d tasks", count)
+	}
+}
+
+// TestStarted tests that the service only reports started once Start entered the dispatch loop.
+func TestStarted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := NewService(ctx, make(chan string, 10))
+	if service.Started() {
+		t.Fatal("Expected the service not to be started before Start")
+	}
+
+	go service.Start()
+	deadline := time.Now().Add(2 * time.Second)
+	for !service.Started() {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the service to be started after Start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestCancelTwice tests that cancelling a canceled task again is reported as already canceled,
+// while cancelling a finished task is invalid.
+func TestCancelTwice(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   TaskState // State of the task before the second cancel, Pending cancels it first
+		wantErr error
+	}{
+		{"Double cancel", Pending, ErrAlreadyCanceled},
+		{"Cancel requested twice", InProgress, ErrAlreadyCanceled},
+		{"Cancel after complete", Completed, ErrInvalidState},
+		{"Cancel after abort", Aborted, ErrInvalidState},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(context.Background(), make(chan string, 1))
+			taskID, _ := service.EnqueueTask("N", "0s")
+			service.UpdateTaskState(taskID, tt.state, "")
+			if tt.state == Pending || tt.state == InProgress {
+				if err := service.CancelTask(taskID); err != nil {
+					t.Fatalf("First cancel failed: %v", err)
+				}
+			}
+			before, _ := service.GetTaskState(taskID)
+
+			if err := service.CancelTask(taskID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if state, _ := service.GetTaskState(taskID); state != before {
+				t.Errorf("Expected task to stay '%s', got '%s'", before, state)
+			}
+		})
+	}
+}
+
+// TestTaskStates tests looking up the states of known and unknown tasks at once.
+func TestTaskStates(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	pending, _ := service.EnqueueTask("N", "0s")
+	done, _ := service.EnqueueTask("E", "0s")
+	service.UpdateTaskState(done, Completed, ReasonCompleted)
+
+	states := service.TaskStates([]string{pending, "missing", done})
+	want := map[string]TaskState{pending: Pending, done: Completed}
+	if len(states) != len(want) {
+		t.Fatalf("Expected %d states, got %v", len(want), states)
+	}
+	for taskID, state := range want {
+		if states[taskID] != state {
+			t.Errorf("Expected task %s to be '%s', got '%s'", taskID, state, states[taskID])
+		}
+	}
+}
+
+// TestTaskIDPrefix tests that generated task IDs carry the configured prefix and stay unique.
+func TestTaskIDPrefix(t *testing.T) {
+	config := DefaultConfig()
+	config.TaskIDPrefix = "whA-"
+	service := NewServiceWithConfig(context.Background(), make(chan string, 100), config)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		taskID, err := service.EnqueueTask("N", "0s")
+		if err != nil {
+			t.Fatalf("Failed to enqueue task: %v", err)
+		}
+		if !strings.HasPrefix(taskID, "whA-") || len(taskID) <= len("whA-") {
+			t.Errorf("Expected task ID with prefix whA-, got %s", taskID)
+		}
+		if seen[taskID] {
+			t.Errorf("Duplicate task ID %s", taskID)
+		}
+		seen[taskID] = true
+	}
+
+	// The prefix also applies to a custom generator
+	config.IDGenerator = NewSequentialIDGenerator()
+	service = NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+	if taskID, _ := service.EnqueueTask("N", "0s"); taskID != "whA-task-1" {
+		t.Errorf("Expected task ID whA-task-1, got %s", taskID)
+	}
+
+	config.TaskIDPrefix = "a/b"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected a prefix containing a slash to be rejected")
+	}
+}
+
+// TestCorrelationID tests that every event of a task carries the correlation ID it was submitted with.
+func TestCorrelationID(t *testing.T) {
+	config := DefaultConfig()
+	config.Clock = newFakeClock()
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+
+	tagged, _ := service.SubmitTask(TaskSpec{Commands: "N E", CorrelationID: "req-42"})
+	untagged, _ := service.SubmitTask(TaskSpec{Commands: "N"})
+	service.ExecuteTask(tagged)
+	service.ExecuteTask(untagged)
+
+	if task := service.CurrentState().Tasks[tagged]; task.CorrelationID != "req-42" {
+		t.Errorf("Expected the task to store the correlation ID, got %q", task.CorrelationID)
+	}
+	events := map[string]int{}
+	for len(events) < 2 || events[untagged] < 3 {
+		select {
+		case event := <-sub.Events():
+			want := ""
+			if event.TaskID == tagged {
+				want = "req-42"
+			}
+			if event.CorrelationID != want {
+				t.Errorf("Expected correlation ID %q on the %s event of task %s, got %q", want, event.State, event.TaskID, event.CorrelationID)
+			}
+			events[event.TaskID]++
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the events of both tasks, got %v", events)
+		}
+	}
+}
+
+// TestMaxPathLength tests that tasks are rejected by the cells they traverse, scaled by the step size,
+// rather than by the number of commands.
+func TestMaxPathLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		stepSize int
+		commands string
+		wantErr  error
+	}{
+		{"Within the limit", 1, "N S N S", nil},
+		{"At the limit", 3, "N S", nil},
+		{"Few commands expanding beyond the limit", 3, "N S N", ErrPathTooLong},
+		{"Single command expanding beyond the limit", 7, "N", ErrPathTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.StepSize = tt.stepSize
+			config.MaxPathLength = 6
+			service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+			pending, _ := service.EnqueueTask("E", "0s")
+
+			if _, err := service.EnqueueTask(tt.commands, "0s"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("EnqueueTask: expected %v, got %v", tt.wantErr, err)
+			}
+			if _, err := service.SubmitBatch([]TaskSpec{{Commands: "E"}, {Commands: tt.commands}}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SubmitBatch: expected %v, got %v", tt.wantErr, err)
+			}
+			if _, err := service.ReplaceTask(pending, TaskSpec{Commands: tt.commands}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReplaceTask: expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
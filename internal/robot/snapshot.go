@@ -0,0 +1,132 @@
+package robot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// Snapshot is the complete serializable state of the service, used to save and restore it, e.g. for tests and backups.
+// @Description Complete serializable state of the robot service
+type Snapshot struct {
+	RobotState   RobotState  `json:"robot_state"`        // Position and heading of the robot
+	Battery      int         `json:"battery,omitempty"`  // Battery level, omitted when the battery simulation is disabled
+	CurTaskCount int         `json:"current_task_count"` // Number of tasks submitted so far, the last assigned sequence number
+	Tasks        []RobotTask `json:"tasks"`              // All tasks ordered by sequence number
+	Config       Config      `json:"config"`             // Configuration of the service with secrets redacted, informational only and not restored
+}
+
+// Snapshot returns a copy of the complete service state.
+func (s *Service) Snapshot() Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := make([]RobotTask, 0, len(s.state.Tasks))
+	for _, task := range s.state.Tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].SequenceNum < tasks[j].SequenceNum
+	})
+
+	return Snapshot{
+		RobotState:   s.state.RobotState,
+		Battery:      s.state.Battery,
+		CurTaskCount: s.state.CurTaskCount,
+		Tasks:        tasks,
+		Config:       s.config.Redacted(), // Snapshots are served to clients, see RuntimeConfig
+	}
+}
+
+// Restore replaces the robot state and all tasks with the snapshot. The service mode and configuration are kept.
+// It fails while a task is being executed. Tasks that were running when the snapshot was taken are restored as Aborted,
+// pending tasks are queued again.
+func (s *Service) Restore(snapshot Snapshot) error {
+	robot := snapshot.RobotState
+	if !(Coord{X: int(robot.X), Y: int(robot.Y)}).inWarehouse() {
+		return fmt.Errorf("%w: position (%d, %d) is outside the %dx%d warehouse", ErrOutOfBounds, robot.X, robot.Y, warehouseSize, warehouseSize)
+	}
+
+	tasks := make(map[string]RobotTask, len(snapshot.Tasks))
+	curTaskCount := snapshot.CurTaskCount
+	pending := 0
+	for _, task := range snapshot.Tasks {
+		if task.ID == "" {
+			return errors.New("invalid snapshot: task without ID")
+		}
+		if _, exists := tasks[task.ID]; exists {
+			return fmt.Errorf("invalid snapshot: duplicate task %s", task.ID)
+		}
+
+		switch task.State {
+		case Pending:
+			pending++
+		case InProgress, RequestCancellation:
+			// The execution cannot be resumed, the robot position in the snapshot is where it stopped
+			task.State = Aborted
+			task.Reason = ReasonRestored
+			s.stampAborted(&task)
+			task.Error = "Task interrupted by a state restore"
+		case CancelPending:
+			task.State = Canceled // The uncancel window does not survive a restore
+		}
+		task.DeltaX, task.DeltaY = 0, 0
+		for _, cmd := range task.Commands {
+			dx, dy := cmd.Delta()
+			task.DeltaX += dx
+			task.DeltaY += dy
+		}
+
+		tasks[task.ID] = task
+		curTaskCount = max(curTaskCount, task.SequenceNum)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task, running := s.runningTaskLocked(); running {
+		return fmt.Errorf("%w: cannot restore while task %s is '%s'", ErrInvalidState, task.ID, task.State)
+	}
+
+	// Every pending task needs a dispatch token, tokens left over from the replaced tasks are reused
+	tokens := pending - len(s.taskIdQueue)
+	if free := cap(s.taskIdQueue) - len(s.taskIdQueue); tokens > free {
+		return fmt.Errorf("%w: %d pending tasks in snapshot, room for %d", ErrQueueFull, pending, cap(s.taskIdQueue))
+	}
+
+	s.state.RobotState = robot
+	s.trackDwellLocked()
+	s.state.Battery = 0
+	if s.config.BatteryCapacity > 0 {
+		s.state.Battery = min(max(snapshot.Battery, 0), s.config.BatteryCapacity)
+	}
+	if s.webhook != nil {
+		for taskID := range s.state.Tasks {
+			if _, restored := tasks[taskID]; !restored {
+				s.webhook.forget(taskID)
+			}
+		}
+	}
+	s.state.Tasks = tasks
+	s.uncancels = make(map[string]time.Time)
+	s.state.CurTaskCount = curTaskCount
+	for _, task := range tasks {
+		if tokens <= 0 {
+			break
+		}
+		if task.State != Pending {
+			continue
+		}
+		select {
+		case s.taskIdQueue <- task.ID:
+		default:
+			go s.dispatchWhenFree(task.ID) // A preempting submission took the free slot meanwhile
+		}
+		tokens--
+	}
+
+	log.Printf("State restored: robot at (%d, %d) facing %s, %d tasks of which %d pending", robot.X, robot.Y, robot.Heading, len(tasks), pending)
+	return nil
+}
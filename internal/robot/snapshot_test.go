@@ -0,0 +1,171 @@
+package robot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestSnapshotRoundTrip tests that restoring a serialized snapshot after mutating the service brings back the snapshotted state.
+func TestSnapshotRoundTrip(t *testing.T) {
+	taskIdQueue := make(chan string, 10)
+	service := NewService(context.Background(), taskIdQueue)
+
+	done, _ := service.EnqueueTask("N E", "0s")
+	<-taskIdQueue
+	if err := service.ExecuteTask(done); err != nil {
+		t.Fatalf("Failed to execute task: %v", err)
+	}
+	service.SubmitTask(TaskSpec{Commands: "S", DelayBetweenCommands: "0s", Priority: 2})
+	service.EnqueueTask("E E", "1s")
+
+	data, err := json.Marshal(service.Snapshot())
+	if err != nil {
+		t.Fatalf("Failed to serialize snapshot: %v", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("Failed to deserialize snapshot: %v", err)
+	}
+
+	// Mutate the robot and the tasks
+	service.SetPosition(7, 7)
+	service.EnqueueTask("W", "0s")
+	service.CancelTask(snapshot.Tasks[1].ID)
+	for len(taskIdQueue) > 0 {
+		<-taskIdQueue
+	}
+
+	if err := service.Restore(snapshot); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+
+	restored, _ := json.Marshal(service.Snapshot())
+	if string(restored) != string(data) {
+		t.Errorf("Expected restored state\n%s\ngot\n%s", data, restored)
+	}
+	if len(taskIdQueue) != 2 {
+		t.Errorf("Expected 2 dispatch tokens for the pending tasks, got %d", len(taskIdQueue))
+	}
+
+	// The restored pending tasks are executable, the sequence continues after the snapshot
+	taskID, _ := service.EnqueueTask("N", "0s")
+	if seq := service.CurrentState().Tasks[taskID].SequenceNum; seq != 4 {
+		t.Errorf("Expected sequence number 4 after restore, got %d", seq)
+	}
+	next, ok := service.nextPendingTask()
+	if !ok || next != snapshot.Tasks[1].ID {
+		t.Fatalf("Expected the restored priority task to be dispatched first, got %s", next)
+	}
+	if err := service.ExecuteTask(next); err != nil {
+		t.Errorf("Failed to execute restored task: %v", err)
+	}
+}
+
+// TestRestoreErrors tests that invalid snapshots and restores during execution are rejected without changing the state.
+func TestRestoreErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		running  bool
+		snapshot Snapshot
+		wantErr  error
+	}{
+		{"Task running", true, Snapshot{}, ErrInvalidState},
+		{"Robot outside", false, Snapshot{RobotState: RobotState{X: warehouseSize}}, ErrOutOfBounds},
+		{"Too many pending tasks", false, Snapshot{Tasks: []RobotTask{{ID: "a"}, {ID: "b"}}}, ErrQueueFull},
+		{"Duplicate task", false, Snapshot{Tasks: []RobotTask{{ID: "a", State: Completed}, {ID: "a", State: Completed}}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(context.Background(), make(chan string, 1))
+			service.SetPosition(2, 2)
+			taskID, _ := service.EnqueueTask("N", "0s")
+			if tt.running {
+				service.UpdateTaskState(taskID, InProgress, ReasonDispatched)
+			}
+
+			err := service.Restore(tt.snapshot)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if state := service.CurrentState(); len(state.Tasks) != 1 || state.RobotState.X != 2 {
+				t.Errorf("Expected state to be unchanged, got %+v", state)
+			}
+		})
+	}
+}
+
+// TestRestoreInterruptedTask tests that a task running when the snapshot was taken is restored as Aborted.
+func TestRestoreInterruptedTask(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 1))
+	snapshot := Snapshot{
+		RobotState: RobotState{X: 1, Y: 2, Heading: HeadingEast},
+		Tasks:      []RobotTask{{ID: "running", State: InProgress, SequenceNum: 3}},
+	}
+
+	if err := service.Restore(snapshot); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	state := service.CurrentState()
+	if task := state.Tasks["running"]; task.State != Aborted {
+		t.Errorf("Expected interrupted task to be Aborted, got %s", task.State)
+	}
+	if state.CurTaskCount != 3 {
+		t.Errorf("Expected task count to follow the highest sequence number 3, got %d", state.CurTaskCount)
+	}
+	if state.RobotState != snapshot.RobotState {
+		t.Errorf("Expected robot state %+v, got %+v", snapshot.RobotState, state.RobotState)
+	}
+}
+
+// constantIDGenerator always returns the same ID, like a misconfigured custom generator.
+type constantIDGenerator string
+
+func (g constantIDGenerator) NewID() string {
+	return string(g)
+}
+
+// TestRestoreSequentialIDs tests that tasks submitted after a restore never overwrite restored tasks,
+// even if the ID generator starts over and produces the restored IDs again.
+func TestRestoreSequentialIDs(t *testing.T) {
+	newSequentialService := func() *Service {
+		config := DefaultConfig()
+		config.IDGenerator = NewSequentialIDGenerator()
+		return NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	}
+	previous := newSequentialService()
+	previous.EnqueueTask("N", "0s")
+	previous.EnqueueTask("E", "0s")
+	snapshot := previous.Snapshot()
+
+	service := newSequentialService()
+	if err := service.Restore(snapshot); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	taskID, err := service.EnqueueTask("S", "0s")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+	batch, err := service.SubmitBatch([]TaskSpec{{Commands: "W", DelayBetweenCommands: "0s"}})
+	if err != nil {
+		t.Fatalf("Failed to submit batch: %v", err)
+	}
+	if taskID != "task-3" || batch[0] != "task-4" {
+		t.Errorf("Expected the IDs after the restored ones, got %s and %s", taskID, batch[0])
+	}
+	state := service.CurrentState()
+	if len(state.Tasks) != 4 || state.Tasks["task-1"].Commands.String() != "N" || state.Tasks["task-2"].Commands.String() != "E" {
+		t.Errorf("Expected the restored tasks kept next to the new ones, got %+v", state.Tasks)
+	}
+
+	// A generator that only produces taken IDs is refused instead of overwriting a task
+	service.config.IDGenerator = constantIDGenerator("task-1")
+	if _, err := service.EnqueueTask("N", "0s"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected ErrInvalidState for a taken ID, got %v", err)
+	}
+	if commands := service.CurrentState().Tasks["task-1"].Commands.String(); commands != "N" {
+		t.Errorf("Expected task-1 untouched, got commands %s", commands)
+	}
+}
@@ -1,19 +1,24 @@
 package robot
 
 type RobotState struct {
-	X uint `json:"x"` // Current X coordinate of the robot
-	Y uint `json:"y"` // Current Y coordinate of the robot
+	X       uint    `json:"x"`                                        // Current X coordinate of the robot
+	Y       uint    `json:"y"`                                        // Current Y coordinate of the robot
+	Heading Heading `json:"heading" swaggertype:"string" example:"N"` // Direction the robot is facing
 }
 
 type ServiceState struct {
-	RobotState   RobotState           `json:"robot_state"`        // Current state of the robot
-	Tasks        map[string]RobotTask `json:"tasks"`              // Map of task IDs to RobotTask objects
-	CurTaskCount int                  `json:"current_task_count"` // Current number of tasks in the service
+	Mode         ServiceMode          `json:"mode" swaggertype:"string" example:"Running"` // Whether the service accepts new tasks
+	RobotState   RobotState           `json:"robot_state"`                                 // Current state of the robot
+	Tasks        map[string]RobotTask `json:"tasks"`                                       // Map of task IDs to RobotTask objects
+	CurTaskCount int                  `json:"current_task_count"`                          // Current number of tasks in the service
+	Battery      int                  `json:"battery,omitempty"`                           // Battery level of the robot, omitted when the battery simulation is disabled
 }
 
-func NewServiceState() ServiceState {
+// NewServiceState returns the initial service state with the robot at the origin facing initialHeading.
+func NewServiceState(initialHeading Heading) ServiceState {
 	return ServiceState{
-		RobotState: RobotState{X: 0, Y: 0}, // Initialize robot at origin
+		RobotState: RobotState{X: 0, Y: 0, Heading: initialHeading}, // Initialize robot at origin
 		Tasks:      make(map[string]RobotTask),
+		Mode:       ModeRunning,
 	}
 }
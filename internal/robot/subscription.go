@@ -0,0 +1,169 @@
+package robot
+
+import (
+	"errors"
+	"log"
+	"sync"
+)
+
+// subscriberBufferSize is the number of events buffered per subscriber before events get dropped.
+const subscriberBufferSize = 100
+
+// ErrTooManySubscribers is returned by Subscribe when the configured subscriber cap is reached.
+var ErrTooManySubscribers = errors.New("maximum number of event subscribers reached")
+
+// ErrShuttingDown is returned by Subscribe once the subscriptions have been closed for shutdown,
+// and by task submission once the service context is cancelled.
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// ErrSubscriberLagging is reported by Subscription.Err once a subscriber was disconnected for falling behind,
+// see Config.MaxSubscriberLag.
+var ErrSubscriberLagging = errors.New("event subscriber fell too far behind")
+
+// Subscription represents a single consumer of task status update events.
+// Every subscription receives its own copy of each published event.
+type Subscription interface {
+	// Events returns the channel on which events are delivered.
+	// The channel is closed when the subscription is closed.
+	Events() <-chan TaskStatusUpdateEvent
+
+	// Close unsubscribes from the event stream and releases the subscriber slot.
+	// It is safe to call Close multiple times.
+	Close()
+
+	// Err returns why the service closed the events channel: ErrSubscriberLagging if the subscriber fell
+	// too far behind, ErrShuttingDown on shutdown, and nil while the subscription is open or closed by Close.
+	Err() error
+}
+
+// subscriber is the Service implementation of Subscription.
+type subscriber struct {
+	events  chan TaskStatusUpdateEvent
+	once    sync.Once
+	service *Service
+	lag     int   // Number of consecutive events missed because of a full buffer, guarded by the subscribers lock
+	err     error // Why the service closed the subscription, guarded by the subscribers lock
+}
+
+func (sub *subscriber) Events() <-chan TaskStatusUpdateEvent {
+	return sub.events
+}
+
+func (sub *subscriber) Err() error {
+	sub.service.subMu.Lock()
+	defer sub.service.subMu.Unlock()
+	return sub.err
+}
+
+func (sub *subscriber) Close() {
+	sub.once.Do(func() {
+		sub.service.unsubscribe(sub)
+	})
+}
+
+// Subscribe registers a new event subscriber.
+// It returns ErrTooManySubscribers if the maximum number of concurrent subscribers is reached.
+func (s *Service) Subscribe() (Subscription, error) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	return s.subscribeLocked()
+}
+
+// subscribeLocked registers a new event subscriber. The caller must hold the subscribers lock.
+func (s *Service) subscribeLocked() (Subscription, error) {
+	if s.subClosed {
+		return nil, ErrShuttingDown
+	}
+
+	maxSubscribers := int64(s.config.MaxSubscribers)
+	if maxSubscribers > 0 && s.activeSubscribers.Load() >= maxSubscribers {
+		return nil, ErrTooManySubscribers
+	}
+
+	sub := &subscriber{
+		events:  make(chan TaskStatusUpdateEvent, subscriberBufferSize),
+		service: s,
+	}
+	s.subscribers[sub] = struct{}{}
+	count := s.activeSubscribers.Add(1)
+	log.Printf("Event subscriber added, active subscribers: %d", count)
+
+	return sub, nil
+}
+
+// CloseSubscriptions closes every event and telemetry subscription and rejects new ones, e.g. on graceful shutdown.
+// Consumers see their events channel closed and can say goodbye to their clients. Telemetry samples stop
+// after the current interval, telemetry consumers that must react right away watch the Closing channel.
+func (s *Service) CloseSubscriptions() {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	if !s.subClosed {
+		close(s.closing)
+	}
+	s.subClosed = true
+	for sub := range s.subscribers {
+		s.dropSubscriberLocked(sub, ErrShuttingDown)
+	}
+	log.Println("All event subscriptions closed")
+}
+
+// Closing returns a channel that is closed by CloseSubscriptions, so streams to clients that do not subscribe
+// to the events can close their connection on shutdown as well.
+func (s *Service) Closing() <-chan struct{} {
+	return s.closing
+}
+
+// SubscriberCount returns the number of currently active event subscribers.
+func (s *Service) SubscriberCount() int {
+	return int(s.activeSubscribers.Load())
+}
+
+func (s *Service) unsubscribe(sub *subscriber) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	if _, exists := s.subscribers[sub]; !exists {
+		return
+	}
+	count := s.dropSubscriberLocked(sub, nil)
+	log.Printf("Event subscriber removed, active subscribers: %d", count)
+}
+
+// dropSubscriberLocked removes the subscriber and closes its events channel, recording err as the reason.
+// It returns the number of remaining subscribers. The caller must hold the subscribers lock.
+func (s *Service) dropSubscriberLocked(sub *subscriber, err error) int64 {
+	delete(s.subscribers, sub)
+	sub.err = err
+	close(sub.events)
+	return s.activeSubscribers.Add(-1)
+}
+
+// broadcast assigns the next sequence number to the event, keeps it for replay and delivers it
+// to every subscriber without blocking. Subscribers whose buffer is full miss the event, and are disconnected
+// once they missed Config.MaxSubscriberLag events in a row, so they cannot hold the other subscribers back.
+// In strict sequence mode they are disconnected right away, so no subscriber sees a gap.
+func (s *Service) broadcast(event TaskStatusUpdateEvent) TaskStatusUpdateEvent {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	s.lastSeq++
+	event.Seq = s.lastSeq
+	s.eventLog.push(event)
+
+	for sub := range s.subscribers {
+		select {
+		case sub.events <- event:
+			sub.lag = 0
+		default:
+			sub.lag++
+			dropped := s.eventsDropped.Add(1)
+			log.Printf("Subscriber buffer full, dropped event for task %s (%d dropped in total)", event.TaskID, dropped)
+			if maxLag := s.config.MaxSubscriberLag; s.config.StrictSequence || (maxLag > 0 && sub.lag >= maxLag) {
+				count := s.dropSubscriberLocked(sub, ErrSubscriberLagging)
+				log.Printf("Event subscriber disconnected after missing %d events in a row, active subscribers: %d", sub.lag, count)
+			}
+		}
+	}
+	return event
+}
@@ -0,0 +1,221 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSubscribeCap tests that subscribing beyond the configured cap fails and that unsubscribing frees a slot.
+func TestSubscribeCap(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxSubscribers = 2
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	subs := make([]Subscription, 0, config.MaxSubscribers)
+	for i := 0; i < config.MaxSubscribers; i++ {
+		sub, err := service.Subscribe()
+		if err != nil {
+			t.Fatalf("Subscriber %d: unexpected error: %v", i, err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if service.SubscriberCount() != config.MaxSubscribers {
+		t.Errorf("Expected %d active subscribers, got %d", config.MaxSubscribers, service.SubscriberCount())
+	}
+
+	// Subscribing beyond the cap must fail
+	if _, err := service.Subscribe(); !errors.Is(err, ErrTooManySubscribers) {
+		t.Errorf("Expected ErrTooManySubscribers, got %v", err)
+	}
+
+	// Closing a subscription frees a slot, closing twice must not free two
+	subs[0].Close()
+	subs[0].Close()
+	if service.SubscriberCount() != config.MaxSubscribers-1 {
+		t.Errorf("Expected %d active subscribers, got %d", config.MaxSubscribers-1, service.SubscriberCount())
+	}
+
+	if _, err := service.Subscribe(); err != nil {
+		t.Errorf("Expected subscribe to succeed after unsubscribe, got %v", err)
+	}
+}
+
+// TestSubscribeFanOut tests that every subscriber receives each published event.
+func TestSubscribeFanOut(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+
+	first, err := service.Subscribe()
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer first.Close()
+	second, err := service.Subscribe()
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer second.Close()
+
+	taskID, err := service.EnqueueTask("N", "10ms")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+
+	for i, sub := range []Subscription{first, second} {
+		select {
+		case event := <-sub.Events():
+			if event.TaskID != taskID || event.State != Pending {
+				t.Errorf("Subscriber %d: unexpected event %+v", i, event)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("Subscriber %d: did not receive event within timeout", i)
+		}
+	}
+}
+
+// TestDroppedEvents tests that events not delivered to a full subscriber are counted and optionally mark the service unhealthy.
+func TestDroppedEvents(t *testing.T) {
+	for _, fatal := range []bool{false, true} {
+		config := DefaultConfig()
+		config.DroppedEventsUnhealthy = fatal
+		service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+		sub, _ := service.Subscribe()
+		defer sub.Close()
+
+		for i := 0; i < subscriberBufferSize; i++ {
+			service.publishEvent("task-1", InProgress, "", "")
+		}
+		if stats := service.Stats(); stats.EventsDropped != 0 || stats.Unhealthy {
+			t.Fatalf("Expected no dropped events while the buffer has room, got %+v", stats)
+		}
+
+		service.publishEvent("task-1", InProgress, "", "")
+		service.publishEvent("task-1", Completed, "", "")
+		stats := service.Stats()
+		if stats.EventsDropped != 2 {
+			t.Errorf("Expected 2 dropped events, got %d", stats.EventsDropped)
+		}
+		if stats.Unhealthy != fatal {
+			t.Errorf("Expected unhealthy %t with fatal drops %t, got %t", fatal, fatal, stats.Unhealthy)
+		}
+	}
+}
+
+// TestSubscriberLag tests that only a subscriber missing too many events in a row is disconnected.
+func TestSubscriberLag(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxSubscriberLag = 3
+	service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+	slow, _ := service.Subscribe()
+	defer slow.Close()
+	fast, _ := service.Subscribe()
+	defer fast.Close()
+
+	publish := func(n int) {
+		for i := 0; i < n; i++ {
+			service.publishEvent("task-1", InProgress, "", "")
+			<-fast.Events()
+		}
+	}
+
+	// Missing fewer events than the limit keeps the slow subscriber, a delivered event resets its lag
+	publish(subscriberBufferSize + config.MaxSubscriberLag - 1)
+	<-slow.Events()
+	publish(config.MaxSubscriberLag)
+	if service.SubscriberCount() != 2 || slow.Err() != nil {
+		t.Fatalf("Expected the slow subscriber to stay connected, got %d subscribers and error %v", service.SubscriberCount(), slow.Err())
+	}
+
+	// One more missed event in a row disconnects it, the fast subscriber keeps receiving events
+	publish(1)
+	if !errors.Is(slow.Err(), ErrSubscriberLagging) {
+		t.Errorf("Expected ErrSubscriberLagging for the slow subscriber, got %v", slow.Err())
+	}
+	if service.SubscriberCount() != 1 || fast.Err() != nil {
+		t.Errorf("Expected only the fast subscriber to stay connected, got %d subscribers and error %v", service.SubscriberCount(), fast.Err())
+	}
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	if received != subscriberBufferSize {
+		t.Errorf("Expected the slow subscriber to drain %d buffered events before its channel closed, got %d", subscriberBufferSize, received)
+	}
+	publish(1)
+}
+
+// TestMinSubscribedDelay tests that tasks without delay are slowed down only while events have subscribers.
+func TestMinSubscribedDelay(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	config.MinSubscribedDelay = time.Millisecond
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	run := func(delay string) time.Duration {
+		t.Helper()
+		taskID, _ := service.EnqueueTask("N S N S", delay)
+		start := clock.Now()
+		if err := service.ExecuteTask(taskID); err != nil {
+			t.Fatalf("Failed to execute task: %v", err)
+		}
+		return clock.Now().Sub(start)
+	}
+
+	if took := run("0s"); took != 0 {
+		t.Errorf("Expected no delay without subscribers, took %s", took)
+	}
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+	if took := run("0s"); took != 4*time.Millisecond {
+		t.Errorf("Expected the minimum delay per command with a subscriber, took %s", took)
+	}
+	if took := run("5ms"); took != 20*time.Millisecond {
+		t.Errorf("Expected a longer delay to be kept, took %s", took)
+	}
+}
+
+// TestMinSubscribedDelay_NoDrops tests that a long task without delay does not push the terminal events
+// of other tasks out of a subscriber's buffer.
+func TestMinSubscribedDelay_NoDrops(t *testing.T) {
+	config := DefaultConfig()
+	config.MoveEvents = true
+	config.MinSubscribedDelay = 2 * time.Millisecond
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+
+	// A subscriber needing a little time per event, as a client behind a network connection does
+	completed := make(map[string]bool)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range sub.Events() {
+			if event.Type == "" && event.State == Completed {
+				completed[event.TaskID] = true
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+
+	fast, _ := service.EnqueueTask(strings.Repeat("N S ", 100), "0s")
+	other, _ := service.EnqueueTask("E", "0s")
+	for _, taskID := range []string{fast, other} {
+		if err := service.ExecuteTask(taskID); err != nil {
+			t.Fatalf("Failed to execute task %s: %v", taskID, err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond) // Let the subscriber catch up with the asynchronous state events
+	sub.Close()
+	<-done
+
+	if dropped := service.Stats().EventsDropped; dropped != 0 {
+		t.Errorf("Expected no dropped events, got %d", dropped)
+	}
+	if !completed[fast] || !completed[other] {
+		t.Errorf("Expected the Completed events of both tasks, got %v", completed)
+	}
+}
@@ -0,0 +1,78 @@
+package robot
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// dispatchRestartDelay is the pause before a dead dispatch loop is restarted, so a loop failing over and over
+// does not spin.
+const dispatchRestartDelay = 100 * time.Millisecond
+
+// superviseDispatchLoop runs the dispatch loop and restarts it whenever it stops before the context is cancelled,
+// e.g. because a task execution panicked. The task that was running is aborted rather than executed again,
+// as some of its commands may already have moved the robot. Pending tasks keep their queue tokens.
+func (s *Service) superviseDispatchLoop() {
+	for restarts := 0; ; restarts++ {
+		reason := s.runDispatchLoop()
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("Dispatch loop stopped unexpectedly (%s), restarting (restart %d)", reason, restarts+1)
+		s.abortInterruptedTask(reason)
+		s.checkDrained()
+		s.checkIdle()
+		s.config.Clock.Sleep(dispatchRestartDelay)
+	}
+}
+
+// runDispatchLoop runs the dispatch loop once and returns why it stopped.
+func (s *Service) runDispatchLoop() (reason string) {
+	defer func() {
+		if r := recover(); r != nil {
+			reason = fmt.Sprintf("panic: %v", r)
+		}
+	}()
+	s.dispatchLoop()
+	return "loop exited"
+}
+
+// abortInterruptedTask aborts the task the dispatch loop was executing when it died, keeping the robot position
+// and the number of executed commands as its progress.
+func (s *Service) abortInterruptedTask(reason string) {
+	s.mu.Lock()
+	task, running := s.runningTaskLocked()
+	if running {
+		task.State = Aborted
+		task.Reason = ReasonDispatcherFailed
+		s.stampAborted(&task)
+		task.Error = "Task interrupted by a dispatch loop restart: " + reason
+		executed := 0
+		if s.executing.taskID == task.ID {
+			executed = s.executing.executed
+		}
+		task.Progress = &TaskProgress{CommandsExecuted: executed, FinalPosition: s.state.RobotState}
+		s.state.Tasks[task.ID] = task
+	}
+	s.mu.Unlock()
+
+	if running {
+		log.Printf("Task %s aborted after %d commands: %s", task.ID, task.Progress.CommandsExecuted, task.Error)
+		s.publish(TaskStatusUpdateEvent{TaskID: task.ID, State: Aborted, Reason: task.Reason, Error: task.Error, Progress: task.Progress})
+	}
+}
+
+// executingTask tracks the commands executed of the running task, so its progress survives a dispatch loop crash.
+type executingTask struct {
+	taskID   string
+	executed int
+}
+
+// markExecuted records that the first executed commands of the task ran successfully.
+func (s *Service) markExecuted(taskID string, executed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executing = executingTask{taskID: taskID, executed: executed}
+}
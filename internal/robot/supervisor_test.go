@@ -0,0 +1,57 @@
+package robot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDispatchLoopRestart tests that the dispatch loop is restarted after a crash, aborts the interrupted task
+// without executing it again and resumes processing the queued tasks.
+func TestDispatchLoopRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := NewService(ctx, make(chan string, 10))
+
+	// The 2nd move of the first task crashes the dispatch loop
+	moves := 0
+	service.cellBlocked = func(x, y int) bool {
+		moves++
+		if moves == 2 {
+			panic("simulated crash")
+		}
+		return false
+	}
+
+	crashed, _ := service.EnqueueTask("N N N", "0s")
+	next, _ := service.EnqueueTask("E", "0s")
+	go service.Start()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if state, _ := service.GetTaskState(next); state == Completed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the queued task to complete after the restart, state %v", service.CurrentState().Tasks[next].State)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	task := service.CurrentState().Tasks[crashed]
+	if task.State != Aborted || !strings.Contains(task.Error, "simulated crash") {
+		t.Errorf("Expected the interrupted task to be aborted with the crash reason, got '%s': %s", task.State, task.Error)
+	}
+	want := TaskProgress{CommandsExecuted: 1, FinalPosition: RobotState{X: 0, Y: 1, Heading: HeadingNorth}}
+	if task.Progress == nil || *task.Progress != want {
+		t.Errorf("Expected progress %+v, got %+v", want, task.Progress)
+	}
+	// 1 move of the crashed task, the crashing attempt and 1 move of the next task, nothing was executed twice
+	if moves != 3 {
+		t.Errorf("Expected 3 move attempts, got %d", moves)
+	}
+	if got := service.GetRobotState(); got.X != 1 || got.Y != 1 {
+		t.Errorf("Expected robot at (1, 1), got (%d, %d)", got.X, got.Y)
+	}
+}
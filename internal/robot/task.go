@@ -5,13 +5,11 @@ import (
 	"fmt"
 	"strings"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // TaskState represents the state of a robot task.
 // @Description Current state of the robot task
-// @Enum Pending InProgress Aborted RequestCancellation Canceled Completed Invalid
+// @Enum Pending InProgress Aborted RequestCancellation Canceled Completed CancelPending Invalid
 type TaskState int
 
 // RobotCommands represents a slice of RobotCommand values.
@@ -41,12 +39,26 @@ func (rc RobotCommands) MarshalJSON() ([]byte, error) {
 	return json.Marshal(rc.String())
 }
 
-func (cd CommandDuration) String() string {
-	return time.Duration(cd).String()
+// UnmarshalJSON parses the space separated command string produced by MarshalJSON.
+func (rc *RobotCommands) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if strings.TrimSpace(raw) == "" {
+		*rc = RobotCommands{} // No-op task
+		return nil
+	}
+	commands, _, _, err := parseCommands(raw)
+	if err != nil {
+		return err
+	}
+	*rc = commands
+	return nil
 }
 
-func (cd CommandDuration) MarshalJSON() ([]byte, error) {
-	return json.Marshal(cd.String())
+func (cd CommandDuration) String() string {
+	return time.Duration(cd).String()
 }
 
 const (
@@ -56,6 +68,7 @@ const (
 	RequestCancellation // Represents a task that has been requested for cancellation via API
 	Canceled            // Represents a task that has been cancelled after cancellation request
 	Completed
+	CancelPending // Represents a pending task cancelled within the uncancel window, it is Canceled once the window passed
 
 	Invalid // Represents an invalid state, can be used for error handling
 )
@@ -75,6 +88,8 @@ func (s TaskState) String() string {
 		return "Canceled"
 	case Completed:
 		return "Completed"
+	case CancelPending:
+		return "CancelPending"
 	case Invalid:
 		return "Invalid"
 	default:
@@ -86,23 +101,180 @@ func (s TaskState) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.String())
 }
 
+// UnmarshalJSON parses the state name produced by MarshalJSON.
+func (s *TaskState) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for state := Pending; state <= Invalid; state++ {
+		if state.String() == raw {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid task state: %s", raw)
+}
+
 type RobotTask struct {
 	ID                   string          `json:"id"`                                                       // Unique identifier for the task
 	Commands             RobotCommands   `json:"commands" swaggertype:"string" example:"N E S W"`          // List of commands to be executed by the robot
 	State                TaskState       `json:"state" swaggertype:"string" example:"Pending"`             // Current state of the task
 	DelayBetweenCommands CommandDuration `json:"delay_between_commands" swaggertype:"string" example:"1s"` // Delay between executing commands
 
-	SequenceNum int    `json:"sequence_num"` // Sequence number for the task, used for ordering tasks in the queue
-	Error       string `json:"error"`        // Error message if the task fails
+	SequenceNum   int               `json:"sequence_num"`             // Sequence number for the task, used for ordering tasks in the queue
+	Priority      int               `json:"priority"`                 // Priority of the task, higher priority tasks are dispatched first
+	GroupID       string            `json:"group_id,omitempty"`       // Group the task belongs to, if any
+	Labels        []string          `json:"labels,omitempty"`         // Free-form labels of the submitter, e.g. the job list a task was imported from
+	CorrelationID string            `json:"correlation_id,omitempty"` // Request ID of the API call that created the task, copied to all its events
+	Ping          bool              `json:"ping,omitempty"`           // True for health check tasks without commands
+	Follow        bool              `json:"follow,omitempty"`         // True for tasks chasing a target cell, see Service.Follow
+	FollowTarget  *Coord            `json:"follow_target,omitempty"`  // Cell a follow task moves to, nil once cleared
+	OnInvalid     InvalidTaskPolicy `json:"on_invalid,omitempty"`     // What happens if the task is invalid when dispatched, see InvalidTaskPolicy
+	Replanned     bool              `json:"replanned,omitempty"`      // True if the commands were re-planned at dispatch
+	Frame         CommandFrame      `json:"frame,omitempty"`          // How the commands are interpreted, see CommandFrame
+	Replaces      string            `json:"replaces,omitempty"`       // ID of the task this task replaced, see Service.ReplaceTask
+	ReplacedBy    string            `json:"replaced_by,omitempty"`    // ID of the task that replaced this task
+	QueueSeq      int               `json:"queue_seq,omitempty"`      // Dispatch position among tasks of equal priority if not the sequence number, set for replacements
+	Error         string            `json:"error"`                    // Error message if the task fails
+	Reason        TransitionReason  `json:"reason,omitempty"`         // Why the task entered its current state, see TransitionReason
+	StartedAt     *time.Time        `json:"started_at,omitempty"`     // Time at which the task execution started
+	AbortedAt     *time.Time        `json:"aborted_at,omitempty"`     // Time at which the task was aborted, see Service.RecentAborts
+
+	AbortCategory AbortCategory `json:"abort_category,omitempty" swaggertype:"string" example:"Obstacle"` // Kind of failure of an aborted task, see AbortCategory
+
+	AllowedCommands RobotCommands `json:"allowed_commands,omitempty" swaggertype:"string" example:"N S"` // Commands the submitter may use, nil allows every command. Checked again at dispatch, once the commands are resolved
+
+	CancelRequestedAt *time.Time `json:"cancel_requested_at,omitempty"` // Time at which the cancellation of the running task was requested
+
+	RelativeTo *Heading `json:"relative_to,omitempty" swaggertype:"string" example:"E"` // Heading the relative commands were resolved against at dispatch, Commands holds the grid directions since
+
+	Progress *TaskProgress `json:"progress,omitempty"` // How far the task got before it was aborted
+
+	Trace []TaskStep `json:"-"` // Executed commands with the resulting positions, see Service.TaskTrace
 
 	// DeltaX and DeltaY represent the change in robot's position after executing the commands
 	DeltaX int `json:"-"` // Change in X coordinate
 	DeltaY int `json:"-"` // Change in Y coordinate
 }
 
+// TaskProgress reports how far an aborted task got, so clients can decide how to recover.
+// @Description Progress of an aborted task
+type TaskProgress struct {
+	CommandsExecuted int        `json:"commands_executed" example:"2"` // Number of commands executed successfully
+	FinalPosition    RobotState `json:"final_position"`                // Robot position when the task stopped
+}
+
+// TaskSpec describes a task submitted to the robot service.
+type TaskSpec struct {
+	Commands             string   // Raw space separated command sequence, e.g. "N E S W"
+	DelayBetweenCommands string   // Optional delay between commands, e.g. "1s"
+	Priority             int      // Optional priority, higher priority tasks are dispatched first
+	GroupID              string   // Optional group the task belongs to, groups can be monitored and cancelled as a whole
+	NewGroup             bool     // Start a new group named after the task's own ID, exclusive with GroupID
+	Labels               []string // Optional free-form labels, at most maxTaskLabels of at most maxLabelLength characters
+	Ping                 bool     // A health check task without commands, completing without moving the robot
+	FollowTarget         *Coord   // Chase this cell instead of executing commands, see Service.Follow
+	OnInvalid            string   // Optional policy for a task invalid when dispatched: abort (default), skip or replan
+	Frame                string   // Optional frame of the commands: absolute or relative, the service default if empty
+	CorrelationID        string   // Optional request ID of the API call creating the task, copied to all its events
+	CaseInsensitive      bool     // Accept the commands in any case, e.g. "n e s w", also set for all tasks by the service config
+
+	// Commands the submitter may use, e.g. as configured for its client, nil allows all commands
+	AllowedCommands []RobotCommand
+}
+
+// Limits of the labels of a task
+const (
+	maxTaskLabels  = 10
+	maxLabelLength = 64
+)
+
+// checkLabels returns an error if the labels exceed the limits or one of them is blank.
+func checkLabels(labels []string) error {
+	if len(labels) > maxTaskLabels {
+		return fmt.Errorf("a task has at most %d labels, got %d", maxTaskLabels, len(labels))
+	}
+	for _, label := range labels {
+		if strings.TrimSpace(label) == "" || len(label) > maxLabelLength {
+			return fmt.Errorf("invalid label %q: labels are non-blank and at most %d characters long", label, maxLabelLength)
+		}
+	}
+	return nil
+}
+
+// Duration returns the estimated time needed to execute all commands of the task, including holds.
+func (t RobotTask) Duration() time.Duration {
+	return time.Duration(len(t.Commands))*time.Duration(t.DelayBetweenCommands) + t.Commands.holdTime()
+}
+
+// holdTime returns the total duration of the hold commands.
+func (rc RobotCommands) holdTime() time.Duration {
+	var total time.Duration
+	for _, cmd := range rc {
+		if duration, ok := cmd.Hold(); ok {
+			total += duration
+		}
+	}
+	return total
+}
+
+// moves returns the number of commands moving the robot, i.e. all commands but holds.
+func (rc RobotCommands) moves() int {
+	moves := 0
+	for _, cmd := range rc {
+		if _, ok := cmd.Hold(); !ok {
+			moves++
+		}
+	}
+	return moves
+}
+
 // NewTask creates a new RobotTask from a raw command sequence string.
 // It parses the string into individual RobotCommand values and initializes the task state to Pending.
 func NewTask(rawCmdSequence string, delayBetweenCommandsStr string) (*RobotTask, error) {
+	return newTask(TaskSpec{Commands: rawCmdSequence, DelayBetweenCommands: delayBetweenCommandsStr}, UUIDGenerator{}, false)
+}
+
+// newTask creates a new RobotTask from the spec, using idGenerator to assign the task ID.
+// If allowEmpty is set, a spec without commands yields a no-op task instead of an error.
+func newTask(spec TaskSpec, idGenerator IDGenerator, allowEmpty bool) (*RobotTask, error) {
+	rawCmdSequence, delayBetweenCommandsStr := spec.Commands, spec.DelayBetweenCommands
+	if spec.CaseInsensitive {
+		rawCmdSequence = strings.ToUpper(rawCmdSequence)
+	}
+	if spec.NewGroup && spec.GroupID != "" {
+		return nil, fmt.Errorf("a task cannot join group %s and start a new group", spec.GroupID)
+	}
+	if err := checkLabels(spec.Labels); err != nil {
+		return nil, err
+	}
+	onInvalid, err := ParseInvalidTaskPolicy(spec.OnInvalid)
+	if err != nil {
+		return nil, err
+	}
+	var frame CommandFrame // Left empty for the service default
+	if spec.Frame != "" {
+		if frame, err = ParseCommandFrame(spec.Frame); err != nil {
+			return nil, err
+		}
+	}
+	if spec.Ping {
+		if strings.TrimSpace(rawCmdSequence) != "" {
+			return nil, fmt.Errorf("%w: a ping task has no commands", ErrInvalidCommand)
+		}
+		allowEmpty = true
+	}
+	if spec.FollowTarget != nil {
+		if strings.TrimSpace(rawCmdSequence) != "" {
+			return nil, fmt.Errorf("%w: a follow task has no commands", ErrInvalidCommand)
+		}
+		// The path is planned while the task runs, so it may take any move
+		if err := checkAllowed(moveCommands, spec.AllowedCommands); err != nil {
+			return nil, fmt.Errorf("a follow task may move in any direction: %w", err)
+		}
+		allowEmpty = true
+	}
 
 	delayBetweenCommands := defaultDelayBetweenCommands // Default delay is set to 1 second
 
@@ -110,67 +282,117 @@ func NewTask(rawCmdSequence string, delayBetweenCommandsStr string) (*RobotTask,
 		// Parse the delay and set it in the task
 		duration, err := time.ParseDuration(delayBetweenCommandsStr)
 		if err != nil {
-			return nil, fmt.Errorf("invalid delay format: %v", err)
+			return nil, fmt.Errorf("%w: invalid delay format: %v", ErrInvalidCommand, err)
 		}
 		delayBetweenCommands = CommandDuration(duration) // Set the delay in the task
 	}
 
-	commands, deltaX, deltaY, err := parseCommands(rawCmdSequence)
-	if err != nil {
-		return nil, err
+	commands := []RobotCommand{} // An empty task is a no-op and completes as soon as it is dispatched
+	deltaX, deltaY := 0, 0
+	if !allowEmpty || strings.TrimSpace(rawCmdSequence) != "" {
+		var err error
+		commands, deltaX, deltaY, err = parseCommands(rawCmdSequence)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return &RobotTask{
-		ID:                   uuid.New().String(),
+	task := &RobotTask{
+		ID:                   idGenerator.NewID(),
 		Commands:             commands,
 		DelayBetweenCommands: delayBetweenCommands,
+		Priority:             spec.Priority,
+		GroupID:              spec.GroupID,
+		Labels:               append([]string(nil), spec.Labels...),
+		OnInvalid:            onInvalid,
+		Frame:                frame,
+		AllowedCommands:      spec.AllowedCommands,
+		CorrelationID:        spec.CorrelationID,
+		Ping:                 spec.Ping,
 		State:                Pending,
+		Reason:               ReasonSubmitted,
 		DeltaX:               deltaX,
 		DeltaY:               deltaY,
-	}, nil
+	}
+	if spec.NewGroup {
+		task.GroupID = task.ID
+	}
+	if spec.FollowTarget != nil {
+		target := *spec.FollowTarget
+		task.Follow = true
+		task.FollowTarget = &target
+	}
+	return task, nil
 }
 
-// removeEmptyStrings removes empty strings from a slice of strings.
-// This is useful for cleaning up command sequences that may have extra spaces.
-func removeEmptyStrings(slice []string) []string {
-	var result []string
-	for _, s := range slice {
-		if strings.TrimSpace(s) != "" {
-			result = append(result, s)
-		}
+// NormalizeCommands returns the canonical form of a raw command sequence, i.e. exactly what will be executed,
+// with irregular whitespace collapsed to single spaces.
+func NormalizeCommands(rawCmdSequence string) (string, error) {
+	commands, _, _, err := parseCommands(rawCmdSequence)
+	if err != nil {
+		return "", err
 	}
-	return result
+	return RobotCommands(commands).String(), nil
 }
 
 // parseCommands takes a raw command sequence string and converts it into a slice of RobotCommand.
 // It returns an error if any command in the sequence is invalid.
+//
+// The sequence is scanned one command at a time and parsing stops at the first command moving the robot further
+// from its start than the warehouse is wide, as it leaves the warehouse from any start cell. Enormous sequences
+// are thus rejected early with the failing index, without splitting or simulating the rest of them.
 func parseCommands(raw string) ([]RobotCommand, int, int, error) {
-	parts := strings.Split(raw, " ")
-	parts = removeEmptyStrings(parts) // Remove any empty strings from the split
+	commands := []RobotCommand{}
 	deltaX, deltaY := 0, 0
-	if len(parts) == 0 {
-		return nil, deltaX, deltaY, fmt.Errorf("no commands provided")
-	}
-
-	commands := make([]RobotCommand, 0, len(parts))
-
-	for _, p := range parts {
-		switch p {
-		case "N":
-			deltaY++
-			commands = append(commands, North)
-		case "W":
-			deltaX--
-			commands = append(commands, West)
-		case "E":
-			deltaX++
-			commands = append(commands, East)
-		case "S":
-			deltaY--
-			commands = append(commands, South)
-		default:
-			return nil, deltaX, deltaY, fmt.Errorf("invalid command: %s", p)
+	minX, maxX, minY, maxY := 0, 0, 0, 0 // Bounding box of the path relative to its start
+
+	for rest := raw; rest != ""; {
+		var p string
+		p, rest = nextToken(rest)
+		if strings.TrimSpace(p) == "" {
+			continue // Extra spaces between commands are allowed
+		}
+		if p == holdToken {
+			var arg string
+			arg, rest = nextToken(rest)
+			cmd, err := parseHold(arg)
+			if err != nil {
+				return nil, deltaX, deltaY, fmt.Errorf("command %d: %w", len(commands)+1, err)
+			}
+			commands = append(commands, cmd)
+			continue // A hold does not move the robot
+		}
+
+		cmd, ok := lookupCommand(p)
+		if !ok {
+			if suggestion, found := suggestCommand(p); found {
+				return nil, deltaX, deltaY, fmt.Errorf("%w: %s (did you mean '%s'?)", ErrInvalidCommand, p, suggestion)
+			}
+			return nil, deltaX, deltaY, fmt.Errorf("%w: %s", ErrInvalidCommand, p)
+		}
+		dx, dy := cmd.Delta()
+		deltaX += dx
+		deltaY += dy
+		commands = append(commands, cmd)
+
+		minX, maxX = min(minX, deltaX), max(maxX, deltaX)
+		minY, maxY = min(minY, deltaY), max(maxY, deltaY)
+		if maxX-minX >= warehouseSize || maxY-minY >= warehouseSize {
+			return nil, deltaX, deltaY, fmt.Errorf("%w: command %d (%s) leaves the warehouse from any start cell, the path spans more than %d cells",
+				ErrOutOfBounds, len(commands), cmd, warehouseSize)
 		}
 	}
+	if len(commands) == 0 {
+		return nil, deltaX, deltaY, fmt.Errorf("%w: no commands provided", ErrInvalidCommand)
+	}
 	return commands, deltaX, deltaY, nil
 }
+
+// nextToken splits the next space separated token off a command sequence, skipping leading spaces.
+func nextToken(sequence string) (token, rest string) {
+	sequence = strings.TrimLeft(sequence, " ")
+	if i := strings.IndexByte(sequence, ' '); i >= 0 {
+		return sequence[:i], sequence[i+1:]
+	}
+	return sequence, ""
+}
@@ -1,7 +1,10 @@
 package robot
 
 import (
+	"context"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -63,3 +66,116 @@ func TestNewTask(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"Already canonical", "N E S W", "N E S W", false},
+		{"Leading and trailing spaces", "  N E  ", "N E", false},
+		{"Repeated spaces", "N    E S     W", "N E S W", false},
+		{"Invalid command", "N  X", "", true},
+		{"Whitespace only", "    ", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeCommands(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeCommands() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeCommands() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTask_Labels(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  []string
+		wantErr bool
+	}{
+		{"No labels", nil, false},
+		{"Valid labels", []string{"dock", "night shift"}, false},
+		{"Blank label", []string{"dock", " "}, true},
+		{"Label too long", []string{strings.Repeat("a", maxLabelLength+1)}, true},
+		{"Too many labels", make([]string, maxTaskLabels+1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := newTask(TaskSpec{Commands: "N", Labels: tt.labels}, UUIDGenerator{}, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(task.Labels, tt.labels) && len(tt.labels) > 0 {
+				t.Errorf("newTask() Labels = %v, want %v", task.Labels, tt.labels)
+			}
+		})
+	}
+}
+
+// TestParseCommands_EarlyTermination tests that parsing stops at the first command spanning more than the
+// warehouse, reporting its index, instead of parsing the whole sequence.
+func TestParseCommands_EarlyTermination(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		wantIndex string
+	}{
+		{"North", strings.Repeat("N ", 1_000_000), "command 10 (N)"},
+		{"East after a detour", "W W " + strings.Repeat("E ", 1_000_000), "command 12 (E)"},
+		{"West with extra spaces", "E    " + strings.Repeat("W   ", 20), "command 11 (W)"},
+		{"Invalid command after the failing index", strings.Repeat("S ", 10) + "X", "command 10 (S)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands, _, _, err := parseCommands(tt.raw)
+			if !errors.Is(err, ErrOutOfBounds) {
+				t.Fatalf("Expected ErrOutOfBounds, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantIndex) {
+				t.Errorf("Expected the error to report %s, got %v", tt.wantIndex, err)
+			}
+			if commands != nil {
+				t.Errorf("Expected no commands, got %d", len(commands))
+			}
+		})
+	}
+
+	// A sequence spanning the warehouse exactly still parses, its start cell decides whether it fits
+	if _, _, _, err := parseCommands(strings.Repeat("E ", warehouseSize-1) + strings.Repeat("W ", warehouseSize-1)); err != nil {
+		t.Errorf("Expected a sequence spanning %d cells to parse, got %v", warehouseSize, err)
+	}
+}
+
+// TestCaseInsensitiveCommands tests that lower case commands are only accepted when the task or the service asks for it.
+func TestCaseInsensitiveCommands(t *testing.T) {
+	if _, err := newTask(TaskSpec{Commands: "n E s w"}, UUIDGenerator{}, false); !errors.Is(err, ErrInvalidCommand) {
+		t.Errorf("Expected lower case commands to be rejected by default, got %v", err)
+	}
+	task, err := newTask(TaskSpec{Commands: "n E s w", CaseInsensitive: true}, UUIDGenerator{}, false)
+	if err != nil {
+		t.Fatalf("Expected lower case commands to be accepted case-insensitively, got %v", err)
+	}
+	if task.Commands.String() != "N E S W" {
+		t.Errorf("Expected commands N E S W, got %s", task.Commands)
+	}
+	if _, err := newTask(TaskSpec{Commands: "n x", CaseInsensitive: true}, UUIDGenerator{}, false); !errors.Is(err, ErrInvalidCommand) {
+		t.Errorf("Expected an unknown command to be rejected in any case, got %v", err)
+	}
+
+	config := DefaultConfig()
+	config.CaseInsensitiveCommands = true
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	taskID, err := service.EnqueueTask("n e", "0s")
+	if err != nil {
+		t.Fatalf("Expected the service to accept lower case commands, got %v", err)
+	}
+	if commands := service.CurrentState().Tasks[taskID].Commands.String(); commands != "N E" {
+		t.Errorf("Expected commands N E, got %s", commands)
+	}
+}
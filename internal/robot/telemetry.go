@@ -0,0 +1,88 @@
+package robot
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// TelemetrySample is the robot state read at one tick of a telemetry stream.
+type TelemetrySample struct {
+	Timestamp  time.Time  // Time of the service clock the state was read at
+	RobotState RobotState // Position and heading of the robot
+}
+
+// TelemetrySubscription streams the robot state at a fixed rate, independent of the task events.
+type TelemetrySubscription interface {
+	// Samples returns the channel on which the samples are delivered, the first one right away.
+	// The channel is closed when the subscription is closed, by Close or on shutdown.
+	Samples() <-chan TelemetrySample
+
+	// Close stops the stream and releases the subscriber slot. It is safe to call Close multiple times.
+	Close()
+}
+
+// telemetrySubscriber is the Service implementation of TelemetrySubscription.
+type telemetrySubscriber struct {
+	samples chan TelemetrySample
+	done    chan struct{}
+	once    sync.Once
+	service *Service
+}
+
+func (sub *telemetrySubscriber) Samples() <-chan TelemetrySample {
+	return sub.samples
+}
+
+func (sub *telemetrySubscriber) Close() {
+	sub.once.Do(func() {
+		close(sub.done)
+		count := sub.service.activeSubscribers.Add(-1)
+		log.Printf("Telemetry subscriber removed, active subscribers: %d", count)
+	})
+}
+
+// SubscribeTelemetry starts a stream sampling the robot state once per interval of the service clock.
+// Telemetry subscribers count against Config.MaxSubscribers like event subscribers, it returns
+// ErrTooManySubscribers if the limit is reached and ErrShuttingDown once the subscriptions have been closed.
+func (s *Service) SubscribeTelemetry(interval time.Duration) (TelemetrySubscription, error) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	if s.subClosed {
+		return nil, ErrShuttingDown
+	}
+	maxSubscribers := int64(s.config.MaxSubscribers)
+	if maxSubscribers > 0 && s.activeSubscribers.Load() >= maxSubscribers {
+		return nil, ErrTooManySubscribers
+	}
+
+	sub := &telemetrySubscriber{
+		samples: make(chan TelemetrySample),
+		done:    make(chan struct{}),
+		service: s,
+	}
+	count := s.activeSubscribers.Add(1)
+	log.Printf("Telemetry subscriber added, active subscribers: %d", count)
+
+	go s.sampleTelemetry(sub, interval)
+	return sub, nil
+}
+
+// sampleTelemetry delivers a sample per interval until the subscription is closed. A sample is only read
+// once the previous one was taken, so a slow consumer gets fresh samples at a lower rate instead of a backlog.
+func (s *Service) sampleTelemetry(sub *telemetrySubscriber, interval time.Duration) {
+	defer close(sub.samples)
+	for {
+		sample := TelemetrySample{Timestamp: s.config.Clock.Now(), RobotState: s.GetRobotState()}
+		select {
+		case sub.samples <- sample:
+		case <-sub.done:
+			return
+		case <-s.closing:
+			sub.Close() // Shutdown, see CloseSubscriptions
+			return
+		}
+		s.config.Clock.Sleep(interval)
+	}
+}
@@ -0,0 +1,56 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSubscribeTelemetry tests that samples follow the service clock and that telemetry subscribers
+// count against the subscriber limit until they are closed or shut down.
+func TestSubscribeTelemetry(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	config.MaxSubscribers = 2
+	service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+
+	telemetry, err := service.SubscribeTelemetry(time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to subscribe to telemetry: %v", err)
+	}
+	start := clock.Now()
+	for i := 0; i < 3; i++ {
+		sample := <-telemetry.Samples()
+		if want := start.Add(time.Duration(i) * time.Hour); !sample.Timestamp.Equal(want) {
+			t.Errorf("Sample %d: expected timestamp %s, got %s", i, want, sample.Timestamp)
+		}
+	}
+
+	events, err := service.Subscribe()
+	if err != nil {
+		t.Fatalf("Failed to subscribe to events: %v", err)
+	}
+	if _, err := service.SubscribeTelemetry(time.Hour); !errors.Is(err, ErrTooManySubscribers) {
+		t.Errorf("Expected ErrTooManySubscribers, got %v", err)
+	}
+	events.Close()
+	telemetry.Close()
+	telemetry.Close() // Releases the slot only once
+	if count := service.SubscriberCount(); count != 0 {
+		t.Errorf("Expected no subscribers after close, got %d", count)
+	}
+
+	telemetry, _ = service.SubscribeTelemetry(time.Hour)
+	service.CloseSubscriptions()
+	for range telemetry.Samples() {
+		// Samples taken before the shutdown
+	}
+	if count := service.SubscriberCount(); count != 0 {
+		t.Errorf("Expected the shutdown to release the slot, got %d subscribers", count)
+	}
+	if _, err := service.SubscribeTelemetry(time.Hour); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected ErrShuttingDown, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package robot
+
+import (
+	"sync"
+	"time"
+)
+
+// rateCounter keeps the timestamps of events within a sliding window to compute their rate on demand.
+type rateCounter struct {
+	mu    sync.Mutex
+	times []time.Time // Event timestamps in chronological order
+}
+
+// record adds an event at the given time and drops events that left the window.
+func (r *rateCounter) record(now time.Time, window time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pruneLocked(now, window)
+	r.times = append(r.times, now)
+}
+
+// rate returns the number of events per unit within the window ending at now.
+func (r *rateCounter) rate(now time.Time, window, unit time.Duration) float64 {
+	if window <= 0 {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pruneLocked(now, window)
+	return float64(len(r.times)) * float64(unit) / float64(window)
+}
+
+// pruneLocked drops the events older than the window. The caller must hold the counter lock.
+func (r *rateCounter) pruneLocked(now time.Time, window time.Duration) {
+	cutoff := now.Add(-window)
+	keep := 0
+	for keep < len(r.times) && !r.times[keep].After(cutoff) {
+		keep++
+	}
+	r.times = r.times[keep:]
+}
+
+// recordCommand counts an executed command for the throughput statistics.
+func (s *Service) recordCommand() {
+	s.commandRate.record(s.config.Clock.Now(), s.config.ThroughputWindow)
+}
+
+// recordTaskCompleted counts a completed task for the throughput statistics.
+func (s *Service) recordTaskCompleted() {
+	s.taskRate.record(s.config.Clock.Now(), s.config.ThroughputWindow)
+}
@@ -0,0 +1,97 @@
+package robot
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TaskStep is one successfully executed command of a task, recorded for offline analysis.
+// Timestamp minus Started is the real time the command took, including the delay before it, the speed ramp,
+// the move animation and any retries.
+type TaskStep struct {
+	Index     int // Position of the command in the task, starting at 0
+	Command   RobotCommand
+	Position  RobotState // Robot position after the command
+	Started   time.Time  // Time at which the step began, before the delay preceding the command
+	Timestamp time.Time  // Time at which the command finished
+}
+
+// Duration returns the real time the step took, from its start to the end of the command.
+func (step TaskStep) Duration() time.Duration {
+	return step.Timestamp.Sub(step.Started)
+}
+
+// recordStep appends the command that just moved the robot to the trace of the task, the step began at started.
+func (s *Service) recordStep(taskID string, index int, cmd RobotCommand, started time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task, exists := s.state.Tasks[taskID]; exists {
+		step := TaskStep{Index: index, Command: cmd, Position: s.state.RobotState, Started: started, Timestamp: s.config.Clock.Now()}
+		task.Trace = append(task.Trace, step)
+		s.state.Tasks[taskID] = task
+	}
+}
+
+// TaskTrace returns the commands the task executed so far in order, with the robot position after each of them.
+func (s *Service) TaskTrace(taskID string) ([]TaskStep, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, exists := s.state.Tasks[taskID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	return append([]TaskStep(nil), task.Trace...), nil
+}
+
+// CellVisit is one move that brought the robot onto a cell.
+// @Description Move of a task that brought the robot onto a cell
+type CellVisit struct {
+	TaskID       string    `json:"task_id" example:"12345"`                  // Task that moved the robot onto the cell
+	CommandIndex int       `json:"command_index" example:"2"`                // Position of the command in the task, starting at 0
+	Timestamp    time.Time `json:"timestamp" example:"2024-01-15T10:30:00Z"` // Time at which the command finished
+}
+
+// CellHistory returns every move of a task that brought the robot onto the cell, oldest first. With a step size
+// above 1 the cells a command passes count as well. It scans the traces of the known tasks, so purged tasks are
+// not included, and it fails with ErrOutOfBounds for a cell outside the warehouse.
+func (s *Service) CellHistory(cell Coord) ([]CellVisit, error) {
+	if !cell.inWarehouse() {
+		return nil, fmt.Errorf("%w: %s is outside the warehouse", ErrOutOfBounds, cell)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	visits := []CellVisit{}
+	sequence := map[string]int{} // Sequence numbers of the visiting tasks, ordering visits at the same time
+	for _, task := range s.state.Tasks {
+		for _, step := range task.Trace {
+			if _, hold := step.Command.Hold(); hold {
+				continue // The robot was already on the cell
+			}
+			dx, dy := step.Command.Delta()
+			for back := 0; back < s.config.StepSize; back++ {
+				passed := Coord{X: int(step.Position.X) - back*dx, Y: int(step.Position.Y) - back*dy}
+				if passed == cell {
+					visits = append(visits, CellVisit{TaskID: task.ID, CommandIndex: step.Index, Timestamp: step.Timestamp})
+					sequence[task.ID] = task.SequenceNum
+					break
+				}
+			}
+		}
+	}
+	sort.Slice(visits, func(i, j int) bool {
+		a, b := visits[i], visits[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		if a.TaskID != b.TaskID {
+			return sequence[a.TaskID] < sequence[b.TaskID]
+		}
+		return a.CommandIndex < b.CommandIndex
+	})
+	return visits, nil
+}
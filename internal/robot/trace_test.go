@@ -0,0 +1,230 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestTaskTrace tests that every executed command is recorded with the resulting position, up to the failing one.
+func TestTaskTrace(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	completed, _ := service.EnqueueTask("N E E", "0s")
+	service.ExecuteTask(completed)
+	want := []TaskStep{
+		{Index: 0, Command: North, Position: RobotState{X: 0, Y: 1, Heading: HeadingNorth}, Started: clock.Now(), Timestamp: clock.Now()},
+		{Index: 1, Command: East, Position: RobotState{X: 1, Y: 1, Heading: HeadingNorth}, Started: clock.Now(), Timestamp: clock.Now()},
+		{Index: 2, Command: East, Position: RobotState{X: 2, Y: 1, Heading: HeadingNorth}, Started: clock.Now(), Timestamp: clock.Now()},
+	}
+	assertTrace(t, service, completed, want)
+
+	// An aborted task keeps the steps executed before the failure
+	service.cellBlocked = func(x, y int) bool { return x == 4 }
+	aborted, _ := service.EnqueueTask("E E E", "0s")
+	service.ExecuteTask(aborted)
+	assertTrace(t, service, aborted, []TaskStep{
+		{Index: 0, Command: East, Position: RobotState{X: 3, Y: 1, Heading: HeadingNorth}, Started: clock.Now(), Timestamp: clock.Now()},
+	})
+
+	if _, err := service.TaskTrace("missing"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound for an unknown task, got %v", err)
+	}
+}
+
+// TestTaskTrace_Timing tests that every step records when it began and ended, so its duration is the real delay
+// before the command after the speed ramp and the minimum delay for subscribers were applied.
+func TestTaskTrace_Timing(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	config.SpeedRamp = SpeedRamp{30 * time.Millisecond}
+	config.MinSubscribedDelay = 50 * time.Millisecond
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	sub, _ := service.Subscribe()
+	defer sub.Close()
+
+	taskID, _ := service.EnqueueTask("N E N", "10ms")
+	start := clock.Now()
+	service.ExecuteTask(taskID)
+	trace, _ := service.TaskTrace(taskID)
+
+	// The task delay is raised to the minimum for subscribers, the first step is slowed down by the ramp
+	wantDurations := []time.Duration{80 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	if len(trace) != len(wantDurations) {
+		t.Fatalf("Expected %d steps, got %d", len(wantDurations), len(trace))
+	}
+	previous := start
+	for i, step := range trace {
+		if step.Started.Before(previous) || step.Timestamp.Before(step.Started) {
+			t.Errorf("Step %d: expected monotonic times after %s, got %s to %s", i, previous, step.Started, step.Timestamp)
+		}
+		if step.Duration() != wantDurations[i] {
+			t.Errorf("Step %d: expected a duration of %s, got %s", i, wantDurations[i], step.Duration())
+		}
+		previous = step.Timestamp
+	}
+}
+
+func assertTrace(t *testing.T, service *Service, taskID string, want []TaskStep) {
+	t.Helper()
+	got, err := service.TaskTrace(taskID)
+	if err != nil {
+		t.Fatalf("Failed to get trace: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d steps, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Step %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+// TestCellHistory tests that the moves of tasks through a cell are listed in order.
+func TestCellHistory(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	start := clock.Now()
+
+	first, _ := service.EnqueueTask("N E S", "1s")
+	service.ExecuteTask(first)
+	second, _ := service.EnqueueTask("N W E", "1s")
+	service.ExecuteTask(second)
+
+	visits, err := service.CellHistory(Coord{X: 1, Y: 1})
+	if err != nil {
+		t.Fatalf("Failed to get the cell history: %v", err)
+	}
+	want := []CellVisit{
+		{TaskID: first, CommandIndex: 1, Timestamp: start.Add(2 * time.Second)},
+		{TaskID: second, CommandIndex: 0, Timestamp: start.Add(4 * time.Second)},
+		{TaskID: second, CommandIndex: 2, Timestamp: start.Add(6 * time.Second)},
+	}
+	if len(visits) != len(want) {
+		t.Fatalf("Expected %d visits, got %d: %+v", len(want), len(visits), visits)
+	}
+	for i := range want {
+		if visits[i] != want[i] {
+			t.Errorf("Visit %d: expected %+v, got %+v", i, want[i], visits[i])
+		}
+	}
+
+	if visits, _ := service.CellHistory(Coord{X: 5, Y: 5}); len(visits) != 0 {
+		t.Errorf("Expected no visits of an untouched cell, got %+v", visits)
+	}
+	if _, err := service.CellHistory(Coord{X: 10, Y: 0}); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("Expected ErrOutOfBounds for a cell outside the warehouse, got %v", err)
+	}
+}
+
+// TestCellHistory_StepSize tests that the cells a command passes with a larger step size are listed.
+func TestCellHistory_StepSize(t *testing.T) {
+	config := DefaultConfig()
+	config.Clock = newFakeClock()
+	config.StepSize = 2
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	taskID, _ := service.EnqueueTask("E", "0s")
+	service.ExecuteTask(taskID)
+	for _, cell := range []Coord{{X: 1, Y: 0}, {X: 2, Y: 0}} {
+		if visits, _ := service.CellHistory(cell); len(visits) != 1 || visits[0].TaskID != taskID {
+			t.Errorf("Expected one visit of %s, got %+v", cell, visits)
+		}
+	}
+}
+
+// TestHoldExecution tests that a hold adds its duration to the task without moving the robot.
+func TestHoldExecution(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.Clock = clock
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	taskID, err := service.EnqueueTask("N HOLD 5s E", "1s")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+	if estimate := service.CurrentState().Tasks[taskID].Duration(); estimate != 8*time.Second {
+		t.Errorf("Expected the estimate to include the hold, got %s", estimate)
+	}
+	start := clock.Now()
+	if err := service.ExecuteTask(taskID); err != nil {
+		t.Fatalf("Failed to execute task: %v", err)
+	}
+	if elapsed := clock.Now().Sub(start); elapsed != 8*time.Second {
+		t.Errorf("Expected the task to take 3 delays and the hold, 8s, got %s", elapsed)
+	}
+
+	trace, _ := service.TaskTrace(taskID)
+	if len(trace) != 3 {
+		t.Fatalf("Expected 3 steps, got %+v", trace)
+	}
+	if trace[1].Position != trace[0].Position {
+		t.Errorf("Expected the robot to stay at %+v during the hold, got %+v", trace[0].Position, trace[1].Position)
+	}
+	if trace[1].Duration() != 6*time.Second {
+		t.Errorf("Expected the hold step to take its delay and the hold, 6s, got %s", trace[1].Duration())
+	}
+	if state, _ := service.GetTaskState(taskID); state != Completed {
+		t.Errorf("Expected the task completed, got %s", state)
+	}
+	if robotState := service.GetRobotState(); robotState.X != 1 || robotState.Y != 1 {
+		t.Errorf("Expected the robot at (1, 1), got (%d, %d)", robotState.X, robotState.Y)
+	}
+}
+
+// TestHoldInterrupted tests that a long hold stops soon after its task is cancelled or the service shuts down.
+func TestHoldInterrupted(t *testing.T) {
+	tests := []struct {
+		name       string
+		interrupt  func(service *Service, taskID string, shutdown context.CancelFunc)
+		wantState  TaskState
+		wantReason TransitionReason
+	}{
+		{"Cancel", func(service *Service, taskID string, _ context.CancelFunc) { service.CancelTask(taskID) }, Canceled, ReasonUserCancel},
+		{"Shutdown", func(_ *Service, _ string, shutdown context.CancelFunc) { shutdown() }, Aborted, ReasonShutdown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, shutdown := context.WithCancel(context.Background())
+			defer shutdown()
+			service := NewService(ctx, make(chan string, 10))
+			taskID, _ := service.EnqueueTask("N HOLD 1h E", "0s")
+
+			done := make(chan error, 1)
+			go func() { done <- service.ExecuteTask(taskID) }()
+			// The hold starts once the first move is recorded
+			for deadline := time.Now().Add(time.Second); ; {
+				if trace, _ := service.TaskTrace(taskID); len(trace) == 1 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("Timed out waiting for the hold to start")
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			tt.interrupt(service, taskID, shutdown)
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("Expected the hold to stop within a second")
+			}
+			task := service.CurrentState().Tasks[taskID]
+			if task.State != tt.wantState || task.Reason != tt.wantReason {
+				t.Errorf("Expected %s (%s), got %s (%s)", tt.wantState, tt.wantReason, task.State, task.Reason)
+			}
+			if robotState := service.GetRobotState(); robotState.X != 0 || robotState.Y != 1 {
+				t.Errorf("Expected the robot to stay at (0, 1), got (%d, %d)", robotState.X, robotState.Y)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package robot
+
+import "log"
+
+// legalTransition reports whether a task may move from one state to the other. Finished tasks never change
+// their state again and a cancellation request cannot be taken back, so a late update racing a cancel or
+// one of the monitors cannot overwrite the state they set. A cancel held for the uncancel window becomes
+// final or is reversed, see UncancelTask.
+func legalTransition(from, to TaskState) bool {
+	switch from {
+	case Completed, Canceled, Aborted:
+		return false
+	case RequestCancellation:
+		return to == Canceled || to == Aborted
+	case CancelPending:
+		return to == Canceled || to == Pending
+	}
+	return true
+}
+
+// swapTaskState moves the task from the expected state to the new one in one step and reports whether it did.
+// It is used where the executor must not act on a state that changed since it was read, e.g. a task cancelled
+// right before it is dispatched.
+func (s *Service) swapTaskState(taskID string, expected, state TaskState, reason TransitionReason) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, exists := s.state.Tasks[taskID]
+	if !exists || task.State != expected {
+		return false
+	}
+	task.State = state
+	task.Reason = reason
+	s.state.Tasks[taskID] = task
+	log.Printf("Task %s updated to state: %s (%s)", taskID, state, reason)
+	s.publishEventAsync(taskID, state, reason, task.Error)
+	return true
+}
+
+// finishCancellation cancels a task whose cancellation was requested, with the reason of the request, once the
+// executor acts on the request. It reports whether the task was waiting for its cancellation.
+func (s *Service) finishCancellation(taskID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, exists := s.state.Tasks[taskID]
+	if !exists || task.State != RequestCancellation {
+		return false
+	}
+	s.finishCancellationLocked(task)
+	return true
+}
+
+// finishCancellationLocked moves a task in RequestCancellation to Canceled, keeping the reason of the request.
+// The caller must hold the service lock.
+func (s *Service) finishCancellationLocked(task RobotTask) {
+	task.State = Canceled
+	task.Error = "Task cancellation requested by user"
+	s.state.Tasks[task.ID] = task
+	log.Printf("Task %s updated to state: %s (%s)", task.ID, task.State, task.Reason)
+	s.publishEventAsync(task.ID, task.State, task.Reason, task.Error)
+}
+
+// completeTask finishes a task whose commands all ran and returns the state it ends up in. A running task
+// is Completed with the reason. A cancellation requested while the last command ran wins over the completion,
+// the task is Canceled as the request was acknowledged. A task aborted or canceled meanwhile keeps its state.
+func (s *Service) completeTask(taskID string, reason TransitionReason) TaskState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, exists := s.state.Tasks[taskID]
+	if !exists {
+		return Invalid
+	}
+	switch task.State {
+	case InProgress:
+		task.State = Completed
+		task.Reason = reason
+	case RequestCancellation:
+		s.finishCancellationLocked(task)
+		return Canceled
+	default:
+		return task.State
+	}
+	s.state.Tasks[taskID] = task
+	log.Printf("Task %s updated to state: %s (%s)", taskID, task.State, task.Reason)
+	s.publishEventAsync(taskID, task.State, task.Reason, task.Error)
+	return task.State
+}
@@ -0,0 +1,101 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestLegalTransition(t *testing.T) {
+	tests := []struct {
+		from, to TaskState
+		want     bool
+	}{
+		{Pending, InProgress, true},
+		{Pending, Canceled, true},
+		{InProgress, Completed, true},
+		{InProgress, RequestCancellation, true},
+		{RequestCancellation, Canceled, true},
+		{RequestCancellation, Aborted, true},
+		{RequestCancellation, Completed, false},
+		{RequestCancellation, InProgress, false},
+		{Completed, RequestCancellation, false},
+		{Completed, Aborted, false},
+		{Canceled, InProgress, false},
+		{Aborted, Completed, false},
+		{CancelPending, Canceled, true},
+		{CancelPending, Pending, true},
+		{CancelPending, InProgress, false},
+	}
+	for _, tt := range tests {
+		if got := legalTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("legalTransition(%s, %s) = %t, want %t", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// TestCancelCompletionRace cancels a task while it completes, many times, and checks that every run ends in
+// a consistent terminal state. Every other run cancels once the task runs, i.e. while its only command
+// executes, the others race the dispatch. Run it with -race to also check the accesses.
+func TestCancelCompletionRace(t *testing.T) {
+	for i := 0; i < 300; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		service := NewService(ctx, make(chan string, 1))
+		taskID, _ := service.EnqueueTask("N", "100us")
+
+		start := make(chan struct{})
+		var cancelErr error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			<-start
+			service.ExecuteTask(taskID)
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			for i%2 == 0 {
+				if state, _ := service.GetTaskState(taskID); state != Pending {
+					break
+				}
+			}
+			cancelErr = service.CancelTask(taskID)
+		}()
+		close(start)
+		wg.Wait()
+		cancel()
+
+		task := service.CurrentState().Tasks[taskID]
+		switch {
+		case cancelErr == nil && task.State != Canceled:
+			t.Fatalf("Run %d: the cancel was acknowledged, but the task is %s (%s)", i, task.State, task.Reason)
+		case cancelErr != nil && (!errors.Is(cancelErr, ErrInvalidState) || task.State != Completed):
+			t.Fatalf("Run %d: the cancel failed with %v, but the task is %s (%s)", i, cancelErr, task.State, task.Reason)
+		case task.State == Completed && task.CancelRequestedAt != nil:
+			t.Fatalf("Run %d: the task completed, but shows a cancellation request", i)
+		case task.State == Canceled && task.Reason != ReasonUserCancel:
+			t.Fatalf("Run %d: expected the reason %s, got %s", i, ReasonUserCancel, task.Reason)
+		}
+	}
+}
+
+// TestCompletionAfterCancelRequest tests that a cancellation requested during the last command wins over the completion.
+func TestCompletionAfterCancelRequest(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 1))
+	taskID, _ := service.EnqueueTask("N", "0s")
+	service.UpdateTaskState(taskID, InProgress, ReasonDispatched)
+	if err := service.CancelTask(taskID); err != nil {
+		t.Fatalf("Failed to request the cancellation: %v", err)
+	}
+
+	if state := service.completeTask(taskID, ReasonCompleted); state != Canceled {
+		t.Errorf("Expected the task canceled, got %s", state)
+	}
+	// A late update cannot move a finished task
+	service.UpdateTaskState(taskID, Completed, ReasonCompleted)
+	if task := service.CurrentState().Tasks[taskID]; task.State != Canceled || task.Reason != ReasonUserCancel {
+		t.Errorf("Expected the task to stay Canceled (%s), got %s (%s)", ReasonUserCancel, task.State, task.Reason)
+	}
+}
@@ -0,0 +1,89 @@
+package robot
+
+import (
+	"fmt"
+	"log"
+)
+
+// holdCancelLocked moves a cancelled pending task to CancelPending for the uncancel window, measured on the service
+// clock, so the cancel can still be reversed. The task is Canceled, with its terminal event and webhook, only once
+// the window passed, see finishHeldCancelsLocked. The caller must hold the service lock.
+func (s *Service) holdCancelLocked(task RobotTask) {
+	log.Printf("Task %s is pending, holding its cancel for %s", task.ID, s.config.UncancelWindow)
+	task.State = CancelPending
+	s.state.Tasks[task.ID] = task
+	s.uncancels[task.ID] = s.config.Clock.Now()
+	s.publishEventAsync(task.ID, task.State, task.Reason, task.Error)
+}
+
+// finishHeldCancels makes the cancels whose uncancel window passed final.
+func (s *Service) finishHeldCancels() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finishHeldCancelsLocked()
+}
+
+// finishHeldCancelsLocked moves the tasks whose uncancel window passed from CancelPending to Canceled.
+// The caller must hold the service lock.
+func (s *Service) finishHeldCancelsLocked() {
+	now := s.config.Clock.Now()
+	for taskID, canceledAt := range s.uncancels {
+		if now.Sub(canceledAt) < s.config.UncancelWindow {
+			continue
+		}
+		delete(s.uncancels, taskID)
+		task, exists := s.state.Tasks[taskID]
+		if !exists || task.State != CancelPending {
+			continue
+		}
+		task.State = Canceled
+		s.state.Tasks[taskID] = task
+		log.Printf("Task %s updated to state: %s (%s), the uncancel window passed", taskID, task.State, task.Reason)
+		s.publishEventAsync(taskID, task.State, task.Reason, task.Error)
+	}
+}
+
+// UncancelTask restores a pending task cancelled less than the uncancel window ago. The task is Pending again
+// with its priority and queue position. It fails with ErrInvalidState once the cancel is final, for tasks
+// that were running when cancelled or were replaced, and for tasks that were never cancelled.
+func (s *Service) UncancelTask(taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The monitor makes expired cancels final once per second, a request in between must not see them held
+	s.finishHeldCancelsLocked()
+
+	task, exists := s.state.Tasks[taskID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	if task.ReplacedBy != "" {
+		return fmt.Errorf("%w: task %s was replaced by task %s, its cancel cannot be reversed", ErrInvalidState, taskID, task.ReplacedBy)
+	}
+	if task.State != CancelPending {
+		return fmt.Errorf("%w: task %s is '%s', only a pending task cancelled within the last %s can be restored",
+			ErrInvalidState, taskID, task.State, s.config.UncancelWindow)
+	}
+	if err := s.acceptingLocked(); err != nil {
+		return err
+	}
+	if err := s.checkPendingLocked(1); err != nil {
+		return err
+	}
+
+	// The dispatch token of the task may have been used up meanwhile, the dispatcher skips a spare one
+	select {
+	case s.taskIdQueue <- taskID:
+	default:
+		return fmt.Errorf("%w: %d tasks waiting", ErrQueueFull, len(s.taskIdQueue))
+	}
+	delete(s.uncancels, taskID)
+
+	task.State = Pending
+	task.Reason = ReasonUncanceled
+	task.Error = ""
+	s.state.Tasks[taskID] = task
+	log.Printf("Task %s restored, its cancel was reversed", taskID)
+	s.publishEventAsync(taskID, Pending, task.Reason, "")
+	return nil
+}
@@ -0,0 +1,162 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestUncancelTask tests that a cancelled pending task can be restored within the window and not after it.
+func TestUncancelTask(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultConfig()
+	config.UncancelWindow = time.Minute
+	config.Clock = clock
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	restored, _ := service.EnqueueTask("N", "0s")
+	if err := service.CancelTask(restored); err != nil {
+		t.Fatalf("Failed to cancel the task: %v", err)
+	}
+	if state, _ := service.GetTaskState(restored); state != CancelPending {
+		t.Errorf("Expected the cancel held as %s, got %s", CancelPending, state)
+	}
+	if err := service.UncancelTask(restored); err != nil {
+		t.Fatalf("Expected the task to be restored within the window, got %v", err)
+	}
+	if task := service.CurrentState().Tasks[restored]; task.State != Pending || task.Reason != ReasonUncanceled {
+		t.Errorf("Expected the task Pending (%s), got %s (%s)", ReasonUncanceled, task.State, task.Reason)
+	}
+	if err := service.UncancelTask(restored); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected restoring a pending task to fail, got %v", err)
+	}
+	if err := service.ExecuteTask(restored); err != nil {
+		t.Errorf("Expected the restored task to run, got %v", err)
+	}
+
+	final, _ := service.EnqueueTask("E", "0s")
+	if err := service.CancelTask(final); err != nil {
+		t.Fatalf("Failed to cancel the task: %v", err)
+	}
+	clock.Advance(config.UncancelWindow - time.Second)
+	service.finishHeldCancels()
+	if state, _ := service.GetTaskState(final); state != CancelPending {
+		t.Errorf("Expected the cancel still held within the window, got %s", state)
+	}
+	clock.Advance(time.Second)
+	service.finishHeldCancels()
+	if task := service.CurrentState().Tasks[final]; task.State != Canceled || task.Reason != ReasonUserCancel {
+		t.Errorf("Expected the task Canceled (%s) once the window passed, got %s (%s)", ReasonUserCancel, task.State, task.Reason)
+	}
+	if err := service.UncancelTask(final); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected restoring after the window to fail, got %v", err)
+	}
+
+	// A request right after the window, before the monitor ran, sees the cancel final as well
+	late, _ := service.EnqueueTask("E", "0s")
+	service.CancelTask(late)
+	clock.Advance(config.UncancelWindow)
+	if err := service.UncancelTask(late); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected restoring after the window to fail, got %v", err)
+	}
+	if state, _ := service.GetTaskState(late); state != Canceled {
+		t.Errorf("Expected the task Canceled, got %s", state)
+	}
+}
+
+// TestUncancelTask_TerminalEvents tests that a held cancel publishes no terminal event and sends no webhook,
+// so a restored task that runs is reported Completed only.
+func TestUncancelTask_TerminalEvents(t *testing.T) {
+	config := DefaultConfig()
+	config.UncancelWindow = time.Minute
+	config.WebhookURL = "http://127.0.0.1:1/hook" // Nothing is delivered before the task runs
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	sub, err := service.Subscribe()
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	taskID, _ := service.EnqueueTask("N", "0s")
+	service.CancelTask(taskID)
+	if _, err := service.WebhookStatus(taskID); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected no webhook for a held cancel, got %v", err)
+	}
+	if err := service.UncancelTask(taskID); err != nil {
+		t.Fatalf("Failed to restore the task: %v", err)
+	}
+	if err := service.ExecuteTask(taskID); err != nil {
+		t.Fatalf("Failed to execute the task: %v", err)
+	}
+
+	// Events are published asynchronously, collect them until the completion
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-sub.Events():
+			if event.TaskID != taskID || event.Type != "" {
+				continue
+			}
+			if event.State == Canceled || event.State == Aborted {
+				t.Errorf("Expected no terminal event before the completion, got %s", event.State)
+			}
+			if event.State == Completed {
+				// The webhook is queued right after the event is broadcast
+				deadline := time.Now().Add(2 * time.Second)
+				for _, err := service.WebhookStatus(taskID); err != nil; _, err = service.WebhookStatus(taskID) {
+					if time.Now().After(deadline) {
+						t.Fatalf("Expected the webhook of the completion queued, got %v", err)
+					}
+					time.Sleep(time.Millisecond)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("No Completed event")
+		}
+	}
+}
+
+// TestUncancelTask_Replaced tests that the cancel of a replaced task cannot be reversed, even within the window.
+func TestUncancelTask_Replaced(t *testing.T) {
+	config := DefaultConfig()
+	config.UncancelWindow = time.Minute
+	service := NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+
+	taskID, _ := service.EnqueueTask("N", "0s")
+	replacement, err := service.ReplaceTask(taskID, TaskSpec{Commands: "E"})
+	if err != nil {
+		t.Fatalf("Failed to replace the task: %v", err)
+	}
+	if err := service.UncancelTask(taskID); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected restoring a replaced task to fail, got %v", err)
+	}
+	tasks := service.CurrentState().Tasks
+	if tasks[taskID].State != Canceled || tasks[replacement].State != Pending {
+		t.Errorf("Expected the replaced task to stay Canceled and its replacement Pending, got %s and %s", tasks[taskID].State, tasks[replacement].State)
+	}
+}
+
+// TestUncancelTask_Disabled tests that cancels are final without a window and for tasks cancelled while running.
+func TestUncancelTask_Disabled(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 10))
+	taskID, _ := service.EnqueueTask("N", "0s")
+	service.CancelTask(taskID)
+	if err := service.UncancelTask(taskID); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected restoring without a window to fail, got %v", err)
+	}
+	if err := service.UncancelTask("unknown"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+
+	config := DefaultConfig()
+	config.UncancelWindow = time.Minute
+	service = NewServiceWithConfig(context.Background(), make(chan string, 10), config)
+	taskID, _ = service.EnqueueTask("N", "0s")
+	service.UpdateTaskState(taskID, InProgress, ReasonDispatched)
+	service.CancelTask(taskID)
+	if err := service.UncancelTask(taskID); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected restoring a task cancelled while running to fail, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package robot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnqueueRunToWall enqueues a task that moves the robot from its current position in the given direction
+// (N, E, S or W) as far as possible, until the next cell is outside the warehouse or an obstacle.
+// An error is returned if the robot cannot move in that direction at all. The spec sets the options of the task,
+// e.g. its delay and allowed commands, the commands are generated.
+func (s *Service) EnqueueRunToWall(direction string, spec TaskSpec) (string, error) {
+	cmd, ok := lookupCommand(strings.ToUpper(direction))
+	if !ok {
+		return "", fmt.Errorf("%w: invalid direction %q", ErrInvalidCommand, direction)
+	}
+
+	steps := s.stepsToWall(cmd)
+	if steps == 0 {
+		return "", fmt.Errorf("%w: robot cannot move %s from its current position", ErrOutOfBounds, commandTable[cmd].Name)
+	}
+	spec.Commands = strings.TrimSpace(strings.Repeat(cmd.String()+" ", steps))
+	return s.SubmitTask(spec)
+}
+
+// stepsToWall counts the moves in the direction of cmd the robot can make from its current position
+// before it would leave the warehouse or hit an obstacle.
+func (s *Service) stepsToWall(cmd RobotCommand) int {
+	robotState := s.GetRobotState()
+	cell := Coord{X: int(robotState.X), Y: int(robotState.Y)}
+	dx, dy := cmd.Delta()
+
+	steps := 0
+	for {
+		next, ok := s.stepFrom(cell, dx, dy)
+		if !ok {
+			return steps
+		}
+		cell = next
+		steps++
+	}
+}
@@ -0,0 +1,59 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestRunToWall tests running to each wall from a mid-grid position, and stopping in front of obstacles.
+func TestRunToWall(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction string
+		obstacles []Coord
+		want      RobotState
+	}{
+		{"North wall", "N", nil, RobotState{X: 4, Y: warehouseSize - 1}},
+		{"East wall", "E", nil, RobotState{X: warehouseSize - 1, Y: 5}},
+		{"South wall", "S", nil, RobotState{X: 4, Y: 0}},
+		{"West wall", "w", nil, RobotState{X: 0, Y: 5}},
+		{"Obstacle on the way", "E", []Coord{{X: 7, Y: 5}}, RobotState{X: 6, Y: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Obstacles = tt.obstacles
+			service := NewServiceWithConfig(context.Background(), make(chan string, 1), config)
+			service.SetPosition(4, 5)
+
+			taskID, err := service.EnqueueRunToWall(tt.direction, TaskSpec{DelayBetweenCommands: "0s"})
+			if err != nil {
+				t.Fatalf("Failed to enqueue run-to-wall task: %v", err)
+			}
+			if err := service.ExecuteTask(taskID); err != nil {
+				t.Fatalf("Failed to execute run-to-wall task: %v", err)
+			}
+			if got := service.GetRobotState(); got.X != tt.want.X || got.Y != tt.want.Y {
+				t.Errorf("Expected robot at (%d, %d), got (%d, %d)", tt.want.X, tt.want.Y, got.X, got.Y)
+			}
+		})
+	}
+}
+
+// TestRunToWallErrors tests that invalid directions and moves from a wall into it are rejected.
+func TestRunToWallErrors(t *testing.T) {
+	service := NewService(context.Background(), make(chan string, 1))
+	service.SetPosition(0, 3)
+
+	if _, err := service.EnqueueRunToWall("X", TaskSpec{DelayBetweenCommands: "0s"}); !errors.Is(err, ErrInvalidCommand) {
+		t.Errorf("Expected ErrInvalidCommand, got %v", err)
+	}
+	if _, err := service.EnqueueRunToWall("W", TaskSpec{DelayBetweenCommands: "0s"}); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("Expected ErrOutOfBounds, got %v", err)
+	}
+	if count := len(service.CurrentState().Tasks); count != 0 {
+		t.Errorf("Expected no tasks to be enqueued, got %d", count)
+	}
+}
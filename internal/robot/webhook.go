@@ -0,0 +1,220 @@
+package robot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// webhookTimeout bounds a single webhook delivery attempt.
+const webhookTimeout = 5 * time.Second
+
+// webhookMaxBackoff bounds the delay between two webhook delivery attempts.
+const webhookMaxBackoff = 5 * time.Minute
+
+// Delivery states of a task completion webhook
+const (
+	DeliveryPending   = "pending"   // Waiting for the first attempt or a retry
+	DeliveryDelivered = "delivered" // Accepted by the receiver with a 2xx status
+	DeliveryFailed    = "failed"    // Every attempt failed, the delivery was dropped
+	DeliveryDropped   = "dropped"   // The retry queue was full, the delivery was dropped
+)
+
+// DeliveryStatus reports the delivery of the completion webhook of a task.
+// @Description Delivery status of the completion webhook of a task
+type DeliveryStatus struct {
+	TaskID      string     `json:"task_id" example:"12345"`
+	State       string     `json:"state" example:"delivered"`       // pending, delivered, failed or dropped
+	Attempts    int        `json:"attempts" example:"2"`            // Number of delivery attempts so far
+	LastError   string     `json:"last_error,omitempty" example:""` // Error of the latest failed attempt
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`          // Time the receiver accepted the webhook
+}
+
+// webhookDelivery is a queued webhook with the terminal event of a task as payload.
+type webhookDelivery struct {
+	event    TaskStatusUpdateEvent
+	attempts int
+}
+
+// webhookNotifier posts the terminal event of every task to a URL. Deliveries are processed by a background
+// worker from a bounded queue, failed ones are re-queued with exponential backoff until the attempts are used up.
+type webhookNotifier struct {
+	url         string
+	maxAttempts int
+	backoff     time.Duration
+	client      *http.Client
+	clock       Clock
+	queue       chan webhookDelivery
+
+	mu       sync.Mutex
+	statuses map[string]DeliveryStatus // Delivery status per task ID
+	dropped  atomic.Uint64             // Deliveries given up after exhausting their attempts or a full queue
+}
+
+func newWebhookNotifier(config Config) *webhookNotifier {
+	return &webhookNotifier{
+		url:         config.WebhookURL,
+		maxAttempts: config.WebhookMaxAttempts,
+		backoff:     config.WebhookRetryBackoff,
+		client:      &http.Client{Timeout: webhookTimeout},
+		clock:       config.Clock,
+		queue:       make(chan webhookDelivery, config.WebhookQueueSize),
+		statuses:    make(map[string]DeliveryStatus),
+	}
+}
+
+// notifyFinished queues the webhook for a terminal event. Every task is delivered at most once,
+// repeated terminal events of the same task are ignored.
+func (w *webhookNotifier) notifyFinished(event TaskStatusUpdateEvent) {
+	w.mu.Lock()
+	if _, exists := w.statuses[event.TaskID]; exists {
+		w.mu.Unlock()
+		return
+	}
+	w.statuses[event.TaskID] = DeliveryStatus{TaskID: event.TaskID, State: DeliveryPending}
+	w.mu.Unlock()
+
+	w.enqueue(webhookDelivery{event: event})
+}
+
+// enqueue adds the delivery to the queue without blocking, a full queue drops it.
+func (w *webhookNotifier) enqueue(delivery webhookDelivery) {
+	select {
+	case w.queue <- delivery:
+	default:
+		w.drop(delivery.event.TaskID, DeliveryDropped, "webhook queue is full")
+	}
+}
+
+// run delivers queued webhooks until the context is cancelled.
+func (w *webhookNotifier) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-w.queue:
+			w.deliver(ctx, delivery)
+		}
+	}
+}
+
+// deliver makes one attempt and schedules a retry with exponential backoff if it failed.
+func (w *webhookNotifier) deliver(ctx context.Context, delivery webhookDelivery) {
+	delivery.attempts++
+	taskID := delivery.event.TaskID
+	err := w.post(ctx, delivery.event)
+
+	w.mu.Lock()
+	if status, exists := w.statuses[taskID]; exists { // Purged tasks are delivered without a status
+		status.Attempts = delivery.attempts
+		if err == nil {
+			now := w.clock.Now()
+			status.State = DeliveryDelivered
+			status.LastError = ""
+			status.DeliveredAt = &now
+		} else {
+			status.LastError = err.Error()
+		}
+		w.statuses[taskID] = status
+	}
+	w.mu.Unlock()
+
+	if err == nil {
+		log.Printf("Delivered webhook for task %s after %d attempts", taskID, delivery.attempts)
+		return
+	}
+	if delivery.attempts >= w.maxAttempts {
+		w.drop(taskID, DeliveryFailed, err.Error())
+		return
+	}
+
+	backoff := w.retryBackoff(delivery.attempts)
+	log.Printf("Webhook for task %s failed (attempt %d of %d), retrying in %s: %v", taskID, delivery.attempts, w.maxAttempts, backoff, err)
+	go func() {
+		w.clock.Sleep(backoff)
+		if ctx.Err() == nil {
+			w.enqueue(delivery)
+		}
+	}()
+}
+
+// retryBackoff returns the delay before the retry following the given number of attempts,
+// doubled for every attempt up to webhookMaxBackoff.
+func (w *webhookNotifier) retryBackoff(attempts int) time.Duration {
+	backoff := w.backoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
+
+// post sends the event to the webhook URL, any status other than 2xx is an error.
+func (w *webhookNotifier) post(ctx context.Context, event TaskStatusUpdateEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook receiver answered %s", resp.Status)
+	}
+	return nil
+}
+
+// drop gives up on the delivery of the task.
+func (w *webhookNotifier) drop(taskID, state, reason string) {
+	w.mu.Lock()
+	if status, exists := w.statuses[taskID]; exists {
+		status.State = state
+		status.LastError = reason
+		w.statuses[taskID] = status
+	}
+	w.mu.Unlock()
+
+	dropped := w.dropped.Add(1)
+	log.Printf("Dropped webhook for task %s: %s (%d dropped in total)", taskID, reason, dropped)
+}
+
+// forget removes the delivery status of a task that is no longer stored.
+func (w *webhookNotifier) forget(taskID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.statuses, taskID)
+}
+
+func (w *webhookNotifier) status(taskID string) (DeliveryStatus, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status, exists := w.statuses[taskID]
+	return status, exists
+}
+
+// WebhookStatus returns the delivery status of the completion webhook of a task.
+// It fails with ErrTaskNotFound if no webhook was queued for the task, e.g. because it did not finish yet
+// or no webhook URL is configured.
+func (s *Service) WebhookStatus(taskID string) (DeliveryStatus, error) {
+	if s.webhook == nil {
+		return DeliveryStatus{}, fmt.Errorf("%w: no webhook configured", ErrTaskNotFound)
+	}
+	status, exists := s.webhook.status(taskID)
+	if !exists {
+		return DeliveryStatus{}, fmt.Errorf("%w: no webhook delivery for task %s", ErrTaskNotFound, taskID)
+	}
+	return status, nil
+}
@@ -0,0 +1,140 @@
+package robot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// zoneNamePattern restricts zone names to characters that are safe in URL paths.
+var zoneNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Zones holds several independent warehouses, each with its own robot, task queue and event stream.
+// The set of zones is fixed at construction, so it can be read without locking.
+type Zones struct {
+	zones map[string]*Service
+}
+
+// NewZones creates one embedded robot service per zone name, all sharing the given configuration.
+// The entries of a shared audit logger carry the zone of the move. The services stop when ctx is cancelled.
+func NewZones(ctx context.Context, config Config, names ...string) (*Zones, error) {
+	zones := make(map[string]*Service, len(names))
+	for _, name := range names {
+		if !zoneNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid zone name %q, only letters, digits, '-' and '_' are allowed", name)
+		}
+		if _, exists := zones[name]; exists {
+			return nil, fmt.Errorf("duplicate zone name %q", name)
+		}
+		zoneConfig := config
+		if config.AuditLogger != nil {
+			zoneConfig.AuditLogger = zoneAuditLogger{zone: name, logger: config.AuditLogger}
+		}
+		service, err := newEmbeddedService(ctx, zoneConfig)
+		if err != nil {
+			return nil, err
+		}
+		zones[name] = service
+	}
+	return &Zones{zones: zones}, nil
+}
+
+// Names returns the zone names in alphabetical order.
+func (z *Zones) Names() []string {
+	names := make([]string, 0, len(z.zones))
+	for name := range z.zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Zone returns the robot service of the named zone.
+func (z *Zones) Zone(name string) (RobotService, error) {
+	service, exists := z.zones[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrZoneNotFound, name)
+	}
+	return service, nil
+}
+
+// Started reports whether the service of every zone processes its task queue.
+func (z *Zones) Started() bool {
+	for _, service := range z.zones {
+		if !service.Started() {
+			return false
+		}
+	}
+	return true
+}
+
+// EnqueueTask adds a task to the queue of the named zone.
+func (z *Zones) EnqueueTask(zone, commands, delayBetweenCommands string) (string, error) {
+	service, err := z.Zone(zone)
+	if err != nil {
+		return "", err
+	}
+	return service.EnqueueTask(commands, delayBetweenCommands)
+}
+
+// BroadcastResult is the outcome of a broadcast task in one zone, the ID of the enqueued task or the error rejecting it.
+type BroadcastResult struct {
+	TaskID string
+	Err    error
+}
+
+// Broadcast enqueues the same task in each named zone, in every zone if no names are given. Each zone validates
+// the task against the projected position of its own robot like SubmitBatch, so it may be accepted in some zones
+// and rejected in others, the outcome is returned per zone. Unknown zone names fail with ErrZoneNotFound before
+// any task is enqueued.
+func (z *Zones) Broadcast(spec TaskSpec, names ...string) (map[string]BroadcastResult, error) {
+	if len(names) == 0 {
+		names = z.Names()
+	}
+	for _, name := range names {
+		if _, err := z.Zone(name); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make(map[string]BroadcastResult, len(names))
+	for _, name := range names {
+		if _, done := results[name]; done {
+			continue // A zone named twice runs the task once
+		}
+		var result BroadcastResult
+		if taskIDs, err := z.zones[name].SubmitBatch([]TaskSpec{spec}); err != nil {
+			result.Err = err
+		} else {
+			result.TaskID = taskIDs[0]
+		}
+		results[name] = result
+	}
+	return results, nil
+}
+
+// CancelTask cancels a task of the named zone.
+func (z *Zones) CancelTask(zone, taskID string) error {
+	service, err := z.Zone(zone)
+	if err != nil {
+		return err
+	}
+	return service.CancelTask(taskID)
+}
+
+// CurrentState returns the state of the named zone.
+func (z *Zones) CurrentState(zone string) (ServiceState, error) {
+	service, err := z.Zone(zone)
+	if err != nil {
+		return ServiceState{}, err
+	}
+	return service.CurrentState(), nil
+}
+
+// CloseSubscriptions closes the event subscriptions of every zone, e.g. on graceful shutdown.
+func (z *Zones) CloseSubscriptions() {
+	for _, service := range z.zones {
+		service.CloseSubscriptions()
+	}
+}
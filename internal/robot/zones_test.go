@@ -0,0 +1,150 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestZonesIsolation tests that tasks in one zone do not affect the robot, tasks or events of another zone.
+func TestZonesIsolation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	zones, err := NewZones(ctx, DefaultConfig(), "north", "south")
+	if err != nil {
+		t.Fatalf("Failed to create zones: %v", err)
+	}
+
+	north, _ := zones.Zone("north")
+	south, _ := zones.Zone("south")
+	northSub, _ := north.Subscribe()
+	defer northSub.Close()
+	southSub, _ := south.Subscribe()
+	defer southSub.Close()
+
+	northTask, err := zones.EnqueueTask("north", "N N E", "1ms")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task in north: %v", err)
+	}
+	southTask, err := zones.EnqueueTask("south", "E", "1ms")
+	if err != nil {
+		t.Fatalf("Failed to enqueue task in south: %v", err)
+	}
+	waitForState(t, northSub, northTask, Completed)
+	waitForState(t, southSub, southTask, Completed)
+
+	northState, _ := zones.CurrentState("north")
+	southState, _ := zones.CurrentState("south")
+	if northState.RobotState.X != 1 || northState.RobotState.Y != 2 {
+		t.Errorf("Expected north robot at (1, 2), got (%d, %d)", northState.RobotState.X, northState.RobotState.Y)
+	}
+	if southState.RobotState.X != 1 || southState.RobotState.Y != 0 {
+		t.Errorf("Expected south robot at (1, 0), got (%d, %d)", southState.RobotState.X, southState.RobotState.Y)
+	}
+	if _, exists := southState.Tasks[northTask]; exists {
+		t.Error("North task must not be visible in the south zone")
+	}
+	if err := zones.CancelTask("south", northTask); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound cancelling a north task in south, got %v", err)
+	}
+}
+
+// TestZonesNames tests zone name validation and lookup of unknown zones.
+func TestZonesNames(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tests := []struct {
+		name    string
+		zones   []string
+		wantErr bool
+	}{
+		{"Valid names", []string{"b", "a-1", "c_2"}, false},
+		{"Duplicate name", []string{"a", "a"}, true},
+		{"Empty name", []string{""}, true},
+		{"Name with slash", []string{"a/b"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewZones(ctx, DefaultConfig(), tt.zones...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewZones() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	zones, _ := NewZones(ctx, DefaultConfig(), "b", "a")
+	if names := zones.Names(); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("Expected sorted names [a b], got %v", names)
+	}
+	if _, err := zones.CurrentState("missing"); !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("Expected ErrZoneNotFound, got %v", err)
+	}
+}
+
+// TestZonesBroadcast tests that a broadcast task is validated against the robot of every zone on its own.
+func TestZonesBroadcast(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	zones, _ := NewZones(ctx, DefaultConfig(), "dock", "aisle", "edge")
+	edge, _ := zones.Zone("edge")
+	edge.SetPosition(0, 9)
+
+	results, err := zones.Broadcast(TaskSpec{Commands: "N E", DelayBetweenCommands: "1h"})
+	if err != nil {
+		t.Fatalf("Failed to broadcast: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected a result for each of the 3 zones, got %v", results)
+	}
+	for _, name := range []string{"dock", "aisle"} {
+		state, _ := zones.CurrentState(name)
+		if result := results[name]; result.Err != nil || state.Tasks[result.TaskID].State != Pending {
+			t.Errorf("Expected the task accepted in %s, got %+v", name, result)
+		}
+	}
+	if result := results["edge"]; !errors.Is(result.Err, ErrOutOfBounds) || result.TaskID != "" {
+		t.Errorf("Expected the task rejected with %v in edge, got %+v", ErrOutOfBounds, result)
+	}
+
+	// Named zones only get the task, an unknown zone fails the whole broadcast
+	if results, err := zones.Broadcast(TaskSpec{Commands: "E"}, "dock", "dock"); err != nil || len(results) != 1 {
+		t.Errorf("Expected the task enqueued once in dock, got %v, %v", results, err)
+	}
+	if _, err := zones.Broadcast(TaskSpec{Commands: "E"}, "dock", "roof"); !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("Expected %v for an unknown zone, got %v", ErrZoneNotFound, err)
+	}
+	if state, _ := zones.CurrentState("dock"); len(state.Tasks) != 2 {
+		t.Errorf("Expected 2 tasks in dock, got %d", len(state.Tasks))
+	}
+}
+
+// TestZonesStarted tests that zones report started only once the service of every zone processes its queue.
+func TestZonesStarted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	north := NewService(ctx, make(chan string, 10))
+	south := NewService(ctx, make(chan string, 10))
+	zones := &Zones{zones: map[string]*Service{"north": north, "south": south}}
+
+	go north.Start()
+	for !north.Started() {
+		time.Sleep(time.Millisecond)
+	}
+	if zones.Started() {
+		t.Error("Expected the zones not started while south is not")
+	}
+	go south.Start()
+	deadline := time.Now().Add(2 * time.Second)
+	for !zones.Started() {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the zones started once every zone is")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -2,7 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prasnitt/robot-challenge-prasnitt/internal/api"
@@ -22,18 +27,133 @@ import (
 // @host localhost:8080
 // @BasePath /api/v1
 func main() {
+	config := robot.DefaultConfig()
+	apiConfig := api.DefaultConfig()
+	flag.IntVar(&config.QueueSize, "queue-size", config.QueueSize, "Maximum number of tasks in the queue")
+	flag.StringVar(&config.TaskIDPrefix, "task-id-prefix", config.TaskIDPrefix, "Prefix of every task ID, e.g. whA-")
+	flag.IntVar(&config.MaxTasks, "max-tasks", config.MaxTasks, "Maximum number of stored tasks including finished ones, 0 means unlimited")
+	flag.IntVar(&config.MaxPendingTasks, "max-pending-tasks", config.MaxPendingTasks, "Maximum number of pending tasks, independent of the queue size, 0 means unlimited")
+	flag.BoolVar(&config.AllowEmptyTasks, "allow-empty-tasks", config.AllowEmptyTasks, "Accept tasks without commands, which complete immediately as a no-op")
+	flag.BoolVar(&config.CaseInsensitiveCommands, "case-insensitive-commands", config.CaseInsensitiveCommands, "Accept commands in any case, e.g. 'n e s w', for every task")
+	flag.BoolVar(&config.PreemptOnOverload, "preempt-on-overload", config.PreemptOnOverload, "Abort the running task when the queue is full and a higher priority task arrives")
+	flag.IntVar(&config.MaxSubscribers, "max-subscribers", config.MaxSubscribers, "Maximum number of concurrent event subscribers, 0 means unlimited")
+	flag.IntVar(&config.MaxSubscriberLag, "max-subscriber-lag", config.MaxSubscriberLag, "Number of consecutive events a slow subscriber may miss before it is disconnected, 0 never disconnects it")
+	flag.Float64Var(&config.TimeScale, "time-scale", config.TimeScale, "Run the service time this many times faster, e.g. 10 to play a 10 minute routine in one minute")
+	flag.BoolVar(&config.RejectOffGrid, "reject-off-grid", config.RejectOffGrid, "Reject and abort tasks while the robot is outside the warehouse, e.g. during a manual recovery")
+	flag.IntVar(&config.StepSize, "step-size", config.StepSize, "Number of cells the robot moves per command")
+	flag.IntVar(&config.MaxPathLength, "max-path-length", config.MaxPathLength, "Reject tasks traversing more cells than this, commands times the step size, 0 for no limit")
+	flag.IntVar(&config.BatteryCapacity, "battery-capacity", config.BatteryCapacity, "Number of moves on a full battery, recharged at the origin, 0 disables the battery simulation")
+	flag.IntVar(&config.CommandRetries, "command-retries", config.CommandRetries, "Number of retries for a command blocked by a temporarily occupied cell")
+	flag.DurationVar(&config.CommandRetryBackoff, "command-retry-backoff", config.CommandRetryBackoff, "Delay before the first command retry, doubled for every further retry")
+	flag.BoolVar(&config.MoveEvents, "move-events", config.MoveEvents, "Publish a move event before and after every command for animating the robot")
+	flag.DurationVar(&config.MoveEventDelay, "move-event-delay", config.MoveEventDelay, "Delay between the start and end event of a move")
+	flag.DurationVar(&config.MinSubscribedDelay, "min-subscribed-delay", config.MinSubscribedDelay, "Minimum delay between commands while events have subscribers, so fast tasks cannot flood them, 0 disables it")
+	flag.IntVar(&config.ProgressEveryCommands, "progress-every", config.ProgressEveryCommands, "Publish a progress event every N commands of a running task, 0 disables it")
+	flag.DurationVar(&config.ProgressInterval, "progress-interval", config.ProgressInterval, "Publish a progress event once this much time passed during a running task, 0 disables it")
+	flag.DurationVar(&config.EventCoalesceWindow, "event-coalesce-window", config.EventCoalesceWindow, "Coalesce non-terminal events of a task within this window into the latest one, 0 disables coalescing")
+	flag.BoolVar(&config.StrictSequence, "strict-sequence", config.StrictSequence, "Deliver events in the order of the state changes with gapless sequence numbers, disconnecting subscribers instead of dropping events")
+	flag.BoolVar(&config.DroppedEventsUnhealthy, "dropped-events-unhealthy", config.DroppedEventsUnhealthy, "Report the service as unhealthy in the stats once an event was dropped")
+	flag.StringVar(&config.WebhookURL, "webhook-url", config.WebhookURL, "URL receiving a POST with the terminal event of every task")
+	flag.IntVar(&config.WebhookMaxAttempts, "webhook-max-attempts", config.WebhookMaxAttempts, "Number of delivery attempts per webhook before it is dropped")
+	flag.DurationVar(&config.WebhookRetryBackoff, "webhook-retry-backoff", config.WebhookRetryBackoff, "Delay before the first webhook retry, doubled for every further retry")
+	flag.IntVar(&config.WebhookQueueSize, "webhook-queue-size", config.WebhookQueueSize, "Capacity of the webhook delivery queue")
+	flag.IntVar(&config.EventBufferSize, "event-buffer-size", config.EventBufferSize, "Number of recent events kept for replay on reconnect, 0 disables replay")
+	flag.DurationVar(&config.ThroughputWindow, "throughput-window", config.ThroughputWindow, "Sliding window for the commands per second and tasks per minute statistics")
+	flag.DurationVar(&config.StuckAbortGrace, "stuck-abort-grace", config.StuckAbortGrace, "Abort tasks overrunning their expected run time by more than this grace period, 0 disables auto-abort")
+	flag.DurationVar(&config.CancelGrace, "cancel-grace", config.CancelGrace, "Force tasks still waiting for their cancellation this long after the request to Canceled, 0 disables it")
+	flag.DurationVar(&config.UncancelWindow, "uncancel-window", config.UncancelWindow, "Allow restoring a cancelled pending task this long after the cancel, 0 makes cancels final right away")
+	flag.BoolVar(&apiConfig.RelaxedJSON, "relaxed-json", apiConfig.RelaxedJSON, "Accept trailing commas and // comments in request bodies")
+	flag.BoolVar(&apiConfig.CorrelationIDs, "correlation-ids", apiConfig.CorrelationIDs, "Tag created tasks and their events with the X-Request-ID of the request, generated if missing")
+	flag.BoolVar(&apiConfig.PublicTaskView, "public-task-view", apiConfig.PublicTaskView, "Hide internal task fields such as sequence numbers and raw errors from API responses")
+	flag.BoolVar(&apiConfig.CamelCaseJSON, "camel-case-json", apiConfig.CamelCaseJSON, "Name the fields of JSON responses and WebSocket events in camelCase instead of snake_case")
+	flag.BoolVar(&apiConfig.ReadOnly, "read-only", apiConfig.ReadOnly, "Serve as a read-only observer: mutating endpoints and WebSocket actions are refused")
+	flag.BoolVar(&apiConfig.Debug, "debug", apiConfig.Debug, "Enable development-only endpoints such as forcing the robot position")
+	flag.DurationVar(&apiConfig.ReadTimeout, "read-timeout", apiConfig.ReadTimeout, "Maximum duration for reading an entire request")
+	flag.DurationVar(&apiConfig.WriteTimeout, "write-timeout", apiConfig.WriteTimeout, "Maximum duration before timing out writes of a response")
+	flag.DurationVar(&apiConfig.IdleTimeout, "idle-timeout", apiConfig.IdleTimeout, "Maximum time to wait for the next request on keep-alive connections")
+	flag.DurationVar(&apiConfig.ShutdownTimeout, "shutdown-timeout", apiConfig.ShutdownTimeout, "Time allowed for in-flight requests to finish on shutdown")
+	flag.DurationVar(&apiConfig.HandlerTimeout, "handler-timeout", apiConfig.HandlerTimeout, "Deadline for synchronous handlers, 0 disables it")
+	flag.Func("initial-heading", "Heading of the robot at start and after a reset (N, E, S or W)", func(raw string) error {
+		heading, err := robot.ParseHeading(raw)
+		config.InitialHeading = heading
+		return err
+	})
+	flag.Func("tie-break", "Order of pending tasks with equal priority: 'fifo' (submission order) or 'shortest' (fewest commands first)", func(raw string) error {
+		tieBreak, err := robot.ParseTieBreak(raw)
+		config.TieBreak = tieBreak
+		return err
+	})
+	flag.Func("command-frame", "Frame of N, E, S and W for tasks that do not choose one: 'absolute' (grid directions) or 'relative' (to the robot heading)", func(raw string) error {
+		frame, err := robot.ParseCommandFrame(raw)
+		config.CommandFrame = frame
+		return err
+	})
+	flag.Func("duration-format", "JSON format of command delays: 'string' (e.g. \"1s\") or 'ms' (integer milliseconds)", func(raw string) error {
+		format, err := robot.ParseDurationFormat(raw)
+		robot.SetDurationFormat(format)
+		return err
+	})
+	flag.Func("speed-ramp", "Comma separated extra delays before the first commands of a task while the robot speeds up, e.g. 300ms,200ms,100ms", func(raw string) error {
+		ramp, err := robot.ParseSpeedRamp(raw)
+		config.SpeedRamp = ramp
+		return err
+	})
+	flag.Func("obstacle", "Cell the robot can never enter, as x,y (repeatable)", func(raw string) error {
+		obstacle, err := robot.ParseCoord(raw)
+		config.Obstacles = append(config.Obstacles, obstacle)
+		return err
+	})
+	flag.Func("grid-format", "Format of the grid endpoint when the Accept header allows any: json, text or svg", func(raw string) error {
+		format, err := api.ParseGridFormat(raw)
+		apiConfig.GridFormat = format
+		return err
+	})
+	flag.Func("telemetry-interval", "Time between two robot positions on the telemetry stream, at least 10ms (default 1s)", func(raw string) error {
+		interval, err := api.ParseTelemetryInterval(raw)
+		apiConfig.TelemetryInterval = interval
+		return err
+	})
+	flag.Func("allow-commands", "Commands a client may use in its tasks, as client=commands, e.g. dashboard=N,S, the client sends its name in the X-Client-ID header, other clients may use no command (repeatable)", func(raw string) error {
+		client, allowed, err := api.ParseCommandAllowlist(raw)
+		if apiConfig.CommandAllowlists == nil {
+			apiConfig.CommandAllowlists = make(map[string]robot.RobotCommands)
+		}
+		apiConfig.CommandAllowlists[client] = allowed
+		return err
+	})
+	auditLog := flag.String("audit-log", "", "Append a JSON line for every robot move to this file, '-' for stdout, empty disables the audit log")
+	exitWhenDrained := flag.Bool("exit-when-drained", false, "Shut down once the service has been quiesced and finished its queue")
+	zoneNames := flag.String("zones", "", "Comma separated names of additional independent warehouse zones, served under /api/v1/warehouses/{zone}/robot")
+	flag.Parse()
+
+	if err := config.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	switch *auditLog {
+	case "":
+	case "-":
+		config.AuditLogger = robot.NewJSONAuditLogger(os.Stdout)
+	default:
+		auditFile, err := os.OpenFile(*auditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		defer auditFile.Close()
+		config.AuditLogger = robot.NewJSONAuditLogger(auditFile)
+	}
+
 	log.Println("Robot Warehouse System Starting...")
 
-	// Create a context that can be cancelled
-	ctx, cancel := context.WithCancel(context.Background())
+	// Create a context that is cancelled on SIGINT or SIGTERM to shut down gracefully
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	// Create a buffered channel for robot tasks
-	maxNumTasks := 100 // Maximum number of tasks in the queue
-	taskIdQueue := make(chan string, maxNumTasks)
+	taskIdQueue := make(chan string, config.QueueSize)
 
 	// Initialize the robot service
-	robotService := robot.NewService(ctx, taskIdQueue)
+	robotService := robot.NewServiceWithConfig(ctx, taskIdQueue, config)
 
 	// Start the robot service in a separate goroutine
 	go robotService.Start()
@@ -42,7 +162,20 @@ func main() {
 	router := gin.Default()
 
 	// Setup API routes
-	api.SetupRouter(router, robotService)
+	api.SetupRouter(router, robotService, apiConfig)
+
+	// Close the event subscriptions on shutdown, so WebSocket clients, of the events and the telemetry, get a close frame
+	closers := []func(){robotService.CloseSubscriptions}
+
+	if *zoneNames != "" {
+		zones, err := robot.NewZones(ctx, config, strings.Split(*zoneNames, ",")...)
+		if err != nil {
+			log.Fatalf("Invalid zones: %v", err)
+		}
+		api.SetupZoneRouter(router, zones, apiConfig)
+		closers = append(closers, zones.CloseSubscriptions)
+		log.Printf("Serving warehouse zones: %v", zones.Names())
+	}
 
 	// Swagger documentation route
 	// The url points to the API definition (docs.json or docs.yaml)
@@ -52,8 +185,35 @@ func main() {
 	// TODO: Change the port to a configurable value
 	port := ":8080"
 	log.Printf("Starting server on %s...\n", port)
-	if err := router.Run(port); err != nil {
+	server := api.NewServer(port, router, apiConfig)
+	for _, closer := range closers {
+		server.RegisterOnShutdown(closer)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// A nil channel never fires, so the drained signal is only honored when requested
+	var drained <-chan struct{}
+	if *exitWhenDrained {
+		drained = robotService.Drained()
+	}
+
+	select {
+	case err := <-serverErr:
 		log.Printf("Failed to start server: %v\n", err)
 		return
+	case <-ctx.Done():
+	case <-drained:
+		log.Println("Queue drained after quiesce")
+	}
+
+	log.Println("Shutting down server...")
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), apiConfig.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down server gracefully: %v\n", err)
 	}
 }